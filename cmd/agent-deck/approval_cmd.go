@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleSessionApproval answers the tool permission dialog a session is
+// blocked on: "Yes" for approve, Escape for deny
+func handleSessionApproval(profile string, args []string, approve bool) {
+	verb := "deny"
+	if approve {
+		verb = "approve"
+	}
+	fs := flag.NewFlagSet("session "+verb, flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	var always *bool
+	if approve {
+		always = fs.Bool("always", false, "Pick \"Yes, and don't ask again\" where the prompt offers it")
+	}
+
+	fs.Usage = func() {
+		fmt.Printf("Usage: agent-deck session %s <id|title> [options]\n", verb)
+		fmt.Println()
+		if approve {
+			fmt.Println("Answer \"Yes\" to the tool permission prompt the session is waiting on.")
+		} else {
+			fmt.Println("Reject the tool permission prompt the session is waiting on. The agent")
+			fmt.Println("then waits for you to say what to do instead.")
+		}
+		fmt.Println("Sessions showing a prompt have status needs-approval; 'session show'")
+		fmt.Println("prints the tool and command being asked for.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	// Refresh so the request reported below is what is on screen now
+	_ = inst.UpdateStatus()
+	request := inst.PendingApproval
+
+	if approve {
+		err = inst.Approve(*always)
+	} else {
+		err = inst.Deny()
+	}
+	if errors.Is(err, session.ErrNoPermissionPrompt) {
+		out.Error(fmt.Sprintf("'%s' is not waiting on a permission prompt (status: %s)", inst.Title, StatusString(inst.Status)), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to %s '%s': %v", verb, inst.Title, err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	if err := saveSessionData(storage, instances); err != nil {
+		out.Error(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	result := map[string]interface{}{
+		"success":       true,
+		"session_id":    inst.ID,
+		"session_title": inst.Title,
+		"action":        verb,
+	}
+	message := fmt.Sprintf("Approved prompt in '%s'", inst.Title)
+	if !approve {
+		message = fmt.Sprintf("Denied prompt in '%s'", inst.Title)
+	}
+	if request != nil {
+		result["request"] = approvalJSON(request)
+		message += ": " + request.String()
+	}
+	out.Success(message, result)
+}
+
+// approvalJSON renders a pending permission request for --json output
+func approvalJSON(req *session.ApprovalRequest) map[string]interface{} {
+	data := map[string]interface{}{
+		"tool":        req.Tool,
+		"detected_at": req.DetectedAt.Format(time.RFC3339),
+	}
+	if req.Command != "" {
+		data["command"] = req.Command
+	}
+	return data
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// Exit codes of `session ask` beyond the usual 1 (error) and 2 (not found)
+const (
+	askExitTimeout    = 3
+	askExitPermission = 4
+)
+
+// askPollInterval is how often `session ask` checks the transcript and pane
+const askPollInterval = 500 * time.Millisecond
+
+// Consecutive polls with an unchanged transcript needed before trusting a
+// result: a finished turn must stay finished, and a pending tool call must
+// stay pending while the pane waits for input
+const (
+	askStablePolls     = 2
+	askPermissionPolls = 4
+)
+
+// askTurnJSON adds a turn's output to a --json result
+func askTurnJSON(data map[string]interface{}, turn *session.TranscriptTurn) map[string]interface{} {
+	messages := turn.Messages
+	if messages == nil {
+		messages = []session.TurnMessage{}
+	}
+	toolCalls := turn.ToolCalls
+	if toolCalls == nil {
+		toolCalls = []session.TurnToolCall{}
+	}
+	data["content"] = turn.Text()
+	data["messages"] = messages
+	data["tool_calls"] = toolCalls
+	data["start_offset"] = turn.StartOffset
+	data["end_offset"] = turn.EndOffset
+	return data
+}
+
+// formatAskTurn renders a turn's tool calls and messages for humans
+func formatAskTurn(turn *session.TranscriptTurn) string {
+	var sb strings.Builder
+	for _, call := range turn.ToolCalls {
+		state := "✓"
+		switch {
+		case !call.Done:
+			state = "…"
+		case call.IsError:
+			state = errorSymbol
+		}
+		sb.WriteString(fmt.Sprintf("%s %s\n", state, call.Name))
+	}
+	if len(turn.ToolCalls) > 0 && len(turn.Messages) > 0 {
+		sb.WriteString("---\n")
+	}
+	if text := turn.Text(); text != "" {
+		sb.WriteString(text)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// handleSessionAsk sends a prompt and waits for the turn it starts to finish
+func handleSessionAsk(profile string, args []string) {
+	fs := flag.NewFlagSet("session ask", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Print only the response text")
+	quietShort := fs.Bool("q", false, "Print only the response text (short)")
+	timeout := fs.Duration("timeout", 10*time.Minute, "How long to wait for the turn to finish")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session ask <id|title> <prompt> [options]")
+		fmt.Println()
+		fmt.Println("Send a prompt and wait until the agent's turn completes, as recorded in")
+		fmt.Println("its transcript. Prints exactly the assistant messages and tool calls that")
+		fmt.Println("turn produced. Claude sessions only.")
+		fmt.Println()
+		fmt.Println("Exit codes:")
+		fmt.Println("  0  turn completed")
+		fmt.Println("  1  error (session not running, unsupported tool, agent exited)")
+		fmt.Println("  2  session not found")
+		fmt.Println("  3  timed out (output so far is still returned)")
+		fmt.Println("  4  agent is waiting for permission to run a tool")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session ask my-project \"summarize the open TODOs\"")
+		fmt.Println("  agent-deck session ask my-project \"run the tests\" --timeout 30m --json")
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	prompt := strings.Join(fs.Args()[1:], " ")
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	if inst.Tool != "claude" {
+		out.Error(fmt.Sprintf("session ask needs a transcript to follow the turn; '%s' is a %s session (use send and output)", inst.Title, inst.Tool), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if !inst.Exists() {
+		out.Error(fmt.Sprintf("session '%s' is not running", inst.Title), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if err := inst.BudgetError(); err != nil {
+		out.Error(err.Error(), ErrCodeBudgetExceeded)
+		os.Exit(1)
+	}
+	tmuxSess := inst.GetTmuxSession()
+	if tmuxSess == nil {
+		out.Error("could not determine tmux session", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// The transcript is named after the Claude session ID, which lives in tmux
+	inst.UpdateClaudeSession(nil)
+	transcript := inst.ClaudeTranscriptPath()
+	if transcript == "" {
+		out.Error(fmt.Sprintf("no Claude session ID for '%s' yet; try again once it has started", inst.Title), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	if err := waitForAgentReady(tmuxSess, inst.Tool); err != nil {
+		out.Error(fmt.Sprintf("timeout waiting for agent: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Everything the turn writes lands after this offset
+	offset := session.TranscriptSize(transcript)
+	started := time.Now()
+	if err := sendMessageToTmux(tmuxSess.Name, prompt); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	result := map[string]interface{}{
+		"session_id":    inst.ID,
+		"session_title": inst.Title,
+		"tool":          inst.Tool,
+		"prompt":        prompt,
+	}
+
+	deadline := started.Add(*timeout)
+	turn := &session.TranscriptTurn{StartOffset: offset, EndOffset: offset}
+	lastEnd := int64(-1)
+	stable := 0
+	for time.Now().Before(deadline) {
+		time.Sleep(askPollInterval)
+
+		if !inst.Exists() {
+			out.Error(fmt.Sprintf("session '%s' exited before the turn finished", inst.Title), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+
+		next, err := session.ReadClaudeTurn(transcript, offset)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		turn = next
+		if turn.EndOffset == lastEnd {
+			stable++
+		} else {
+			stable = 0
+			lastEnd = turn.EndOffset
+		}
+
+		status, _ := tmuxSess.GetStatus()
+		if turn.Complete && status != "active" && stable >= askStablePolls {
+			result["success"] = true
+			result["elapsed_sec"] = int(time.Since(started).Seconds())
+			if quietMode {
+				fmt.Println(turn.Text())
+				return
+			}
+			out.Print(formatAskTurn(turn), askTurnJSON(result, turn))
+			return
+		}
+
+		if pending := turn.PendingToolCalls(); len(pending) > 0 && status == "waiting" && stable >= askPermissionPolls {
+			names := make([]string, 0, len(pending))
+			for _, call := range pending {
+				names = append(names, call.Name)
+			}
+			result["success"] = false
+			result["code"] = ErrCodePermissionPrompt
+			result["error"] = fmt.Sprintf("'%s' is waiting for permission to run %s (answer with: agent-deck session approve|deny %s)",
+				inst.Title, strings.Join(names, ", "), inst.ID)
+			result["pending_tool_calls"] = pending
+			result["elapsed_sec"] = int(time.Since(started).Seconds())
+			askFail(out, result, turn, askExitPermission)
+		}
+	}
+
+	result["success"] = false
+	result["code"] = ErrCodeTimeout
+	result["error"] = fmt.Sprintf("turn did not finish within %s", *timeout)
+	result["elapsed_sec"] = int(time.Since(started).Seconds())
+	askFail(out, result, turn, askExitTimeout)
+}
+
+// askFail reports an unfinished turn with whatever it produced so far and exits
+func askFail(out *CLIOutput, result map[string]interface{}, turn *session.TranscriptTurn, code int) {
+	if out.jsonMode {
+		out.printJSON(askTurnJSON(result, turn))
+	} else {
+		if !out.quietMode {
+			fmt.Print(formatAskTurn(turn))
+		}
+		fmt.Fprintf(os.Stderr, "Error: %s\n", result["error"])
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleSessionBroadcast sends one message to every session matching the
+// filter and/or listed in sessionList, and prints a result per session
+func handleSessionBroadcast(profile string, filter session.BroadcastFilter, sessionList, message string, opts session.BroadcastOptions, out *CLIOutput) {
+	if strings.TrimSpace(message) == "" {
+		out.Error("usage: agent-deck session send [--group <path>] [--status <"+strings.Join(session.BroadcastStatuses, "|")+">] [--tool <tool>] [--sessions a,b] <message>", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	// Explicit sessions narrow the candidates; the filter applies on top
+	candidates := instances
+	if sessionList != "" {
+		candidates = nil
+		seen := make(map[string]bool)
+		for _, ref := range strings.Split(sessionList, ",") {
+			if ref = strings.TrimSpace(ref); ref == "" {
+				continue
+			}
+			inst, errMsg, errCode := ResolveSession(ref, instances)
+			if inst == nil {
+				out.Error(errMsg, errCode)
+				if errCode == ErrCodeNotFound {
+					os.Exit(2)
+				}
+				os.Exit(1)
+				return // unreachable, satisfies staticcheck SA5011
+			}
+			if !seen[inst.ID] {
+				seen[inst.ID] = true
+				candidates = append(candidates, inst)
+			}
+		}
+	}
+
+	// Fresh statuses decide both the --status filter and who is mid-turn
+	var wg sync.WaitGroup
+	for _, inst := range candidates {
+		if !(session.BroadcastFilter{Group: filter.Group, Tool: filter.Tool}).Matches(inst) {
+			continue
+		}
+		wg.Add(1)
+		go func(inst *session.Instance) {
+			defer wg.Done()
+			_ = inst.UpdateStatus()
+		}(inst)
+	}
+	wg.Wait()
+
+	var targets []*session.Instance
+	for _, inst := range candidates {
+		if filter.Matches(inst) {
+			targets = append(targets, inst)
+		}
+	}
+	if len(targets) == 0 {
+		out.Error("no sessions match", ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	results := session.Broadcast(targets, message, opts)
+
+	sent := 0
+	var sb strings.Builder
+	resultsJSON := make([]map[string]interface{}, 0, len(results))
+	sb.WriteString(fmt.Sprintf("%-24s %-12s %s\n", "SESSION", "RESULT", "DETAIL"))
+	for _, r := range results {
+		detail := broadcastDetail(r)
+		if r.Outcome == session.BroadcastSent {
+			sent++
+		}
+		sb.WriteString(strings.TrimRight(fmt.Sprintf("%-24s %-12s %s", truncateString(r.Title, 24), r.Outcome, detail), " ") + "\n")
+		entry := map[string]interface{}{
+			"session_id":    r.SessionID,
+			"session_title": r.Title,
+			"result":        r.Outcome,
+		}
+		if detail != "" {
+			entry["detail"] = detail
+		}
+		resultsJSON = append(resultsJSON, entry)
+	}
+	sb.WriteString(fmt.Sprintf("\nSent to %d/%d sessions\n", sent, len(results)))
+
+	out.Print(sb.String(), map[string]interface{}{
+		"success": sent == len(results),
+		"message": message,
+		"sent":    sent,
+		"total":   len(results),
+		"results": resultsJSON,
+	})
+	if sent < len(results) {
+		os.Exit(1)
+	}
+}
+
+// broadcastDetail explains a broadcast result that isn't a plain success
+func broadcastDetail(r session.BroadcastResult) string {
+	switch r.Outcome {
+	case session.BroadcastBusy:
+		return "mid-turn or awaiting approval, not sent (use --force to type anyway)"
+	case session.BroadcastNotRunning:
+		return "session is not running"
+	case session.BroadcastOverBudget:
+		return "over its budget, not sent (override with: agent-deck session budget " + r.Title + " --override)"
+	case session.BroadcastFailed:
+		if r.Err != nil {
+			return r.Err.Error()
+		}
+	}
+	return ""
+}
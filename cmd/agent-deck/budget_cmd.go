@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleSessionBudget shows a session's usage against its budget, or lets
+// an over-budget session continue with --override
+func handleSessionBudget(profile string, args []string) {
+	fs := flag.NewFlagSet("session budget", flag.ExitOnError)
+	override := fs.Bool("override", false, "Clear the budget-exceeded mark and count usage from zero again")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session budget <id|title> [options]")
+		fmt.Println()
+		fmt.Println("Show what a session used against the soft and hard limits of its budget")
+		fmt.Println("([budgets] in config.toml). A session that reached a hard limit is")
+		fmt.Println("interrupted and refuses prompts until overridden.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	if *override {
+		exceeded := inst.BudgetExceeded
+		inst.OverrideBudget()
+		if err := saveSessionData(storage, instances); err != nil {
+			out.Error(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		message := fmt.Sprintf("Budget of '%s' overridden; usage counts from zero again", inst.Title)
+		if exceeded != "" {
+			message += " (was: " + exceeded + ")"
+		}
+		out.Success(message, map[string]interface{}{
+			"success":       true,
+			"session_id":    inst.ID,
+			"session_title": inst.Title,
+			"was_exceeded":  exceeded,
+		})
+		return
+	}
+
+	_ = inst.UpdateStatus()
+	budget := session.GetBudgetSettings().For(inst)
+	usage := inst.BudgetUsage()
+
+	jsonData := map[string]interface{}{
+		"session_id":    inst.ID,
+		"session_title": inst.Title,
+		"status":        StatusString(inst.Status),
+		"usage": map[string]interface{}{
+			"cost":            usage.Cost,
+			"tokens":          usage.Tokens,
+			"turns":           usage.Turns,
+			"runtime_minutes": int(usage.Runtime.Minutes()),
+		},
+		"soft": budget.Soft,
+		"hard": budget.Hard,
+	}
+	if inst.BudgetExceeded != "" {
+		jsonData["exceeded"] = inst.BudgetExceeded
+	}
+	if inst.BudgetWarning != "" {
+		jsonData["warning"] = inst.BudgetWarning
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Session: %s\n", inst.Title))
+	sb.WriteString(fmt.Sprintf("Status:  %s %s\n", StatusSymbol(inst.Status), StatusString(inst.Status)))
+	if inst.BudgetExceeded != "" {
+		sb.WriteString(fmt.Sprintf("Over:    %s\n", inst.BudgetExceeded))
+	} else if inst.BudgetWarning != "" {
+		sb.WriteString(fmt.Sprintf("Warning: %s\n", inst.BudgetWarning))
+	}
+	if budget.IsZero() {
+		sb.WriteString("\nNo budget applies to this session (see [budgets] in config.toml).\n")
+		out.Print(sb.String(), jsonData)
+		return
+	}
+
+	costLimit := func(v float64) string {
+		if v <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("$%.2f", v)
+	}
+	intLimit := func(v int) string {
+		if v <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%d", v)
+	}
+	minutesLimit := func(v int) string {
+		if v <= 0 {
+			return "-"
+		}
+		return (time.Duration(v) * time.Minute).String()
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("%-9s %-12s %-12s %s\n", "", "USED", "SOFT", "HARD"))
+	sb.WriteString(fmt.Sprintf("%-9s %-12s %-12s %s\n", "Cost",
+		fmt.Sprintf("$%.2f", usage.Cost), costLimit(budget.Soft.Cost), costLimit(budget.Hard.Cost)))
+	sb.WriteString(fmt.Sprintf("%-9s %-12d %-12s %s\n", "Tokens",
+		usage.Tokens, intLimit(budget.Soft.Tokens), intLimit(budget.Hard.Tokens)))
+	sb.WriteString(fmt.Sprintf("%-9s %-12d %-12s %s\n", "Turns",
+		usage.Turns, intLimit(budget.Soft.Turns), intLimit(budget.Hard.Turns)))
+	sb.WriteString(fmt.Sprintf("%-9s %-12s %-12s %s\n", "Runtime",
+		usage.Runtime.Round(time.Minute).String(), minutesLimit(budget.Soft.RuntimeMinutes), minutesLimit(budget.Hard.RuntimeMinutes)))
+	if inst.BudgetExceeded != "" {
+		sb.WriteString(fmt.Sprintf("\nContinue with: agent-deck session budget %s --override\n", inst.Title))
+	}
+
+	out.Print(sb.String(), jsonData)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// checkpointJSON renders a checkpoint for --json output
+func checkpointJSON(cp *git.Checkpoint) map[string]interface{} {
+	return map[string]interface{}{
+		"number":     cp.Number,
+		"ref":        cp.Ref,
+		"commit":     cp.Commit,
+		"created_at": cp.Time.Format(time.RFC3339),
+		"prompt":     cp.Subject,
+	}
+}
+
+// handleSessionCheckpoints lists a session's working tree checkpoints
+func handleSessionCheckpoints(profile string, args []string) {
+	fs := flag.NewFlagSet("session checkpoints", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session checkpoints [id|title] [options]")
+		fmt.Println()
+		fmt.Println("List the checkpoints of a session's git working tree, each with the")
+		fmt.Println("prompt that produced it. Checkpoints are taken each time the agent")
+		fmt.Println("finishes a turn when [checkpoints] enabled = true in config.toml.")
+		fmt.Println("If no ID is provided, auto-detects current session.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSessionOrCurrent(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	checkpoints, err := inst.Checkpoints()
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	list := make([]map[string]interface{}, 0, len(checkpoints))
+	var sb strings.Builder
+	if len(checkpoints) == 0 {
+		sb.WriteString(fmt.Sprintf("No checkpoints for '%s'", inst.Title))
+		if !inst.CheckpointsEnabled() {
+			sb.WriteString(" (enable them with [checkpoints] enabled = true in config.toml)")
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("Checkpoints for '%s' (newest first):\n", inst.Title))
+	}
+	for idx := len(checkpoints) - 1; idx >= 0; idx-- {
+		cp := checkpoints[idx]
+		list = append(list, checkpointJSON(&cp))
+		sb.WriteString(fmt.Sprintf("  %3d  %s  %s  %s\n",
+			cp.Number, cp.Time.Format("2006-01-02 15:04:05"), cp.Commit[:7], truncateString(cp.Subject, 60)))
+	}
+	if len(checkpoints) > 0 {
+		sb.WriteString(fmt.Sprintf("\nRestore with: agent-deck session rollback %s <n>\n", TruncateID(inst.ID)))
+	}
+
+	out.Print(sb.String(), map[string]interface{}{
+		"success":     true,
+		"session_id":  inst.ID,
+		"enabled":     inst.CheckpointsEnabled(),
+		"checkpoints": list,
+	})
+}
+
+// handleSessionRollback restores a session's working tree to a checkpoint
+func handleSessionRollback(profile string, args []string) {
+	fs := flag.NewFlagSet("session rollback", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session rollback <id|title> <n> [options]")
+		fmt.Println()
+		fmt.Println("Restore the session's working tree to checkpoint n (see 'session checkpoints').")
+		fmt.Println("The current state is checkpointed first, so the rollback can be undone.")
+		fmt.Println("The index and branch are not touched: restored files show up as changes.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(fs.Arg(1), "#"))
+	if err != nil {
+		out.Error(fmt.Sprintf("invalid checkpoint number: %s", fs.Arg(1)), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	safety, err := inst.RollbackToCheckpoint(n)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to roll back '%s': %v", inst.Title, err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	result := map[string]interface{}{
+		"success":    true,
+		"session_id": inst.ID,
+		"restored":   n,
+	}
+	message := fmt.Sprintf("Restored '%s' to checkpoint %d", inst.Title, n)
+	if safety != nil {
+		result["saved"] = checkpointJSON(safety)
+		message += fmt.Sprintf(" (previous state saved as checkpoint %d)", safety.Number)
+	}
+	out.Success(message, result)
+}
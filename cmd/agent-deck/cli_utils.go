@@ -86,6 +86,9 @@ const (
 	ErrCodeInvalidOperation = "INVALID_OPERATION"
 	ErrCodeGroupNotEmpty    = "GROUP_NOT_EMPTY"
 	ErrCodeMCPNotAvailable  = "MCP_NOT_AVAILABLE"
+	ErrCodeTimeout          = "TIMEOUT"
+	ErrCodePermissionPrompt = "PERMISSION_PROMPT"
+	ErrCodeBudgetExceeded   = "BUDGET_EXCEEDED"
 )
 
 // ResolveSession finds a session by flexible matching (title, ID prefix, or path)
@@ -203,6 +206,10 @@ func StatusSymbol(status session.Status) string {
 		return "●"
 	case session.StatusWaiting:
 		return "◐"
+	case session.StatusNeedsApproval:
+		return "◆"
+	case session.StatusBudgetExceeded:
+		return "⊘"
 	case session.StatusIdle:
 		return "○"
 	case session.StatusError:
@@ -219,6 +226,10 @@ func StatusString(status session.Status) string {
 		return "running"
 	case session.StatusWaiting:
 		return "waiting"
+	case session.StatusNeedsApproval:
+		return "needs-approval"
+	case session.StatusBudgetExceeded:
+		return "budget-exceeded"
 	case session.StatusIdle:
 		return "idle"
 	case session.StatusError:
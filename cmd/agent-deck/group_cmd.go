@@ -123,7 +123,7 @@ func handleGroupList(profile string, args []string) {
 						switch sess.Status {
 						case session.StatusRunning:
 							status.Running++
-						case session.StatusWaiting:
+						case session.StatusWaiting, session.StatusNeedsApproval, session.StatusBudgetExceeded:
 							status.Waiting++
 						case session.StatusIdle:
 							status.Idle++
@@ -253,7 +253,7 @@ func handleGroupList(profile string, args []string) {
 						switch sess.Status {
 						case session.StatusRunning:
 							running++
-						case session.StatusWaiting:
+						case session.StatusWaiting, session.StatusNeedsApproval, session.StatusBudgetExceeded:
 							waiting++
 						case session.StatusIdle:
 							idle++
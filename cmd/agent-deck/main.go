@@ -213,6 +213,9 @@ func main() {
 		case "mcp":
 			handleMCP(profile, args[1:])
 			return
+		case "secrets":
+			handleSecrets(args[1:])
+			return
 		case "group":
 			handleGroup(profile, args[1:])
 			return
@@ -222,6 +225,27 @@ func main() {
 		case "worktree", "wt":
 			handleWorktree(profile, args[1:])
 			return
+		case "race":
+			handleRace(profile, args[1:])
+			return
+		case "pipeline":
+			handlePipeline(profile, args[1:])
+			return
+		case "schedule":
+			handleSchedule(profile, args[1:])
+			return
+		case "scheduler":
+			handleScheduler(profile, args[1:])
+			return
+		case "trust":
+			handleTrust(args[1:])
+			return
+		case "repo":
+			handleRepo(args[1:])
+			return
+		case "report":
+			handleReport(args[1:])
+			return
 		case "uninstall":
 			handleUninstall(args[1:])
 			return
@@ -319,6 +343,10 @@ func main() {
 	session.StartMaintenanceWorker(maintenanceCtx, func(result session.MaintenanceResult) {
 		p.Send(ui.MaintenanceCompleteMsg{Result: result})
 	})
+	// Run scheduled jobs unless another process already does for this profile
+	session.StartScheduler(maintenanceCtx, profile, func(run *session.ScheduleRun) {
+		p.Send(ui.ScheduleRunMsg{Run: run})
+	})
 
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v\n", err)
@@ -378,7 +406,8 @@ func reorderArgsForFlagParsing(args []string) []string {
 		"-p": true, "--parent": true,
 		"--mcp": true,
 		"-w": true, "--worktree": true,
-		"--location": true,
+		"--location": true, "--timeout": true, "--var": true,
+		"--status": true, "--tool": true, "--sessions": true,
 	}
 
 	var flags []string
@@ -552,6 +581,10 @@ func handleAdd(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	// A repo's .agent-deck.toml only applies once trusted
+	checkRepoConfigTrust(path, true)
+	repoConfig := session.LoadEffectiveConfig(path)
+
 	// Resolve worktree flags
 	wtBranch := *worktreeBranch
 	if *worktreeBranchLong != "" {
@@ -589,7 +622,7 @@ func handleAdd(profile string, args []string) {
 		}
 
 		// Determine worktree location: CLI flag overrides config
-		wtSettings := session.GetWorktreeSettings()
+		wtSettings := repoConfig.WorktreeSettings()
 		location := wtSettings.DefaultLocation
 		if *worktreeLocation != "" {
 			location = *worktreeLocation
@@ -627,6 +660,21 @@ func handleAdd(profile string, args []string) {
 	sessionTitle := mergeFlags(*title, *titleShort)
 	sessionGroup := mergeFlags(*group, *groupShort)
 	sessionCommand := mergeFlags(*command, *commandShort)
+	if sessionCommand == "" && repoConfig.Repo != nil && repoConfig.Repo.Trusted {
+		// The repo's default_tool; the global one only preselects the TUI dialog
+		sessionCommand = repoConfig.DefaultTool
+	}
+	if _, err := os.Stat(filepath.Join(path, ".mcp.json")); len(mcpFlags) == 0 && os.IsNotExist(err) {
+		// The repo's default MCPs, skipping any this machine doesn't define
+		availableMCPs := session.GetAvailableMCPs()
+		for _, name := range repoConfig.DefaultMCPs {
+			if _, exists := availableMCPs[name]; exists {
+				mcpFlags = append(mcpFlags, name)
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: MCP '%s' from %s not found in config.toml\n", name, session.RepoConfigFileName)
+			}
+		}
+	}
 	sessionParent := mergeFlags(*parent, *parentShort)
 
 	// Default title to folder name
@@ -709,6 +757,10 @@ func handleAdd(profile string, args []string) {
 		newInstance.WorktreePath = worktreePath
 		newInstance.WorktreeRepoRoot = worktreeRepoRoot
 		newInstance.WorktreeBranch = wtBranch
+		if err := newInstance.PrepareWorktree(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to set up worktree: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	// Add to instances
@@ -741,9 +793,25 @@ func handleAdd(profile string, args []string) {
 			}
 		}
 
-		// Write MCPs to .mcp.json
-		if err := session.WriteMCPJsonFromConfig(path, mcpFlags); err != nil {
-			fmt.Printf("Error: failed to write MCPs: %v\n", err)
+		// Write MCPs to .mcp.json, or to the tool's own config for Gemini/Codex/OpenCode
+		var mcpErr error
+		if session.ToolMCPScope(newInstance.Tool) != "" {
+			names := session.GetToolMCPNames(newInstance.Tool, path)
+			attached := make(map[string]bool, len(names))
+			for _, name := range names {
+				attached[name] = true
+			}
+			for _, name := range mcpFlags {
+				if !attached[name] {
+					names = append(names, name)
+				}
+			}
+			mcpErr = session.WriteToolMCPs(newInstance.Tool, path, names)
+		} else {
+			mcpErr = session.WriteMCPJsonFromConfig(path, mcpFlags)
+		}
+		if mcpErr != nil {
+			fmt.Printf("Error: failed to write MCPs: %v\n", mcpErr)
 			os.Exit(1)
 		}
 	}
@@ -1061,7 +1129,7 @@ func countByStatus(instances []*session.Instance) statusCounts {
 		switch inst.Status {
 		case session.StatusRunning:
 			counts.running++
-		case session.StatusWaiting:
+		case session.StatusWaiting, session.StatusNeedsApproval, session.StatusBudgetExceeded:
 			counts.waiting++
 		case session.StatusIdle:
 			counts.idle++
@@ -1171,6 +1239,8 @@ func handleStatus(profile string, args []string) {
 			fmt.Println()
 		}
 
+		printStatusGroup("NEEDS APPROVAL", "◆", session.StatusNeedsApproval)
+		printStatusGroup("OVER BUDGET", "⊘", session.StatusBudgetExceeded)
 		printStatusGroup("WAITING", "◐", session.StatusWaiting)
 		printStatusGroup("RUNNING", "●", session.StatusRunning)
 		printStatusGroup("IDLE", "○", session.StatusIdle)
@@ -1407,8 +1477,16 @@ func printHelp() {
 	fmt.Println("  status           Show session status summary")
 	fmt.Println("  session          Manage session lifecycle")
 	fmt.Println("  mcp              Manage MCP servers")
+	fmt.Println("  secrets          Manage the encrypted MCP secrets vault")
 	fmt.Println("  group            Manage groups")
 	fmt.Println("  worktree, wt     Manage git worktrees")
+	fmt.Println("  race             Race agents on one prompt in separate worktrees")
+	fmt.Println("  pipeline         Hand work from session to session in steps")
+	fmt.Println("  schedule         Send prompts to sessions on a cron schedule")
+	fmt.Println("  scheduler        Run scheduled jobs without the TUI")
+	fmt.Println("  trust [path]     Trust a repository's .agent-deck.toml")
+	fmt.Println("  repo status      Worktrees and sessions of a repo across profiles")
+	fmt.Println("  report           Usage and estimated cost by project, group, tool, model or day")
 	fmt.Println("  profile          Manage profiles")
 	fmt.Println("  update           Check for and install updates")
 	fmt.Println("  uninstall        Uninstall Agent Deck")
@@ -1425,9 +1503,12 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println("MCP Commands:")
 	fmt.Println("  mcp list                  List available MCPs from config.toml")
+	fmt.Println("  mcp add|edit|rm <name>    Manage MCP definitions in config.toml")
+	fmt.Println("  mcp import                Import MCPs from Claude/Gemini/.mcp.json")
 	fmt.Println("  mcp attached [id]         Show MCPs attached to a session")
 	fmt.Println("  mcp attach <id> <mcp>     Attach MCP to session")
 	fmt.Println("  mcp detach <id> <mcp>     Detach MCP from session")
+	fmt.Println("  mcp pool start|status     Run the MCP socket pool as its own process")
 	fmt.Println()
 	fmt.Println("Group Commands:")
 	fmt.Println("  group list                List all groups")
@@ -1439,6 +1520,23 @@ func printHelp() {
 	fmt.Println("  worktree list             List worktrees with session associations")
 	fmt.Println("  worktree info <session>   Show worktree info for a session")
 	fmt.Println("  worktree cleanup          Find and remove orphaned worktrees/sessions")
+	fmt.Println("  worktree finish <session> Land a session's branch and remove its worktree")
+	fmt.Println()
+	fmt.Println("Race Commands:")
+	fmt.Println("  race --prompt <text>      Start one session per tool, each in its own worktree")
+	fmt.Println("  race compare <race>       Compare diffs, checks, cost and time")
+	fmt.Println("  race promote <race> <c>   Land the winner and clean up the rest")
+	fmt.Println()
+	fmt.Println("Pipeline Commands:")
+	fmt.Println("  pipeline run <file.toml>  Send each step's prompt and pass outputs on")
+	fmt.Println("  pipeline resume <run>     Continue an interrupted or failed run")
+	fmt.Println("  pipeline status <run>     Show a run's steps and outputs")
+	fmt.Println()
+	fmt.Println("Schedule Commands:")
+	fmt.Println("  schedule add <name> <p>   Send a prompt on a cron schedule")
+	fmt.Println("  schedule list             List jobs with their next and last runs")
+	fmt.Println("  schedule run-now <name>   Run a job immediately")
+	fmt.Println("  schedule history <name>   Show past runs and their output")
 	fmt.Println()
 	fmt.Println("Profile Commands:")
 	fmt.Println("  profile list              List all profiles")
@@ -28,3 +28,16 @@ func TestHomeView(t *testing.T) {
 		t.Error("View() returned empty string")
 	}
 }
+
+func TestReorderArgsForFlagParsing_SendFilters(t *testing.T) {
+	got := reorderArgsForFlagParsing([]string{"pull main", "--status", "waiting", "--tool", "claude", "--sessions", "a,b", "--force"})
+	want := []string{"--status", "waiting", "--tool", "claude", "--sessions", "a,b", "--force", "pull main"}
+	if len(got) != len(want) {
+		t.Fatalf("reorderArgsForFlagParsing() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("reorderArgsForFlagParsing() = %q, want %q", got, want)
+		}
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 )
 
@@ -22,6 +24,14 @@ func handleMCP(profile string, args []string) {
 	switch args[0] {
 	case "list", "ls":
 		handleMCPList(args[1:])
+	case "add":
+		handleMCPAdd(args[1:])
+	case "edit":
+		handleMCPEdit(args[1:])
+	case "remove", "rm":
+		handleMCPRemove(args[1:])
+	case "import":
+		handleMCPImport(args[1:])
 	case "attached":
 		handleMCPAttached(profile, args[1:])
 	case "attach":
@@ -30,6 +40,12 @@ func handleMCP(profile string, args []string) {
 		handleMCPDetach(profile, args[1:])
 	case "server":
 		handleMCPServer(args[1:])
+	case "pool":
+		handleMCPPool(args[1:])
+	case "exec":
+		handleMCPExec(args[1:])
+	case "env":
+		handleMCPEnv(args[1:])
 	case "help", "-h", "--help":
 		printMCPHelp()
 	default:
@@ -47,13 +63,22 @@ func printMCPHelp() {
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  list                List all available MCPs from config.toml")
+	fmt.Println("  add <name>          Add an MCP to config.toml (prompts when no command is given)")
+	fmt.Println("  edit <name>         Change an MCP in config.toml")
+	fmt.Println("  remove <name>       Remove an MCP from config.toml")
+	fmt.Println("  import              Import MCPs from Claude, project .mcp.json and Gemini configs")
 	fmt.Println("  attached [id]       Show MCPs attached to a session")
 	fmt.Println("  attach <id> <mcp>   Attach an MCP to a session")
 	fmt.Println("  detach <id> <mcp>   Detach an MCP from a session")
 	fmt.Println("  server <cmd>        Manage HTTP MCP servers (start/stop/status)")
+	fmt.Println("  pool <cmd>          Manage the standalone MCP socket pool (start/stop/status/restart)")
+	fmt.Println("  exec <mcp>          Run a stdio MCP with vault:/env: secrets resolved (used by generated configs)")
+	fmt.Println("  env                 Print exports for HTTP MCP header secrets (used by sessions)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  agent-deck mcp list                        # List available MCPs")
+	fmt.Println("  agent-deck mcp add exa -- npx -y exa-mcp-server   # Add a stdio MCP")
+	fmt.Println("  agent-deck mcp import --dry-run            # Preview MCPs found in other tools")
 	fmt.Println("  agent-deck mcp attached                    # Show MCPs for current session")
 	fmt.Println("  agent-deck mcp attached my-project         # Show MCPs for specific session")
 	fmt.Println("  agent-deck mcp attach my-project exa       # Attach exa to my-project (local)")
@@ -61,6 +86,7 @@ func printMCPHelp() {
 	fmt.Println("  agent-deck mcp detach my-project exa       # Detach exa from my-project")
 	fmt.Println("  agent-deck mcp server status               # Show HTTP server status")
 	fmt.Println("  agent-deck mcp server start slack          # Start HTTP server for slack MCP")
+	fmt.Println("  agent-deck mcp pool start                  # Run the socket pool independently of the TUI")
 }
 
 // handleMCPList lists all available MCPs from config.toml
@@ -252,6 +278,38 @@ func handleMCPAttached(profile string, args []string) {
 		return // unreachable, satisfies staticcheck SA5011
 	}
 
+	// Other tools keep MCPs in a single config of their own
+	if toolScope := session.ToolMCPScope(inst.Tool); toolScope != "" {
+		names := session.GetToolMCPNames(inst.Tool, inst.ProjectPath)
+		configPath := session.ToolMCPConfigPath(inst.Tool, inst.ProjectPath)
+		if *jsonOutput {
+			out.Print("", map[string]interface{}{
+				"session":    inst.Title,
+				"session_id": TruncateID(inst.ID),
+				"tool":       inst.Tool,
+				toolScope:    names,
+				"config":     configPath,
+			})
+			return
+		}
+		if quietMode {
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return
+		}
+		fmt.Printf("Session: %s\n\n", inst.Title)
+		if len(names) == 0 {
+			fmt.Println("No MCPs attached to this session.")
+			return
+		}
+		fmt.Printf("%s (%s):\n", strings.ToUpper(toolScope), FormatPath(configPath))
+		for _, name := range names {
+			fmt.Printf("  %s %s\n", bulletSymbol, name)
+		}
+		return
+	}
+
 	// Get MCP info for this session
 	mcpInfo := session.GetMCPInfo(inst.ProjectPath)
 	globalMCPs := mcpInfo.Global
@@ -339,13 +397,14 @@ func handleMCPAttach(profile string, args []string) {
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("quiet", false, "Minimal output")
 	quietShort := fs.Bool("q", false, "Minimal output (short)")
-	global := fs.Bool("global", false, "Attach to global config instead of local .mcp.json")
+	global := fs.Bool("global", false, "Attach to global config instead of local .mcp.json (Claude only)")
 	restart := fs.Bool("restart", false, "Restart session to load MCP immediately")
 
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck mcp attach <session-id> <mcp-name> [options]")
 		fmt.Println()
 		fmt.Println("Attach an MCP to a session.")
+		fmt.Println("Gemini and Codex sessions use their global config; OpenCode uses the project's opencode.json.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -415,7 +474,21 @@ func handleMCPAttach(profile string, args []string) {
 	}
 
 	// Attach the MCP
-	if *global {
+	if toolScope := session.ToolMCPScope(inst.Tool); toolScope != "" {
+		// Gemini/Codex/OpenCode keep MCPs in a single config of their own
+		scope = toolScope
+		current := session.GetToolMCPNames(inst.Tool, inst.ProjectPath)
+		for _, name := range current {
+			if name == mcpName {
+				out.Error(fmt.Sprintf("MCP '%s' is already attached to %s", mcpName, inst.Tool), ErrCodeAlreadyExists)
+				os.Exit(1)
+			}
+		}
+		if err := session.WriteToolMCPs(inst.Tool, inst.ProjectPath, append(current, mcpName)); err != nil {
+			out.Error(fmt.Sprintf("failed to write %s MCP config: %v", inst.Tool, err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+	} else if *global {
 		// Add to global config
 		currentGlobal := session.GetGlobalMCPNames()
 		// Check if already attached
@@ -454,7 +527,7 @@ func handleMCPAttach(profile string, args []string) {
 
 	// Restart if requested
 	restarted := false
-	if *restart && (inst.Tool == "claude" || inst.Tool == "gemini") {
+	if *restart && session.ToolSupportsMCP(inst.Tool) {
 		if err := inst.Restart(); err != nil {
 			// Don't fail the whole operation, just warn
 			if !*jsonOutput && !quietMode {
@@ -462,7 +535,7 @@ func handleMCPAttach(profile string, args []string) {
 			}
 		} else {
 			restarted = true
-			// Auto-continue: wait for Claude/Gemini to initialize, then send continue message
+			// Auto-continue: wait for the agent to initialize, then send continue message
 			time.Sleep(2 * time.Second)
 			if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil {
 				// Send "continue" and Enter to resume the conversation
@@ -496,13 +569,14 @@ func handleMCPDetach(profile string, args []string) {
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("quiet", false, "Minimal output")
 	quietShort := fs.Bool("q", false, "Minimal output (short)")
-	global := fs.Bool("global", false, "Remove from global config instead of local .mcp.json")
+	global := fs.Bool("global", false, "Remove from global config instead of local .mcp.json (Claude only)")
 	restart := fs.Bool("restart", false, "Restart session to unload MCP immediately")
 
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck mcp detach <session-id> <mcp-name> [options]")
 		fmt.Println()
 		fmt.Println("Detach an MCP from a session.")
+		fmt.Println("Gemini and Codex sessions use their global config; OpenCode uses the project's opencode.json.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -559,7 +633,28 @@ func handleMCPDetach(profile string, args []string) {
 	}
 
 	// Detach the MCP
-	if *global {
+	if toolScope := session.ToolMCPScope(inst.Tool); toolScope != "" {
+		// Gemini/Codex/OpenCode keep MCPs in a single config of their own
+		scope = toolScope
+		current := session.GetToolMCPNames(inst.Tool, inst.ProjectPath)
+		found := false
+		remaining := make([]string, 0, len(current))
+		for _, name := range current {
+			if name == mcpName {
+				found = true
+			} else {
+				remaining = append(remaining, name)
+			}
+		}
+		if !found {
+			out.Error(fmt.Sprintf("MCP '%s' is not attached to %s", mcpName, inst.Tool), ErrCodeNotFound)
+			os.Exit(2)
+		}
+		if err := session.WriteToolMCPs(inst.Tool, inst.ProjectPath, remaining); err != nil {
+			out.Error(fmt.Sprintf("failed to write %s MCP config: %v", inst.Tool, err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+	} else if *global {
 		// Remove from global config
 		currentGlobal := session.GetGlobalMCPNames()
 		found := false
@@ -607,7 +702,7 @@ func handleMCPDetach(profile string, args []string) {
 
 	// Restart if requested
 	restarted := false
-	if *restart && (inst.Tool == "claude" || inst.Tool == "gemini") {
+	if *restart && session.ToolSupportsMCP(inst.Tool) {
 		if err := inst.Restart(); err != nil {
 			// Don't fail the whole operation, just warn
 			if !*jsonOutput && !quietMode {
@@ -615,7 +710,7 @@ func handleMCPDetach(profile string, args []string) {
 			}
 		} else {
 			restarted = true
-			// Auto-continue: wait for Claude/Gemini to initialize, then send continue message
+			// Auto-continue: wait for the agent to initialize, then send continue message
 			time.Sleep(2 * time.Second)
 			if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil {
 				// Send "continue" and Enter to resume the conversation
@@ -822,6 +917,8 @@ func handleMCPServerStop(args []string) {
 // handleMCPServerStatus shows HTTP MCP server status
 func handleMCPServerStatus(args []string) {
 	fs := flag.NewFlagSet("mcp server status", flag.ExitOnError)
+	noProbe := fs.Bool("no-probe", false, "Skip the protocol-level health probe")
+	probeTimeout := fs.Duration("timeout", mcppool.DefaultProbeTimeout, "Health probe timeout")
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("quiet", false, "Minimal output")
 	quietShort := fs.Bool("q", false, "Minimal output (short)")
@@ -829,7 +926,9 @@ func handleMCPServerStatus(args []string) {
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck mcp server status [mcp-name]")
 		fmt.Println()
-		fmt.Println("Show HTTP MCP server status.")
+		fmt.Println("Show HTTP MCP server status. Each endpoint is probed with initialize and")
+		fmt.Println("tools/list, so a server that accepts connections but never answers shows")
+		fmt.Println("as unhealthy.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -855,6 +954,8 @@ func handleMCPServerStatus(args []string) {
 		Status      string `json:"status"`
 		StartedByUs bool   `json:"started_by_us"`
 		HasServer   bool   `json:"has_server_config"`
+
+		Health *mcppool.ProbeResult `json:"health,omitempty"`
 	}
 
 	var servers []serverInfo
@@ -905,6 +1006,36 @@ func handleMCPServerStatus(args []string) {
 		os.Exit(2)
 	}
 
+	// Probe endpoints in parallel; reuse the pool's latest probe when this process runs one
+	if !*noProbe {
+		var wg sync.WaitGroup
+		for i := range servers {
+			s := &servers[i]
+			if httpPool != nil {
+				if server := httpPool.GetServer(s.Name); server != nil && server.LastProbe() != nil {
+					s.Health = server.LastProbe()
+					continue
+				}
+			}
+			if s.Transport == "sse" {
+				continue // Legacy SSE endpoints don't accept JSON-RPC POSTs
+			}
+			def := availableMCPs[s.Name]
+			headers, err := session.ResolveMCPHeaders(&def, "")
+			if err != nil {
+				s.Health = &mcppool.ProbeResult{Tools: -1, Error: err.Error(), CheckedAt: time.Now()}
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result := mcppool.ProbeHTTP(s.URL, headers, *probeTimeout)
+				s.Health = &result
+			}()
+		}
+		wg.Wait()
+	}
+
 	if *jsonOutput {
 		out.Print("", map[string]interface{}{
 			"servers": servers,
@@ -935,8 +1066,8 @@ func handleMCPServerStatus(args []string) {
 	// Human-readable table output
 	fmt.Println("HTTP MCP Servers:")
 	fmt.Println()
-	fmt.Printf("%-15s %-10s %-12s %-35s %s\n", "NAME", "TRANSPORT", "STATUS", "URL", "SERVER CONFIG")
-	fmt.Println(strings.Repeat("-", 90))
+	fmt.Printf("%-15s %-10s %-15s %-35s %-7s %s\n", "NAME", "TRANSPORT", "STATUS", "URL", "SERVER", "HEALTH")
+	fmt.Println(strings.Repeat("-", 110))
 
 	for _, s := range servers {
 		statusDisplay := s.Status
@@ -951,12 +1082,18 @@ func handleMCPServerStatus(args []string) {
 			serverConfig = "yes"
 		}
 
-		fmt.Printf("%-15s %-10s %-12s %-35s %s\n",
+		health := "-"
+		if s.Health != nil {
+			health = s.Health.Summary()
+		}
+
+		fmt.Printf("%-15s %-10s %-15s %-35s %-7s %s\n",
 			truncateString(s.Name, 15),
 			s.Transport,
 			statusDisplay,
 			truncateString(s.URL, 35),
 			serverConfig,
+			health,
 		)
 	}
 
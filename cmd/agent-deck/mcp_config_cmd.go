@@ -0,0 +1,484 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// keyValueFlag collects repeated KEY=VALUE flags
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f keyValueFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", value)
+	}
+	f[strings.TrimSpace(key)] = val
+	return nil
+}
+
+// mcpDefFlags are the flags shared by "mcp add" and "mcp edit"
+type mcpDefFlags struct {
+	url         *string
+	transport   *string
+	description *string
+	env         keyValueFlag
+	headers     keyValueFlag
+}
+
+func addMCPDefFlags(fs *flag.FlagSet) *mcpDefFlags {
+	f := &mcpDefFlags{env: keyValueFlag{}, headers: keyValueFlag{}}
+	f.url = fs.String("url", "", "Endpoint for HTTP/SSE MCPs")
+	f.transport = fs.String("transport", "", "Transport: stdio, http or sse")
+	f.description = fs.String("description", "", "Description shown in the MCP Manager")
+	fs.Var(f.env, "env", "Environment variable KEY=VALUE (repeatable; VALUE may be vault:NAME or env:NAME)")
+	fs.Var(f.headers, "header", "HTTP header KEY=VALUE (repeatable)")
+	return f
+}
+
+// apply copies flags that were set on the command line into def.
+// An empty VALUE for --env/--header removes the key.
+func (f *mcpDefFlags) apply(fs *flag.FlagSet, def *session.MCPDef) {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "url":
+			def.URL = *f.url
+		case "transport":
+			def.Transport = *f.transport
+		case "description":
+			def.Description = *f.description
+		}
+	})
+	def.Env = mergeKeyValues(def.Env, f.env)
+	def.Headers = mergeKeyValues(def.Headers, f.headers)
+	if def.URL != "" && def.Transport == "" {
+		def.Transport = "http"
+	}
+}
+
+func mergeKeyValues(dst map[string]string, src keyValueFlag) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	merged := make(map[string]string, len(dst)+len(src))
+	for k, v := range dst {
+		merged[k] = v
+	}
+	for k, v := range src {
+		if v == "" {
+			delete(merged, k)
+		} else {
+			merged[k] = v
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// splitCommandArgs returns the command and args following the MCP name,
+// accepting an optional "--" separator
+func splitCommandArgs(rest []string) (string, []string) {
+	if len(rest) > 0 && rest[0] == "--" {
+		rest = rest[1:]
+	}
+	if len(rest) == 0 {
+		return "", nil
+	}
+	return rest[0], rest[1:]
+}
+
+// handleMCPAdd adds an MCP definition to config.toml
+func handleMCPAdd(args []string) {
+	fs := flag.NewFlagSet("mcp add", flag.ExitOnError)
+	defFlags := addMCPDefFlags(fs)
+	force := fs.Bool("force", false, "Replace an existing MCP with the same name")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp add [options] <name> [-- <command> [args...]]")
+		fmt.Println()
+		fmt.Println("Add an MCP to config.toml. Run with only a name to be prompted for each field.")
+		fmt.Println("Options must come before the name.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck mcp add exa -- npx -y exa-mcp-server")
+		fmt.Println("  agent-deck mcp add --env GITHUB_TOKEN=vault:github_token github -- npx -y @modelcontextprotocol/server-github")
+		fmt.Println("  agent-deck mcp add --url https://mcp.example.com/mcp --header Authorization=env:MCP_TOKEN remote")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		out.Error("MCP name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	name := rest[0]
+	if err := session.ValidateMCPName(name); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if session.GetMCPDef(name) != nil && !*force {
+		out.Error(fmt.Sprintf("MCP '%s' already exists (use --force to replace or 'mcp edit')", name), ErrCodeAlreadyExists)
+		os.Exit(1)
+	}
+
+	var def session.MCPDef
+	def.Command, def.Args = splitCommandArgs(rest[1:])
+	defFlags.apply(fs, &def)
+
+	if fs.NFlag() == 0 && def.Command == "" {
+		if *jsonOutput || !term.IsTerminal(int(os.Stdin.Fd())) {
+			out.Error("a command (after --) or --url is required", ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		promptMCPDef(bufio.NewReader(os.Stdin), &def)
+	}
+
+	if err := session.SetUserMCP(name, def); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Added MCP '%s' to config.toml", name), map[string]interface{}{
+		"success": true,
+		"name":    name,
+		"mcp":     mcpDefJSON(name, def),
+	})
+}
+
+// handleMCPEdit updates an existing MCP definition in config.toml
+func handleMCPEdit(args []string) {
+	fs := flag.NewFlagSet("mcp edit", flag.ExitOnError)
+	defFlags := addMCPDefFlags(fs)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp edit [options] <name> [-- <command> [args...]]")
+		fmt.Println()
+		fmt.Println("Edit an MCP in config.toml. Only the given fields change; a command after")
+		fmt.Println("-- replaces the command and args. With no changes you are prompted for each")
+		fmt.Println("field, pre-filled with the current value. Use --env KEY= to remove a variable.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		out.Error("MCP name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	name := rest[0]
+	current := session.GetMCPDef(name)
+	if current == nil {
+		out.Error(fmt.Sprintf("MCP '%s' not found in config.toml", name), ErrCodeMCPNotAvailable)
+		os.Exit(2)
+	}
+
+	def := *current
+	if command, cmdArgs := splitCommandArgs(rest[1:]); command != "" {
+		def.Command, def.Args = command, cmdArgs
+	}
+	defFlags.apply(fs, &def)
+
+	if fs.NFlag() == 0 && len(rest) == 1 {
+		if *jsonOutput || !term.IsTerminal(int(os.Stdin.Fd())) {
+			out.Error("nothing to change: pass options or a command after --", ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		promptMCPDef(bufio.NewReader(os.Stdin), &def)
+	}
+
+	if err := session.SetUserMCP(name, def); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Updated MCP '%s'", name), map[string]interface{}{
+		"success": true,
+		"name":    name,
+		"mcp":     mcpDefJSON(name, def),
+	})
+}
+
+// handleMCPRemove deletes an MCP definition from config.toml
+func handleMCPRemove(args []string) {
+	fs := flag.NewFlagSet("mcp remove", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp remove [options] <name>")
+		fmt.Println()
+		fmt.Println("Remove an MCP from config.toml. Sessions that have it attached keep their")
+		fmt.Println("current config until the MCP is detached.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() == 0 {
+		out.Error("MCP name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+	if session.GetMCPDef(name) == nil {
+		out.Error(fmt.Sprintf("MCP '%s' not found in config.toml", name), ErrCodeMCPNotAvailable)
+		os.Exit(2)
+	}
+
+	if err := session.RemoveUserMCP(name); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Removed MCP '%s' from config.toml", name), map[string]interface{}{
+		"success": true,
+		"name":    name,
+	})
+}
+
+// handleMCPImport merges MCP definitions from Claude, project and Gemini configs
+func handleMCPImport(args []string) {
+	fs := flag.NewFlagSet("mcp import", flag.ExitOnError)
+	from := fs.String("from", strings.Join(session.MCPImportSources, ","), "Comma-separated sources: claude, project, gemini")
+	project := fs.String("project", "", "Directory to search for .mcp.json (default: current directory)")
+	dryRun := fs.Bool("dry-run", false, "Show what would be imported without changing config.toml")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp import [options]")
+		fmt.Println()
+		fmt.Println("Import MCP definitions into config.toml from:")
+		fmt.Println("  claude    ~/.claude.json and <claude config dir>/.claude.json (mcpServers)")
+		fmt.Println("  project   .mcp.json in the project directory and its parents up to the repo root")
+		fmt.Println("  gemini    ~/.gemini/settings.json (mcpServers)")
+		fmt.Println()
+		fmt.Println("MCPs with the same command and args (or URL) as an existing one are skipped,")
+		fmt.Println("as are names already used by a different definition.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	var sources []string
+	for _, s := range strings.Split(*from, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			sources = append(sources, s)
+		}
+	}
+
+	projectDir := *project
+	if projectDir == "" {
+		projectDir, _ = os.Getwd()
+	}
+
+	found, err := session.DiscoverImportableMCPs(sources, projectDir)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	results, err := session.ImportMCPs(found, *dryRun)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to import MCPs: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	added := 0
+	for _, r := range results {
+		if r.Status == session.MCPImportAdded {
+			added++
+		}
+	}
+
+	if *jsonOutput {
+		type resultJSON struct {
+			Name        string `json:"name"`
+			Source      string `json:"source"`
+			Path        string `json:"path"`
+			Status      string `json:"status"`
+			DuplicateOf string `json:"duplicate_of,omitempty"`
+		}
+		items := make([]resultJSON, 0, len(results))
+		for _, r := range results {
+			items = append(items, resultJSON{r.Name, r.Source, r.Path, r.Status, r.DuplicateOf})
+		}
+		out.Print("", map[string]interface{}{
+			"dry_run": *dryRun,
+			"added":   added,
+			"results": items,
+		})
+		return
+	}
+	if quietMode {
+		return
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No MCP definitions found to import.")
+		return
+	}
+
+	fmt.Printf("%-8s %-20s %-8s %s\n", "STATUS", "NAME", "SOURCE", "NOTE")
+	fmt.Println(strings.Repeat("-", 70))
+	for _, r := range results {
+		note := FormatPath(r.Path)
+		switch r.Status {
+		case session.MCPImportDuplicate:
+			note = fmt.Sprintf("same as '%s'", r.DuplicateOf)
+		case session.MCPImportConflict:
+			note = "name already used by a different MCP"
+		}
+		status := r.Status
+		if status == session.MCPImportDuplicate {
+			status = "skip"
+		}
+		fmt.Printf("%-8s %-20s %-8s %s\n", status, truncateString(r.Name, 20), r.Source, note)
+	}
+	fmt.Println()
+
+	if *dryRun {
+		fmt.Printf("Dry run: %d MCP(s) would be added.\n", added)
+	} else {
+		fmt.Printf("%s Imported %d MCP(s) into config.toml\n", successSymbol, added)
+	}
+}
+
+// promptMCPDef asks for each field of def, showing current values as defaults
+func promptMCPDef(reader *bufio.Reader, def *session.MCPDef) {
+	transport := def.Transport
+	if transport == "" {
+		transport = "stdio"
+	}
+	transport = promptLine(reader, "Transport (stdio/http/sse)", transport)
+
+	if transport == "stdio" {
+		def.Transport = ""
+		def.URL = ""
+		def.Headers = nil
+		def.Command = promptLine(reader, "Command", def.Command)
+		if args := promptLine(reader, "Arguments (space-separated)", strings.Join(def.Args, " ")); args != "" {
+			def.Args = strings.Fields(args)
+		} else {
+			def.Args = nil
+		}
+	} else {
+		def.Transport = transport
+		def.URL = promptLine(reader, "URL", def.URL)
+		def.Headers = promptKeyValues(reader, "Header", def.Headers)
+	}
+
+	def.Env = promptKeyValues(reader, "Env var", def.Env)
+	def.Description = promptLine(reader, "Description", def.Description)
+}
+
+// promptLine reads one line, returning current when the answer is empty
+func promptLine(reader *bufio.Reader, label, current string) string {
+	if current != "" {
+		fmt.Printf("%s [%s]: ", label, current)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	line, _ := reader.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return current
+	}
+	return line
+}
+
+// promptKeyValues reads KEY=VALUE lines until an empty line. "KEY=" removes a key.
+func promptKeyValues(reader *bufio.Reader, label string, current map[string]string) map[string]string {
+	values := keyValueFlag{}
+	if len(current) > 0 {
+		keys := make([]string, 0, len(current))
+		for k := range current {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Printf("Current %ss: %s\n", strings.ToLower(label), strings.Join(keys, ", "))
+	}
+	fmt.Printf("%ss as KEY=VALUE, one per line (vault:NAME or env:NAME for secrets; empty line to finish)\n", label)
+	for {
+		fmt.Print("  > ")
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if setErr := values.Set(line); setErr != nil {
+			fmt.Printf("  %s\n", setErr)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return mergeKeyValues(current, values)
+}
+
+// mcpDefJSON renders a definition for --json output
+func mcpDefJSON(name string, def session.MCPDef) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"command":     def.Command,
+		"args":        def.Args,
+		"env":         def.Env,
+		"url":         def.URL,
+		"transport":   def.Transport,
+		"headers":     def.Headers,
+		"description": def.Description,
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleMCPPool handles mcp pool subcommands (start/stop/status/restart)
+func handleMCPPool(args []string) {
+	if len(args) == 0 {
+		printMCPPoolHelp()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "start":
+		handleMCPPoolStart(args[1:])
+	case "stop":
+		handleMCPPoolStop(args[1:])
+	case "status":
+		handleMCPPoolStatus(args[1:])
+	case "restart":
+		handleMCPPoolRestart(args[1:])
+	case "help", "-h", "--help":
+		printMCPPoolHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown mcp pool command '%s'\n", args[0])
+		printMCPPoolHelp()
+		os.Exit(1)
+	}
+}
+
+// printMCPPoolHelp prints help for mcp pool commands
+func printMCPPoolHelp() {
+	fmt.Println("Usage: agent-deck mcp pool <command> [options]")
+	fmt.Println()
+	fmt.Println("Run the MCP socket pool as a standalone process shared by all TUI and CLI")
+	fmt.Println("instances. MCPs no running session has loaded are stopped after")
+	fmt.Println("[mcp_pool] idle_timeout seconds and restarted on the next attach.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  start               Start the pool process in the background")
+	fmt.Println("  stop                Stop the pool process and its MCPs")
+	fmt.Println("  status              Show pooled MCPs with session and client counts")
+	fmt.Println("  restart <mcp>       Restart a single pooled MCP")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck mcp pool start")
+	fmt.Println("  agent-deck mcp pool status --json")
+	fmt.Println("  agent-deck mcp pool restart exa")
+}
+
+// handleMCPPoolStart starts the standalone pool process
+func handleMCPPoolStart(args []string) {
+	fs := flag.NewFlagSet("mcp pool start", flag.ExitOnError)
+	foreground := fs.Bool("foreground", false, "Run in the foreground (logs to stderr)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp pool start [options]")
+		fmt.Println()
+		fmt.Println("Start the standalone MCP pool process.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if session.IsPoolDaemonRunning() {
+		out.Error("pool process is already running", ErrCodeAlreadyExists)
+		os.Exit(1)
+	}
+
+	if *foreground {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.Ltime | log.Lmicroseconds)
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if err := session.RunPoolDaemon(ctx); err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logPath, err := spawnPoolDaemon()
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Wait for the control socket so follow-up commands work immediately
+	deadline := time.Now().Add(10 * time.Second)
+	for !session.IsPoolDaemonRunning() {
+		if time.Now().After(deadline) {
+			out.Error(fmt.Sprintf("pool process did not start (see %s)", logPath), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	resp, err := session.SendPoolDaemonRequest(session.PoolDaemonRequest{Command: "status"}, 5*time.Second)
+	if err != nil {
+		out.Error(fmt.Sprintf("pool process started but not responding: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Started MCP pool (pid %d, %d MCPs)", resp.PID, len(resp.Servers)), map[string]interface{}{
+		"success": true,
+		"pid":     resp.PID,
+		"mcps":    len(resp.Servers),
+		"log":     logPath,
+	})
+}
+
+// spawnPoolDaemon re-executes agent-deck as a detached foreground pool process.
+// Returns the path of the log file the process writes to.
+func spawnPoolDaemon() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate agent-deck binary: %w", err)
+	}
+
+	baseDir, err := session.GetAgentDeckDir()
+	if err != nil {
+		return "", err
+	}
+	logDir := filepath.Join(baseDir, "logs")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logPath := filepath.Join(logDir, "mcp-pool.log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open pool log: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, "mcp", "pool", "start", "--foreground")
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	// New session so the pool survives the terminal (and TUI) that started it
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start pool process: %w", err)
+	}
+	_ = cmd.Process.Release()
+
+	return logPath, nil
+}
+
+// handleMCPPoolStop stops the standalone pool process
+func handleMCPPoolStop(args []string) {
+	fs := flag.NewFlagSet("mcp pool stop", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp pool stop")
+		fmt.Println()
+		fmt.Println("Stop the standalone MCP pool process and all MCPs it owns.")
+		fmt.Println("Sessions using pooled MCPs lose them until restarted.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	resp, err := session.SendPoolDaemonRequest(session.PoolDaemonRequest{Command: "stop"}, 5*time.Second)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	// Wait for shutdown to finish (MCPs get SIGTERM, then SIGKILL after a few seconds)
+	deadline := time.Now().Add(15 * time.Second)
+	for session.IsPoolDaemonRunning() && time.Now().Before(deadline) {
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	out.Success(fmt.Sprintf("Stopped MCP pool (pid %d)", resp.PID), map[string]interface{}{
+		"success": true,
+		"pid":     resp.PID,
+	})
+}
+
+// handleMCPPoolStatus shows the standalone pool's MCPs
+func handleMCPPoolStatus(args []string) {
+	fs := flag.NewFlagSet("mcp pool status", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp pool status [options]")
+		fmt.Println()
+		fmt.Println("Show pooled MCPs with session references and connected clients.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if !session.IsPoolDaemonRunning() {
+		if *jsonOutput {
+			out.Print("", map[string]interface{}{"running": false})
+		} else if !quietMode {
+			fmt.Println("MCP pool process is not running.")
+			fmt.Println("Start it with: agent-deck mcp pool start")
+		}
+		os.Exit(2)
+	}
+
+	resp, err := session.SendPoolDaemonRequest(session.PoolDaemonRequest{Command: "status"}, 5*time.Second)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		out.Print("", map[string]interface{}{
+			"running":              true,
+			"pid":                  resp.PID,
+			"started_at":           resp.StartedAt,
+			"idle_timeout_seconds": resp.IdleTimeout,
+			"servers":              resp.Servers,
+		})
+		return
+	}
+
+	if quietMode {
+		for _, s := range resp.Servers {
+			fmt.Printf("%s\t%s\t%d\n", s.Name, s.Status, s.Sessions)
+		}
+		return
+	}
+
+	idle := "disabled"
+	if resp.IdleTimeout > 0 {
+		idle = (time.Duration(resp.IdleTimeout) * time.Second).String()
+	}
+	fmt.Printf("MCP pool: pid %d, up %s, idle shutdown %s\n\n",
+		resp.PID, time.Since(resp.StartedAt).Round(time.Second), idle)
+
+	if len(resp.Servers) == 0 {
+		fmt.Println("No MCPs running.")
+		return
+	}
+
+	fmt.Printf("%-20s %-10s %-9s %-8s %-9s %s\n", "NAME", "STATUS", "SESSIONS", "CLIENTS", "IDLE", "HEALTH")
+	fmt.Println(strings.Repeat("-", 80))
+	for _, s := range resp.Servers {
+		idleFor := "-"
+		if s.Sessions == 0 && !s.IdleSince.IsZero() {
+			idleFor = time.Since(s.IdleSince).Round(time.Second).String()
+		}
+		status := s.Status
+		if s.External {
+			status += " (ext)"
+		}
+		health := "-"
+		if s.Health != nil {
+			health = s.Health.Summary()
+		}
+		fmt.Printf("%-20s %-10s %-9d %-8d %-9s %s\n",
+			truncateString(s.Name, 20), status, s.Sessions, s.Clients, idleFor, health)
+	}
+	fmt.Printf("\nTotal: %d MCPs\n", len(resp.Servers))
+}
+
+// handleMCPPoolRestart restarts a single MCP in the standalone pool
+func handleMCPPoolRestart(args []string) {
+	fs := flag.NewFlagSet("mcp pool restart", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp pool restart <mcp-name>")
+		fmt.Println()
+		fmt.Println("Restart a pooled MCP (picks up config.toml changes).")
+		fmt.Println("Connected sessions must reconnect (restart the session) afterwards.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if fs.NArg() < 1 {
+		out.Error("MCP name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	mcpName := fs.Arg(0)
+
+	if !session.IsPoolDaemonRunning() {
+		out.Error("pool process is not running (start it with: agent-deck mcp pool start)", ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	if _, err := session.SendPoolDaemonRequest(session.PoolDaemonRequest{Command: "restart", Name: mcpName}, 30*time.Second); err != nil {
+		out.Error(fmt.Sprintf("failed to restart %s: %v", mcpName, err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Restarted pooled MCP %s", mcpName), map[string]interface{}{
+		"success": true,
+		"mcp":     mcpName,
+	})
+}
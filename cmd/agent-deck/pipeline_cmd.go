@@ -0,0 +1,559 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handlePipeline dispatches pipeline subcommands
+func handlePipeline(profile string, args []string) {
+	if len(args) == 0 {
+		printPipelineUsage()
+		return
+	}
+
+	switch args[0] {
+	case "run":
+		handlePipelineRun(profile, args[1:])
+	case "resume":
+		handlePipelineResume(profile, args[1:])
+	case "list", "ls":
+		handlePipelineList(profile, args[1:])
+	case "status", "show":
+		handlePipelineStatus(profile, args[1:])
+	case "help", "-h", "--help":
+		printPipelineUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown pipeline command: %s\n", args[0])
+		printPipelineUsage()
+		os.Exit(1)
+	}
+}
+
+// printPipelineUsage prints help for pipeline commands
+func printPipelineUsage() {
+	fmt.Println("Usage: agent-deck pipeline <command> [options]")
+	fmt.Println()
+	fmt.Println("Hand work from session to session: each step sends a prompt, waits for the")
+	fmt.Println("agent to finish and passes its response on to later steps.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  run <file.toml>     Run a pipeline definition")
+	fmt.Println("  resume <run>        Continue an interrupted or failed run")
+	fmt.Println("  list                List runs")
+	fmt.Println("  status <run>        Show the steps of a run and their outputs")
+	fmt.Println()
+	fmt.Println("Definition:")
+	fmt.Println("  name = \"feature\"")
+	fmt.Println("  [vars]")
+	fmt.Println("  task = \"add rate limiting to the API\"")
+	fmt.Println()
+	fmt.Println("  [[steps]]")
+	fmt.Println("  name = \"plan\"")
+	fmt.Println("  session = \"planner\"                       # existing session (title or ID)")
+	fmt.Println("  prompt = \"Write a plan for: {{.Vars.task}}\"")
+	fmt.Println()
+	fmt.Println("  [[steps]]")
+	fmt.Println("  name = \"implement\"")
+	fmt.Println("  template = { tool = \"claude\", path = \".\" }  # new session for this step")
+	fmt.Println("  prompt = \"Implement this plan:\\n{{.Steps.plan.Output}}\"")
+	fmt.Println()
+	fmt.Println("  [[steps]]")
+	fmt.Println("  name = \"fix\"")
+	fmt.Println("  session = \"implementer\"")
+	fmt.Println("  when = '{{contains .Steps.review.Output \"CHANGES REQUESTED\"}}'")
+	fmt.Println("  prompt = \"Address this review:\\n{{.Steps.review.Output}}\"")
+	fmt.Println()
+	fmt.Println("Prompts and conditions are Go templates with .Vars, .Steps.<name>.Output and")
+	fmt.Println(".Steps.<name>.Status, plus contains, hasPrefix, hasSuffix, lower, upper and trim.")
+	fmt.Println("A step runs unless its condition renders to \"\", \"false\" or \"0\".")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck pipeline run feature.toml --var task=\"add retries\"")
+	fmt.Println("  agent-deck pipeline resume feature-20260115-093000")
+}
+
+// handlePipelineRun starts a new run of a pipeline definition
+func handlePipelineRun(profile string, args []string) {
+	fs := flag.NewFlagSet("pipeline run", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	vars := keyValueFlag{}
+	fs.Var(vars, "var", "Template variable KEY=VALUE, overriding [vars] (repeatable)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck pipeline run <file.toml> [options]")
+		fmt.Println()
+		fmt.Println("Run each step of a pipeline in order. Progress is saved after every step;")
+		fmt.Println("if this process dies, continue with 'agent-deck pipeline resume <run>'.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	pipeline, err := session.LoadPipelineFile(fs.Arg(0))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if len(vars) > 0 {
+		if pipeline.Vars == nil {
+			pipeline.Vars = make(map[string]string)
+		}
+		for k, v := range vars {
+			pipeline.Vars[k] = v
+		}
+	}
+
+	file, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		file = fs.Arg(0)
+	}
+	run := session.NewPipelineRun(pipeline, file)
+	if _, err := session.LoadPipelineRun(profile, run.ID); err == nil {
+		out.Error(fmt.Sprintf("pipeline run '%s' already exists; try again in a second", run.ID), ErrCodeAlreadyExists)
+		os.Exit(1)
+	}
+	if err := run.Save(profile); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	executePipelineRun(profile, run, out)
+}
+
+// handlePipelineResume continues a run from its first unfinished step
+func handlePipelineResume(profile string, args []string) {
+	fs := flag.NewFlagSet("pipeline resume", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck pipeline resume <run> [options]")
+		fmt.Println()
+		fmt.Println("Continue a run from its first unfinished step. A step whose prompt was")
+		fmt.Println("already sent is waited on, not sent again; a failed step is retried.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	run, err := session.LoadPipelineRun(profile, fs.Arg(0))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+	switch {
+	case run.Status == session.PipelineDone:
+		out.Error(fmt.Sprintf("pipeline run '%s' already finished", run.ID), ErrCodeInvalidOperation)
+		os.Exit(1)
+	case run.Status == session.PipelineRunning && !run.Interrupted():
+		out.Error(fmt.Sprintf("pipeline run '%s' is still running (pid %d)", run.ID, run.PID), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	run.Status = session.PipelineRunning
+	run.Error = ""
+	run.PID = os.Getpid()
+	if err := run.Save(profile); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	executePipelineRun(profile, run, out)
+}
+
+// executePipelineRun runs the remaining steps and reports the result
+func executePipelineRun(profile string, run *session.PipelineRun, out *CLIOutput) {
+	progress := func(format string, a ...interface{}) {
+		if !out.jsonMode && !out.quietMode {
+			fmt.Printf(format+"\n", a...)
+		}
+	}
+	progress("Pipeline run %s (%d steps)", run.ID, len(run.Steps))
+
+	err := runPipeline(profile, run, progress)
+	if err != nil {
+		out.Error(fmt.Sprintf("%v (resume with: agent-deck pipeline resume %s)", err, run.ID), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Pipeline run %s finished", run.ID), pipelineRunJSON(run, true))
+}
+
+// runPipeline executes a run's remaining steps in order, saving the run after
+// every transition so it can be resumed
+func runPipeline(profile string, run *session.PipelineRun, progress func(string, ...interface{})) error {
+	fail := func(idx int, err error) error {
+		run.Steps[idx].Status = session.PipelineFailed
+		run.Steps[idx].Error = err.Error()
+		run.Steps[idx].FinishedAt = time.Now()
+		run.Status = session.PipelineFailed
+		run.Error = fmt.Sprintf("step '%s': %v", run.Steps[idx].Name, err)
+		_ = run.Save(profile)
+		progress("✗ %s: %v", run.Steps[idx].Name, err)
+		return errors.New(run.Error)
+	}
+
+	for idx := run.NextStep(); idx >= 0; idx = run.NextStep() {
+		state := &run.Steps[idx]
+		step := run.Pipeline.Steps[idx]
+
+		// A failed step starts over; a sent one (the runner died while the agent
+		// worked) is only waited on
+		if state.Status == session.PipelineFailed {
+			state.Sent = false
+		}
+
+		if !state.Sent {
+			ok, err := run.ShouldRun(idx)
+			if err != nil {
+				return fail(idx, err)
+			}
+			if !ok {
+				state.Status = session.PipelineSkipped
+				state.FinishedAt = time.Now()
+				if err := run.Save(profile); err != nil {
+					return err
+				}
+				progress("– %s skipped", step.Name)
+				continue
+			}
+
+			prompt, err := run.RenderPrompt(idx)
+			if err != nil {
+				return fail(idx, err)
+			}
+			state.Prompt = prompt
+			state.Status = session.PipelineRunning
+			state.Error = ""
+			state.Output = ""
+			state.StartedAt = time.Now()
+			if err := run.Save(profile); err != nil {
+				return err
+			}
+		}
+
+		inst, err := pipelineStepSession(profile, run, idx)
+		if err != nil {
+			return fail(idx, err)
+		}
+		state.SessionID = inst.ID
+		if err := run.Save(profile); err != nil {
+			return err
+		}
+
+		if !state.Sent {
+			progress("● %s → %s", step.Name, inst.Title)
+			if err := sendPipelinePrompt(profile, inst, state.Prompt); err != nil {
+				return fail(idx, err)
+			}
+			state.Sent = true
+			if err := run.Save(profile); err != nil {
+				return err
+			}
+		} else {
+			progress("● %s → %s (waiting for the prompt sent earlier)", step.Name, inst.Title)
+		}
+
+		timeout, _ := step.StepTimeout()
+		if err := inst.WaitForTurn(state.StartedAt.Add(timeout)); err != nil {
+			return fail(idx, err)
+		}
+
+		if inst.Tool == "claude" {
+			inst.UpdateClaudeSession(nil)
+		}
+		response, err := inst.GetLastResponse()
+		if err != nil {
+			return fail(idx, fmt.Errorf("failed to get response: %w", err))
+		}
+		state.Output = response.Content
+		state.Status = session.PipelineDone
+		state.FinishedAt = time.Now()
+		if err := run.Save(profile); err != nil {
+			return err
+		}
+		progress("✓ %s (%s)", step.Name, state.FinishedAt.Sub(state.StartedAt).Round(time.Second))
+	}
+
+	run.Status = session.PipelineDone
+	run.FinishedAt = time.Now()
+	return run.Save(profile)
+}
+
+// pipelineStepSession returns the session a step talks to: the named session,
+// the one a template step created earlier in this run, or a new one from the
+// step's template. Sessions are reloaded from disk each time, since the TUI
+// or other commands may have changed them while earlier steps ran.
+func pipelineStepSession(profile string, run *session.PipelineRun, idx int) (*session.Instance, error) {
+	step := run.Pipeline.Steps[idx]
+	state := run.Steps[idx]
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if step.Template == nil {
+		inst, errMsg, _ := ResolveSession(step.Session, instances)
+		if inst == nil {
+			return nil, errors.New(errMsg)
+		}
+		return inst, nil
+	}
+
+	if state.SessionID != "" {
+		for _, inst := range instances {
+			if inst.ID == state.SessionID {
+				return inst, nil
+			}
+		}
+	}
+
+	tool, path, title, group := run.Pipeline.TemplateSession(step)
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("template path %s is not a directory", path)
+	}
+	inst := session.NewInstanceWithGroupAndTool(title, path, group, tool)
+	inst.Command = tool
+	if toolDef := session.GetToolDef(tool); toolDef != nil {
+		inst.Command = toolDef.Command
+	}
+	instances = append(instances, inst)
+	if err := saveSessionData(storage, instances); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	return inst, nil
+}
+
+// sendPipelinePrompt delivers a step's prompt: a stopped session is started
+// with it, a running one gets it once the agent is ready
+func sendPipelinePrompt(profile string, inst *session.Instance, prompt string) error {
+	if err := inst.BudgetError(); err != nil {
+		return err
+	}
+	if !inst.Exists() {
+		if err := inst.StartWithMessage(prompt); err != nil {
+			return fmt.Errorf("failed to start '%s': %w", inst.Title, err)
+		}
+		// Capture the tool's session ID so later responses can be read
+		inst.PostStartSync(3 * time.Second)
+		return savePipelineSession(profile, inst)
+	}
+
+	tmuxSess := inst.GetTmuxSession()
+	if tmuxSess == nil {
+		return errors.New("could not determine tmux session")
+	}
+	if err := waitForAgentReady(tmuxSess, inst.Tool); err != nil {
+		return fmt.Errorf("'%s' is not ready: %w", inst.Title, err)
+	}
+	return sendMessageToTmux(tmuxSess.Name, prompt)
+}
+
+// savePipelineSession writes one session back into freshly loaded session data
+func savePipelineSession(profile string, inst *session.Instance) error {
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		return err
+	}
+	found := false
+	for idx, existing := range instances {
+		if existing.ID == inst.ID {
+			instances[idx] = inst
+			found = true
+			break
+		}
+	}
+	if !found {
+		instances = append(instances, inst)
+	}
+	return saveSessionData(storage, instances)
+}
+
+// pipelineRunJSON renders a run for --json output
+func pipelineRunJSON(run *session.PipelineRun, withOutput bool) map[string]interface{} {
+	steps := make([]map[string]interface{}, 0, len(run.Steps))
+	for _, s := range run.Steps {
+		step := map[string]interface{}{
+			"name":       s.Name,
+			"status":     s.Status,
+			"session_id": s.SessionID,
+		}
+		if s.Error != "" {
+			step["error"] = s.Error
+		}
+		if withOutput {
+			step["prompt"] = s.Prompt
+			step["output"] = s.Output
+		}
+		steps = append(steps, step)
+	}
+	data := map[string]interface{}{
+		"success":    run.Status == session.PipelineDone,
+		"id":         run.ID,
+		"pipeline":   run.Pipeline.Name,
+		"file":       run.File,
+		"status":     run.DisplayStatus(),
+		"started_at": run.StartedAt,
+		"updated_at": run.UpdatedAt,
+		"steps":      steps,
+	}
+	if run.Error != "" {
+		data["error"] = run.Error
+	}
+	return data
+}
+
+// pipelineProgress returns "done/total" for a run
+func pipelineProgress(run *session.PipelineRun) string {
+	finished := 0
+	for _, s := range run.Steps {
+		if s.Status == session.PipelineDone || s.Status == session.PipelineSkipped {
+			finished++
+		}
+	}
+	return fmt.Sprintf("%d/%d", finished, len(run.Steps))
+}
+
+// handlePipelineList lists the pipeline runs of a profile
+func handlePipelineList(profile string, args []string) {
+	fs := flag.NewFlagSet("pipeline list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck pipeline list [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	runs, err := session.ListPipelineRuns(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to list pipeline runs: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	if len(runs) == 0 {
+		out.Print("No pipeline runs.\n", map[string]interface{}{"runs": []interface{}{}})
+		return
+	}
+
+	var sb strings.Builder
+	runsJSON := make([]map[string]interface{}, 0, len(runs))
+	sb.WriteString(fmt.Sprintf("%-32s %-12s %-6s %s\n", "RUN", "STATUS", "STEPS", "STARTED"))
+	for _, run := range runs {
+		sb.WriteString(fmt.Sprintf("%-32s %-12s %-6s %s\n",
+			truncateString(run.ID, 32),
+			run.DisplayStatus(),
+			pipelineProgress(run),
+			run.StartedAt.Format("2006-01-02 15:04")))
+		runsJSON = append(runsJSON, pipelineRunJSON(run, false))
+	}
+
+	out.Print(sb.String(), map[string]interface{}{"runs": runsJSON})
+}
+
+// handlePipelineStatus shows the steps of a run
+func handlePipelineStatus(profile string, args []string) {
+	fs := flag.NewFlagSet("pipeline status", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck pipeline status <run> [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	run, err := session.LoadPipelineRun(profile, fs.Arg(0))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Run:     %s\n", run.ID))
+	sb.WriteString(fmt.Sprintf("File:    %s\n", FormatPath(run.File)))
+	sb.WriteString(fmt.Sprintf("Status:  %s (%s steps)\n", run.DisplayStatus(), pipelineProgress(run)))
+	if run.Error != "" {
+		sb.WriteString(fmt.Sprintf("Error:   %s\n", run.Error))
+	}
+	sb.WriteString("\n")
+	for _, s := range run.Steps {
+		sb.WriteString(fmt.Sprintf("%s %-16s %s\n", pipelineStepSymbol(s.Status), s.Name, s.Status))
+		if s.Output != "" {
+			sb.WriteString(fmt.Sprintf("    %s\n", truncateString(strings.Join(strings.Fields(s.Output), " "), 100)))
+		}
+		if s.Error != "" {
+			sb.WriteString(fmt.Sprintf("    %s\n", s.Error))
+		}
+	}
+
+	out.Print(sb.String(), pipelineRunJSON(run, true))
+}
+
+// pipelineStepSymbol returns the progress symbol of a step status
+func pipelineStepSymbol(status string) string {
+	switch status {
+	case session.PipelineDone:
+		return "✓"
+	case session.PipelineRunning:
+		return "●"
+	case session.PipelineSkipped:
+		return "–"
+	case session.PipelineFailed:
+		return "✗"
+	default:
+		return "○"
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleSessionQueue dispatches session queue subcommands
+func handleSessionQueue(profile string, args []string) {
+	if len(args) == 0 {
+		printSessionQueueUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "add":
+		handleSessionQueueAdd(profile, args[1:])
+	case "list", "ls":
+		handleSessionQueueList(profile, args[1:])
+	case "clear":
+		handleSessionQueueClear(profile, args[1:])
+	case "help", "-h", "--help":
+		printSessionQueueUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown queue command: %s\n", args[0])
+		printSessionQueueUsage()
+		os.Exit(1)
+	}
+}
+
+// printSessionQueueUsage prints help for session queue commands
+func printSessionQueueUsage() {
+	fmt.Println("Usage: agent-deck session queue <command> <id|title> [options]")
+	fmt.Println()
+	fmt.Println("Queue prompts for a session instead of typing them into a turn in progress.")
+	fmt.Println("Queued prompts are sent one at a time, each once the agent is waiting again.")
+	fmt.Println("Delivery happens while the agent-deck TUI is running; the queue is saved")
+	fmt.Println("with the session, so it survives restarts.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  add <id> <prompt>    Add a prompt to the end of the queue")
+	fmt.Println("  list [id]            Show the queued prompts, next first")
+	fmt.Println("  clear <id>           Drop every queued prompt")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck session queue add my-project \"now add tests for it\"")
+	fmt.Println("  agent-deck session queue list my-project --json")
+	fmt.Println("  agent-deck session queue clear my-project")
+}
+
+// queuedPromptJSON renders a queued prompt for --json output
+func queuedPromptJSON(p session.QueuedPrompt) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        p.ID,
+		"text":      p.Text,
+		"queued_at": p.QueuedAt.Format(time.RFC3339),
+	}
+}
+
+// handleSessionQueueAdd appends a prompt to a session's queue
+func handleSessionQueueAdd(profile string, args []string) {
+	fs := flag.NewFlagSet("session queue add", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session queue add <id|title> <prompt> [options]")
+		fmt.Println()
+		fmt.Println("Add a prompt to the end of the session's queue.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	text := strings.TrimSpace(strings.Join(fs.Args()[1:], " "))
+	if text == "" {
+		out.Error("prompt cannot be empty", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	prompt := inst.EnqueuePrompt(text)
+	if err := saveSessionData(storage, instances); err != nil {
+		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	position := len(inst.QueuedPrompts())
+	out.Success(fmt.Sprintf("Queued prompt for '%s' (position %d)", inst.Title, position), map[string]interface{}{
+		"success":       true,
+		"session_id":    inst.ID,
+		"session_title": inst.Title,
+		"prompt":        queuedPromptJSON(prompt),
+		"position":      position,
+	})
+}
+
+// handleSessionQueueList shows a session's queued prompts
+func handleSessionQueueList(profile string, args []string) {
+	fs := flag.NewFlagSet("session queue list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Print only the number of queued prompts")
+	quietShort := fs.Bool("q", false, "Print only the number of queued prompts (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session queue list [id|title] [options]")
+		fmt.Println()
+		fmt.Println("Show the session's queued prompts, next first.")
+		fmt.Println("If no ID is provided, auto-detects current session.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSessionOrCurrent(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	queue := inst.QueuedPrompts()
+	if *quiet || *quietShort {
+		fmt.Println(len(queue))
+		return
+	}
+
+	list := make([]map[string]interface{}, 0, len(queue))
+	var sb strings.Builder
+	if len(queue) == 0 {
+		sb.WriteString(fmt.Sprintf("No prompts queued for '%s'\n", inst.Title))
+	} else {
+		sb.WriteString(fmt.Sprintf("Queued prompts for '%s' (next first):\n", inst.Title))
+	}
+	for idx, p := range queue {
+		list = append(list, queuedPromptJSON(p))
+		sb.WriteString(fmt.Sprintf("  %d. %s  %s\n", idx+1, p.QueuedAt.Format("15:04:05"), truncateString(strings.Join(strings.Fields(p.Text), " "), 70)))
+	}
+
+	out.Print(sb.String(), map[string]interface{}{
+		"success":    true,
+		"session_id": inst.ID,
+		"queue":      list,
+		"count":      len(queue),
+	})
+}
+
+// handleSessionQueueClear drops every queued prompt of a session
+func handleSessionQueueClear(profile string, args []string) {
+	fs := flag.NewFlagSet("session queue clear", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session queue clear <id|title> [options]")
+		fmt.Println()
+		fmt.Println("Drop every queued prompt of the session.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	cleared := inst.ClearPromptQueue()
+	if cleared > 0 {
+		if err := saveSessionData(storage, instances); err != nil {
+			out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+	}
+
+	out.Success(fmt.Sprintf("Cleared %d queued prompt(s) for '%s'", cleared, inst.Title), map[string]interface{}{
+		"success":    true,
+		"session_id": inst.ID,
+		"cleared":    cleared,
+	})
+}
@@ -0,0 +1,550 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleRace dispatches race subcommands; a bare "race --prompt ..." starts a race
+func handleRace(profile string, args []string) {
+	if len(args) == 0 {
+		printRaceUsage()
+		return
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		handleRaceList(profile, args[1:])
+	case "compare", "status":
+		handleRaceCompare(profile, args[1:])
+	case "promote":
+		handleRacePromote(profile, args[1:])
+	case "help", "-h", "--help":
+		printRaceUsage()
+	default:
+		if strings.HasPrefix(args[0], "-") {
+			handleRaceStart(profile, args)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Unknown race command: %s\n", args[0])
+		printRaceUsage()
+		os.Exit(1)
+	}
+}
+
+// printRaceUsage prints help for race commands
+func printRaceUsage() {
+	fmt.Println("Usage: agent-deck race --prompt \"...\" [options]")
+	fmt.Println("       agent-deck race <command> [options]")
+	fmt.Println()
+	fmt.Println("Give the same prompt to several agents, each in its own worktree, then")
+	fmt.Println("compare their results and keep the best one.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list                          List races")
+	fmt.Println("  compare <race>                Compare diff stats, check results, cost and time")
+	fmt.Println("  promote <race> <contestant>   Land the winner and clean up the rest")
+	fmt.Println()
+	fmt.Println("Start Options:")
+	fmt.Println("  --prompt <text>     Prompt sent to every contestant (required)")
+	fmt.Println("  --tools <list>      Comma-separated tools, repeats allowed (default: claude,gemini,codex)")
+	fmt.Println("  --repo <path>       Repository to race in (default: current directory)")
+	fmt.Println("  --check <command>   Command run in each worktree by compare (e.g. \"go test ./...\")")
+	fmt.Println("  --name <name>       Race name (default: timestamp)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck race --prompt \"Fix the flaky login test\" --tools claude,gemini,codex --repo .")
+	fmt.Println("  agent-deck race --name retry --check \"go test ./...\" --tools claude,claude --prompt \"Add retries\"")
+	fmt.Println("  agent-deck race compare retry")
+	fmt.Println("  agent-deck race promote retry claude-2")
+}
+
+// handleRaceStart creates the worktrees and sessions of a new race and starts them
+func handleRaceStart(profile string, args []string) {
+	fs := flag.NewFlagSet("race", flag.ExitOnError)
+	prompt := fs.String("prompt", "", "Prompt sent to every contestant")
+	tools := fs.String("tools", "claude,gemini,codex", "Comma-separated tools to race")
+	repo := fs.String("repo", ".", "Repository to race in")
+	check := fs.String("check", "", "Check command run in each worktree by compare")
+	name := fs.String("name", "", "Race name (default: timestamp)")
+	location := fs.String("location", "", "Worktree location: sibling or subdirectory (default from config)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		printRaceUsage()
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if strings.TrimSpace(*prompt) == "" {
+		out.Error("--prompt is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	var toolList []string
+	for _, tool := range strings.Split(*tools, ",") {
+		if tool = strings.TrimSpace(tool); tool != "" {
+			toolList = append(toolList, tool)
+		}
+	}
+	if len(toolList) == 0 {
+		out.Error("--tools must name at least one tool", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	raceName := *name
+	if raceName == "" {
+		raceName = time.Now().Format("20060102-150405")
+	}
+	if _, err := session.LoadRace(profile, raceName); err == nil {
+		out.Error(fmt.Sprintf("race '%s' already exists", raceName), ErrCodeAlreadyExists)
+		os.Exit(1)
+	}
+
+	repoDir, err := filepath.Abs(*repo)
+	if err != nil {
+		out.Error(fmt.Sprintf("invalid repo path: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	race, contestants, err := session.NewRace(raceName, *prompt, repoDir, toolList, *check, *location)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to create race: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	instances = append(instances, contestants...)
+	if err := race.Save(profile); err != nil {
+		out.Error(fmt.Sprintf("failed to save race: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if err := saveSessionData(storage, instances); err != nil {
+		out.Error(fmt.Sprintf("failed to save session data: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Start every contestant; a failed start is reported but does not stop the race
+	var startErrors []string
+	for _, inst := range contestants {
+		if err := inst.StartWithMessage(*prompt); err != nil {
+			startErrors = append(startErrors, fmt.Sprintf("%s: %v", inst.Title, err))
+		}
+	}
+
+	// Capture tool session IDs (needed for cost tracking) in parallel
+	var wg sync.WaitGroup
+	for _, inst := range contestants {
+		if !inst.Exists() {
+			continue
+		}
+		wg.Add(1)
+		go func(inst *session.Instance) {
+			defer wg.Done()
+			inst.PostStartSync(3 * time.Second)
+		}(inst)
+	}
+	wg.Wait()
+
+	if err := saveSessionData(storage, instances); err != nil {
+		out.Error(fmt.Sprintf("failed to save session data: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Started race '%s' from %s (%s) in group '%s'\n",
+		race.Name, race.BaseBranch, race.BaseCommit[:7], race.GroupPath))
+	contestantsJSON := make([]map[string]interface{}, 0, len(race.Contestants))
+	for _, c := range race.Contestants {
+		sb.WriteString(fmt.Sprintf("  %-12s %-8s %s\n", c.Name, c.Tool, FormatPath(c.WorktreePath)))
+		contestantsJSON = append(contestantsJSON, map[string]interface{}{
+			"name":          c.Name,
+			"tool":          c.Tool,
+			"session_id":    c.SessionID,
+			"branch":        c.Branch,
+			"worktree_path": c.WorktreePath,
+		})
+	}
+	for _, msg := range startErrors {
+		sb.WriteString(fmt.Sprintf("Failed to start %s\n", msg))
+	}
+	sb.WriteString(fmt.Sprintf("\nCompare with: agent-deck race compare %s\n", race.Name))
+
+	out.Print(sb.String(), map[string]interface{}{
+		"success":      len(startErrors) == 0,
+		"name":         race.Name,
+		"group":        race.GroupPath,
+		"base_branch":  race.BaseBranch,
+		"base_commit":  race.BaseCommit,
+		"contestants":  contestantsJSON,
+		"start_errors": startErrors,
+	})
+}
+
+// handleRaceList lists the races of a profile
+func handleRaceList(profile string, args []string) {
+	fs := flag.NewFlagSet("race list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck race list [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	races, err := session.ListRaces(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to list races: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	if len(races) == 0 {
+		out.Print("No races.\n", map[string]interface{}{"races": []interface{}{}})
+		return
+	}
+
+	var sb strings.Builder
+	racesJSON := make([]map[string]interface{}, 0, len(races))
+	sb.WriteString(fmt.Sprintf("%-20s %-18s %-30s %s\n", "NAME", "STARTED", "CONTESTANTS", "PROMPT"))
+	for _, race := range races {
+		names := make([]string, 0, len(race.Contestants))
+		for _, c := range race.Contestants {
+			names = append(names, c.Name)
+		}
+		sb.WriteString(fmt.Sprintf("%-20s %-18s %-30s %s\n",
+			truncateString(race.Name, 20),
+			race.CreatedAt.Format("2006-01-02 15:04"),
+			truncateString(strings.Join(names, ","), 30),
+			truncateString(race.Prompt, 40)))
+		racesJSON = append(racesJSON, map[string]interface{}{
+			"name":        race.Name,
+			"prompt":      race.Prompt,
+			"repo":        race.RepoRoot,
+			"group":       race.GroupPath,
+			"created_at":  race.CreatedAt,
+			"contestants": names,
+		})
+	}
+
+	out.Print(sb.String(), map[string]interface{}{"races": racesJSON})
+}
+
+// handleRaceCompare shows a side-by-side comparison of a race's contestants
+func handleRaceCompare(profile string, args []string) {
+	fs := flag.NewFlagSet("race compare", flag.ExitOnError)
+	noCheck := fs.Bool("no-check", false, "Skip the race's check command")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck race compare <race> [options]")
+		fmt.Println()
+		fmt.Println("Compare contestants by lines changed against the race's starting commit,")
+		fmt.Println("check command result, tokens, estimated cost and time to last activity.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	if fs.Arg(0) == "" {
+		out.Error("race name is required", ErrCodeNotFound)
+		fmt.Println()
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	race, err := session.LoadRace(profile, fs.Arg(0))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	byID := make(map[string]*session.Instance, len(instances))
+	for _, inst := range instances {
+		byID[inst.ID] = inst
+	}
+
+	results := race.Compare(byID, !*noCheck)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Race '%s' · %s · started %s\n", race.Name, FormatPath(race.RepoRoot), race.CreatedAt.Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("Prompt: %s\n\n", truncateString(race.Prompt, 70)))
+	sb.WriteString(fmt.Sprintf("%-12s %-8s %-8s %6s %7s %7s %-6s %9s %8s %8s\n",
+		"CONTESTANT", "TOOL", "STATUS", "FILES", "+", "-", "CHECK", "TOKENS", "COST", "TIME"))
+
+	resultsJSON := make([]map[string]interface{}, 0, len(results))
+	for _, r := range results {
+		files, ins, del := "-", "-", "-"
+		if r.DiffErr == nil {
+			files = fmt.Sprintf("%d", r.Diff.Files)
+			ins = fmt.Sprintf("+%d", r.Diff.Insertions)
+			del = fmt.Sprintf("-%d", r.Diff.Deletions)
+		}
+		checkStr := "-"
+		if r.Checked {
+			checkStr = "fail"
+			if r.CheckPassed {
+				checkStr = "pass"
+			}
+		}
+		tokens, cost, elapsed := "-", "-", "-"
+		if r.Tokens > 0 {
+			tokens = fmt.Sprintf("%d", r.Tokens)
+			cost = fmt.Sprintf("$%.2f", r.Cost)
+		}
+		if r.Elapsed > 0 {
+			elapsed = r.Elapsed.Round(time.Second).String()
+		}
+		sb.WriteString(fmt.Sprintf("%-12s %-8s %-8s %6s %7s %7s %-6s %9s %8s %8s\n",
+			truncateString(r.Contestant.Name, 12), truncateString(r.Contestant.Tool, 8), r.Status,
+			files, ins, del, checkStr, tokens, cost, elapsed))
+
+		entry := map[string]interface{}{
+			"name":          r.Contestant.Name,
+			"tool":          r.Contestant.Tool,
+			"session_id":    r.Contestant.SessionID,
+			"branch":        r.Contestant.Branch,
+			"worktree_path": r.Contestant.WorktreePath,
+			"status":        string(r.Status),
+			"files":         r.Diff.Files,
+			"insertions":    r.Diff.Insertions,
+			"deletions":     r.Diff.Deletions,
+			"tokens":        r.Tokens,
+			"cost":          r.Cost,
+			"elapsed_sec":   int(r.Elapsed.Seconds()),
+		}
+		if r.DiffErr != nil {
+			entry["error"] = r.DiffErr.Error()
+		}
+		if r.Checked {
+			entry["check_passed"] = r.CheckPassed
+			entry["check_output"] = r.CheckOutput
+		}
+		resultsJSON = append(resultsJSON, entry)
+	}
+
+	// Show why checks failed
+	for _, r := range results {
+		if r.Checked && !r.CheckPassed && r.CheckOutput != "" {
+			sb.WriteString(fmt.Sprintf("\n%s check output:\n", r.Contestant.Name))
+			for _, line := range strings.Split(r.CheckOutput, "\n") {
+				sb.WriteString("  " + line + "\n")
+			}
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\nPromote a winner with: agent-deck race promote %s <contestant>\n", race.Name))
+
+	out.Print(sb.String(), map[string]interface{}{
+		"name":          race.Name,
+		"prompt":        race.Prompt,
+		"base_branch":   race.BaseBranch,
+		"base_commit":   race.BaseCommit,
+		"check_command": race.CheckCommand,
+		"contestants":   resultsJSON,
+	})
+}
+
+// handleRacePromote lands the winning contestant and discards the others
+func handleRacePromote(profile string, args []string) {
+	settings := session.GetWorktreeSettings()
+
+	fs := flag.NewFlagSet("race promote", flag.ExitOnError)
+	strategy := fs.String("strategy", settings.FinishStrategy, "How to land the winner: rebase, squash or pr")
+	target := fs.String("target", "", "Branch to land on (default: branch the race started from)")
+	push := fs.Bool("push", settings.FinishPush, "Push the winner's branch to origin before landing it")
+	noLand := fs.Bool("no-land", false, "Keep the winner's session and worktree instead of landing it")
+	yes := fs.Bool("yes", false, "Skip confirmation")
+	yesShort := fs.Bool("y", false, "Skip confirmation (short)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck race promote <race> <contestant> [options]")
+		fmt.Println()
+		fmt.Println("Land the winner's branch like 'worktree finish', then stop the other")
+		fmt.Println("contestants and delete their sessions, worktrees and branches.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	if fs.NArg() < 2 {
+		out.Error("race name and contestant are required", ErrCodeNotFound)
+		fmt.Println()
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	race, err := session.LoadRace(profile, fs.Arg(0))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+	winner, ok := race.Contestant(fs.Arg(1))
+	if !ok {
+		out.Error(fmt.Sprintf("race '%s' has no contestant '%s'", race.Name, fs.Arg(1)), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	byID := make(map[string]*session.Instance, len(instances))
+	for _, inst := range instances {
+		byID[inst.ID] = inst
+	}
+
+	if !*yes && !*yesShort && !*jsonOutput {
+		action := fmt.Sprintf("%s %s onto %s", *strategy, winner.Branch, race.BaseBranch)
+		if *noLand {
+			action = fmt.Sprintf("keep %s", winner.Branch)
+		}
+		fmt.Printf("Promote '%s': %s and delete the other %d contestant(s) with their worktrees. Continue? [y/N]: ",
+			winner.Name, action, len(race.Contestants)-1)
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Aborted.")
+			return
+		}
+	}
+
+	removed := make(map[string]bool)
+	var sb strings.Builder
+
+	// Land the winner first: if that fails, nothing else is touched
+	var result *session.WorktreeFinishResult
+	if !*noLand {
+		winnerInst := byID[winner.SessionID]
+		if winnerInst == nil {
+			out.Error(fmt.Sprintf("session for contestant '%s' not found; use --no-land", winner.Name), ErrCodeNotFound)
+			os.Exit(2)
+		}
+		landOn := *target
+		if landOn == "" {
+			landOn = race.BaseBranch
+		}
+		result, err = winnerInst.FinishWorktree(session.WorktreeFinishOptions{
+			Strategy: *strategy,
+			Target:   landOn,
+			Push:     *push,
+		})
+		if err != nil {
+			msg := err.Error()
+			if errors.Is(err, git.ErrConflict) {
+				msg += "; resolve the conflicts manually in " + FormatPath(winner.WorktreePath)
+			}
+			if result != nil {
+				msg = fmt.Sprintf("%s landed on %s, but cleanup failed: %s", result.Branch, result.Target, msg)
+			}
+			out.Error(msg, ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		removed[winner.SessionID] = true
+		sb.WriteString(fmt.Sprintf("Landed %s on %s (%s)\n", winner.Branch, result.Target, result.Strategy))
+	} else {
+		sb.WriteString(fmt.Sprintf("Kept %s in %s\n", winner.Name, FormatPath(winner.WorktreePath)))
+	}
+
+	var cleanupErrors []string
+	for _, c := range race.Contestants {
+		if c.Name == winner.Name {
+			continue
+		}
+		if err := race.DiscardContestant(c, byID[c.SessionID]); err != nil {
+			cleanupErrors = append(cleanupErrors, fmt.Sprintf("%s: %v", c.Name, err))
+			continue
+		}
+		removed[c.SessionID] = true
+		sb.WriteString(fmt.Sprintf("Discarded %s\n", c.Name))
+	}
+
+	remaining := make([]*session.Instance, 0, len(instances))
+	for _, inst := range instances {
+		if !removed[inst.ID] {
+			remaining = append(remaining, inst)
+		}
+	}
+	if err := saveSessionData(storage, remaining); err != nil {
+		out.Error(fmt.Sprintf("failed to save session data: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Keep the manifest while contestants are left to clean up, so promote can be retried
+	if len(cleanupErrors) == 0 {
+		if err := session.DeleteRace(profile, race.Name); err != nil {
+			cleanupErrors = append(cleanupErrors, fmt.Sprintf("race manifest: %v", err))
+		}
+	}
+	for _, msg := range cleanupErrors {
+		sb.WriteString(fmt.Sprintf("Failed to discard %s\n", msg))
+	}
+
+	jsonData := map[string]interface{}{
+		"success":        len(cleanupErrors) == 0,
+		"race":           race.Name,
+		"winner":         winner.Name,
+		"branch":         winner.Branch,
+		"landed":         result != nil,
+		"cleanup_errors": cleanupErrors,
+	}
+	if result != nil {
+		jsonData["target"] = result.Target
+		jsonData["strategy"] = result.Strategy
+		jsonData["pushed"] = result.Pushed
+		jsonData["branch_deleted"] = result.BranchDeleted
+	}
+	out.Print(sb.String(), jsonData)
+	if len(cleanupErrors) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleRepo dispatches repo subcommands
+func handleRepo(args []string) {
+	if len(args) == 0 {
+		printRepoUsage()
+		return
+	}
+
+	switch args[0] {
+	case "status":
+		handleRepoStatus(args[1:])
+	case "help", "-h", "--help":
+		printRepoUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown repo command: %s\n", args[0])
+		printRepoUsage()
+		os.Exit(1)
+	}
+}
+
+// printRepoUsage prints help for repo commands
+func printRepoUsage() {
+	fmt.Println("Usage: agent-deck repo <command> [options]")
+	fmt.Println()
+	fmt.Println("See everything working on one repository, across worktrees and profiles.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  status [path]     Worktrees of the repo with their sessions from all profiles")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck repo status")
+	fmt.Println("  agent-deck repo status ~/src/monorepo --json")
+}
+
+// repoSessionJSON renders a session working in a repo for --json output
+func repoSessionJSON(ps session.ProfileSession) map[string]interface{} {
+	inst := ps.Instance
+	return map[string]interface{}{
+		"id":         inst.ID,
+		"title":      inst.Title,
+		"profile":    ps.Profile,
+		"tool":       inst.Tool,
+		"status":     string(inst.Status),
+		"path":       inst.ProjectPath,
+		"created_at": inst.CreatedAt.Format(time.RFC3339),
+		"age_sec":    int(time.Since(inst.CreatedAt).Seconds()),
+	}
+}
+
+// formatAge formats how long ago t was, e.g. "45s", "3h", "2d"
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// handleRepoStatus shows every worktree of a repository and the sessions in them
+func handleRepoStatus(args []string) {
+	fs := flag.NewFlagSet("repo status", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck repo status [path] [options]")
+		fmt.Println()
+		fmt.Println("Show every worktree of the repository containing path (default: current")
+		fmt.Println("directory) with its branch and uncommitted changes, and the sessions from")
+		fmt.Println("all profiles working in it: tool, status and age.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	path := fs.Arg(0)
+	if path == "" {
+		path = "."
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		out.Error(fmt.Sprintf("invalid path: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	sessions, err := session.LoadAllProfileSessions()
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	repo, err := session.GetRepoStatus(absPath, sessions, true)
+	if err != nil {
+		out.Error(fmt.Sprintf("%s: %v", FormatPath(absPath), err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Repository: %s\n", FormatPath(repo.Root)))
+	sb.WriteString(fmt.Sprintf("%d worktree(s), %d session(s)\n", len(repo.Worktrees), repo.SessionCount()))
+
+	worktreesJSON := make([]map[string]interface{}, 0, len(repo.Worktrees))
+	for _, wt := range repo.Worktrees {
+		branch := wt.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		state := "clean"
+		changes := 0
+		if wt.Status == nil {
+			state = "unknown"
+		} else if wt.Dirty() {
+			changes = len(wt.Status.Files)
+			state = fmt.Sprintf("%d changed", changes)
+		}
+		label := FormatPath(wt.Path)
+		if wt.Main {
+			label += " (main)"
+		}
+		sb.WriteString(fmt.Sprintf("\n%s\n  branch: %s  •  %s\n", label, branch, state))
+
+		sessionsJSON := make([]map[string]interface{}, 0, len(wt.Sessions))
+		if len(wt.Sessions) == 0 {
+			sb.WriteString("  (no sessions)\n")
+		}
+		for _, ps := range wt.Sessions {
+			sb.WriteString(formatRepoSessionLine(ps))
+			sessionsJSON = append(sessionsJSON, repoSessionJSON(ps))
+		}
+
+		worktreesJSON = append(worktreesJSON, map[string]interface{}{
+			"path":     wt.Path,
+			"branch":   wt.Branch,
+			"main":     wt.Main,
+			"dirty":    wt.Dirty(),
+			"changes":  changes,
+			"sessions": sessionsJSON,
+		})
+	}
+
+	orphanedJSON := make([]map[string]interface{}, 0, len(repo.Orphaned))
+	if len(repo.Orphaned) > 0 {
+		sb.WriteString("\nSessions whose worktree no longer exists:\n")
+		for _, ps := range repo.Orphaned {
+			sb.WriteString(formatRepoSessionLine(ps))
+			orphanedJSON = append(orphanedJSON, repoSessionJSON(ps))
+		}
+		sb.WriteString("Clean them up with: agent-deck worktree cleanup\n")
+	}
+
+	out.Print(sb.String(), map[string]interface{}{
+		"success":   true,
+		"repo_root": repo.Root,
+		"worktrees": worktreesJSON,
+		"orphaned":  orphanedJSON,
+		"sessions":  repo.SessionCount(),
+	})
+}
+
+// formatRepoSessionLine renders one session row of repo status
+func formatRepoSessionLine(ps session.ProfileSession) string {
+	inst := ps.Instance
+	return fmt.Sprintf("  %s %-24s %-10s %-8s %-8s %4s  %s\n",
+		StatusSymbol(inst.Status),
+		truncateString(inst.Title, 24),
+		truncateString(ps.Profile, 10),
+		inst.Tool,
+		string(inst.Status),
+		formatAge(inst.CreatedAt),
+		TruncateID(inst.ID))
+}
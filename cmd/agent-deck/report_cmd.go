@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// reportSincePattern matches relative periods like "30d", "2w" or "12h"
+var reportSincePattern = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+// parseReportSince turns --since into the start of the reported period. It
+// accepts a number of hours, days or weeks back ("12h", "30d", "2w") or a
+// date (YYYY-MM-DD).
+func parseReportSince(value string, now time.Time) (time.Time, error) {
+	if m := reportSincePattern.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "h":
+			return now.Add(-time.Duration(n) * time.Hour), nil
+		case "d":
+			return now.AddDate(0, 0, -n), nil
+		case "w":
+			return now.AddDate(0, 0, -7*n), nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use e.g. 30d, 2w, 12h or 2026-01-31)", value)
+}
+
+// formatTokenCount formats a token count compactly, e.g. "950", "12.3k", "4.1M"
+func formatTokenCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// formatActiveTime formats active time in hours and minutes
+func formatActiveTime(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+// formatTopTools lists tools with their call counts, e.g. "Bash 120, Edit 80"
+func formatTopTools(tools []session.ToolCall, sep string) string {
+	parts := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		parts = append(parts, fmt.Sprintf("%s%s%d", tool.Name, sep, tool.Count))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reportRowJSON renders a report row for --format json
+func reportRowJSON(row session.ReportRow) map[string]interface{} {
+	tools := make([]map[string]interface{}, 0, len(row.TopTools))
+	for _, tool := range row.TopTools {
+		tools = append(tools, map[string]interface{}{"name": tool.Name, "count": tool.Count})
+	}
+	return map[string]interface{}{
+		"key":                row.Key,
+		"sessions":           row.Sessions,
+		"turns":              row.Turns,
+		"input_tokens":       row.InputTokens,
+		"output_tokens":      row.OutputTokens,
+		"cache_read_tokens":  row.CacheReadTokens,
+		"cache_write_tokens": row.CacheWriteTokens,
+		"total_tokens":       row.TotalTokens(),
+		"cost":               row.Cost,
+		"active_minutes":     int(row.ActiveTime.Minutes()),
+		"top_tools":          tools,
+	}
+}
+
+// handleReport aggregates token usage, estimated cost, turns and active time
+// across all Claude and Gemini transcripts on this machine
+func handleReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	since := fs.String("since", "30d", "Period to report: 12h, 30d, 2w or a date (YYYY-MM-DD)")
+	by := fs.String("by", session.ReportByProject, "Group usage by: "+strings.Join(session.ReportDimensions, "|"))
+	format := fs.String("format", "table", "Output format: table|csv|json")
+	jsonOutput := fs.Bool("json", false, "Output as JSON (same as --format json)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck report [options]")
+		fmt.Println()
+		fmt.Println("Report agent usage (tokens, estimated cost, turns, active time and top tools)")
+		fmt.Println("from every Claude and Gemini transcript on this machine, across all profiles,")
+		fmt.Println("including sessions that were deleted. Parsed transcripts are cached, so")
+		fmt.Println("reruns only read what changed.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck report")
+		fmt.Println("  agent-deck report --since 2026-09-01 --by group")
+		fmt.Println("  agent-deck report --by day --format csv > usage.csv")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if *jsonOutput {
+		*format = "json"
+	}
+
+	out := NewCLIOutput(*format == "json", false)
+
+	if *format != "table" && *format != "csv" && *format != "json" {
+		out.Error(fmt.Sprintf("invalid --format %q (use table, csv or json)", *format), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	start, err := parseReportSince(*since, time.Now())
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	report, err := session.BuildUsageReport(session.ReportOptions{Since: start, By: *by})
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		rows := make([]map[string]interface{}, 0, len(report.Rows))
+		for _, row := range report.Rows {
+			rows = append(rows, reportRowJSON(row))
+		}
+		out.Print("", map[string]interface{}{
+			"since":       report.Since.Format(time.RFC3339),
+			"by":          report.By,
+			"transcripts": report.Transcripts,
+			"rows":        rows,
+			"total":       reportRowJSON(report.Total),
+		})
+
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write([]string{report.By, "sessions", "turns", "input_tokens", "output_tokens",
+			"cache_read_tokens", "cache_write_tokens", "total_tokens", "cost_usd", "active_minutes", "top_tools"})
+		for _, row := range report.Rows {
+			_ = w.Write([]string{
+				row.Key,
+				strconv.Itoa(row.Sessions),
+				strconv.Itoa(row.Turns),
+				strconv.Itoa(row.InputTokens),
+				strconv.Itoa(row.OutputTokens),
+				strconv.Itoa(row.CacheReadTokens),
+				strconv.Itoa(row.CacheWriteTokens),
+				strconv.Itoa(row.TotalTokens()),
+				strconv.FormatFloat(row.Cost, 'f', 2, 64),
+				strconv.Itoa(int(row.ActiveTime.Minutes())),
+				formatTopTools(row.TopTools, ":"),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	default:
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Usage since %s by %s (%d transcripts)\n\n",
+			report.Since.Format("2006-01-02"), report.By, report.Transcripts))
+		if len(report.Rows) == 0 {
+			sb.WriteString("No usage in this period.\n")
+			fmt.Print(sb.String())
+			return
+		}
+		line := "%-40s %8s %7s %9s %10s %8s  %s\n"
+		sb.WriteString(fmt.Sprintf(line, strings.ToUpper(report.By), "SESSIONS", "TURNS", "TOKENS", "COST", "ACTIVE", "TOP TOOLS"))
+		rows := append(report.Rows, report.Total)
+		for idx, row := range rows {
+			key := row.Key
+			if report.By == session.ReportByProject {
+				key = FormatPath(key)
+			}
+			if idx == len(rows)-1 {
+				sb.WriteString(strings.Repeat("-", 100) + "\n")
+			}
+			sb.WriteString(fmt.Sprintf(line,
+				truncateString(key, 40),
+				strconv.Itoa(row.Sessions),
+				strconv.Itoa(row.Turns),
+				formatTokenCount(row.TotalTokens()),
+				fmt.Sprintf("$%.2f", row.Cost),
+				formatActiveTime(row.ActiveTime),
+				formatTopTools(row.TopTools, " ")))
+		}
+		sb.WriteString("\nCosts are estimates from token counts at list prices.\n")
+		fmt.Print(sb.String())
+	}
+}
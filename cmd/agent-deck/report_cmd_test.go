@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReportSince(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"12h", now.Add(-12 * time.Hour)},
+		{"30d", time.Date(2026, 2, 13, 12, 0, 0, 0, time.Local)},
+		{"2w", time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)},
+		{"2026-01-31", time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseReportSince(tt.value, now)
+		if err != nil {
+			t.Errorf("parseReportSince(%q) error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseReportSince(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "30", "d", "-3d", "1y", "2026-13-01"} {
+		if _, err := parseReportSince(bad, now); err == nil {
+			t.Errorf("parseReportSince(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFormatTokenCount(t *testing.T) {
+	tests := map[int]string{950: "950", 12_345: "12.3k", 4_100_000: "4.1M"}
+	for n, want := range tests {
+		if got := formatTokenCount(n); got != want {
+			t.Errorf("formatTokenCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,517 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleSchedule dispatches schedule subcommands
+func handleSchedule(profile string, args []string) {
+	if len(args) == 0 {
+		printScheduleUsage()
+		return
+	}
+
+	switch args[0] {
+	case "add":
+		handleScheduleAdd(profile, args[1:])
+	case "list", "ls":
+		handleScheduleList(profile, args[1:])
+	case "remove", "rm":
+		handleScheduleRemove(profile, args[1:])
+	case "run-now", "run":
+		handleScheduleRunNow(profile, args[1:])
+	case "history":
+		handleScheduleHistory(profile, args[1:])
+	case "help", "-h", "--help":
+		printScheduleUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown schedule command: %s\n", args[0])
+		printScheduleUsage()
+		os.Exit(1)
+	}
+}
+
+// printScheduleUsage prints help for schedule commands
+func printScheduleUsage() {
+	fmt.Println("Usage: agent-deck schedule <command> [options]")
+	fmt.Println()
+	fmt.Println("Send prompts to sessions on a cron schedule. Jobs run while the TUI or")
+	fmt.Println("'agent-deck scheduler' is running for the profile.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  add <name> \"prompt\"   Add a job (--cron and --session or --tool/--path)")
+	fmt.Println("  list                  List jobs with their next and last runs")
+	fmt.Println("  rm <name>             Remove a job and its history")
+	fmt.Println("  run-now <name>        Run a job immediately and print the response")
+	fmt.Println("  history <name>        Show past runs and their output")
+	fmt.Println()
+	fmt.Println("Cron: minute hour day-of-month month day-of-week, or @hourly, @daily, ...")
+	fmt.Println("  \"0 3 * * *\"            3am every day")
+	fmt.Println("  \"*/30 9-17 * * mon-fri\" every half hour in working hours")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck schedule add deps --cron \"0 3 * * *\" --session api \\")
+	fmt.Println("      \"Update dependencies and open a branch with the changes\"")
+	fmt.Println("  agent-deck schedule add flaky --cron @hourly --tool claude --path ~/ci \\")
+	fmt.Println("      \"Check the flaky test dashboard and summarize new failures\"")
+	fmt.Println("  agent-deck schedule history deps")
+}
+
+// handleScheduleAdd adds a scheduled job
+func handleScheduleAdd(profile string, args []string) {
+	fs := flag.NewFlagSet("schedule add", flag.ExitOnError)
+	cronExpr := fs.String("cron", "", "Cron expression (required)")
+	sessionRef := fs.String("session", "", "Existing session to prompt (title or ID)")
+	tool := fs.String("tool", "", "Tool of a session to create on the first run")
+	path := fs.String("path", "", "Project path of the created session")
+	title := fs.String("title", "", "Title of the created session (default: schedule-<name>)")
+	group := fs.String("group", "", "Group of the created session (default: scheduled)")
+	timeout := fs.String("timeout", "", "Time limit per run (default: 30m)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck schedule add <name> --cron <expr> (--session <id> | --tool <tool> --path <dir>) [options] \"prompt\"")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderScheduleArgs(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() != 2 || *cronExpr == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	job := session.NewScheduledJob(fs.Arg(0), *cronExpr, fs.Arg(1))
+	job.Timeout = *timeout
+	if *sessionRef != "" {
+		_, instances, _, err := loadSessionData(profile)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		inst, errMsg, errCode := ResolveSession(*sessionRef, instances)
+		if inst == nil {
+			out.Error(errMsg, errCode)
+			os.Exit(2)
+			return // unreachable, satisfies staticcheck SA5011
+		}
+		job.Session = inst.ID
+	}
+	if *tool != "" || *path != "" {
+		absPath := *path
+		if absPath != "" && !strings.HasPrefix(absPath, "~/") {
+			if p, err := filepath.Abs(absPath); err == nil {
+				absPath = p
+			}
+		}
+		job.Template = &session.PipelineTemplate{Tool: *tool, Path: absPath, Title: *title, Group: *group}
+	}
+
+	if err := session.AddScheduledJob(profile, job); err != nil {
+		code := ErrCodeInvalidOperation
+		if strings.Contains(err.Error(), "already exists") {
+			code = ErrCodeAlreadyExists
+		}
+		out.Error(err.Error(), code)
+		os.Exit(1)
+	}
+
+	msg := fmt.Sprintf("Scheduled '%s' (%s), next run %s", job.Name, job.Cron, job.NextRunAt.Format("2006-01-02 15:04"))
+	if session.SchedulerPID(profile) == 0 {
+		msg += "\nNo scheduler is running: start the TUI or 'agent-deck scheduler'"
+	}
+	out.Success(msg, scheduledJobJSON(job, nil, true))
+}
+
+// scheduledJobTarget describes where a job's prompt goes, naming existing
+// sessions by title when titles (ID → title) are known
+func scheduledJobTarget(job *session.ScheduledJob, titles map[string]string) string {
+	if job.Template == nil {
+		if title := titles[job.Session]; title != "" {
+			return title
+		}
+		return job.Session
+	}
+	tool, _, title, _ := job.TemplateSession()
+	return fmt.Sprintf("%s (new %s)", title, tool)
+}
+
+// scheduledJobJSON renders a job for --json output
+func scheduledJobJSON(job *session.ScheduledJob, titles map[string]string, success bool) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":          job.ID,
+		"name":        job.Name,
+		"cron":        job.Cron,
+		"prompt":      job.Prompt,
+		"target":      scheduledJobTarget(job, titles),
+		"next_run_at": job.NextRunAt,
+	}
+	if success {
+		data["success"] = true
+	}
+	if job.Session != "" {
+		data["session"] = job.Session
+	}
+	if job.Template != nil {
+		data["template"] = job.Template
+	}
+	if job.SessionID != "" {
+		data["session_id"] = job.SessionID
+	}
+	if job.Timeout != "" {
+		data["timeout"] = job.Timeout
+	}
+	if !job.LastRunAt.IsZero() {
+		data["last_run_at"] = job.LastRunAt
+		data["last_status"] = job.LastStatus
+	}
+	return data
+}
+
+// handleScheduleList lists the scheduled jobs of a profile
+func handleScheduleList(profile string, args []string) {
+	fs := flag.NewFlagSet("schedule list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck schedule list [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	jobs, err := session.LoadScheduledJobs(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to list scheduled jobs: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	schedulerPID := session.SchedulerPID(profile)
+	if len(jobs) == 0 {
+		out.Print("No scheduled jobs.\n", map[string]interface{}{"jobs": []interface{}{}, "scheduler_pid": schedulerPID})
+		return
+	}
+
+	titles := make(map[string]string)
+	if _, instances, _, err := loadSessionData(profile); err == nil {
+		for _, inst := range instances {
+			titles[inst.ID] = inst.Title
+		}
+	}
+
+	var sb strings.Builder
+	jobsJSON := make([]map[string]interface{}, 0, len(jobs))
+	sb.WriteString(fmt.Sprintf("%-16s %-18s %-24s %-17s %s\n", "NAME", "CRON", "TARGET", "NEXT RUN", "LAST"))
+	for _, job := range jobs {
+		last := "-"
+		if !job.LastRunAt.IsZero() {
+			last = fmt.Sprintf("%s %s ago", job.LastStatus, formatAge(job.LastRunAt))
+		}
+		sb.WriteString(fmt.Sprintf("%-16s %-18s %-24s %-17s %s\n",
+			truncateString(job.Name, 16),
+			truncateString(job.Cron, 18),
+			truncateString(scheduledJobTarget(job, titles), 24),
+			job.NextRunAt.Format("2006-01-02 15:04"),
+			last))
+		jobsJSON = append(jobsJSON, scheduledJobJSON(job, titles, false))
+	}
+	sb.WriteString("\n")
+	if schedulerPID != 0 {
+		sb.WriteString(fmt.Sprintf("Scheduler running (pid %d)\n", schedulerPID))
+	} else {
+		sb.WriteString("No scheduler is running: start the TUI or 'agent-deck scheduler'\n")
+	}
+
+	out.Print(sb.String(), map[string]interface{}{"jobs": jobsJSON, "scheduler_pid": schedulerPID})
+}
+
+// handleScheduleRemove removes a scheduled job
+func handleScheduleRemove(profile string, args []string) {
+	fs := flag.NewFlagSet("schedule rm", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck schedule rm <name> [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderScheduleArgs(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	job, err := session.RemoveScheduledJob(profile, fs.Arg(0))
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	out.Success(fmt.Sprintf("Removed scheduled job '%s'", job.Name), map[string]interface{}{
+		"success": true,
+		"id":      job.ID,
+		"name":    job.Name,
+	})
+}
+
+// handleScheduleRunNow runs a job in this process and prints its response
+func handleScheduleRunNow(profile string, args []string) {
+	fs := flag.NewFlagSet("schedule run-now", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck schedule run-now <name> [options]")
+		fmt.Println()
+		fmt.Println("Send the job's prompt now, wait for the agent to finish and print the")
+		fmt.Println("response. The run is added to the job's history; its cron times are")
+		fmt.Println("unchanged.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderScheduleArgs(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	jobs, err := session.LoadScheduledJobs(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	job := session.FindScheduledJob(jobs, fs.Arg(0))
+	if job == nil {
+		out.Error(fmt.Sprintf("scheduled job '%s' not found", fs.Arg(0)), ErrCodeNotFound)
+		os.Exit(2)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	// Keep progress logging out of the printed response
+	log.SetOutput(io.Discard)
+	run := session.RunScheduledJob(profile, job, session.ScheduleTriggerManual)
+	if run.Status == session.ScheduleFailed {
+		code := ErrCodeInvalidOperation
+		if strings.Contains(run.Error, "not finished by") {
+			code = ErrCodeTimeout
+		}
+		out.Error(fmt.Sprintf("scheduled job '%s' failed: %s", job.Name, run.Error), code)
+		os.Exit(1)
+	}
+
+	out.Print(run.Output+"\n", scheduleRunJSON(run, true))
+}
+
+// scheduleRunJSON renders a run for --json output
+func scheduleRunJSON(run *session.ScheduleRun, withOutput bool) map[string]interface{} {
+	data := map[string]interface{}{
+		"success":    run.Status == session.ScheduleDone,
+		"job":        run.JobName,
+		"trigger":    run.Trigger,
+		"status":     run.DisplayStatus(),
+		"session_id": run.SessionID,
+		"started_at": run.StartedAt,
+	}
+	if !run.FinishedAt.IsZero() {
+		data["finished_at"] = run.FinishedAt
+	}
+	if run.Error != "" {
+		data["error"] = run.Error
+	}
+	if withOutput {
+		data["prompt"] = run.Prompt
+		data["output"] = run.Output
+	}
+	return data
+}
+
+// handleScheduleHistory shows the past runs of a job
+func handleScheduleHistory(profile string, args []string) {
+	fs := flag.NewFlagSet("schedule history", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	limit := fs.Int("n", 10, "Number of runs to show")
+	full := fs.Bool("full", false, "Show complete outputs")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck schedule history <name> [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderScheduleArgs(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	jobs, err := session.LoadScheduledJobs(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	job := session.FindScheduledJob(jobs, fs.Arg(0))
+	if job == nil {
+		out.Error(fmt.Sprintf("scheduled job '%s' not found", fs.Arg(0)), ErrCodeNotFound)
+		os.Exit(2)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	runs, err := session.ListScheduleRuns(profile, job.ID)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if *limit > 0 && len(runs) > *limit {
+		runs = runs[:*limit]
+	}
+	if len(runs) == 0 {
+		out.Print(fmt.Sprintf("No runs of '%s' yet.\n", job.Name), map[string]interface{}{"runs": []interface{}{}})
+		return
+	}
+
+	var sb strings.Builder
+	runsJSON := make([]map[string]interface{}, 0, len(runs))
+	for _, run := range runs {
+		duration := "-"
+		if !run.FinishedAt.IsZero() {
+			duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+		}
+		sb.WriteString(fmt.Sprintf("%s  %-11s %-6s %s\n",
+			run.StartedAt.Format("2006-01-02 15:04"), run.DisplayStatus(), run.Trigger, duration))
+		switch {
+		case run.Error != "":
+			sb.WriteString(fmt.Sprintf("    %s\n", run.Error))
+		case *full:
+			for _, line := range strings.Split(strings.TrimRight(run.Output, "\n"), "\n") {
+				sb.WriteString("    " + line + "\n")
+			}
+		case run.Output != "":
+			sb.WriteString(fmt.Sprintf("    %s\n", truncateString(strings.Join(strings.Fields(run.Output), " "), 100)))
+		}
+		runsJSON = append(runsJSON, scheduleRunJSON(run, true))
+	}
+
+	out.Print(sb.String(), map[string]interface{}{"job": job.Name, "runs": runsJSON})
+}
+
+// reorderScheduleArgs moves flags ahead of the job name and prompt so
+// "add deps --cron @daily ..." parses
+func reorderScheduleArgs(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+
+	// Known flags that take a value
+	valueFlags := map[string]bool{
+		"--cron": true, "-cron": true,
+		"--session": true, "-session": true,
+		"--tool": true, "-tool": true,
+		"--path": true, "-path": true,
+		"--title": true, "-title": true,
+		"--group": true, "-group": true,
+		"--timeout": true, "-timeout": true,
+		"-n": true,
+	}
+
+	var flags []string
+	var positional []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Check if it's a flag
+		if strings.HasPrefix(arg, "-") {
+			flags = append(flags, arg)
+
+			// Check if this flag takes a value (and value is separate)
+			if !strings.Contains(arg, "=") && valueFlags[arg] && i+1 < len(args) {
+				i++
+				flags = append(flags, args[i])
+			}
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+
+	// Return flags first, then positional args
+	return append(flags, positional...)
+}
+
+// handleScheduler runs a profile's scheduled jobs in the foreground, for
+// machines where the TUI is not left open
+func handleScheduler(profile string, args []string) {
+	fs := flag.NewFlagSet("scheduler", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck scheduler")
+		fmt.Println()
+		fmt.Println("Run scheduled jobs until interrupted. Only one process per profile runs")
+		fmt.Println("jobs at a time; if the TUI already does, this one takes over when it exits.")
+	}
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.Ltime)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if pid := session.SchedulerPID(profile); pid != 0 && pid != os.Getpid() {
+		fmt.Printf("Scheduler already running (pid %d); waiting to take over\n", pid)
+	} else {
+		fmt.Println("Running scheduled jobs (Ctrl+C to stop)")
+	}
+	session.RunScheduler(ctx, profile, func(run *session.ScheduleRun) {
+		fmt.Println(session.ScheduleRunSummary(run))
+	})
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleSecrets dispatches secrets subcommands
+func handleSecrets(args []string) {
+	if len(args) == 0 {
+		printSecretsHelp()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "set":
+		handleSecretsSet(args[1:])
+	case "get":
+		handleSecretsGet(args[1:])
+	case "list", "ls":
+		handleSecretsList(args[1:])
+	case "rm", "remove", "delete":
+		handleSecretsRemove(args[1:])
+	case "help", "-h", "--help":
+		printSecretsHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown secrets command '%s'\n", args[0])
+		printSecretsHelp()
+		os.Exit(1)
+	}
+}
+
+// printSecretsHelp prints help for secrets commands
+func printSecretsHelp() {
+	fmt.Println("Usage: agent-deck secrets <command> [options]")
+	fmt.Println()
+	fmt.Println("Manage the local encrypted vault used for MCP env and header values.")
+	fmt.Println("The vault key is kept in the OS keyring (falls back to ~/.agent-deck/vault.key).")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  set <name> [value]  Store a secret (prompts if no value, reads stdin if piped)")
+	fmt.Println("  get <name>          Print a secret")
+	fmt.Println("  list                List secret names (values are never shown)")
+	fmt.Println("  rm <name>           Remove a secret")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --project <path>    Use a project scope instead of the global scope")
+	fmt.Println()
+	fmt.Println("Reference secrets in config.toml:")
+	fmt.Println("  [mcps.github]")
+	fmt.Println("  command = \"npx\"")
+	fmt.Println("  args = [\"-y\", \"@modelcontextprotocol/server-github\"]")
+	fmt.Println("  env = { GITHUB_TOKEN = \"vault:github_token\" }   # or \"env:GITHUB_TOKEN\"")
+	fmt.Println()
+	fmt.Println("Project secrets apply to sessions at or below the project path and")
+	fmt.Println("override global secrets with the same name.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck secrets set github_token")
+	fmt.Println("  echo \"$TOKEN\" | agent-deck secrets set --project ~/work/api github_token")
+	fmt.Println("  agent-deck secrets list")
+}
+
+// readSecretValue reads a secret from the terminal without echo, or from piped stdin
+func readSecretValue(name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		return string(value), nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	value, err := reader.ReadString('\n')
+	if err != nil && value == "" {
+		return "", fmt.Errorf("no value on stdin")
+	}
+	return strings.TrimRight(value, "\r\n"), nil
+}
+
+// handleSecretsSet stores a secret in the vault
+func handleSecretsSet(args []string) {
+	fs := flag.NewFlagSet("secrets set", flag.ExitOnError)
+	project := fs.String("project", "", "Project path for a project-scoped secret")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck secrets set [options] <name> [value]")
+		fmt.Println()
+		fmt.Println("Store a secret. Omit the value to be prompted (avoids shell history).")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if fs.NArg() < 1 {
+		out.Error("secret name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+	if err := session.ValidateSecretName(name); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	value := fs.Arg(1)
+	if fs.NArg() < 2 {
+		var err error
+		if value, err = readSecretValue(name); err != nil {
+			out.Error(fmt.Sprintf("failed to read value: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+	}
+	if value == "" {
+		out.Error("secret value cannot be empty", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	if err := session.SetSecret(*project, name, value); err != nil {
+		out.Error(fmt.Sprintf("failed to store secret: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	scope := secretScopeLabel(session.NormalizeSecretProject(*project))
+	out.Success(fmt.Sprintf("Stored %s (%s)", name, scope), map[string]interface{}{
+		"success": true,
+		"name":    name,
+		"scope":   scope,
+	})
+}
+
+// handleSecretsGet prints a secret from the vault
+func handleSecretsGet(args []string) {
+	fs := flag.NewFlagSet("secrets get", flag.ExitOnError)
+	project := fs.String("project", "", "Resolve for a project (project scope, then global)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck secrets get [options] <name>")
+		fmt.Println()
+		fmt.Println("Print a secret value.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	if fs.NArg() < 1 {
+		out.Error("secret name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+
+	value, ok, err := session.LookupSecret(*project, name)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to read vault: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if !ok {
+		out.Error(fmt.Sprintf("secret '%s' not found", name), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	out.Print(value+"\n", map[string]interface{}{
+		"name":  name,
+		"value": value,
+	})
+}
+
+// handleSecretsList lists secret names by scope
+func handleSecretsList(args []string) {
+	fs := flag.NewFlagSet("secrets list", flag.ExitOnError)
+	project := fs.String("project", "", "Only show secrets visible to this project")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck secrets list [options]")
+		fmt.Println()
+		fmt.Println("List secret names. Values are never shown.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	scopes, err := session.ListSecrets()
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to read vault: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Filter to scopes visible from a project (the project or its parents, plus global)
+	if *project != "" {
+		dir := session.NormalizeSecretProject(*project)
+		for scope := range scopes {
+			if scope != "" && dir != scope && !strings.HasPrefix(dir, scope+string(os.PathSeparator)) {
+				delete(scopes, scope)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		keys = append(keys, scope)
+	}
+	sort.Strings(keys) // "" (global) sorts first
+
+	if *jsonOutput {
+		type scopeInfo struct {
+			Scope string   `json:"scope"`
+			Names []string `json:"names"`
+		}
+		result := make([]scopeInfo, 0, len(keys))
+		for _, scope := range keys {
+			result = append(result, scopeInfo{Scope: secretScopeLabel(scope), Names: scopes[scope]})
+		}
+		out.Print("", map[string]interface{}{"scopes": result})
+		return
+	}
+
+	if quietMode {
+		for _, scope := range keys {
+			for _, name := range scopes[scope] {
+				fmt.Println(name)
+			}
+		}
+		return
+	}
+
+	if len(keys) == 0 {
+		fmt.Println("No secrets stored.")
+		fmt.Println("Add one with: agent-deck secrets set <name>")
+		return
+	}
+
+	for i, scope := range keys {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n", secretScopeLabel(scope))
+		for _, name := range scopes[scope] {
+			fmt.Printf("  %s %s\n", bulletSymbol, name)
+		}
+	}
+}
+
+// handleSecretsRemove deletes a secret from the vault
+func handleSecretsRemove(args []string) {
+	fs := flag.NewFlagSet("secrets rm", flag.ExitOnError)
+	project := fs.String("project", "", "Project path for a project-scoped secret")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck secrets rm [options] <name>")
+		fmt.Println()
+		fmt.Println("Remove a secret.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if fs.NArg() < 1 {
+		out.Error("secret name is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+
+	removed, err := session.DeleteSecret(*project, name)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to update vault: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if !removed {
+		out.Error(fmt.Sprintf("secret '%s' not found", name), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	out.Success(fmt.Sprintf("Removed %s", name), map[string]interface{}{
+		"success": true,
+		"name":    name,
+	})
+}
+
+// secretScopeLabel returns a display name for a vault scope
+func secretScopeLabel(scope string) string {
+	if scope == "" {
+		return "global"
+	}
+	return FormatPath(scope)
+}
+
+// handleMCPExec launches a stdio MCP with secret references resolved.
+// Generated MCP configs point here so resolved secrets never touch disk.
+func handleMCPExec(args []string) {
+	fs := flag.NewFlagSet("mcp exec", flag.ExitOnError)
+	project := fs.String("project", "", "Project path used to resolve project secrets")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp exec [--project <path>] <mcp-name>")
+		fmt.Println()
+		fmt.Println("Run a stdio MCP from config.toml with vault:/env: references resolved.")
+		fmt.Println("Used as the command in generated MCP configs; not usually run by hand.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	// stdout is the MCP protocol channel, so all errors go to stderr
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Error: MCP name is required")
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+
+	def := session.GetMCPDef(name)
+	if def == nil {
+		fmt.Fprintf(os.Stderr, "Error: MCP '%s' not found in config.toml\n", name)
+		os.Exit(2)
+	}
+	if def.Command == "" {
+		fmt.Fprintf(os.Stderr, "Error: MCP '%s' has no command (HTTP MCPs can't be exec'd)\n", name)
+		os.Exit(1)
+	}
+
+	env, err := session.ResolveMCPEnv(def, *project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: MCP '%s': %v\n", name, err)
+		os.Exit(1)
+	}
+
+	path, err := exec.LookPath(def.Command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: MCP '%s': %v\n", name, err)
+		os.Exit(1)
+	}
+
+	cmdEnv := os.Environ()
+	for k, v := range env {
+		cmdEnv = append(cmdEnv, k+"="+v)
+	}
+
+	argv := append([]string{def.Command}, def.Args...)
+	if err := syscall.Exec(path, argv, cmdEnv); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to exec %s: %v\n", def.Command, err)
+		os.Exit(1)
+	}
+}
+
+// handleMCPEnv prints export statements for the vault secrets used in HTTP
+// MCP headers. Generated MCP configs refer to these variables instead of
+// holding the values, and sessions eval this output when they start.
+func handleMCPEnv(args []string) {
+	fs := flag.NewFlagSet("mcp env", flag.ExitOnError)
+	project := fs.String("project", "", "Project path used to resolve project secrets")
+	mcps := fs.String("mcps", "", "Comma-separated MCPs whose secrets to print")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp env [--project <path>] --mcps <name,...>")
+		fmt.Println()
+		fmt.Println("Print export statements for the vault:/env: secrets in the headers of the")
+		fmt.Println("given HTTP MCPs.")
+		fmt.Println("Sessions run this at launch; not usually run by hand.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	// stdout is eval'd by the session shell, so all errors go to stderr
+	var names []string
+	for _, name := range strings.Split(*mcps, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	env, err := session.MCPHeaderSecretEnv(*project, names)
+	fmt.Print(session.ShellExports(env))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
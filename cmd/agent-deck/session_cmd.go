@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/asheshgoplani/agent-deck/internal/clipboard"
+	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/profile"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/asheshgoplani/agent-deck/internal/tmux"
@@ -45,8 +46,26 @@ func handleSession(profile string, args []string) {
 		handleSessionSet(profile, args[1:])
 	case "send":
 		handleSessionSend(profile, args[1:])
+	case "ask":
+		handleSessionAsk(profile, args[1:])
+	case "queue":
+		handleSessionQueue(profile, args[1:])
 	case "output":
 		handleSessionOutput(profile, args[1:])
+	case "diff":
+		handleSessionDiff(profile, args[1:])
+	case "tree":
+		handleSessionTree(profile, args[1:])
+	case "checkpoints":
+		handleSessionCheckpoints(profile, args[1:])
+	case "rollback":
+		handleSessionRollback(profile, args[1:])
+	case "approve":
+		handleSessionApproval(profile, args[1:], true)
+	case "deny":
+		handleSessionApproval(profile, args[1:], false)
+	case "budget":
+		handleSessionBudget(profile, args[1:])
 	case "help", "--help", "-h":
 		printSessionHelp()
 	default:
@@ -72,7 +91,16 @@ func printSessionHelp() {
 	fmt.Println("  current                 Show current session and profile (auto-detect)")
 	fmt.Println("  set <id> <field> <value>  Update session property")
 	fmt.Println("  send <id> <message>     Send a message to a running session")
+	fmt.Println("  send --group|--status|--tool|--sessions <value> <message>  Broadcast to matching sessions")
+	fmt.Println("  ask <id> <prompt>       Send a prompt and wait for the agent's reply")
+	fmt.Println("  queue add|list|clear <id>  Queue prompts to send when the agent is ready")
 	fmt.Println("  output <id>             Get the last response from a session")
+	fmt.Println("  diff <id>               Show git branch, changed files and diff")
+	fmt.Println("  tree <id>               Show the session's forks and sub-sessions")
+	fmt.Println("  checkpoints <id>        List working tree checkpoints with their prompts")
+	fmt.Println("  rollback <id> <n>       Restore the working tree to checkpoint n")
+	fmt.Println("  approve|deny <id>       Answer a tool permission prompt without attaching")
+	fmt.Println("  budget <id>             Show usage against the session's budget (--override to continue)")
 	fmt.Println("  set-parent <id> <parent>  Link session as sub-session of parent")
 	fmt.Println("  unset-parent <id>       Remove sub-session link")
 	fmt.Println()
@@ -93,6 +121,13 @@ func printSessionHelp() {
 	fmt.Println("  agent-deck session unset-parent sub-task             # Remove sub-session link")
 	fmt.Println("  agent-deck session output my-project                 # Get last response from session")
 	fmt.Println("  agent-deck session output my-project --json          # Get response as JSON")
+	fmt.Println("  agent-deck session ask my-project \"fix the build\" --timeout 10m --json")
+	fmt.Println("  agent-deck session send --group work --tool claude \"/compact\"   # Every Claude session in work")
+	fmt.Println("  agent-deck session diff my-project --stat            # Branch and changed files only")
+	fmt.Println("  agent-deck session tree --diff my-fork               # Compare fork's last response to its source")
+	fmt.Println("  agent-deck session rollback my-project 3             # Restore files from checkpoint 3")
+	fmt.Println("  agent-deck session approve my-project --always       # Yes, and don't ask again")
+	fmt.Println("  agent-deck session budget my-project --override      # Let an over-budget session continue")
 	fmt.Println()
 	fmt.Println("Set command fields:")
 	fmt.Println("  title              Session title")
@@ -166,17 +201,23 @@ func handleSessionStart(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	// A repo's .agent-deck.toml only applies once trusted
+	checkRepoConfigTrust(inst.ProjectPath, !*jsonOutput && !quietMode)
+
 	// Start the session (with or without initial message)
+	var startErr error
 	if initialMessage != "" {
-		if err := inst.StartWithMessage(initialMessage); err != nil {
-			out.Error(fmt.Sprintf("failed to start session: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
-		}
+		startErr = inst.StartWithMessage(initialMessage)
 	} else {
-		if err := inst.Start(); err != nil {
-			out.Error(fmt.Sprintf("failed to start session: %v", err), ErrCodeInvalidOperation)
-			os.Exit(1)
+		startErr = inst.Start()
+	}
+	if startErr != nil {
+		// Keep a failed worktree bootstrap visible in the session list
+		if inst.BootstrapError != "" {
+			_ = saveSessionData(storage, instances)
 		}
+		out.Error(fmt.Sprintf("failed to start session: %v", startErr), ErrCodeInvalidOperation)
+		os.Exit(1)
 	}
 
 	// Capture session ID from tmux env before saving to JSON
@@ -355,11 +396,14 @@ func handleSessionFork(profile string, args []string) {
 	titleShort := fs.String("t", "", "Title for forked session (short)")
 	group := fs.String("group", "", "Group for forked session")
 	groupShort := fs.String("g", "", "Group for forked session (short)")
+	worktreeBranch := fs.String("worktree", "", "Fork into a new git worktree on this branch (created from the parent's HEAD)")
+	worktreeBranchShort := fs.String("w", "", "Fork into a new git worktree on this branch (short)")
+	worktreeLocation := fs.String("location", "", "Worktree location: sibling, subdirectory")
 
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck session fork <id|title> [options]")
 		fmt.Println()
-		fmt.Println("Fork a Claude session with conversation context.")
+		fmt.Println("Fork a Claude, Gemini or Codex session with conversation context.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -368,6 +412,7 @@ func handleSessionFork(profile string, args []string) {
 		fmt.Println("  agent-deck session fork my-project")
 		fmt.Println("  agent-deck session fork my-project -t \"my-fork\"")
 		fmt.Println("  agent-deck session fork my-project -t \"my-fork\" -g \"experiments\"")
+		fmt.Println("  agent-deck session fork -w fork/retry-approach my-project")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -381,6 +426,7 @@ func handleSessionFork(profile string, args []string) {
 	// Merge short and long flags
 	forkTitle := mergeFlags(*title, *titleShort)
 	forkGroup := mergeFlags(*group, *groupShort)
+	forkBranch := mergeFlags(*worktreeBranch, *worktreeBranchShort)
 
 	// Load sessions
 	storage, instances, groupsData, err := loadSessionData(profile)
@@ -400,20 +446,20 @@ func handleSessionFork(profile string, args []string) {
 		return // unreachable, satisfies staticcheck SA5011
 	}
 
-	// Verify it's a Claude session
-	if inst.Tool != "claude" {
-		out.Error(fmt.Sprintf("session '%s' is not a Claude session (tool: %s)", inst.Title, inst.Tool), ErrCodeInvalidOperation)
+	// Verify its tool can fork
+	if !session.ToolSupportsFork(inst.Tool) {
+		out.Error(fmt.Sprintf("session '%s' cannot be forked (tool: %s): %s", inst.Title, inst.Tool, inst.ForkUnavailableReason()), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
 
 	// Try to capture session ID from tmux if missing (handles pre-fix sessions)
-	if inst.ClaudeSessionID == "" && inst.Exists() {
+	if !inst.CanFork() && inst.Exists() {
 		inst.PostStartSync(2 * time.Second)
 	}
 
 	// Verify it can be forked
-	if !inst.CanFork() {
-		out.Error(fmt.Sprintf("session '%s' cannot be forked: no active Claude session ID", inst.Title), ErrCodeInvalidOperation)
+	if reason := inst.ForkUnavailableReason(); reason != "" {
+		out.Error(fmt.Sprintf("session '%s' cannot be forked: %s", inst.Title, reason), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
 
@@ -427,15 +473,23 @@ func handleSessionFork(profile string, args []string) {
 		forkGroup = inst.GroupPath
 	}
 
-	// Create the forked instance
-	forkedInst, _, err := inst.CreateForkedInstance(forkTitle, forkGroup)
+	// Create the forked instance, in its own worktree if requested
+	var forkedInst *session.Instance
+	if forkBranch != "" {
+		forkedInst, _, err = inst.CreateForkedInstanceInWorktree(forkTitle, forkGroup, forkBranch, *worktreeLocation, nil)
+	} else {
+		forkedInst, _, err = inst.CreateForkedInstance(forkTitle, forkGroup)
+	}
 	if err != nil {
 		out.Error(fmt.Sprintf("failed to create fork: %v", err), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
 
-	// Start the forked session
+	// Start the forked session; a fork that isn't saved leaves no worktree behind
 	if err := forkedInst.Start(); err != nil {
+		if err := forkedInst.RemoveForkWorktree(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove fork worktree: %v\n", err)
+		}
 		out.Error(fmt.Sprintf("failed to start forked session: %v", err), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
@@ -459,12 +513,19 @@ func handleSessionFork(profile string, args []string) {
 	}
 
 	// Output success
-	out.Success(fmt.Sprintf("Forked session: %s -> %s (%s)", inst.Title, forkedInst.Title, TruncateID(forkedInst.ID)), map[string]interface{}{
+	result := map[string]interface{}{
 		"success":   true,
 		"parent_id": inst.ID,
 		"new_id":    forkedInst.ID,
 		"new_title": forkedInst.Title,
-	})
+	}
+	message := fmt.Sprintf("Forked session: %s -> %s (%s)", inst.Title, forkedInst.Title, TruncateID(forkedInst.ID))
+	if forkedInst.IsWorktree() {
+		result["worktree_path"] = forkedInst.WorktreePath
+		result["worktree_branch"] = forkedInst.WorktreeBranch
+		message += fmt.Sprintf(" in worktree %s [%s]", FormatPath(forkedInst.WorktreePath), forkedInst.WorktreeBranch)
+	}
+	out.Success(message, result)
 }
 
 // handleSessionAttach attaches to a session interactively
@@ -611,9 +672,37 @@ func handleSessionShow(profile string, args []string) {
 		jsonData["command"] = inst.Command
 	}
 
+	if inst.BootstrapError != "" {
+		jsonData["bootstrap_error"] = inst.BootstrapError
+	}
+
+	if inst.PendingApproval != nil {
+		jsonData["pending_approval"] = approvalJSON(inst.PendingApproval)
+	}
+
+	if inst.BudgetExceeded != "" {
+		jsonData["budget_exceeded"] = inst.BudgetExceeded
+	}
+
+	if inst.IsFork() {
+		jsonData["forked_from"] = map[string]interface{}{
+			"session_id":        inst.ForkedFromID,
+			"claude_session_id": inst.ForkedFromClaudeID,
+			"forked_at":         inst.ForkedAt.Format(time.RFC3339),
+		}
+	}
+
+	settings, repoConfig := inst.EffectiveSettings()
+	jsonData["settings"] = settings
+	if repoConfig != nil {
+		jsonData["repo_config"] = map[string]interface{}{
+			"path":    repoConfig.Path,
+			"trusted": repoConfig.Trusted,
+		}
+	}
+
 	if inst.Tool == "claude" {
 		jsonData["claude_session_id"] = inst.ClaudeSessionID
-		jsonData["can_fork"] = inst.CanFork()
 		jsonData["can_restart"] = inst.CanRestart()
 
 		if mcpInfo != nil && mcpInfo.HasAny() {
@@ -625,6 +714,11 @@ func handleSessionShow(profile string, args []string) {
 		}
 	}
 
+	jsonData["can_fork"] = inst.CanFork()
+	if reason := inst.ForkUnavailableReason(); reason != "" {
+		jsonData["fork_unavailable_reason"] = reason
+	}
+
 	if inst.Exists() {
 		tmuxSession := inst.GetTmuxSession()
 		if tmuxSession != nil {
@@ -639,6 +733,12 @@ func handleSessionShow(profile string, args []string) {
 	sb.WriteString(fmt.Sprintf("Profile: %s\n", profile))
 	sb.WriteString(fmt.Sprintf("ID:      %s\n", inst.ID))
 	sb.WriteString(fmt.Sprintf("Status:  %s %s\n", StatusSymbol(inst.Status), StatusString(inst.Status)))
+	if inst.PendingApproval != nil {
+		sb.WriteString(fmt.Sprintf("Asks:    %s\n", inst.PendingApproval))
+	}
+	if inst.BudgetExceeded != "" {
+		sb.WriteString(fmt.Sprintf("Budget:  %s (see 'session budget')\n", inst.BudgetExceeded))
+	}
 	sb.WriteString(fmt.Sprintf("Path:    %s\n", FormatPath(inst.ProjectPath)))
 
 	if inst.GroupPath != "" {
@@ -651,6 +751,27 @@ func handleSessionShow(profile string, args []string) {
 		sb.WriteString(fmt.Sprintf("Command: %s\n", inst.Command))
 	}
 
+	if inst.BootstrapError != "" {
+		sb.WriteString(fmt.Sprintf("Error:   worktree bootstrap failed: %s\n", inst.BootstrapError))
+	}
+
+	if inst.IsFork() {
+		source := inst.ForkedFromClaudeID
+		for _, other := range instances {
+			if other.ID == inst.ForkedFromID {
+				source = other.Title
+				break
+			}
+		}
+		sb.WriteString(fmt.Sprintf("Forked:  from %s at %s\n", source, inst.ForkedAt.Format("2006-01-02 15:04:05")))
+	}
+
+	if reason := inst.ForkUnavailableReason(); reason != "" {
+		sb.WriteString(fmt.Sprintf("Fork:    no (%s)\n", reason))
+	} else {
+		sb.WriteString(fmt.Sprintf("Fork:    yes (%s)\n", session.ToolForkMethod(inst.Tool)))
+	}
+
 	if inst.Tool == "claude" {
 		if inst.ClaudeSessionID != "" {
 			truncatedID := inst.ClaudeSessionID
@@ -694,6 +815,19 @@ func handleSessionShow(profile string, args []string) {
 		}
 	}
 
+	sb.WriteString("\nSettings:\n")
+	if repoConfig != nil && !repoConfig.Trusted {
+		sb.WriteString(fmt.Sprintf("  (%s is not trusted and is ignored; run 'agent-deck trust %s')\n",
+			FormatPath(repoConfig.Path), FormatPath(repoConfig.RepoRoot)))
+	}
+	for _, setting := range settings {
+		value := setting.Value
+		if value == "" {
+			value = "(unset)"
+		}
+		sb.WriteString(fmt.Sprintf("  %-27s %s (%s)\n", setting.Key, value, setting.Source))
+	}
+
 	out.Print(sb.String(), jsonData)
 }
 
@@ -1175,13 +1309,43 @@ func handleSessionSend(profile string, args []string) {
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("q", false, "Quiet mode")
 	noWait := fs.Bool("no-wait", false, "Don't wait for agent to be ready (send immediately)")
-	if err := fs.Parse(args); err != nil {
+	group := fs.String("group", "", "Broadcast to every session in this group (and its subgroups)")
+	status := fs.String("status", "", "Broadcast to sessions with this status ("+strings.Join(session.BroadcastStatuses, ", ")+")")
+	tool := fs.String("tool", "", "Broadcast to sessions of this tool")
+	sessions := fs.String("sessions", "", "Broadcast to these comma-separated sessions (titles or IDs)")
+	force := fs.Bool("force", false, "Broadcast: also type into sessions that are mid-turn")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session send <id|title> <message> [options]")
+		fmt.Println("       agent-deck session send [--group <path>] [--status <status>] [--tool <tool>] [--sessions a,b] <message> [options]")
+		fmt.Println()
+		fmt.Println("Send a message to a running session, waiting for the agent to be ready.")
+		fmt.Println("With --group, --status, --tool or --sessions the message is broadcast to")
+		fmt.Println("every matching session; the filters combine.")
+		fmt.Println()
+		fmt.Println("Statuses: " + strings.Join(session.BroadcastStatuses, ", "))
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
 		os.Exit(1)
 	}
 	remaining := fs.Args()
 
 	out := NewCLIOutput(*jsonOutput, *quiet)
 
+	filter := session.BroadcastFilter{Group: *group, Status: *status, Tool: *tool}
+	if err := filter.Validate(); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if !filter.IsEmpty() || *sessions != "" {
+		handleSessionBroadcast(profile, filter, *sessions, strings.Join(remaining, " "), session.BroadcastOptions{Force: *force, NoWait: *noWait}, out)
+		return
+	}
+
 	if len(remaining) < 2 {
 		out.Error("usage: agent-deck session send <id> <message>", ErrCodeInvalidOperation)
 		os.Exit(1)
@@ -1213,6 +1377,10 @@ func handleSessionSend(profile string, args []string) {
 		out.Error(fmt.Sprintf("session '%s' is not running", inst.Title), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
+	if err := inst.BudgetError(); err != nil {
+		out.Error(err.Error(), ErrCodeBudgetExceeded)
+		os.Exit(1)
+	}
 
 	// Get tmux session
 	tmuxSess := inst.GetTmuxSession()
@@ -1230,17 +1398,8 @@ func handleSessionSend(profile string, args []string) {
 	}
 
 	// Send message via tmux
-	tmuxName := tmuxSess.Name
-	cmd := exec.Command("tmux", "send-keys", "-l", "-t", tmuxName, message)
-	if err := cmd.Run(); err != nil {
-		out.Error(fmt.Sprintf("failed to send message: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
-
-	// Send Enter
-	cmd = exec.Command("tmux", "send-keys", "-t", tmuxName, "Enter")
-	if err := cmd.Run(); err != nil {
-		out.Error(fmt.Sprintf("failed to send Enter: %v", err), ErrCodeInvalidOperation)
+	if err := sendMessageToTmux(tmuxSess.Name, message); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
 
@@ -1252,6 +1411,21 @@ func handleSessionSend(profile string, args []string) {
 	})
 }
 
+// sendMessageToTmux types message into a tmux session and presses Enter
+func sendMessageToTmux(tmuxName, message string) error {
+	cmd := exec.Command("tmux", "send-keys", "-l", "-t", tmuxName, message)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	// Send Enter
+	cmd = exec.Command("tmux", "send-keys", "-t", tmuxName, "Enter")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to send Enter: %w", err)
+	}
+	return nil
+}
+
 // waitForAgentReady waits for Claude/Gemini/other agents to be ready for input
 // Uses status detection: waits for "active" → "waiting" transition
 func waitForAgentReady(tmuxSess *tmux.Session, tool string) error {
@@ -1403,6 +1577,131 @@ func handleSessionOutput(profile string, args []string) {
 	out.Print(sb.String(), jsonData)
 }
 
+// handleSessionDiff shows the git state of a session's working directory
+func handleSessionDiff(profile string, args []string) {
+	fs := flag.NewFlagSet("session diff", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	statOnly := fs.Bool("stat", false, "Show branch and changed files without the diff")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session diff [id|title] [options]")
+		fmt.Println()
+		fmt.Println("Show the branch, ahead/behind counts, changed files and uncommitted diff")
+		fmt.Println("of a session's working directory. If no ID is provided, auto-detects current session.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	identifier := fs.Arg(0)
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	// Load sessions
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSessionOrCurrent(identifier, instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	workDir := inst.GetActualWorkDir()
+	status, err := git.GetStatus(workDir)
+	if err != nil {
+		out.Error(fmt.Sprintf("%s is not a git repository", FormatPath(workDir)), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	diff := ""
+	if !*statOnly {
+		if diff, err = git.GetDiff(workDir); err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+	}
+
+	// Quiet mode: just print the raw diff
+	if quietMode {
+		fmt.Print(diff)
+		return
+	}
+
+	files := make([]map[string]interface{}, 0, len(status.Files))
+	for _, f := range status.Files {
+		file := map[string]interface{}{
+			"path":   f.Path,
+			"status": f.Code(),
+		}
+		if f.OrigPath != "" {
+			file["orig_path"] = f.OrigPath
+		}
+		files = append(files, file)
+	}
+	jsonData := map[string]interface{}{
+		"success":       true,
+		"session_id":    inst.ID,
+		"session_title": inst.Title,
+		"path":          workDir,
+		"branch":        status.Branch,
+		"detached":      status.Detached,
+		"upstream":      status.Upstream,
+		"ahead":         status.Ahead,
+		"behind":        status.Behind,
+		"dirty":         status.Dirty(),
+		"files":         files,
+	}
+	if !*statOnly {
+		jsonData["diff"] = diff
+	}
+
+	// Build human-readable output
+	var sb strings.Builder
+	branch := status.Branch
+	if status.Detached {
+		branch = "(detached HEAD)"
+	}
+	sb.WriteString(fmt.Sprintf("Session: %s\n", inst.Title))
+	sb.WriteString(fmt.Sprintf("Path:    %s\n", FormatPath(workDir)))
+	sb.WriteString(fmt.Sprintf("Branch:  %s", branch))
+	if status.Upstream != "" {
+		sb.WriteString(fmt.Sprintf(" → %s (↑%d ↓%d)", status.Upstream, status.Ahead, status.Behind))
+	}
+	sb.WriteString("\n")
+	if !status.Dirty() {
+		sb.WriteString("\nWorking tree clean\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("\nChanged files (%d):\n", len(status.Files)))
+		for _, f := range status.Files {
+			if f.OrigPath != "" {
+				sb.WriteString(fmt.Sprintf("  %s %s → %s\n", f.Code(), f.OrigPath, f.Path))
+			} else {
+				sb.WriteString(fmt.Sprintf("  %s %s\n", f.Code(), f.Path))
+			}
+		}
+	}
+	if diff != "" {
+		sb.WriteString("---\n")
+		sb.WriteString(strings.TrimRight(diff, "\n"))
+	}
+
+	out.Print(strings.TrimRight(sb.String(), "\n")+"\n", jsonData)
+}
+
 // handleSessionCurrent shows current session and profile (auto-detected)
 // Uses a fast path that reads session data without tmux initialization (LoadLite).
 func handleSessionCurrent(profileArg string, args []string) {
@@ -1557,3 +1856,140 @@ func matchInstanceDataByTmuxName(instances []*session.InstanceData, tmuxSessionN
 	}
 	return nil
 }
+
+// handleSessionTree shows the fork and sub-session lineage of a conversation
+func handleSessionTree(profile string, args []string) {
+	fs := flag.NewFlagSet("session tree", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	diffParent := fs.Bool("diff", false, "Diff the session's last response against the session it came from")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session tree [id|title] [options]")
+		fmt.Println()
+		fmt.Println("Show every fork and sub-session descended from the same conversation,")
+		fmt.Println("with each session's status, last prompt and estimated cost.")
+		fmt.Println("If no ID is provided, auto-detects current session.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	identifier := fs.Arg(0)
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSessionOrCurrent(identifier, instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	root := session.BuildLineage(instances, inst.ID)
+
+	if *diffParent {
+		node := root.Find(inst.ID)
+		if node.Parent == nil {
+			out.Error(fmt.Sprintf("session '%s' is not a fork or sub-session", inst.Title), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		diff, err := session.DiffLastResponses(node.Parent.Session, inst)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		human := diff
+		if diff == "" {
+			human = "Last responses are identical\n"
+		}
+		out.Print(human, map[string]interface{}{
+			"success": true,
+			"from":    node.Parent.Session.ID,
+			"to":      inst.ID,
+			"diff":    diff,
+		})
+		return
+	}
+
+	// Cost comes from parsing each session's files, so read it once
+	costs := make(map[string]float64)
+	for _, n := range root.Walk() {
+		costs[n.Session.ID] = n.Session.EstimatedCost()
+	}
+
+	var toJSON func(n *session.LineageNode) map[string]interface{}
+	toJSON = func(n *session.LineageNode) map[string]interface{} {
+		s := n.Session
+		node := map[string]interface{}{
+			"id":             s.ID,
+			"title":          s.Title,
+			"relation":       n.Relation,
+			"status":         StatusString(s.Status),
+			"tool":           s.Tool,
+			"latest_prompt":  s.LatestPrompt,
+			"estimated_cost": costs[s.ID],
+			"created_at":     s.CreatedAt.Format(time.RFC3339),
+		}
+		if !s.ForkedAt.IsZero() {
+			node["forked_at"] = s.ForkedAt.Format(time.RFC3339)
+			node["forked_from_claude_id"] = s.ForkedFromClaudeID
+		}
+		children := make([]map[string]interface{}, 0, len(n.Children))
+		for _, c := range n.Children {
+			children = append(children, toJSON(c))
+		}
+		node["children"] = children
+		return node
+	}
+
+	var sb strings.Builder
+	for _, n := range root.Walk() {
+		s := n.Session
+		prefix := n.TreePrefix()
+		line := fmt.Sprintf("%s%s %s", prefix, StatusSymbol(s.Status), s.Title)
+		if n.Relation == session.LineageFork && !s.ForkedAt.IsZero() {
+			line += fmt.Sprintf("  [fork %s]", s.ForkedAt.Format("2006-01-02 15:04"))
+		} else if n.Relation != session.LineageRoot {
+			line += fmt.Sprintf("  [%s]", n.Relation)
+		}
+		if cost := costs[s.ID]; cost > 0 {
+			line += fmt.Sprintf("  $%.2f", cost)
+		}
+		if s.ID == inst.ID {
+			line += "  ←"
+		}
+		sb.WriteString(line + "\n")
+
+		if s.LatestPrompt != "" {
+			// Continue the tree's vertical lines under this node
+			indent := strings.NewReplacer("├─ ", "│  ", "└─ ", "   ").Replace(prefix)
+			if len(n.Children) > 0 {
+				indent += "│ "
+			} else {
+				indent += "  "
+			}
+			prompt := strings.Join(strings.Fields(s.LatestPrompt), " ")
+			sb.WriteString(fmt.Sprintf("%s  \"%s\"\n", indent, truncateString(prompt, 60)))
+		}
+	}
+
+	out.Print(sb.String(), map[string]interface{}{
+		"success": true,
+		"tree":    toJSON(root),
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleTrust trusts (or revokes trust in) a repository's .agent-deck.toml
+func handleTrust(args []string) {
+	fs := flag.NewFlagSet("trust", flag.ExitOnError)
+	revoke := fs.Bool("revoke", false, "Stop applying the repository's config file")
+	yes := fs.Bool("yes", false, "Skip confirmation")
+	yesShort := fs.Bool("y", false, "Skip confirmation (short)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck trust [path] [options]")
+		fmt.Println()
+		fmt.Printf("Trust the %s of the repository containing path (default: current directory).\n", session.RepoConfigFileName)
+		fmt.Println("A repo config is only applied to sessions once trusted, because it can set")
+		fmt.Println("commands that run when a session starts. Editing the file requires trusting it again.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	// Allow "trust . -y" as well as "trust -y ."
+	if err := fs.Parse(reorderArgsForFlagParsing(args)); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	path := fs.Arg(0)
+	if path == "" {
+		path = "."
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		out.Error(fmt.Sprintf("invalid path: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	rc, err := session.LoadRepoConfig(absPath)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if rc == nil {
+		out.Error(fmt.Sprintf("no %s found for %s", session.RepoConfigFileName, FormatPath(absPath)), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	if *revoke {
+		if err := session.UntrustRepoConfig(rc.RepoRoot); err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		out.Success(fmt.Sprintf("No longer applying %s", FormatPath(rc.Path)), map[string]interface{}{
+			"success": true,
+			"repo":    rc.RepoRoot,
+			"trusted": false,
+		})
+		return
+	}
+
+	if rc.Trusted {
+		out.Success(fmt.Sprintf("%s is already trusted", FormatPath(rc.Path)), map[string]interface{}{
+			"success": true,
+			"repo":    rc.RepoRoot,
+			"trusted": true,
+		})
+		return
+	}
+
+	if !*yes && !*yesShort && !*jsonOutput {
+		if !promptRepoConfigTrust(rc) {
+			fmt.Println("Aborted.")
+			return
+		}
+	} else if err := session.TrustRepoConfig(rc); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Trusted %s", FormatPath(rc.Path)), map[string]interface{}{
+		"success": true,
+		"repo":    rc.RepoRoot,
+		"trusted": true,
+		"keys":    rc.Keys(),
+	})
+}
+
+// promptRepoConfigTrust shows what a repo config sets and asks whether to
+// trust it. Returns true if the config is now trusted.
+func promptRepoConfigTrust(rc *session.RepoConfig) bool {
+	fmt.Printf("%s wants to change these settings:\n", FormatPath(rc.Path))
+	for _, key := range rc.Keys() {
+		fmt.Printf("  %s\n", key)
+	}
+	if commands := rc.Commands(); len(commands) > 0 {
+		fmt.Println("It runs these commands when sessions start:")
+		for _, command := range commands {
+			fmt.Printf("  %s\n", command)
+		}
+	}
+	fmt.Print("Trust this repo config? [y/N]: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "y" && response != "yes" {
+		return false
+	}
+	if err := session.TrustRepoConfig(rc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// checkRepoConfigTrust asks to trust dir's repo config the first time it is seen
+// (or after it changed). Without a terminal, or with --json/--quiet, it only
+// warns that the file is ignored.
+func checkRepoConfigTrust(dir string, interactive bool) {
+	rc, err := session.LoadRepoConfig(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	if rc == nil || rc.Trusted {
+		return
+	}
+	if interactive && term.IsTerminal(int(os.Stdin.Fd())) {
+		if promptRepoConfigTrust(rc) {
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Note: ignoring untrusted %s (run 'agent-deck trust %s' to apply it)\n",
+		FormatPath(rc.Path), FormatPath(rc.RepoRoot))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -25,6 +26,8 @@ func handleWorktree(profile string, args []string) {
 		handleWorktreeInfo(profile, args[1:])
 	case "cleanup":
 		handleWorktreeCleanup(profile, args[1:])
+	case "finish":
+		handleWorktreeFinish(profile, args[1:])
 	case "help", "-h", "--help":
 		printWorktreeUsage()
 	default:
@@ -44,6 +47,7 @@ func printWorktreeUsage() {
 	fmt.Println("  list              List all worktrees in current repository")
 	fmt.Println("  info <session>    Show worktree info for a session")
 	fmt.Println("  cleanup [--force] Find and remove orphaned worktrees/sessions")
+	fmt.Println("  finish <session>  Land a session's branch, remove its worktree and session")
 	fmt.Println()
 	fmt.Println("Global Options:")
 	fmt.Println("  -p, --profile <name>   Use specific profile")
@@ -55,6 +59,8 @@ func printWorktreeUsage() {
 	fmt.Println("  agent-deck worktree info \"My Session\"")
 	fmt.Println("  agent-deck worktree cleanup")
 	fmt.Println("  agent-deck worktree cleanup --force")
+	fmt.Println("  agent-deck worktree finish \"My Session\"")
+	fmt.Println("  agent-deck worktree finish --strategy pr --archive \"My Session\"")
 }
 
 // handleWorktreeList lists all worktrees with session associations
@@ -474,6 +480,164 @@ func handleWorktreeCleanup(profile string, args []string) {
 		removedSessions, removedWorktrees)
 }
 
+// handleWorktreeFinish lands a worktree session's branch and cleans up
+func handleWorktreeFinish(profile string, args []string) {
+	settings := session.GetWorktreeSettings()
+
+	fs := flag.NewFlagSet("worktree finish", flag.ExitOnError)
+	strategy := fs.String("strategy", settings.FinishStrategy, "How to land the branch: rebase, squash or pr")
+	target := fs.String("target", settings.FinishTarget, "Branch to land on (default: branch checked out in the main worktree)")
+	push := fs.Bool("push", settings.FinishPush, "Push the branch to origin before landing it")
+	archive := fs.Bool("archive", settings.FinishSession == "archive", "Keep the session in the 'archived' group instead of deleting it")
+	yes := fs.Bool("yes", false, "Skip confirmation")
+	yesShort := fs.Bool("y", false, "Skip confirmation (short)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck worktree finish <session> [options]")
+		fmt.Println()
+		fmt.Println("Land a worktree session's branch, then remove the worktree and the session.")
+		fmt.Println()
+		fmt.Println("Strategies:")
+		fmt.Println("  rebase   Rebase onto the target, then fast-forward the target (default)")
+		fmt.Println("  squash   Squash-merge into the target as a single commit")
+		fmt.Println("  pr       Rebase onto the target and push the branch; the branch is kept")
+		fmt.Println()
+		fmt.Println("Stops without changing anything if the worktree has uncommitted changes,")
+		fmt.Println("the main worktree is not on the target branch, or the rebase/merge conflicts.")
+		fmt.Println("Defaults come from the [worktree] section of config.toml.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	identifier := fs.Arg(0)
+	out := NewCLIOutput(*jsonOutput, false)
+
+	if identifier == "" {
+		out.Error("session identifier is required", ErrCodeNotFound)
+		fmt.Println()
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	// Load sessions
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(identifier, instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+		return // unreachable, satisfies staticcheck SA5011
+	}
+
+	if !inst.IsWorktree() {
+		out.Error(fmt.Sprintf("session '%s' is not in a worktree", inst.Title), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	sessionAction := "delete"
+	if *archive {
+		sessionAction = "archive"
+	}
+
+	// Confirm before proceeding
+	if !*yes && !*yesShort && !*jsonOutput {
+		fmt.Printf("Finish '%s': %s %s, remove worktree %s and %s the session. Continue? [y/N]: ",
+			inst.Title, *strategy, inst.WorktreeBranch, FormatPath(inst.WorktreePath), sessionAction)
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Aborted.")
+			return
+		}
+	}
+
+	worktreePath := inst.WorktreePath
+	result, err := inst.FinishWorktree(session.WorktreeFinishOptions{
+		Strategy: *strategy,
+		Target:   *target,
+		Push:     *push,
+	})
+	if err != nil {
+		msg := err.Error()
+		if errors.Is(err, git.ErrConflict) {
+			msg += "; resolve the conflicts manually in " + FormatPath(worktreePath)
+		}
+		if result != nil {
+			// Landing succeeded but cleanup did not: the session is left as is
+			msg = fmt.Sprintf("%s landed on %s, but cleanup failed: %s", result.Branch, result.Target, msg)
+		}
+		out.Error(msg, ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Delete or archive the session
+	remaining := make([]*session.Instance, 0, len(instances))
+	for _, other := range instances {
+		if other.ID != inst.ID {
+			remaining = append(remaining, other)
+		}
+	}
+	if sessionAction == "archive" {
+		inst.ArchiveFinishedWorktree()
+		inst.GroupPath = session.ArchivedGroupPath
+		remaining = append(remaining, inst)
+	}
+	if err := saveSessionData(storage, remaining); err != nil {
+		out.Error(fmt.Sprintf("failed to save session data: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	var sb strings.Builder
+	switch result.Strategy {
+	case "pr":
+		sb.WriteString(fmt.Sprintf("Rebased %s onto %s and pushed it for a pull request\n", result.Branch, result.Target))
+	case "squash":
+		sb.WriteString(fmt.Sprintf("Squash-merged %s into %s\n", result.Branch, result.Target))
+	default:
+		sb.WriteString(fmt.Sprintf("Rebased and fast-forwarded %s onto %s\n", result.Branch, result.Target))
+	}
+	if result.Pushed && result.Strategy != "pr" {
+		sb.WriteString(fmt.Sprintf("Pushed %s to origin\n", result.Branch))
+	}
+	sb.WriteString(fmt.Sprintf("Removed worktree: %s\n", FormatPath(worktreePath)))
+	if result.BranchDeleted {
+		sb.WriteString(fmt.Sprintf("Deleted branch: %s\n", result.Branch))
+	}
+	if sessionAction == "archive" {
+		sb.WriteString(fmt.Sprintf("Archived session: %s\n", inst.Title))
+	} else {
+		sb.WriteString(fmt.Sprintf("Removed session: %s\n", inst.Title))
+	}
+
+	out.Print(sb.String(), map[string]interface{}{
+		"success":        true,
+		"session":        inst.Title,
+		"session_id":     inst.ID,
+		"branch":         result.Branch,
+		"target":         result.Target,
+		"strategy":       result.Strategy,
+		"pushed":         result.Pushed,
+		"merged":         result.Merged,
+		"branch_deleted": result.BranchDeleted,
+		"worktree_path":  worktreePath,
+		"session_action": sessionAction,
+	})
+}
+
 // truncateString truncates a string to maxLen, adding "..." if truncated
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -5,6 +5,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git/gittest"
 )
 
 // TestMain is in testmain_test.go - sets AGENTDECK_PROFILE=_test
@@ -37,25 +39,8 @@ func TestWorktreeListInNonGitRepo(t *testing.T) {
 func TestWorktreeListInGitRepo(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	// Initialize git repo
-	cmd := exec.Command("git", "init")
-	cmd.Dir = tmpDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to init git repo: %v", err)
-	}
-
-	// Create initial commit (required for worktree operations)
-	cmd = exec.Command("git", "commit", "--allow-empty", "-m", "init")
-	cmd.Dir = tmpDir
-	cmd.Env = append(os.Environ(),
-		"GIT_AUTHOR_NAME=Test",
-		"GIT_AUTHOR_EMAIL=test@test.com",
-		"GIT_COMMITTER_NAME=Test",
-		"GIT_COMMITTER_EMAIL=test@test.com",
-	)
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to create commit: %v", err)
-	}
+	// Initialize git repo with an initial commit (required for worktree operations)
+	gittest.InitRepo(t, tmpDir)
 
 	// Verify it's a git repo
 	gitDir := filepath.Join(tmpDir, ".git")
@@ -70,33 +55,12 @@ func TestWorktreeListInGitRepo(t *testing.T) {
 func TestWorktreeListWithWorktrees(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	// Initialize git repo
-	cmd := exec.Command("git", "init")
-	cmd.Dir = tmpDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to init git repo: %v", err)
-	}
-
-	// Create initial commit
-	cmd = exec.Command("git", "commit", "--allow-empty", "-m", "init")
-	cmd.Dir = tmpDir
-	cmd.Env = append(os.Environ(),
-		"GIT_AUTHOR_NAME=Test",
-		"GIT_AUTHOR_EMAIL=test@test.com",
-		"GIT_COMMITTER_NAME=Test",
-		"GIT_COMMITTER_EMAIL=test@test.com",
-	)
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to create commit: %v", err)
-	}
+	// Initialize git repo with an initial commit
+	gittest.InitRepo(t, tmpDir)
 
 	// Create a worktree
 	worktreePath := filepath.Join(tmpDir, "worktree-feature")
-	cmd = exec.Command("git", "worktree", "add", "-b", "feature-branch", worktreePath)
-	cmd.Dir = tmpDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to create worktree: %v", err)
-	}
+	gittest.Run(t, tmpDir, "worktree", "add", "-q", "-b", "feature-branch", worktreePath)
 
 	// Verify worktree exists
 	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
@@ -104,7 +68,7 @@ func TestWorktreeListWithWorktrees(t *testing.T) {
 	}
 
 	// Verify worktree list command works
-	cmd = exec.Command("git", "worktree", "list")
+	cmd := exec.Command("git", "worktree", "list")
 	cmd.Dir = tmpDir
 	output, err := cmd.Output()
 	if err != nil {
@@ -0,0 +1,229 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Checkpoint is a snapshot of a working tree stored as a commit under a
+// hidden ref (e.g. refs/agent-deck/<session>/<n>), outside any branch
+type Checkpoint struct {
+	Number  int
+	Ref     string
+	Commit  string
+	Time    time.Time
+	Subject string // First line of the commit message
+}
+
+// checkpointIdentity signs checkpoint commits, so they work without a
+// configured user.name/user.email
+var checkpointIdentity = []string{
+	"GIT_AUTHOR_NAME=agent-deck",
+	"GIT_AUTHOR_EMAIL=agent-deck@localhost",
+	"GIT_COMMITTER_NAME=agent-deck",
+	"GIT_COMMITTER_EMAIL=agent-deck@localhost",
+}
+
+// runGitEnv runs git in dir with extra environment variables
+func runGitEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// withTempIndex runs fn with the path of a scratch index file, seeded from
+// the repository's real index when seed is true. The real index (and so the
+// user's staged changes) is never modified.
+func withTempIndex(dir string, seed bool, fn func(indexFile string) error) error {
+	tmp, err := os.CreateTemp("", "agent-deck-index-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	indexFile := tmp.Name()
+	tmp.Close()
+	defer os.Remove(indexFile)
+
+	// An empty file is not a valid index, so start from the real one or none
+	os.Remove(indexFile)
+	if seed {
+		if path, err := runGit(dir, "rev-parse", "--git-path", "index"); err == nil {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			if data, err := os.ReadFile(path); err == nil {
+				if err := os.WriteFile(indexFile, data, 0600); err != nil {
+					return fmt.Errorf("failed to seed temporary index: %w", err)
+				}
+			}
+		}
+	}
+	return fn(indexFile)
+}
+
+// SnapshotTree writes the working tree of dir's checkout (tracked and
+// untracked files, honoring .gitignore) to the object store and returns
+// the tree ID, without touching the index or HEAD
+func SnapshotTree(dir string) (string, error) {
+	root, err := GetRepoRoot(dir)
+	if err != nil {
+		return "", err
+	}
+	var tree string
+	err = withTempIndex(root, true, func(indexFile string) error {
+		env := []string{"GIT_INDEX_FILE=" + indexFile}
+		if out, err := runGitEnv(root, env, "add", "-A"); err != nil {
+			return fmt.Errorf("failed to snapshot working tree: %s: %w", out, err)
+		}
+		out, err := runGitEnv(root, env, "write-tree")
+		if err != nil {
+			return fmt.Errorf("failed to write tree: %s: %w", out, err)
+		}
+		tree = out
+		return nil
+	})
+	return tree, err
+}
+
+// ListCheckpoints returns the checkpoints under refPrefix (e.g.
+// "refs/agent-deck/<session>/"), oldest first
+func ListCheckpoints(dir, refPrefix string) ([]Checkpoint, error) {
+	out, err := runGit(dir, "for-each-ref",
+		"--format=%(refname)%09%(objectname)%09%(committerdate:unix)%09%(contents:subject)", refPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %s: %w", out, err)
+	}
+
+	var checkpoints []Checkpoint
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(fields[0], refPrefix))
+		if err != nil {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[2], 10, 64)
+		checkpoints = append(checkpoints, Checkpoint{
+			Number:  n,
+			Ref:     fields[0],
+			Commit:  fields[1],
+			Time:    time.Unix(unix, 0),
+			Subject: fields[3],
+		})
+	}
+	sort.Slice(checkpoints, func(a, b int) bool {
+		return checkpoints[a].Number < checkpoints[b].Number
+	})
+	return checkpoints, nil
+}
+
+// CreateCheckpoint snapshots dir's working tree as the next numbered
+// checkpoint under refPrefix. Returns nil without error if nothing changed
+// since the last checkpoint. The commit's parent is the previous checkpoint,
+// or HEAD for the first one, so `git log <ref>` shows the timeline.
+func CreateCheckpoint(dir, refPrefix, message string) (*Checkpoint, error) {
+	existing, err := ListCheckpoints(dir, refPrefix)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := SnapshotTree(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	number := 1
+	parent := ""
+	if len(existing) > 0 {
+		last := existing[len(existing)-1]
+		if lastTree, err := runGit(dir, "rev-parse", last.Commit+"^{tree}"); err == nil && lastTree == tree {
+			return nil, nil
+		}
+		number = last.Number + 1
+		parent = last.Commit
+	} else if head, err := runGit(dir, "rev-parse", "--verify", "-q", "HEAD"); err == nil {
+		parent = head
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	commit, err := runGitEnv(dir, checkpointIdentity, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit checkpoint: %s: %w", commit, err)
+	}
+
+	ref := refPrefix + strconv.Itoa(number)
+	if out, err := runGit(dir, "update-ref", ref, commit); err != nil {
+		return nil, fmt.Errorf("failed to record checkpoint: %s: %w", out, err)
+	}
+	subject, _, _ := strings.Cut(message, "\n")
+	return &Checkpoint{Number: number, Ref: ref, Commit: commit, Time: time.Now(), Subject: subject}, nil
+}
+
+// DeleteCheckpoint removes a checkpoint ref
+func DeleteCheckpoint(dir, ref string) error {
+	if out, err := runGit(dir, "update-ref", "-d", ref); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %s: %w", out, err)
+	}
+	return nil
+}
+
+// RestoreCheckpoint makes dir's working tree match a checkpoint commit:
+// files are rewritten from it and files it doesn't have are removed
+// (ignored files are left alone). The index, HEAD and branch are untouched,
+// so the restored state shows up as uncommitted changes.
+func RestoreCheckpoint(dir, commit string) error {
+	root, err := GetRepoRoot(dir)
+	if err != nil {
+		return err
+	}
+	current, err := SnapshotTree(root)
+	if err != nil {
+		return err
+	}
+
+	// Files in the working tree that the checkpoint doesn't have
+	out, err := runGit(root, "diff-tree", "-r", "-z", "--name-only", "--no-renames", "--diff-filter=D", current, commit)
+	if err != nil {
+		return fmt.Errorf("failed to compare with checkpoint: %s: %w", out, err)
+	}
+	for _, path := range strings.Split(out, "\x00") {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(root, path)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	return withTempIndex(root, false, func(indexFile string) error {
+		env := []string{"GIT_INDEX_FILE=" + indexFile}
+		if out, err := runGitEnv(root, env, "read-tree", commit); err != nil {
+			return fmt.Errorf("failed to read checkpoint: %s: %w", out, err)
+		}
+		if out, err := runGitEnv(root, env, "checkout-index", "-a", "-f"); err != nil {
+			return fmt.Errorf("failed to restore files: %s: %w", out, err)
+		}
+		return nil
+	})
+}
+
+// CheckpointDiff returns what a checkpoint changed relative to its parent
+// (the previous checkpoint, or HEAD when it was taken): a diffstat followed
+// by the patch
+func CheckpointDiff(dir, commit string) (string, error) {
+	out, err := runGit(dir, "show", "--format=", "--stat", "--patch", commit)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff checkpoint: %s: %w", out, err)
+	}
+	return out, nil
+}
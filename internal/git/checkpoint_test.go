@@ -0,0 +1,85 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateAndRestoreCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	createTestRepo(t, dir)
+	prefix := "refs/agent-deck/test-session/"
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Stage something so we can check the index survives
+	write("staged.txt", "staged\n")
+	if out, err := runGit(dir, "add", "staged.txt"); err != nil {
+		t.Fatalf("git add: %s", out)
+	}
+	indexBefore, _ := runGit(dir, "diff", "--cached", "--name-only")
+
+	write("README.md", "# first\n")
+	cp1, err := CreateCheckpoint(dir, prefix, "first prompt\n\nfull text")
+	if err != nil || cp1 == nil {
+		t.Fatalf("CreateCheckpoint() = %v, %v", cp1, err)
+	}
+	if cp1.Number != 1 || cp1.Subject != "first prompt" {
+		t.Errorf("checkpoint = #%d %q, want #1 \"first prompt\"", cp1.Number, cp1.Subject)
+	}
+
+	// Nothing changed: no new checkpoint
+	if cp, err := CreateCheckpoint(dir, prefix, "idle"); err != nil || cp != nil {
+		t.Errorf("CreateCheckpoint() without changes = %v, %v; want nil, nil", cp, err)
+	}
+
+	write("README.md", "# second\n")
+	write("new.txt", "created later\n")
+	cp2, err := CreateCheckpoint(dir, prefix, "second prompt")
+	if err != nil || cp2 == nil || cp2.Number != 2 {
+		t.Fatalf("CreateCheckpoint() = %v, %v; want checkpoint 2", cp2, err)
+	}
+
+	list, err := ListCheckpoints(dir, prefix)
+	if err != nil || len(list) != 2 || list[0].Number != 1 || list[1].Subject != "second prompt" {
+		t.Fatalf("ListCheckpoints() = %+v, %v", list, err)
+	}
+	diff, err := CheckpointDiff(dir, cp2.Commit)
+	if err != nil || !strings.Contains(diff, "new.txt") || !strings.Contains(diff, "+# second") {
+		t.Errorf("CheckpointDiff() = %q, %v; want new.txt and README change", diff, err)
+	}
+
+	if err := RestoreCheckpoint(dir, cp1.Commit); err != nil {
+		t.Fatalf("RestoreCheckpoint() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "README.md")); string(data) != "# first\n" {
+		t.Errorf("README.md = %q after restore, want \"# first\\n\"", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Error("new.txt should be removed when restoring a checkpoint that predates it")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "staged.txt")); string(data) != "staged\n" {
+		t.Errorf("staged.txt = %q, want it kept", data)
+	}
+
+	// The user's index and branch are untouched
+	if indexAfter, _ := runGit(dir, "diff", "--cached", "--name-only"); indexAfter != indexBefore {
+		t.Errorf("index changed: %q -> %q", indexBefore, indexAfter)
+	}
+	if log, _ := runGit(dir, "log", "--oneline"); strings.Count(log, "\n") != 0 {
+		t.Errorf("branch history changed: %s", log)
+	}
+
+	if err := DeleteCheckpoint(dir, cp1.Ref); err != nil {
+		t.Fatalf("DeleteCheckpoint() failed: %v", err)
+	}
+	if list, _ := ListCheckpoints(dir, prefix); len(list) != 1 || list[0].Number != 2 {
+		t.Errorf("ListCheckpoints() after delete = %+v, want only checkpoint 2", list)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Worktree represents a git worktree
@@ -46,6 +49,253 @@ func GetCurrentBranch(dir string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// FileStatus is one changed path reported by git status
+type FileStatus struct {
+	Path     string // Path relative to the repository root
+	OrigPath string // Source path for renames and copies
+	Index    byte   // Staged change ('.' if none, '?' untracked, 'U' conflicted)
+	Worktree byte   // Unstaged change ('.' if none)
+}
+
+// Code returns the two-letter short status code, e.g. " M", "A ", "??"
+func (f FileStatus) Code() string {
+	code := []byte{f.Index, f.Worktree}
+	for i, c := range code {
+		if c == '.' {
+			code[i] = ' '
+		}
+	}
+	return string(code)
+}
+
+// Status describes the branch and uncommitted changes of a working tree
+type Status struct {
+	Branch   string       // Checked out branch ("" when detached)
+	Upstream string       // Tracking branch, e.g. origin/main ("" if none)
+	Ahead    int          // Commits on Branch not on Upstream
+	Behind   int          // Commits on Upstream not on Branch
+	Detached bool         // HEAD is not on a branch
+	Files    []FileStatus // Staged, unstaged, conflicted and untracked paths
+}
+
+// Dirty returns true if the working tree has uncommitted or untracked changes
+func (s *Status) Dirty() bool {
+	return s != nil && len(s.Files) > 0
+}
+
+// GetStatus returns the branch, upstream divergence and changed files for dir
+func GetStatus(dir string) (*Status, error) {
+	cmd := exec.Command("git", "-C", dir, "status", "--porcelain=v2", "--branch", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %w", err)
+	}
+	return parseStatusPorcelain(string(output)), nil
+}
+
+// parseStatusPorcelain parses `git status --porcelain=v2 --branch -z` output
+func parseStatusPorcelain(output string) *Status {
+	status := &Status{}
+	records := strings.Split(output, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+		switch record[0] {
+		case '#':
+			fields := strings.Fields(record)
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.head":
+				if fields[2] == "(detached)" {
+					status.Detached = true
+				} else {
+					status.Branch = fields[2]
+				}
+			case "branch.upstream":
+				status.Upstream = fields[2]
+			case "branch.ab":
+				if len(fields) >= 4 {
+					fmt.Sscanf(fields[2], "+%d", &status.Ahead)
+					fmt.Sscanf(fields[3], "-%d", &status.Behind)
+				}
+			}
+		case '1':
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			if fields := strings.SplitN(record, " ", 9); len(fields) == 9 {
+				status.Files = append(status.Files, FileStatus{
+					Path: fields[8], Index: fields[1][0], Worktree: fields[1][1],
+				})
+			}
+		case '2':
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <score> <path>, then <origPath>
+			if fields := strings.SplitN(record, " ", 10); len(fields) == 10 {
+				file := FileStatus{Path: fields[9], Index: fields[1][0], Worktree: fields[1][1]}
+				if i+1 < len(records) {
+					i++
+					file.OrigPath = records[i]
+				}
+				status.Files = append(status.Files, file)
+			}
+		case 'u':
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			if fields := strings.SplitN(record, " ", 11); len(fields) == 11 {
+				status.Files = append(status.Files, FileStatus{Path: fields[10], Index: 'U', Worktree: 'U'})
+			}
+		case '?':
+			status.Files = append(status.Files, FileStatus{Path: record[2:], Index: '?', Worktree: '?'})
+		}
+	}
+	return status
+}
+
+// GetDiff returns the uncommitted changes in dir (staged, unstaged and
+// untracked) as a unified diff against HEAD. Repositories without commits
+// diff the index.
+func GetDiff(dir string) (string, error) {
+	// Untracked files are marked intent-to-add in a copy of the index, so
+	// they show up as new files without touching the real index
+	tmpDir, err := os.MkdirTemp("", "agentdeck-diff-")
+	if err != nil {
+		return "", fmt.Errorf("failed to get git diff: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	index := filepath.Join(tmpDir, "index")
+	if realIndex, err := runGit(dir, "rev-parse", "--path-format=absolute", "--git-path", "index"); err == nil {
+		if data, err := os.ReadFile(realIndex); err == nil {
+			_ = os.WriteFile(index, data, 0600)
+		}
+	}
+	env := append(os.Environ(), "GIT_INDEX_FILE="+index)
+
+	add := exec.Command("git", "-C", dir, "add", "--intent-to-add", "--all")
+	add.Env = env
+	if output, err := add.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to get git diff: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+
+	cmd := exec.Command("git", "-C", dir, "diff", "HEAD", "--no-color", "--no-ext-diff")
+	cmd.Env = env
+	output, err := cmd.Output()
+	if err != nil {
+		// No HEAD yet: show what has been staged so far
+		cmd = exec.Command("git", "-C", dir, "diff", "--cached", "--no-color", "--no-ext-diff")
+		output, err = cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to get git diff: %w", err)
+		}
+	}
+	return string(output), nil
+}
+
+// DiffStat summarizes how far a working tree has moved from a commit
+type DiffStat struct {
+	Files      int // Changed files, untracked files included
+	Insertions int
+	Deletions  int
+}
+
+// GetDiffStat compares the working tree in dir (committed, uncommitted and
+// untracked changes) against base, e.g. the commit a worktree was created from.
+func GetDiffStat(dir, base string) (DiffStat, error) {
+	var stat DiffStat
+	output, err := runGit(dir, "diff", "--numstat", base)
+	if err != nil {
+		return stat, fmt.Errorf("failed to diff against %s: %s: %w", base, output, err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		stat.Files++
+		// Binary files report "-" for both counts
+		var added, deleted int
+		fmt.Sscanf(fields[0], "%d", &added)
+		fmt.Sscanf(fields[1], "%d", &deleted)
+		stat.Insertions += added
+		stat.Deletions += deleted
+	}
+
+	// New files the agent has not added yet count as all-insertions
+	status, err := GetStatus(dir)
+	if err != nil {
+		return stat, err
+	}
+	for _, f := range status.Files {
+		if f.Index != '?' {
+			continue
+		}
+		stat.Files++
+		if data, err := os.ReadFile(filepath.Join(dir, f.Path)); err == nil {
+			stat.Insertions += strings.Count(string(data), "\n")
+		}
+	}
+	return stat, nil
+}
+
+// GetHeadCommit returns the full SHA of HEAD in dir
+func GetHeadCommit(dir string) (string, error) {
+	output, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %s: %w", output, err)
+	}
+	return output, nil
+}
+
+// statusCacheTTL is how long a cached Status is served before it is refreshed
+const statusCacheTTL = 5 * time.Second
+
+type statusCacheEntry struct {
+	status  *Status // nil if dir is not a git repository
+	fetched time.Time
+}
+
+var (
+	statusCache   = make(map[string]statusCacheEntry)
+	statusCacheMu sync.RWMutex
+)
+
+// CachedStatus returns the last Status fetched for dir without running git.
+// Returns nil if dir has not been fetched yet or is not a git repository.
+// Callers that render often (e.g. the session list) should use this and
+// refresh in the background with RefreshStatus.
+func CachedStatus(dir string) *Status {
+	statusCacheMu.RLock()
+	defer statusCacheMu.RUnlock()
+	return statusCache[dir].status
+}
+
+// RefreshStatus re-reads the Status for dir if the cached copy is older than
+// the cache TTL and returns the (possibly cached) result.
+func RefreshStatus(dir string) *Status {
+	statusCacheMu.RLock()
+	entry, ok := statusCache[dir]
+	statusCacheMu.RUnlock()
+	if ok && time.Since(entry.fetched) < statusCacheTTL {
+		return entry.status
+	}
+
+	status, err := GetStatus(dir)
+	if err != nil {
+		status = nil
+	}
+	statusCacheMu.Lock()
+	statusCache[dir] = statusCacheEntry{status: status, fetched: time.Now()}
+	statusCacheMu.Unlock()
+	return status
+}
+
+// InvalidateStatus drops the cached Status for dir so the next RefreshStatus runs git
+func InvalidateStatus(dir string) {
+	statusCacheMu.Lock()
+	delete(statusCache, dir)
+	statusCacheMu.Unlock()
+}
+
 // BranchExists checks if a branch exists in the repository
 func BranchExists(repoDir, branchName string) bool {
 	cmd := exec.Command("git", "-C", repoDir, "show-ref", "--verify", "--quiet", "refs/heads/"+branchName)
@@ -229,6 +479,78 @@ func RemoveWorktree(repoDir, worktreePath string, force bool) error {
 	return nil
 }
 
+// ErrConflict is returned when a rebase or merge stops on conflicts.
+// The operation has been aborted and the working tree is back where it started.
+var ErrConflict = errors.New("conflicts detected")
+
+// runGit runs git in dir and returns its trimmed combined output
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// Rebase rebases the branch checked out in dir onto the given branch.
+// On conflicts the rebase is aborted and ErrConflict is returned.
+func Rebase(dir, onto string) error {
+	output, err := runGit(dir, "rebase", onto)
+	if err == nil {
+		return nil
+	}
+	if _, abortErr := runGit(dir, "rebase", "--abort"); abortErr == nil {
+		return fmt.Errorf("rebase onto %s: %w (rebase aborted)", onto, ErrConflict)
+	}
+	return fmt.Errorf("failed to rebase onto %s: %s: %w", onto, output, err)
+}
+
+// MergeFastForward fast-forwards the branch checked out in repoDir to branch
+func MergeFastForward(repoDir, branch string) error {
+	if output, err := runGit(repoDir, "merge", "--ff-only", branch); err != nil {
+		return fmt.Errorf("failed to fast-forward to %s: %s: %w", branch, output, err)
+	}
+	return nil
+}
+
+// SquashMerge merges branch into the branch checked out in repoDir as a
+// single commit with the given message. On conflicts the merge is undone
+// and ErrConflict is returned.
+func SquashMerge(repoDir, branch, message string) error {
+	if _, err := runGit(repoDir, "merge", "--squash", branch); err != nil {
+		_, _ = runGit(repoDir, "reset", "--merge")
+		return fmt.Errorf("squash merge of %s: %w (merge undone)", branch, ErrConflict)
+	}
+	if _, err := runGit(repoDir, "diff", "--cached", "--quiet"); err == nil {
+		return nil // Nothing to land: branch has no changes beyond the target
+	}
+	if output, err := runGit(repoDir, "commit", "-m", message); err != nil {
+		_, _ = runGit(repoDir, "reset", "--merge")
+		return fmt.Errorf("failed to commit squash merge of %s: %s: %w", branch, output, err)
+	}
+	return nil
+}
+
+// PushBranch pushes branch from dir to origin and sets it as upstream.
+// Uses --force-with-lease so a rebased branch can be re-pushed safely.
+func PushBranch(dir, branch string) error {
+	if output, err := runGit(dir, "push", "--force-with-lease", "-u", "origin", branch); err != nil {
+		return fmt.Errorf("failed to push %s: %s: %w", branch, output, err)
+	}
+	return nil
+}
+
+// DeleteBranch deletes a local branch. Without force, git refuses to delete
+// a branch that is not merged into HEAD.
+func DeleteBranch(repoDir, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if output, err := runGit(repoDir, "branch", flag, branch); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %s: %w", branch, output, err)
+	}
+	return nil
+}
+
 // GetWorktreeForBranch returns the worktree path for a given branch, if any
 func GetWorktreeForBranch(repoDir, branchName string) (string, error) {
 	worktrees, err := ListWorktrees(repoDir)
@@ -276,6 +598,10 @@ func GetMainWorktreePath(dir string) (string, error) {
 	}
 
 	commonDir := strings.TrimSpace(string(output))
+	// In the main worktree git prints the common dir relative to dir
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
 
 	// For worktrees, common-dir points to the main repo's .git directory
 	// We need to get the parent of that
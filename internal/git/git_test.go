@@ -198,6 +198,144 @@ func TestGetCurrentBranch(t *testing.T) {
 	})
 }
 
+func TestParseStatusPorcelain(t *testing.T) {
+	output := strings.Join([]string{
+		"# branch.oid 1234567890abcdef",
+		"# branch.head feature/x",
+		"# branch.upstream origin/feature/x",
+		"# branch.ab +3 -1",
+		"1 .M N... 100644 100644 100644 abc abc main.go",
+		"1 A. N... 000000 100644 100644 000 abc dir/new file.go",
+		"2 R. N... 100644 100644 100644 abc abc R100 renamed.go",
+		"old.go",
+		"u UU N... 100644 100644 100644 100644 a b c conflict.go",
+		"? untracked.txt",
+		"",
+	}, "\x00")
+
+	status := parseStatusPorcelain(output)
+
+	if status.Branch != "feature/x" || status.Upstream != "origin/feature/x" {
+		t.Errorf("branch = %q upstream = %q", status.Branch, status.Upstream)
+	}
+	if status.Ahead != 3 || status.Behind != 1 {
+		t.Errorf("ahead/behind = %d/%d, want 3/1", status.Ahead, status.Behind)
+	}
+	if !status.Dirty() {
+		t.Error("expected dirty status")
+	}
+
+	want := []FileStatus{
+		{Path: "main.go", Index: '.', Worktree: 'M'},
+		{Path: "dir/new file.go", Index: 'A', Worktree: '.'},
+		{Path: "renamed.go", OrigPath: "old.go", Index: 'R', Worktree: '.'},
+		{Path: "conflict.go", Index: 'U', Worktree: 'U'},
+		{Path: "untracked.txt", Index: '?', Worktree: '?'},
+	}
+	if len(status.Files) != len(want) {
+		t.Fatalf("got %d files, want %d: %+v", len(status.Files), len(want), status.Files)
+	}
+	for i, f := range want {
+		if status.Files[i] != f {
+			t.Errorf("file %d = %+v, want %+v", i, status.Files[i], f)
+		}
+	}
+
+	detached := parseStatusPorcelain("# branch.oid abc\x00# branch.head (detached)\x00")
+	if !detached.Detached || detached.Branch != "" || detached.Dirty() {
+		t.Errorf("unexpected detached status: %+v", detached)
+	}
+}
+
+func TestGetStatusAndDiff(t *testing.T) {
+	dir := t.TempDir()
+	createTestRepo(t, dir)
+
+	status, err := GetStatus(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Dirty() {
+		t.Errorf("expected clean repo, got %+v", status.Files)
+	}
+	RefreshStatus(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cache serves the clean status until invalidated
+	if cached := CachedStatus(dir); cached == nil || cached.Dirty() {
+		t.Fatalf("expected cached clean status, got %+v", cached)
+	}
+	InvalidateStatus(dir)
+	if cached := RefreshStatus(dir); !cached.Dirty() {
+		t.Error("expected dirty status after invalidation")
+	}
+
+	diff, err := GetDiff(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(diff, "-# Test Repo") || !strings.Contains(diff, "+# Changed") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+
+	// Untracked files are part of the diff, and stay untracked
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diff, err = GetDiff(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(diff, "+++ b/new.txt") || !strings.Contains(diff, "+hello") {
+		t.Errorf("diff should include the untracked file:\n%s", diff)
+	}
+	if status, err := GetStatus(dir); err != nil || len(status.Files) != 2 || status.Files[1].Index != '?' {
+		t.Errorf("new.txt should still be untracked, status %+v (%v)", status, err)
+	}
+
+	if _, err := GetStatus(t.TempDir()); err == nil {
+		t.Error("expected error for non-git directory")
+	}
+}
+
+func TestGetDiffStat(t *testing.T) {
+	dir := t.TempDir()
+	createTestRepo(t, dir)
+
+	base, err := GetHeadCommit(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(base) != 40 {
+		t.Errorf("expected full SHA, got %q", base)
+	}
+
+	// One committed modification plus one untracked file
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\nmore\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "commit", "-qam", "change readme")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := GetDiffStat(dir, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := DiffStat{Files: 2, Insertions: 5, Deletions: 1}
+	if stat != want {
+		t.Errorf("GetDiffStat() = %+v, want %+v", stat, want)
+	}
+}
+
 func TestBranchExists(t *testing.T) {
 	t.Run("returns true for existing branch", func(t *testing.T) {
 		dir := t.TempDir()
@@ -545,6 +683,29 @@ func TestRemoveWorktree(t *testing.T) {
 	})
 }
 
+func TestGetMainWorktreePath(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	createTestRepo(t, dir)
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	worktreePath := filepath.Join(t.TempDir(), "worktree")
+	if err := CreateWorktree(dir, worktreePath, "feature"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+
+	for _, from := range []string{dir, filepath.Join(dir, "sub"), worktreePath} {
+		got, err := GetMainWorktreePath(from)
+		if err != nil {
+			t.Fatalf("GetMainWorktreePath(%s) failed: %v", from, err)
+		}
+		if got != dir {
+			t.Errorf("GetMainWorktreePath(%s) = %s, want %s", from, got, dir)
+		}
+	}
+}
+
 func TestWorktreeStruct(t *testing.T) {
 	t.Run("worktree has expected fields", func(t *testing.T) {
 		wt := Worktree{
@@ -0,0 +1,56 @@
+// Package gittest creates git repositories for tests
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Run runs git in dir and fails the test on error. Returns the trimmed output.
+func Run(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// InitRepo makes dir a repo on main with one commit of shared.txt
+func InitRepo(t testing.TB, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	Run(t, dir, "init", "-q", "-b", "main")
+	Run(t, dir, "config", "user.email", "test@test.com")
+	Run(t, dir, "config", "user.name", "Test User")
+	CommitFile(t, dir, "shared.txt", "base\n")
+}
+
+// NewRepo creates a repo like InitRepo in a temporary directory and returns
+// its path with symlinks resolved, as git reports it
+func NewRepo(t testing.TB) string {
+	t.Helper()
+	repo := filepath.Join(t.TempDir(), "repo")
+	InitRepo(t, repo)
+	repo, _ = filepath.EvalSymlinks(repo)
+	return repo
+}
+
+// CommitFile writes content to name in dir and commits it
+func CommitFile(t testing.TB, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Run(t, dir, "add", name)
+	Run(t, dir, "commit", "-q", "-m", "change "+name)
+}
@@ -149,8 +149,8 @@ func (p *HTTPPool) Shutdown() error {
 	return nil
 }
 
-// StartHealthMonitor launches a background goroutine that checks for
-// failed HTTP servers and restarts them automatically
+// StartHealthMonitor launches a background goroutine that probes HTTP
+// servers and restarts failed or unresponsive ones automatically
 func (p *HTTPPool) StartHealthMonitor() {
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
@@ -161,6 +161,7 @@ func (p *HTTPPool) StartHealthMonitor() {
 			case <-p.ctx.Done():
 				return
 			case <-ticker.C:
+				p.probeServers()
 				p.restartFailedServers()
 			}
 		}
@@ -185,17 +186,78 @@ func (p *HTTPPool) restartFailedServers() {
 		server := p.servers[name]
 		p.mu.RUnlock()
 
-		if server != nil {
-			log.Printf("[HTTP-POOL] Auto-restarting failed server: %s", name)
-			if err := server.Restart(); err != nil {
-				log.Printf("[HTTP-POOL] Failed to restart %s: %v", name, err)
-			} else {
-				log.Printf("[HTTP-POOL] Successfully restarted %s", name)
-			}
+		if server == nil {
+			continue
+		}
+
+		// Exponential backoff between restarts; a server that stayed up resets it
+		server.mu.Lock()
+		if time.Since(server.lastRestart) > 2*maxRestartBackoff {
+			server.restartCount = 0
+		}
+		wait := restartBackoff(server.restartCount)
+		if time.Since(server.lastRestart) < wait {
+			server.mu.Unlock()
+			continue
+		}
+		server.restartCount++
+		server.lastRestart = time.Now()
+		attempt := server.restartCount
+		server.mu.Unlock()
+
+		log.Printf("[HTTP-POOL] Auto-restarting failed server: %s (restart #%d)", name, attempt)
+		if err := server.Restart(); err != nil {
+			log.Printf("[HTTP-POOL] Failed to restart %s: %v", name, err)
+		} else {
+			log.Printf("[HTTP-POOL] Successfully restarted %s", name)
 		}
 	}
 }
 
+// probeServers runs a protocol-level probe against every running server.
+// Servers we started that fail probeFailureThreshold probes in a row are
+// marked failed so restartFailedServers restarts them.
+func (p *HTTPPool) probeServers() {
+	p.mu.RLock()
+	var servers []*HTTPServer
+	for _, server := range p.servers {
+		if !server.IsRunning() {
+			continue
+		}
+		server.mu.RLock()
+		starting := server.startedByUs && time.Since(server.readyAt) < probeGracePeriod
+		server.mu.RUnlock()
+		if !starting {
+			servers = append(servers, server)
+		}
+	}
+	p.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *HTTPServer) {
+			defer wg.Done()
+			result := ProbeHTTP(server.url, nil, DefaultProbeTimeout)
+			failures := server.probes.record(result)
+			if result.Healthy || !server.StartedByUs() {
+				return
+			}
+			log.Printf("[HTTP-POOL] %s: health probe failed (%d/%d): %s", server.name, failures, probeFailureThreshold, result.Error)
+			if failures >= probeFailureThreshold {
+				server.mu.Lock()
+				if server.status == StatusRunning {
+					log.Printf("[HTTP-POOL] %s: unresponsive, marking failed", server.name)
+					server.status = StatusFailed
+					server.lastError = fmt.Errorf("health probe failed: %s", result.Error)
+				}
+				server.mu.Unlock()
+			}
+		}(server)
+	}
+	wg.Wait()
+}
+
 // ListServers returns info about all HTTP servers
 func (p *HTTPPool) ListServers() []HTTPServerInfo {
 	p.mu.RLock()
@@ -208,6 +270,7 @@ func (p *HTTPPool) ListServers() []HTTPServerInfo {
 			URL:         server.url,
 			Status:      server.GetStatus().String(),
 			StartedByUs: server.StartedByUs(),
+			Probe:       server.LastProbe(),
 		})
 	}
 	return list
@@ -254,4 +317,5 @@ type HTTPServerInfo struct {
 	URL         string
 	Status      string
 	StartedByUs bool
+	Probe       *ProbeResult // Latest health probe, nil until the first one
 }
@@ -32,8 +32,14 @@ type HTTPServer struct {
 
 	mu          sync.RWMutex
 	status      ServerStatus
-	startedByUs bool  // True if we started the server vs. discovered external
-	lastError   error // Last error encountered
+	startedByUs bool      // True if we started the server vs. discovered external
+	lastError   error     // Last error encountered
+	readyAt     time.Time // When the server last became ready (probe grace period)
+
+	lastRestart  time.Time // For restart backoff
+	restartCount int       // Consecutive automatic restarts
+
+	probes probeTracker
 }
 
 // NewHTTPServer creates a new HTTP server manager
@@ -192,6 +198,7 @@ func (s *HTTPServer) Start() error {
 	s.mu.Lock()
 	s.status = StatusRunning
 	s.startedByUs = true
+	s.readyAt = time.Now()
 	s.mu.Unlock()
 
 	log.Printf("[HTTP] %s: Server is ready at %s", s.name, s.url)
@@ -240,6 +247,18 @@ func (s *HTTPServer) Stop() error {
 	return nil
 }
 
+// Probe sends initialize and tools/list to the MCP endpoint and records the result
+func (s *HTTPServer) Probe(timeout time.Duration) ProbeResult {
+	result := ProbeHTTP(s.url, nil, timeout)
+	s.probes.record(result)
+	return result
+}
+
+// LastProbe returns the most recent probe result, or nil if none ran yet
+func (s *HTTPServer) LastProbe() *ProbeResult {
+	return s.probes.lastResult()
+}
+
 // HealthCheck checks if the server is responding
 func (s *HTTPServer) HealthCheck() error {
 	if !s.isURLReachable() {
@@ -18,6 +18,11 @@ type Pool struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	config  *PoolConfig
+
+	// refs counts running sessions that have each MCP loaded.
+	// idleSince records when an MCP's reference count last dropped to zero.
+	refs      map[string]int
+	idleSince map[string]time.Time
 }
 
 type PoolConfig struct {
@@ -26,15 +31,22 @@ type PoolConfig struct {
 	ExcludeMCPs   []string
 	PoolMCPs      []string
 	FallbackStdio bool
+
+	// TakeOverSockets makes Start replace another agent-deck instance's
+	// socket with a process of this pool's own instead of reusing it. The
+	// standalone pool sets it: sockets of a TUI die when the TUI exits.
+	TakeOverSockets bool
 }
 
 func NewPool(ctx context.Context, config *PoolConfig) (*Pool, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	return &Pool{
-		proxies: make(map[string]*SocketProxy),
-		ctx:     ctx,
-		cancel:  cancel,
-		config:  config,
+		proxies:   make(map[string]*SocketProxy),
+		ctx:       ctx,
+		cancel:    cancel,
+		config:    config,
+		refs:      make(map[string]int),
+		idleSince: make(map[string]time.Time),
 	}, nil
 }
 
@@ -42,11 +54,16 @@ func (p *Pool) Start(name, command string, args []string, env map[string]string)
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if _, exists := p.proxies[name]; exists {
-		return nil
+	if existing, exists := p.proxies[name]; exists {
+		if existing.mcpProcess != nil || !p.config.TakeOverSockets {
+			return nil
+		}
+		// Drop the adopted socket; stopping it only disconnects
+		_ = existing.Stop()
+		delete(p.proxies, name)
 	}
 
-	proxy, err := NewSocketProxy(p.ctx, name, command, args, env)
+	proxy, err := newSocketProxy(p.ctx, name, command, args, env, p.config.TakeOverSockets)
 	if err != nil {
 		return err
 	}
@@ -56,9 +73,92 @@ func (p *Pool) Start(name, command string, args []string, env map[string]string)
 	}
 
 	p.proxies[name] = proxy
+	if p.refs[name] == 0 {
+		p.idleSince[name] = time.Now()
+	}
 	return nil
 }
 
+// Stop stops a single proxy and removes it from the pool
+func (p *Pool) Stop(name string) error {
+	p.mu.Lock()
+	proxy, exists := p.proxies[name]
+	if !exists {
+		p.mu.Unlock()
+		return fmt.Errorf("proxy %s not found", name)
+	}
+	delete(p.proxies, name)
+	delete(p.idleSince, name)
+	p.mu.Unlock()
+
+	return proxy.Stop()
+}
+
+// SetReferences replaces the per-MCP session reference counts.
+// MCPs missing from refs are treated as unreferenced and start their idle clock.
+func (p *Pool) SetReferences(refs map[string]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refs = make(map[string]int, len(refs))
+	for name, count := range refs {
+		if count > 0 {
+			p.refs[name] = count
+		}
+	}
+
+	now := time.Now()
+	for name := range p.proxies {
+		if p.refs[name] > 0 {
+			delete(p.idleSince, name)
+		} else if _, idle := p.idleSince[name]; !idle {
+			p.idleSince[name] = now
+		}
+	}
+}
+
+// References returns the number of running sessions using an MCP
+func (p *Pool) References(name string) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.refs[name]
+}
+
+// StopIdle stops owned proxies that have had no session references and no
+// connected clients for at least timeout. Returns the names that were stopped.
+func (p *Pool) StopIdle(timeout time.Duration) []string {
+	p.mu.Lock()
+	var idle []*SocketProxy
+	now := time.Now()
+	for name, proxy := range p.proxies {
+		// Skip external sockets (we don't own them)
+		if proxy.mcpProcess == nil {
+			continue
+		}
+		since, ok := p.idleSince[name]
+		if !ok || p.refs[name] > 0 || now.Sub(since) < timeout {
+			continue
+		}
+		// A connected client means something is still talking to it, even if
+		// no tracked session lists the MCP (e.g. a session we can't see)
+		if proxy.GetClientCount() > 0 {
+			continue
+		}
+		idle = append(idle, proxy)
+		delete(p.proxies, name)
+		delete(p.idleSince, name)
+	}
+	p.mu.Unlock()
+
+	stopped := make([]string, 0, len(idle))
+	for _, proxy := range idle {
+		log.Printf("[Pool] Stopping idle MCP: %s (no sessions for %v)", proxy.name, timeout)
+		_ = proxy.Stop()
+		stopped = append(stopped, proxy.name)
+	}
+	return stopped
+}
+
 func (p *Pool) ShouldPool(mcpName string) bool {
 	if !p.config.Enabled {
 		return false
@@ -81,6 +181,15 @@ func (p *Pool) ShouldPool(mcpName string) bool {
 	return false
 }
 
+// IsExternal reports whether an MCP's socket belongs to another agent-deck
+// instance, so this pool can't stop or restart its process
+func (p *Pool) IsExternal(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	proxy, exists := p.proxies[name]
+	return exists && proxy.mcpProcess == nil
+}
+
 func (p *Pool) IsRunning(name string) bool {
 	p.mu.RLock()
 	proxy, exists := p.proxies[name]
@@ -190,8 +299,8 @@ func (p *Pool) Shutdown() error {
 	return nil
 }
 
-// StartHealthMonitor launches a background goroutine that checks for
-// failed proxies every 10 seconds and restarts them automatically.
+// StartHealthMonitor launches a background goroutine that probes proxies
+// every 10 seconds and restarts failed or unresponsive ones automatically.
 func (p *Pool) StartHealthMonitor() {
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
@@ -202,6 +311,7 @@ func (p *Pool) StartHealthMonitor() {
 			case <-p.ctx.Done():
 				return
 			case <-ticker.C:
+				p.probeProxies()
 				p.restartFailedProxies()
 			}
 		}
@@ -209,6 +319,43 @@ func (p *Pool) StartHealthMonitor() {
 	log.Printf("[Pool] Health monitor started (10s interval)")
 }
 
+// probeProxies runs a protocol-level probe against every running proxy.
+// Owned proxies that fail probeFailureThreshold probes in a row are marked
+// failed so restartFailedProxies replaces them.
+func (p *Pool) probeProxies() {
+	p.mu.RLock()
+	var proxies []*SocketProxy
+	for _, proxy := range p.proxies {
+		if proxy.GetStatus() != StatusRunning {
+			continue
+		}
+		if proxy.mcpProcess != nil && time.Since(proxy.startedAt) < probeGracePeriod {
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+	p.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, proxy := range proxies {
+		wg.Add(1)
+		go func(proxy *SocketProxy) {
+			defer wg.Done()
+			result := ProbeSocket(proxy.socketPath, DefaultProbeTimeout)
+			failures := proxy.probes.record(result)
+			if result.Healthy || proxy.mcpProcess == nil {
+				return
+			}
+			log.Printf("[Pool] %s: health probe failed (%d/%d): %s", proxy.name, failures, probeFailureThreshold, result.Error)
+			if failures >= probeFailureThreshold && proxy.GetStatus() == StatusRunning {
+				log.Printf("[Pool] %s: unresponsive, marking failed", proxy.name)
+				proxy.SetStatus(StatusFailed)
+			}
+		}(proxy)
+	}
+	wg.Wait()
+}
+
 func (p *Pool) restartFailedProxies() {
 	p.mu.RLock()
 	var failedProxies []string
@@ -240,12 +387,16 @@ func (p *Pool) RestartProxyWithRateLimit(name string) error {
 		return fmt.Errorf("proxy %s not found", name)
 	}
 
-	// Rate limit: minimum 5 seconds between restarts, max 3 per minute
-	if time.Since(proxy.lastRestart) < 5*time.Second {
-		return fmt.Errorf("rate limited: last restart was %v ago", time.Since(proxy.lastRestart))
+	// A proxy that stayed up past the longest backoff starts over
+	restartCount := proxy.restartCount
+	if time.Since(proxy.lastRestart) > 2*maxRestartBackoff {
+		restartCount = 0
 	}
-	if proxy.restartCount >= 3 && time.Since(proxy.lastRestart) < time.Minute {
-		return fmt.Errorf("rate limited: %d restarts in last minute", proxy.restartCount)
+
+	// Exponential backoff: 5s after the first restart, doubling up to 5 minutes
+	if wait := restartBackoff(restartCount); time.Since(proxy.lastRestart) < wait {
+		return fmt.Errorf("rate limited: %d restarts, next allowed in %v", restartCount,
+			(wait - time.Since(proxy.lastRestart)).Round(time.Second))
 	}
 
 	log.Printf("[Pool] Auto-restarting failed proxy: %s", name)
@@ -254,7 +405,7 @@ func (p *Pool) RestartProxyWithRateLimit(name string) error {
 	command := proxy.command
 	args := proxy.args
 	env := proxy.env
-	prevRestartCount := proxy.restartCount
+	prevRestartCount := restartCount
 
 	// Stop and remove old proxy
 	_ = proxy.Stop()
@@ -292,6 +443,10 @@ func (p *Pool) ListServers() []ProxyInfo {
 			SocketPath: proxy.socketPath,
 			Status:     proxy.GetStatus().String(),
 			Clients:    proxy.GetClientCount(),
+			Refs:       p.refs[proxy.name],
+			IdleSince:  p.idleSince[proxy.name],
+			External:   proxy.mcpProcess == nil,
+			Probe:      proxy.LastProbe(),
 		})
 	}
 	return list
@@ -316,6 +471,10 @@ type ProxyInfo struct {
 	SocketPath string
 	Status     string
 	Clients    int
+	Refs       int          // Running sessions with this MCP loaded
+	IdleSince  time.Time    // Zero while referenced
+	External   bool         // Socket owned by another process
+	Probe      *ProbeResult // Latest health probe, nil until the first one
 }
 
 // DiscoverExistingSockets scans for existing pool sockets owned by another agent-deck instance
@@ -0,0 +1,141 @@
+package mcppool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// startCatProxy starts a pooled "MCP" backed by cat so tests don't need a real server
+func startCatProxy(t *testing.T, pool *Pool, name string) {
+	t.Helper()
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	if err := pool.Start(name, "cat", nil, nil); err != nil {
+		t.Fatalf("Start(%s) failed: %v", name, err)
+	}
+}
+
+func newTestPool(t *testing.T) *Pool {
+	t.Helper()
+	pool, err := NewPool(context.Background(), &PoolConfig{Enabled: true, PoolAll: true})
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	t.Cleanup(func() { _ = pool.Shutdown() })
+	return pool
+}
+
+func TestPool_StopIdle_StopsUnreferenced(t *testing.T) {
+	pool := newTestPool(t)
+	used := fmt.Sprintf("test-used-%d", os.Getpid())
+	unused := fmt.Sprintf("test-unused-%d", os.Getpid())
+	startCatProxy(t, pool, used)
+	startCatProxy(t, pool, unused)
+
+	pool.SetReferences(map[string]int{used: 2})
+
+	if got := pool.References(used); got != 2 {
+		t.Errorf("References(%s) = %d, want 2", used, got)
+	}
+	if got := pool.References(unused); got != 0 {
+		t.Errorf("References(%s) = %d, want 0", unused, got)
+	}
+
+	stopped := pool.StopIdle(0)
+	if len(stopped) != 1 || stopped[0] != unused {
+		t.Fatalf("StopIdle() = %v, want [%s]", stopped, unused)
+	}
+	if pool.IsRunning(unused) {
+		t.Errorf("%s should be stopped", unused)
+	}
+	if !pool.IsRunning(used) {
+		t.Errorf("%s should still be running", used)
+	}
+}
+
+func TestPool_StopIdle_RespectsTimeout(t *testing.T) {
+	pool := newTestPool(t)
+	name := fmt.Sprintf("test-idle-%d", os.Getpid())
+	startCatProxy(t, pool, name)
+
+	pool.SetReferences(nil)
+	if stopped := pool.StopIdle(time.Hour); len(stopped) != 0 {
+		t.Errorf("StopIdle(1h) stopped %v, want none", stopped)
+	}
+
+	// A reference resets the idle clock
+	pool.SetReferences(map[string]int{name: 1})
+	for _, info := range pool.ListServers() {
+		if info.Name == name && !info.IdleSince.IsZero() {
+			t.Errorf("IdleSince should be zero while referenced")
+		}
+	}
+	if stopped := pool.StopIdle(0); len(stopped) != 0 {
+		t.Errorf("StopIdle(0) stopped referenced MCP: %v", stopped)
+	}
+}
+
+func TestPool_Stop(t *testing.T) {
+	pool := newTestPool(t)
+	name := fmt.Sprintf("test-stop-%d", os.Getpid())
+	startCatProxy(t, pool, name)
+
+	if err := pool.Stop(name); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+	if pool.IsRunning(name) {
+		t.Error("expected proxy to be stopped")
+	}
+	if err := pool.Stop(name); err == nil {
+		t.Error("expected error stopping unknown proxy")
+	}
+}
+
+func TestPool_TakeOverSockets(t *testing.T) {
+	tui := newTestPool(t)
+	name := fmt.Sprintf("test-takeover-%d", os.Getpid())
+	startCatProxy(t, tui, name)
+	socketPath := tui.GetSocketPath(name)
+
+	standalone, err := NewPool(context.Background(), &PoolConfig{Enabled: true, PoolAll: true, TakeOverSockets: true})
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	t.Cleanup(func() { _ = standalone.Shutdown() })
+
+	// An adopted socket belongs to the TUI: it can't be stopped when idle
+	if n := standalone.DiscoverExistingSockets(); n < 1 || !standalone.IsExternal(name) {
+		t.Fatalf("DiscoverExistingSockets() = %d, want %s adopted", n, name)
+	}
+	standalone.SetReferences(nil)
+	for _, stopped := range standalone.StopIdle(0) {
+		if stopped == name {
+			t.Fatalf("StopIdle() stopped adopted socket %s", name)
+		}
+	}
+
+	// Starting it takes the socket over with a process of the pool's own
+	startCatProxy(t, standalone, name)
+	if standalone.IsExternal(name) {
+		t.Fatalf("%s should be owned after Start", name)
+	}
+
+	// The TUI exiting kills its process but leaves the taken-over socket
+	_ = tui.Shutdown()
+	if !isSocketAliveCheck(socketPath) {
+		t.Fatalf("socket %s should survive the TUI's shutdown", socketPath)
+	}
+
+	standalone.SetReferences(nil)
+	if stopped := standalone.StopIdle(0); len(stopped) != 1 || stopped[0] != name {
+		t.Errorf("StopIdle() = %v, want [%s]", stopped, name)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("socket should be removed once the owner stops it, stat: %v", err)
+	}
+}
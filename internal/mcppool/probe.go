@@ -0,0 +1,341 @@
+package mcppool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProbeTimeout bounds a whole probe (connect, initialize, tools/list)
+const DefaultProbeTimeout = 5 * time.Second
+
+// probeProtocolVersion is the MCP protocol version sent in initialize
+const probeProtocolVersion = "2024-11-05"
+
+// ProbeResult is the outcome of a protocol-level MCP health probe
+type ProbeResult struct {
+	Healthy   bool      `json:"healthy"`
+	Tools     int       `json:"tools"`                // Tools reported by tools/list (-1 if not checked)
+	LatencyMs int64     `json:"latency_ms"`           // Time for the whole probe
+	Error     string    `json:"error,omitempty"`      // Why the probe failed
+	Note      string    `json:"note,omitempty"`       // Why the protocol check was skipped
+	CheckedAt time.Time `json:"checked_at,omitempty"` // When the probe ran
+}
+
+// Summary returns a short human-readable description of the result
+func (r ProbeResult) Summary() string {
+	switch {
+	case !r.Healthy:
+		return "unhealthy: " + r.Error
+	case r.Note != "":
+		return "ok (" + r.Note + ")"
+	case r.Tools >= 0:
+		return fmt.Sprintf("ok (%d tools, %dms)", r.Tools, r.LatencyMs)
+	default:
+		return "ok"
+	}
+}
+
+// probeCall sends one JSON-RPC request and returns its result. A nil id sends a notification.
+type probeCall func(ctx context.Context, method string, params interface{}, id interface{}) (json.RawMessage, error)
+
+// rpcError is a JSON-RPC error object returned by the server
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+var probeSeq atomic.Int64
+
+// runProbe performs initialize (or ping, for servers that reject a second
+// initialize) followed by tools/list
+func runProbe(ctx context.Context, call probeCall) ProbeResult {
+	start := time.Now()
+	result := ProbeResult{Tools: -1, CheckedAt: start}
+	prefix := fmt.Sprintf("agentdeck-probe-%d", probeSeq.Add(1))
+
+	initParams := map[string]interface{}{
+		"protocolVersion": probeProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "agent-deck-probe", "version": "1"},
+	}
+	_, err := call(ctx, "initialize", initParams, prefix+"-init")
+	if _, isRPC := err.(*rpcError); isRPC {
+		// Shared servers may already be initialized by another client
+		_, err = call(ctx, "ping", nil, prefix+"-ping")
+	} else if err == nil {
+		_, _ = call(ctx, "notifications/initialized", nil, nil)
+	}
+	if err != nil {
+		result.Error = fmt.Sprintf("initialize: %v", err)
+		result.LatencyMs = time.Since(start).Milliseconds()
+		return result
+	}
+
+	raw, err := call(ctx, "tools/list", nil, prefix+"-tools")
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = fmt.Sprintf("tools/list: %v", err)
+		return result
+	}
+	var tools struct {
+		Tools []json.RawMessage `json:"tools"`
+	}
+	if err := json.Unmarshal(raw, &tools); err != nil {
+		result.Error = fmt.Sprintf("tools/list: invalid result: %v", err)
+		return result
+	}
+	result.Healthy = true
+	result.Tools = len(tools.Tools)
+	return result
+}
+
+// rpcMessage is the subset of a JSON-RPC response the probe reads
+type rpcMessage struct {
+	ID     interface{}     `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// matchResponse returns the result for id if line is its response
+func matchResponse(line []byte, id interface{}) (json.RawMessage, bool, error) {
+	var msg rpcMessage
+	if json.Unmarshal(line, &msg) != nil || msg.ID == nil || fmt.Sprint(msg.ID) != fmt.Sprint(id) {
+		return nil, false, nil
+	}
+	if msg.Error != nil {
+		return nil, true, msg.Error
+	}
+	return msg.Result, true, nil
+}
+
+// ProbeSocket checks a pooled MCP through its Unix socket. A server that
+// accepts the connection but never answers fails the probe once the timeout
+// expires.
+func ProbeSocket(socketPath string, timeout time.Duration) ProbeResult {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := net.DialTimeout("unix", socketPath, timeout)
+	if err != nil {
+		return ProbeResult{Tools: -1, CheckedAt: time.Now(), Error: fmt.Sprintf("connect: %v", err)}
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	reader := bufio.NewReader(conn)
+	call := func(ctx context.Context, method string, params interface{}, id interface{}) (json.RawMessage, error) {
+		data, err := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", Method: method, Params: params, ID: id})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := conn.Write(append(data, '\n')); err != nil {
+			return nil, err
+		}
+		if id == nil {
+			return nil, nil
+		}
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if result, ok, rpcErr := matchResponse(line, id); ok {
+					return result, rpcErr
+				}
+			}
+			if err != nil {
+				if ne, ok := err.(net.Error); ok && ne.Timeout() {
+					return nil, fmt.Errorf("no response within %v", timeout)
+				}
+				return nil, err
+			}
+		}
+	}
+	return runProbe(ctx, call)
+}
+
+// ProbeHTTP checks a streamable HTTP MCP endpoint. Endpoints that require
+// auth or don't accept JSON-RPC POSTs (legacy SSE) are reported healthy
+// with a Note, since they are responding but can't be checked further.
+func ProbeHTTP(url string, headers map[string]string, timeout time.Duration) ProbeResult {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := &http.Client{}
+	var sessionID string
+	var note string
+
+	call := func(ctx context.Context, method string, params interface{}, id interface{}) (json.RawMessage, error) {
+		data, err := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", Method: method, Params: params, ID: id})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("no response within %v", timeout)
+			}
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			note = "auth required"
+			return nil, errProbeSkipped
+		case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed:
+			note = "protocol check not supported"
+			return nil, errProbeSkipped
+		case resp.StatusCode >= 400:
+			return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		if sid := resp.Header.Get("Mcp-Session-Id"); sid != "" {
+			sessionID = sid
+		}
+		if id == nil {
+			return nil, nil
+		}
+
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			return readSSEResponse(resp.Body, id)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if result, ok, rpcErr := matchResponse(body, id); ok {
+			return result, rpcErr
+		}
+		return nil, fmt.Errorf("unexpected response")
+	}
+
+	result := runProbe(ctx, call)
+	if note != "" {
+		return ProbeResult{Healthy: true, Tools: -1, LatencyMs: result.LatencyMs, Note: note, CheckedAt: result.CheckedAt}
+	}
+	return result
+}
+
+// errProbeSkipped stops a probe whose endpoint responded but can't be checked
+var errProbeSkipped = errors.New("protocol check skipped")
+
+// readSSEResponse reads "data:" events until the response for id arrives
+func readSSEResponse(body io.Reader, id interface{}) (json.RawMessage, error) {
+	reader := bufio.NewReader(body)
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		trimmed := strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(trimmed, "data:"):
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(trimmed, "data:")))
+		case trimmed == "" && data.Len() > 0:
+			if result, ok, rpcErr := matchResponse(data.Bytes(), id); ok {
+				return result, rpcErr
+			}
+			data.Reset()
+		}
+		if err != nil {
+			if data.Len() > 0 {
+				if result, ok, rpcErr := matchResponse(data.Bytes(), id); ok {
+					return result, rpcErr
+				}
+			}
+			if err == io.EOF {
+				return nil, fmt.Errorf("stream closed before response")
+			}
+			return nil, err
+		}
+	}
+}
+
+const (
+	// probeGracePeriod skips probes right after start so slow MCPs (e.g. npx installs) can come up
+	probeGracePeriod = 30 * time.Second
+
+	// probeFailureThreshold is the number of consecutive failed probes before a server is restarted
+	probeFailureThreshold = 3
+
+	// maxRestartBackoff caps the delay between automatic restarts
+	maxRestartBackoff = 5 * time.Minute
+)
+
+// restartBackoff returns the minimum time between the previous restart and the next one:
+// 5s after the first restart, doubling up to maxRestartBackoff
+func restartBackoff(restartCount int) time.Duration {
+	if restartCount <= 0 {
+		return 0
+	}
+	if restartCount > 7 {
+		return maxRestartBackoff
+	}
+	backoff := 5 * time.Second << (restartCount - 1)
+	if backoff > maxRestartBackoff {
+		return maxRestartBackoff
+	}
+	return backoff
+}
+
+// probeTracker keeps the latest probe result for one server
+type probeTracker struct {
+	mu       sync.Mutex
+	last     *ProbeResult
+	failures int // Consecutive failed probes
+}
+
+// record stores a result and returns the number of consecutive failures
+func (t *probeTracker) record(r ProbeResult) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last = &r
+	if r.Healthy {
+		t.failures = 0
+	} else {
+		t.failures++
+	}
+	return t.failures
+}
+
+// lastResult returns a copy of the latest result, or nil if no probe ran yet
+func (t *probeTracker) lastResult() *ProbeResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.last == nil {
+		return nil
+	}
+	r := *t.last
+	return &r
+}
@@ -0,0 +1,233 @@
+package mcppool
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeMCPReply answers one JSON-RPC request; returning nil sends nothing
+type fakeMCPReply func(req JSONRPCRequest) interface{}
+
+func healthyMCP(req JSONRPCRequest) interface{} {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": map[string]interface{}{"protocolVersion": probeProtocolVersion}}
+	case "tools/list":
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": map[string]interface{}{
+			"tools": []map[string]string{{"name": "search"}, {"name": "fetch"}},
+		}}
+	case "ping":
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": map[string]interface{}{}}
+	}
+	return nil
+}
+
+// startFakeSocketMCP serves reply on a Unix socket
+func startFakeSocketMCP(t *testing.T, reply fakeMCPReply) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("/tmp", "adprobe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "mcp.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var req JSONRPCRequest
+					if json.Unmarshal(scanner.Bytes(), &req) != nil {
+						continue
+					}
+					if resp := reply(req); resp != nil {
+						data, _ := json.Marshal(resp)
+						_, _ = conn.Write(append(data, '\n'))
+					}
+				}
+			}(conn)
+		}
+	}()
+	return socketPath
+}
+
+func TestProbeSocket_Healthy(t *testing.T) {
+	socketPath := startFakeSocketMCP(t, healthyMCP)
+
+	result := ProbeSocket(socketPath, time.Second)
+	if !result.Healthy || result.Tools != 2 {
+		t.Fatalf("ProbeSocket() = %+v, want healthy with 2 tools", result)
+	}
+	if !strings.HasPrefix(result.Summary(), "ok (2 tools") {
+		t.Errorf("Summary() = %q", result.Summary())
+	}
+}
+
+func TestProbeSocket_HungServer(t *testing.T) {
+	// Accepts connections but never answers
+	socketPath := startFakeSocketMCP(t, func(JSONRPCRequest) interface{} { return nil })
+
+	start := time.Now()
+	result := ProbeSocket(socketPath, 200*time.Millisecond)
+	if result.Healthy {
+		t.Fatal("hung server reported healthy")
+	}
+	if !strings.Contains(result.Error, "no response") {
+		t.Errorf("Error = %q, want timeout", result.Error)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("probe took %v, deadline not enforced", elapsed)
+	}
+}
+
+func TestProbeSocket_AlreadyInitialized(t *testing.T) {
+	socketPath := startFakeSocketMCP(t, func(req JSONRPCRequest) interface{} {
+		if req.Method == "initialize" {
+			return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32600, "message": "already initialized"}}
+		}
+		return healthyMCP(req)
+	})
+
+	if result := ProbeSocket(socketPath, time.Second); !result.Healthy {
+		t.Fatalf("ProbeSocket() = %+v, want healthy via ping", result)
+	}
+}
+
+func TestProbeSocket_ToolsListError(t *testing.T) {
+	socketPath := startFakeSocketMCP(t, func(req JSONRPCRequest) interface{} {
+		if req.Method == "tools/list" {
+			return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32603, "message": "boom"}}
+		}
+		return healthyMCP(req)
+	})
+
+	result := ProbeSocket(socketPath, time.Second)
+	if result.Healthy || !strings.Contains(result.Error, "boom") {
+		t.Fatalf("ProbeSocket() = %+v, want tools/list error", result)
+	}
+}
+
+func TestProbeHTTP(t *testing.T) {
+	serve := func(sse bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var req JSONRPCRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			if req.Method == "initialize" {
+				w.Header().Set("Mcp-Session-Id", "s1")
+			} else if r.Header.Get("Mcp-Session-Id") != "s1" {
+				http.Error(w, "missing session", http.StatusBadRequest)
+				return
+			}
+			resp := healthyMCP(req)
+			if resp == nil {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			data, _ := json.Marshal(resp)
+			if sse {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(data)
+		}
+	}
+
+	for _, sse := range []bool{false, true} {
+		srv := httptest.NewServer(serve(sse))
+		result := ProbeHTTP(srv.URL, nil, time.Second)
+		srv.Close()
+		if !result.Healthy || result.Tools != 2 {
+			t.Errorf("ProbeHTTP(sse=%v) = %+v, want healthy with 2 tools", sse, result)
+		}
+	}
+
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer auth.Close()
+	if result := ProbeHTTP(auth.URL, nil, time.Second); !result.Healthy || result.Note != "auth required" {
+		t.Errorf("ProbeHTTP(401) = %+v, want healthy with auth note", result)
+	}
+
+	block := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer hung.Close()
+	defer close(block)
+	if result := ProbeHTTP(hung.URL, nil, 200*time.Millisecond); result.Healthy {
+		t.Errorf("ProbeHTTP(hung) = %+v, want unhealthy", result)
+	}
+}
+
+func TestRestartBackoff(t *testing.T) {
+	tests := []struct {
+		count int
+		want  time.Duration
+	}{
+		{0, 0},
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{4, 40 * time.Second},
+		{7, maxRestartBackoff},
+		{50, maxRestartBackoff},
+	}
+	for _, tt := range tests {
+		if got := restartBackoff(tt.count); got != tt.want {
+			t.Errorf("restartBackoff(%d) = %v, want %v", tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestPool_ProbeMarksUnresponsiveFailed(t *testing.T) {
+	pool := newTestPool(t)
+	name := fmt.Sprintf("test-probe-%d", os.Getpid())
+	// cat echoes requests back, which is not a valid tools/list result
+	startCatProxy(t, pool, name)
+
+	proxy := pool.proxies[name]
+	proxy.startedAt = time.Now().Add(-time.Hour)
+
+	for i := 0; i < probeFailureThreshold; i++ {
+		pool.probeProxies()
+	}
+	if status := proxy.GetStatus(); status != StatusFailed {
+		t.Fatalf("status = %v after %d failed probes, want failed", status, probeFailureThreshold)
+	}
+
+	var info *ProxyInfo
+	for _, srv := range pool.ListServers() {
+		if srv.Name == name {
+			info = &srv
+		}
+	}
+	if info == nil || info.Probe == nil || info.Probe.Healthy {
+		t.Errorf("ListServers() probe = %+v, want failed probe", info)
+	}
+}
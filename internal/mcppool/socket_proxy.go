@@ -28,7 +28,8 @@ type SocketProxy struct {
 	mcpStdin   io.WriteCloser
 	mcpStdout  io.ReadCloser
 
-	listener net.Listener
+	listener   net.Listener
+	socketFile os.FileInfo // The socket file this proxy created
 
 	clients   map[string]net.Conn
 	clientsMu sync.RWMutex
@@ -46,6 +47,9 @@ type SocketProxy struct {
 	statusMu     sync.RWMutex // Protects Status field
 	lastRestart  time.Time    // For rate limiting restarts
 	restartCount int          // Track restart attempts
+	startedAt    time.Time    // When the owned MCP process was started
+
+	probes probeTracker
 }
 
 // SetStatus safely updates the proxy status
@@ -94,11 +98,19 @@ func isSocketAlive(socketPath string) bool {
 }
 
 func NewSocketProxy(ctx context.Context, name, command string, args []string, env map[string]string) (*SocketProxy, error) {
+	return newSocketProxy(ctx, name, command, args, env, false)
+}
+
+// newSocketProxy creates a proxy for an MCP. A live socket of another
+// agent-deck instance is reused, unless takeOver is set: then the proxy
+// starts its own process behind a new socket at the same path, and the other
+// instance's clients stay connected to it until that instance exits.
+func newSocketProxy(ctx context.Context, name, command string, args []string, env map[string]string, takeOver bool) (*SocketProxy, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	socketPath := filepath.Join("/tmp", fmt.Sprintf("agentdeck-mcp-%s.sock", name))
 
 	// Check if socket already exists and is alive (another agent-deck instance owns it)
-	if isSocketAlive(socketPath) {
+	if !takeOver && isSocketAlive(socketPath) {
 		log.Printf("[Pool] Socket %s already alive (owned by another agent-deck), reusing", name)
 		// Return a proxy that just points to the existing socket (no process to manage)
 		return &SocketProxy{
@@ -115,7 +127,7 @@ func NewSocketProxy(ctx context.Context, name, command string, args []string, en
 		}, nil
 	}
 
-	// Socket doesn't exist or is stale - remove and create fresh
+	// Socket doesn't exist, is stale or is taken over - remove and create fresh
 	os.Remove(socketPath)
 
 	return &SocketProxy{
@@ -189,12 +201,19 @@ func (p *SocketProxy) Start() error {
 		return err
 	}
 	p.listener = listener
+	// The socket file is only removed while it is still ours: another
+	// agent-deck instance may have taken the path over
+	if ul, ok := listener.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	p.socketFile, _ = os.Stat(p.socketPath)
 
 	log.Printf("Socket proxy %s at: %s", p.name, p.socketPath)
 
 	go p.acceptConnections()
 	go p.broadcastResponses()
 
+	p.startedAt = time.Now()
 	p.SetStatus(StatusRunning)
 	return nil
 }
@@ -359,7 +378,9 @@ func (p *SocketProxy) Stop() error {
 			_ = p.mcpProcess.Process.Kill()
 			<-done // Wait must return after Kill
 		}
-		os.Remove(p.socketPath)
+		if info, err := os.Stat(p.socketPath); err == nil && p.socketFile != nil && os.SameFile(info, p.socketFile) {
+			os.Remove(p.socketPath)
+		}
 		log.Printf("[Pool] %s: Stopped owned process and removed socket", p.name)
 	} else {
 		log.Printf("[Pool] %s: Disconnected from external socket (not removing)", p.name)
@@ -395,3 +416,15 @@ func (p *SocketProxy) HealthCheck() error {
 	}
 	return nil
 }
+
+// Probe sends initialize and tools/list through the socket and records the result
+func (p *SocketProxy) Probe(timeout time.Duration) ProbeResult {
+	result := ProbeSocket(p.socketPath, timeout)
+	p.probes.record(result)
+	return result
+}
+
+// LastProbe returns the most recent probe result, or nil if none ran yet
+func (p *SocketProxy) LastProbe() *ProbeResult {
+	return p.probes.lastResult()
+}
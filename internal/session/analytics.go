@@ -1,10 +1,8 @@
 package session
 
 import (
-	"bufio"
-	"encoding/json"
-	"os"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -32,11 +30,20 @@ type SessionAnalytics struct {
 	// Subagents
 	Subagents []SubagentInfo `json:"subagents"`
 
-	// Cost estimation
+	// Cost estimation in USD, each message priced at its model's rate
 	EstimatedCost float64 `json:"estimated_cost"`
 
 	// 5-hour billing blocks
 	BillingBlocks []BillingBlock `json:"billing_blocks"`
+
+	// Working directory the transcript was recorded in
+	ProjectPath string `json:"project_path,omitempty"`
+
+	// Usage split by day and model, for reports
+	DailyUsage []UsageSlice `json:"daily_usage,omitempty"`
+
+	// Usage of each agent message, for activity over time
+	Activity []UsagePoint `json:"-"`
 }
 
 // ToolCall represents a tool and its usage count
@@ -52,6 +59,90 @@ type SubagentInfo struct {
 	Turns     int       `json:"turns"`
 }
 
+// activeGapLimit is the longest pause between two agent messages that still
+// counts as active time. Longer gaps mean the agent sat waiting for input.
+const activeGapLimit = 5 * time.Minute
+
+// UsagePoint is the usage of one agent message
+type UsagePoint struct {
+	Time   time.Time
+	Tokens int
+	Cost   float64 // Estimated USD
+}
+
+// UsageSlice is the usage of one model on one day (local time) in a session
+type UsageSlice struct {
+	Day              string         `json:"day"` // YYYY-MM-DD, empty if messages had no timestamp
+	Model            string         `json:"model,omitempty"`
+	InputTokens      int            `json:"input_tokens"`
+	OutputTokens     int            `json:"output_tokens"`
+	CacheReadTokens  int            `json:"cache_read_input_tokens,omitempty"`
+	CacheWriteTokens int            `json:"cache_creation_input_tokens,omitempty"`
+	Turns            int            `json:"turns"`
+	ActiveTime       time.Duration  `json:"active_time"`
+	Cost             float64        `json:"cost"` // Estimated USD
+	Tools            map[string]int `json:"tools,omitempty"`
+}
+
+// TotalTokens returns the sum of all token types
+func (s *UsageSlice) TotalTokens() int {
+	return s.InputTokens + s.OutputTokens + s.CacheReadTokens + s.CacheWriteTokens
+}
+
+// usageSlicer splits a session's messages into UsageSlices
+type usageSlicer struct {
+	slices map[[2]string]*UsageSlice
+	last   time.Time
+}
+
+// add counts one agent message and returns its slice. The pause since the
+// previous message is credited as active time unless it exceeds activeGapLimit.
+func (s *usageSlicer) add(ts time.Time, model string) *UsageSlice {
+	day := ""
+	if !ts.IsZero() {
+		day = ts.Local().Format("2006-01-02")
+	}
+	key := [2]string{day, model}
+	if s.slices == nil {
+		s.slices = make(map[[2]string]*UsageSlice)
+	}
+	slice, ok := s.slices[key]
+	if !ok {
+		slice = &UsageSlice{Day: day, Model: model}
+		s.slices[key] = slice
+	}
+	if !ts.IsZero() {
+		slice.ActiveTime += activeGap(s.last, ts)
+		s.last = ts
+	}
+	slice.Turns++
+	return slice
+}
+
+// activeGap returns how much of the pause between an agent message at last
+// and the next one at ts counts as active time
+func activeGap(last, ts time.Time) time.Duration {
+	if gap := ts.Sub(last); !last.IsZero() && gap > 0 && gap <= activeGapLimit {
+		return gap
+	}
+	return 0
+}
+
+// result returns the slices ordered by day and model
+func (s *usageSlicer) result() []UsageSlice {
+	result := make([]UsageSlice, 0, len(s.slices))
+	for _, slice := range s.slices {
+		result = append(result, *slice)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Day != result[b].Day {
+			return result[a].Day < result[b].Day
+		}
+		return result[a].Model < result[b].Model
+	})
+	return result
+}
+
 // BillingBlock represents a 5-hour billing window
 type BillingBlock struct {
 	StartTime  time.Time `json:"start_time"`
@@ -93,12 +184,24 @@ var modelPricing = map[string]ModelPricing{
 	"default": {Input: 3.0, Output: 15.0, CacheRead: 0.30, CacheWrite: 3.75},
 }
 
+// claudeModelPricing returns the pricing of a model. Model IDs that are not
+// listed are priced by family (opus, haiku), then at the default.
+func claudeModelPricing(model string) ModelPricing {
+	if pricing, ok := modelPricing[model]; ok {
+		return pricing
+	}
+	switch {
+	case strings.Contains(model, "opus"):
+		return modelPricing["claude-opus-4-20250514"]
+	case strings.Contains(model, "haiku"):
+		return modelPricing["claude-3-5-haiku"]
+	}
+	return modelPricing["default"]
+}
+
 // CalculateCost estimates session cost based on token usage and model pricing
 func (a *SessionAnalytics) CalculateCost(model string) float64 {
-	pricing, ok := modelPricing[model]
-	if !ok {
-		pricing = modelPricing["default"]
-	}
+	pricing := claudeModelPricing(model)
 
 	// Convert to millions
 	inputM := float64(a.InputTokens) / 1_000_000
@@ -112,103 +215,18 @@ func (a *SessionAnalytics) CalculateCost(model string) float64 {
 		cacheWriteM*pricing.CacheWrite
 }
 
-// jsonlEntry represents a single line in a Claude session JSONL file
-type jsonlEntry struct {
-	Type      string    `json:"type"`
-	Timestamp time.Time `json:"timestamp"`
-	Message   struct {
-		Usage struct {
-			InputTokens              int `json:"input_tokens"`
-			OutputTokens             int `json:"output_tokens"`
-			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
-			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
-		} `json:"usage"`
-		Content []struct {
-			Type string `json:"type"`
-			Name string `json:"name"`
-		} `json:"content"`
-	} `json:"message"`
-	AgentID string `json:"agent_id,omitempty"`
-}
-
-// ParseSessionJSONL parses a Claude session JSONL file and returns analytics
+// ParseSessionJSONL returns analytics for a Claude session JSONL file. The
+// file is followed across calls: only lines appended since the last call are
+// parsed, so repeated reads of a growing transcript stay cheap.
 func ParseSessionJSONL(path string) (*SessionAnalytics, error) {
-	file, err := os.Open(path)
+	var analytics *SessionAnalytics
+	err := claudeTranscripts.read(path, func(t *claudeTranscript) {
+		analytics = t.snapshot()
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	analytics := &SessionAnalytics{
-		ToolCalls: []ToolCall{},
-	}
-	toolCounts := make(map[string]int)
-	var firstTime, lastTime time.Time
-
-	scanner := bufio.NewScanner(file)
-	// Increase buffer for large lines (some tool outputs can be huge)
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 10*1024*1024)
-
-	for scanner.Scan() {
-		var entry jsonlEntry
-		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
-			continue // Skip malformed lines
-		}
-
-		// Only count assistant messages
-		if entry.Type != "assistant" {
-			continue
-		}
-
-		// Track timing
-		if !entry.Timestamp.IsZero() {
-			if firstTime.IsZero() || entry.Timestamp.Before(firstTime) {
-				firstTime = entry.Timestamp
-			}
-			if entry.Timestamp.After(lastTime) {
-				lastTime = entry.Timestamp
-			}
-		}
-
-		// Accumulate tokens (cumulative totals for cost calculation)
-		analytics.InputTokens += entry.Message.Usage.InputTokens
-		analytics.OutputTokens += entry.Message.Usage.OutputTokens
-		analytics.CacheReadTokens += entry.Message.Usage.CacheReadInputTokens
-		analytics.CacheWriteTokens += entry.Message.Usage.CacheCreationInputTokens
-
-		// Track current context size (last turn's input + cache read)
-		// This represents the actual context window usage
-		analytics.CurrentContextTokens = entry.Message.Usage.InputTokens +
-			entry.Message.Usage.CacheReadInputTokens
-
-		// Count turn
-		analytics.TotalTurns++
-
-		// Count tool calls
-		for _, content := range entry.Message.Content {
-			if content.Type == "tool_use" && content.Name != "" {
-				toolCounts[content.Name]++
-			}
-		}
-	}
-
-	// Convert tool counts to slice
-	for name, count := range toolCounts {
-		analytics.ToolCalls = append(analytics.ToolCalls, ToolCall{
-			Name:  name,
-			Count: count,
-		})
-	}
-
-	// Set timing
-	analytics.StartTime = firstTime
-	analytics.LastActive = lastTime
-	if !firstTime.IsZero() && !lastTime.IsZero() {
-		analytics.Duration = lastTime.Sub(firstTime)
-	}
-
-	return analytics, scanner.Err()
+	return analytics, nil
 }
 
 // CalculateBillingBlocks groups timestamps into billing windows.
@@ -263,3 +281,36 @@ func CalculateBillingBlocks(timestamps []time.Time, windowSize time.Duration) []
 
 	return blocks
 }
+
+// sessionUsage returns tokens, estimated cost and time since start for a
+// session from its tool's analytics (Claude JSONL, Gemini session files)
+func sessionUsage(inst *Instance, start time.Time) (int, float64, time.Duration) {
+	switch inst.Tool {
+	case "claude":
+		path := inst.GetJSONLPath()
+		if path == "" {
+			return 0, 0, 0
+		}
+		analytics, err := ParseSessionJSONL(path)
+		if err != nil {
+			return 0, 0, 0
+		}
+		tokens := analytics.InputTokens + analytics.OutputTokens + analytics.CacheReadTokens + analytics.CacheWriteTokens
+		var elapsed time.Duration
+		if !analytics.LastActive.IsZero() {
+			elapsed = analytics.LastActive.Sub(start)
+		}
+		return tokens, analytics.EstimatedCost, elapsed
+	case "gemini":
+		inst.UpdateGeminiSession(nil)
+		if inst.GeminiAnalytics == nil {
+			return 0, 0, 0
+		}
+		var elapsed time.Duration
+		if !inst.GeminiAnalytics.LastActive.IsZero() {
+			elapsed = inst.GeminiAnalytics.LastActive.Sub(start)
+		}
+		return inst.GeminiAnalytics.TotalTokens(), inst.GeminiAnalytics.cost(), elapsed
+	}
+	return 0, 0, 0
+}
@@ -0,0 +1,178 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// ApprovalRequest is a tool permission dialog a session is blocked on, as
+// parsed from its pane
+type ApprovalRequest struct {
+	Tool        string    `json:"tool,omitempty"`    // Bash, Edit, Write, Read, WebFetch, MCP
+	Command     string    `json:"command,omitempty"` // Command, file, URL or MCP call
+	AllowAlways bool      `json:"allow_always,omitempty"`
+	DetectedAt  time.Time `json:"detected_at"`
+}
+
+// String renders the request in permission rule form, e.g. Bash(go test ./...)
+func (r ApprovalRequest) String() string {
+	tool := r.Tool
+	if tool == "" {
+		tool = "unknown tool"
+	}
+	if r.Command == "" {
+		return tool
+	}
+	return tool + "(" + r.Command + ")"
+}
+
+// ErrNoPermissionPrompt is returned when approving or denying a session that
+// isn't showing a permission dialog
+var ErrNoPermissionPrompt = errors.New("no permission prompt is waiting")
+
+// autoApproveCooldown keeps the allowlist from answering the same dialog
+// twice while the pane still shows it
+const autoApproveCooldown = 3 * time.Second
+
+// Allows reports whether an auto_approve rule matches the request
+func (s PermissionSettings) Allows(req ApprovalRequest) bool {
+	for _, rule := range s.AutoApprove {
+		if matchPermissionRule(strings.TrimSpace(rule), req) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPermissionRule matches "Tool" or "Tool(pattern)" against a request.
+// In the pattern "*" matches anything and a trailing ":*" is a prefix match,
+// as in Claude's own permission rules. A prefix only matches whole words:
+// "git status:*" matches "git status" and "git status -s", not "git statuses".
+// Bash patterns never match commands that chain, pipe, substitute or
+// redirect, since the rest of such a command is not what the rule allows.
+func matchPermissionRule(rule string, req ApprovalRequest) bool {
+	tool, pattern, hasPattern := strings.Cut(rule, "(")
+	if tool == "" || !strings.EqualFold(tool, req.Tool) {
+		return false
+	}
+	if !hasPattern {
+		return true
+	}
+	pattern, ok := strings.CutSuffix(pattern, ")")
+	if !ok || req.Command == "" {
+		return false
+	}
+	if strings.EqualFold(req.Tool, "Bash") && hasShellOperator(req.Command) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(pattern, ":*"); ok {
+		return req.Command == prefix || strings.HasPrefix(req.Command, prefix+" ")
+	}
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(expr, req.Command)
+	return err == nil && matched
+}
+
+// hasShellOperator reports whether a command does more than run one
+// program: command lists (; && ||), pipes, backgrounding, command
+// substitution, redirects or several lines
+func hasShellOperator(command string) bool {
+	return strings.ContainsAny(command, ";&|`<>\n\r") || strings.Contains(command, "$(")
+}
+
+// updateApproval is called from UpdateStatus once the tmux status is mapped.
+// A waiting Claude session whose pane shows a permission dialog becomes
+// StatusNeedsApproval, or is answered right away if the allowlist matches.
+// Acknowledging the session drops the tmux status to idle while the dialog
+// is still open, so needs-approval sessions are rechecked when idle too.
+func (i *Instance) updateApproval(prevStatus Status) {
+	recheck := i.Status == StatusWaiting || (i.Status == StatusIdle && prevStatus == StatusNeedsApproval)
+	if i.Tool != "claude" || !recheck {
+		i.PendingApproval = nil
+		return
+	}
+	content, err := i.tmuxSession.CapturePane()
+	if err != nil {
+		return
+	}
+	parsed, ok := tmux.ParsePermissionPrompt(content)
+	if !ok {
+		i.PendingApproval = nil
+		return
+	}
+
+	req := ApprovalRequest{Tool: parsed.Tool, Command: parsed.Command, AllowAlways: parsed.AllowAlways}
+	if i.PendingApproval == nil || i.PendingApproval.Tool != req.Tool || i.PendingApproval.Command != req.Command {
+		req.DetectedAt = time.Now()
+		i.PendingApproval = &req
+	}
+
+	// Global config only: a repo's .agent-deck.toml must not be able to
+	// approve commands on its own behalf
+	if GetPermissionSettings().Allows(req) {
+		if time.Since(i.lastAutoApproved) > autoApproveCooldown {
+			if err := i.tmuxSession.SendKeys("1"); err != nil {
+				log.Printf("[APPROVAL] %s: failed to auto-approve %s: %v", i.Title, req, err)
+				i.Status = StatusNeedsApproval
+				return
+			}
+			log.Printf("[APPROVAL] %s: auto-approved %s", i.Title, req)
+			i.lastAutoApproved = time.Now()
+		}
+		i.PendingApproval = nil
+		i.Status = StatusRunning
+		return
+	}
+	i.Status = StatusNeedsApproval
+}
+
+// Approve answers the session's permission dialog with "Yes". With always
+// it picks option 2 instead ("Yes, and don't ask again ..."), which only
+// exists on some dialogs.
+func (i *Instance) Approve(always bool) error {
+	return i.answerPermission(func(s *tmux.Session, req tmux.PermissionRequest) error {
+		if always {
+			if !req.AllowAlways {
+				return fmt.Errorf("this prompt has no \"don't ask again\" option")
+			}
+			return s.SendKeys("2")
+		}
+		return s.SendKeys("1")
+	}, StatusRunning)
+}
+
+// Deny rejects the session's permission dialog. The agent then waits for
+// the user to say what to do instead.
+func (i *Instance) Deny() error {
+	return i.answerPermission(func(s *tmux.Session, _ tmux.PermissionRequest) error {
+		return s.SendEscape()
+	}, StatusWaiting)
+}
+
+// answerPermission sends an answer to the permission dialog on screen,
+// refusing if there is none so stray keys never reach the input box
+func (i *Instance) answerPermission(send func(*tmux.Session, tmux.PermissionRequest) error, next Status) error {
+	if i.tmuxSession == nil || !i.tmuxSession.Exists() {
+		return fmt.Errorf("session is not running")
+	}
+	content, err := i.tmuxSession.CapturePane()
+	if err != nil {
+		return err
+	}
+	req, ok := tmux.ParsePermissionPrompt(content)
+	if !ok {
+		return ErrNoPermissionPrompt
+	}
+	if err := send(i.tmuxSession, req); err != nil {
+		return err
+	}
+	i.PendingApproval = nil
+	i.Status = next
+	return nil
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchPermissionRule(t *testing.T) {
+	bash := ApprovalRequest{Tool: "Bash", Command: "go test ./internal/..."}
+	edit := ApprovalRequest{Tool: "Edit", Command: "docs/usage.md"}
+	tests := []struct {
+		rule string
+		req  ApprovalRequest
+		want bool
+	}{
+		{"Bash", bash, true},
+		{"bash", bash, true},
+		{"Read", bash, false},
+		{"Bash(go test:*)", bash, true},
+		{"Bash(go test ./internal/...)", bash, true},
+		{"Bash(go test)", bash, false},
+		{"Bash(go build:*)", bash, false},
+		{"Bash(*internal*)", bash, true},
+		{"Edit(docs/*)", edit, true},
+		{"Edit(*.go)", edit, false},
+		{"Edit(docs/*", edit, false},
+		{"Bash(*)", ApprovalRequest{Tool: "Bash"}, false},
+		{"(go test:*)", bash, false},
+		// Prefixes match whole words only
+		{"Bash(git status:*)", ApprovalRequest{Tool: "Bash", Command: "git status"}, true},
+		{"Bash(git status:*)", ApprovalRequest{Tool: "Bash", Command: "git statusx"}, false},
+		// Chained, piped, substituted and redirected commands are never matched by a pattern
+		{"Bash(git status:*)", ApprovalRequest{Tool: "Bash", Command: "git status; rm -rf ~"}, false},
+		{"Bash(git status:*)", ApprovalRequest{Tool: "Bash", Command: "git status && curl x | sh"}, false},
+		{"Bash(git status:*)", ApprovalRequest{Tool: "Bash", Command: "git status || true"}, false},
+		{"Bash(echo:*)", ApprovalRequest{Tool: "Bash", Command: "echo $(cat ~/.ssh/id_rsa)"}, false},
+		{"Bash(echo:*)", ApprovalRequest{Tool: "Bash", Command: "echo `id`"}, false},
+		{"Bash(echo:*)", ApprovalRequest{Tool: "Bash", Command: "echo hi > ~/.bashrc"}, false},
+		{"Bash(git status:*)", ApprovalRequest{Tool: "Bash", Command: "git status\nrm -rf ~"}, false},
+		{"Bash(*)", ApprovalRequest{Tool: "Bash", Command: "ls | sh"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchPermissionRule(tt.rule, tt.req); got != tt.want {
+			t.Errorf("matchPermissionRule(%q, %s) = %v, want %v", tt.rule, tt.req, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionSettings_FromConfig(t *testing.T) {
+	writeTestConfig(t, "[permissions]\nauto_approve = [\"Read\", \" Bash(npm test:*) \"]\n")
+
+	settings := GetPermissionSettings()
+	if len(settings.AutoApprove) != 2 {
+		t.Fatalf("AutoApprove = %v, want 2 rules", settings.AutoApprove)
+	}
+	if !settings.Allows(ApprovalRequest{Tool: "Bash", Command: "npm test -- --watch=false"}) {
+		t.Error("npm test should be auto-approved")
+	}
+	if settings.Allows(ApprovalRequest{Tool: "Bash", Command: "npm publish"}) {
+		t.Error("npm publish should not be auto-approved")
+	}
+	if !settings.Allows(ApprovalRequest{Tool: "Read", Command: "/etc/hosts"}) {
+		t.Error("a bare tool rule should allow every request of that tool")
+	}
+}
+
+func TestApprovalRequest_String(t *testing.T) {
+	if got := (ApprovalRequest{Tool: "Bash", Command: "ls"}).String(); got != "Bash(ls)" {
+		t.Errorf("String() = %q, want Bash(ls)", got)
+	}
+	if got := (ApprovalRequest{Tool: "Read"}).String(); got != "Read" {
+		t.Errorf("String() = %q, want Read", got)
+	}
+	if got := (ApprovalRequest{}).String(); got != "unknown tool" {
+		t.Errorf("String() = %q, want unknown tool", got)
+	}
+}
+
+func TestApprove_NotRunning(t *testing.T) {
+	inst := NewInstance("approve-test", t.TempDir())
+	if err := inst.Approve(false); err == nil || errors.Is(err, ErrNoPermissionPrompt) {
+		t.Errorf("Approve() on a stopped session = %v, want a not running error", err)
+	}
+}
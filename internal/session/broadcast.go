@@ -0,0 +1,136 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// BroadcastFilter selects the sessions a message is broadcast to. Empty
+// fields match every session; set fields must all match.
+type BroadcastFilter struct {
+	Group  string // Group path; subgroups are included
+	Status string // One of BroadcastStatuses
+	Tool   string
+}
+
+// BroadcastStatuses lists the session statuses a broadcast can filter on
+var BroadcastStatuses = []string{
+	string(StatusRunning),
+	string(StatusWaiting),
+	string(StatusNeedsApproval),
+	string(StatusIdle),
+	string(StatusError),
+	string(StatusStarting),
+	string(StatusBudgetExceeded),
+}
+
+// Validate checks that the filter's status is one a session can have
+func (f BroadcastFilter) Validate() error {
+	if f.Status == "" {
+		return nil
+	}
+	for _, status := range BroadcastStatuses {
+		if f.Status == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid status %q (use %s)", f.Status, strings.Join(BroadcastStatuses, ", "))
+}
+
+// IsEmpty returns true if the filter selects nothing by itself
+func (f BroadcastFilter) IsEmpty() bool {
+	return f.Group == "" && f.Status == "" && f.Tool == ""
+}
+
+// Matches reports whether a session passes the filter
+func (f BroadcastFilter) Matches(inst *Instance) bool {
+	if f.Group != "" && inst.GroupPath != f.Group && !strings.HasPrefix(inst.GroupPath, f.Group+"/") {
+		return false
+	}
+	if f.Status != "" && string(inst.Status) != f.Status {
+		return false
+	}
+	if f.Tool != "" && inst.Tool != f.Tool {
+		return false
+	}
+	return true
+}
+
+// Broadcast outcomes per session
+const (
+	BroadcastSent       = "sent"
+	BroadcastBusy       = "busy"        // Mid-turn; skipped unless forced
+	BroadcastNotRunning = "not_running" // No tmux session to type into
+	BroadcastOverBudget = "over_budget" // Crossed a hard budget limit; never sent, even when forced
+	BroadcastFailed     = "failed"      // Not ready in time, or tmux refused the keys
+)
+
+// BroadcastOptions controls how a broadcast treats each session
+type BroadcastOptions struct {
+	Force  bool // Also type into sessions that are mid-turn
+	NoWait bool // Don't wait for each agent to be ready
+}
+
+// BroadcastResult is what happened when broadcasting to one session
+type BroadcastResult struct {
+	SessionID string
+	Title     string
+	Outcome   string
+	Err       error
+}
+
+// Broadcast sends message to every target concurrently, each after its own
+// readiness wait, and returns one result per target in the same order.
+// Targets are never typed into mid-turn (running or needs-approval) unless opts.Force;
+// callers should refresh statuses first.
+func Broadcast(targets []*Instance, message string, opts BroadcastOptions) []BroadcastResult {
+	results := make([]BroadcastResult, len(targets))
+	var wg sync.WaitGroup
+	for idx, inst := range targets {
+		// Read before starting goroutines: the TUI updates statuses in the background.
+		// Text typed into a permission dialog would answer it, so those count as busy.
+		busy := inst.Status == StatusRunning || inst.Status == StatusNeedsApproval
+		wg.Add(1)
+		go func(idx int, inst *Instance, busy bool) {
+			defer wg.Done()
+			results[idx] = inst.broadcastTo(message, busy, opts)
+		}(idx, inst, busy)
+	}
+	wg.Wait()
+	return results
+}
+
+// broadcastTo delivers a broadcast message to one session using the same
+// wait for input and send path as queued prompts
+func (i *Instance) broadcastTo(message string, busy bool, opts BroadcastOptions) BroadcastResult {
+	result := BroadcastResult{SessionID: i.ID, Title: i.Title}
+	if i.tmuxSession == nil || !i.tmuxSession.Exists() {
+		result.Outcome = BroadcastNotRunning
+		return result
+	}
+	if i.BudgetExceeded != "" {
+		result.Outcome = BroadcastOverBudget
+		return result
+	}
+	if busy && !opts.Force {
+		result.Outcome = BroadcastBusy
+		return result
+	}
+
+	// A forced send to a busy session goes in now; waiting would mean waiting for the turn to end
+	if !opts.NoWait && !busy {
+		if err := i.waitForInput(); err != nil {
+			result.Outcome = BroadcastFailed
+			result.Err = err
+			return result
+		}
+	}
+	if err := i.sendKeys(message); err != nil {
+		result.Outcome = BroadcastFailed
+		result.Err = err
+		return result
+	}
+	result.Outcome = BroadcastSent
+	return result
+}
@@ -0,0 +1,66 @@
+package session
+
+import "testing"
+
+func TestBroadcastFilter(t *testing.T) {
+	api := &Instance{ID: "1", Title: "api", GroupPath: "work", Tool: "claude", Status: StatusWaiting}
+	web := &Instance{ID: "2", Title: "web", GroupPath: "work/frontend", Tool: "gemini", Status: StatusRunning}
+	notes := &Instance{ID: "3", Title: "notes", GroupPath: "workshop", Tool: "claude", Status: StatusIdle}
+
+	tests := []struct {
+		filter BroadcastFilter
+		want   []string
+	}{
+		{BroadcastFilter{Group: "work"}, []string{"api", "web"}},
+		{BroadcastFilter{Tool: "claude"}, []string{"api", "notes"}},
+		{BroadcastFilter{Group: "work", Status: "waiting"}, []string{"api"}},
+		{BroadcastFilter{Status: "running", Tool: "claude"}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, inst := range []*Instance{api, web, notes} {
+			if tt.filter.Matches(inst) {
+				got = append(got, inst.Title)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%+v matches %v, want %v", tt.filter, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%+v matches %v, want %v", tt.filter, got, tt.want)
+			}
+		}
+	}
+	if !(BroadcastFilter{}).IsEmpty() || (BroadcastFilter{Tool: "claude"}).IsEmpty() {
+		t.Error("IsEmpty() should be true only without any field set")
+	}
+}
+
+func TestBroadcastSkipsSessionsWithoutTmux(t *testing.T) {
+	targets := []*Instance{
+		{ID: "1", Title: "stopped"},
+		{ID: "2", Title: "busy", Status: StatusRunning},
+	}
+	results := Broadcast(targets, "/compact", BroadcastOptions{Force: true})
+	if len(results) != 2 || results[0].Title != "stopped" || results[1].Title != "busy" {
+		t.Fatalf("Broadcast() = %+v, want one result per target in order", results)
+	}
+	for _, r := range results {
+		if r.Outcome != BroadcastNotRunning {
+			t.Errorf("%s: outcome %q, want %q", r.Title, r.Outcome, BroadcastNotRunning)
+		}
+	}
+}
+
+func TestBroadcastFilter_Validate(t *testing.T) {
+	for _, status := range []string{"", "needs-approval", "budget-exceeded", "starting"} {
+		if err := (BroadcastFilter{Status: status}).Validate(); err != nil {
+			t.Errorf("Validate() with status %q = %v", status, err)
+		}
+	}
+	if err := (BroadcastFilter{Status: "active"}).Validate(); err == nil {
+		t.Error("Validate() should reject an unknown status")
+	}
+}
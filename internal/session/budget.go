@@ -0,0 +1,282 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// budgetCheckInterval is how often a session's usage is compared with its
+// budget. Reading usage parses the session transcript, so it is not done on
+// every status poll.
+const budgetCheckInterval = 15 * time.Second
+
+// budgetInterruptCooldown spaces out interrupts of an over-budget agent.
+// Claude exits on a second Ctrl+C in quick succession.
+const budgetInterruptCooldown = 10 * time.Second
+
+// maxBusyPollGap caps the time credited between two status polls, so a
+// process that was suspended does not count the gap as work
+const maxBusyPollGap = 30 * time.Second
+
+// ErrBudgetExceeded is returned when prompting a session that crossed a
+// hard budget limit and was not overridden since
+var ErrBudgetExceeded = errors.New("session is over its budget")
+
+// BudgetLevel is the outcome of comparing usage with a budget
+type BudgetLevel int
+
+const (
+	BudgetOK   BudgetLevel = iota
+	BudgetSoft             // A soft limit was reached: warn
+	BudgetHard             // A hard limit was reached: interrupt and block
+)
+
+// BudgetUsage is what a session used, as counted against its budget
+type BudgetUsage struct {
+	Cost    float64       `json:"cost"` // Estimated USD
+	Tokens  int           `json:"tokens"`
+	Turns   int           `json:"turns"`
+	Runtime time.Duration `json:"runtime"` // Time spent running
+}
+
+// minus returns the usage since base. A field below its base means the tool
+// started a new conversation, which is then counted from zero.
+func (u BudgetUsage) minus(base BudgetUsage) BudgetUsage {
+	if u.Cost >= base.Cost {
+		u.Cost -= base.Cost
+	}
+	if u.Tokens >= base.Tokens {
+		u.Tokens -= base.Tokens
+	}
+	if u.Turns >= base.Turns {
+		u.Turns -= base.Turns
+	}
+	if u.Runtime >= base.Runtime {
+		u.Runtime -= base.Runtime
+	}
+	return u
+}
+
+// BudgetLimits are caps on a session's usage. Zero means no cap.
+type BudgetLimits struct {
+	Cost           float64 `toml:"cost" json:"cost,omitempty"`                       // Estimated USD
+	Tokens         int     `toml:"tokens" json:"tokens,omitempty"`                   // Input, output and cache tokens
+	Turns          int     `toml:"turns" json:"turns,omitempty"`                     // Assistant turns
+	RuntimeMinutes int     `toml:"runtime_minutes" json:"runtime_minutes,omitempty"` // Time spent running
+}
+
+// IsZero returns true if no cap is set
+func (l BudgetLimits) IsZero() bool {
+	return l == BudgetLimits{}
+}
+
+// overlay returns l with the caps set in o replacing its own
+func (l BudgetLimits) overlay(o BudgetLimits) BudgetLimits {
+	if o.Cost > 0 {
+		l.Cost = o.Cost
+	}
+	if o.Tokens > 0 {
+		l.Tokens = o.Tokens
+	}
+	if o.Turns > 0 {
+		l.Turns = o.Turns
+	}
+	if o.RuntimeMinutes > 0 {
+		l.RuntimeMinutes = o.RuntimeMinutes
+	}
+	return l
+}
+
+// reached describes the first cap that usage has reached, or returns ""
+func (l BudgetLimits) reached(u BudgetUsage) string {
+	runtime := time.Duration(l.RuntimeMinutes) * time.Minute
+	switch {
+	case l.Cost > 0 && u.Cost >= l.Cost:
+		return fmt.Sprintf("cost $%.2f reached the $%.2f limit", u.Cost, l.Cost)
+	case l.Tokens > 0 && u.Tokens >= l.Tokens:
+		return fmt.Sprintf("%d tokens reached the %d limit", u.Tokens, l.Tokens)
+	case l.Turns > 0 && u.Turns >= l.Turns:
+		return fmt.Sprintf("%d turns reached the %d limit", u.Turns, l.Turns)
+	case runtime > 0 && u.Runtime >= runtime:
+		return fmt.Sprintf("%s of work reached the %s limit", u.Runtime.Round(time.Minute), runtime)
+	}
+	return ""
+}
+
+// Budget holds a session's soft limits, which warn, and hard limits, which
+// interrupt the agent
+type Budget struct {
+	Soft BudgetLimits `toml:"soft" json:"soft"`
+	Hard BudgetLimits `toml:"hard" json:"hard"`
+}
+
+// IsZero returns true if the budget sets no limits
+func (b Budget) IsZero() bool {
+	return b.Soft.IsZero() && b.Hard.IsZero()
+}
+
+// Check compares usage with the budget: the first hard limit reached wins,
+// then the first soft one
+func (b Budget) Check(u BudgetUsage) (BudgetLevel, string) {
+	if reason := b.Hard.reached(u); reason != "" {
+		return BudgetHard, reason
+	}
+	if reason := b.Soft.reached(u); reason != "" {
+		return BudgetSoft, reason
+	}
+	return BudgetOK, ""
+}
+
+// For returns the budget of a session. Limits are merged field by field:
+// the default, then each enclosing group from the top down, then the
+// session's own entry (by ID or title).
+func (s BudgetSettings) For(inst *Instance) Budget {
+	budget := s.Default
+	apply := func(b Budget) {
+		budget.Soft = budget.Soft.overlay(b.Soft)
+		budget.Hard = budget.Hard.overlay(b.Hard)
+	}
+	if inst.GroupPath != "" {
+		parts := strings.Split(inst.GroupPath, "/")
+		for n := 1; n <= len(parts); n++ {
+			if b, ok := s.Groups[strings.Join(parts[:n], "/")]; ok {
+				apply(b)
+			}
+		}
+	}
+	if b, ok := s.Sessions[inst.Title]; ok {
+		apply(b)
+	}
+	if b, ok := s.Sessions[inst.ID]; ok {
+		apply(b)
+	}
+	return budget
+}
+
+// rawBudgetUsage returns the session's usage from its tool's analytics and
+// its counted running time
+func (i *Instance) rawBudgetUsage() BudgetUsage {
+	usage := BudgetUsage{Runtime: i.BusyTime}
+	switch i.Tool {
+	case "claude":
+		path := i.GetJSONLPath()
+		if path == "" {
+			return usage
+		}
+		analytics, err := ParseSessionJSONL(path)
+		if err != nil {
+			return usage
+		}
+		usage.Cost = analytics.EstimatedCost
+		usage.Tokens = analytics.TotalTokens()
+		usage.Turns = analytics.TotalTurns
+	case "gemini":
+		if analytics := i.GeminiAnalytics; analytics != nil {
+			usage.Cost = analytics.cost()
+			usage.Tokens = analytics.TotalTokens()
+			usage.Turns = analytics.TotalTurns
+		}
+	}
+	return usage
+}
+
+// BudgetUsage returns what the session used since its budget was last
+// overridden
+func (i *Instance) BudgetUsage() BudgetUsage {
+	usage := i.rawBudgetUsage()
+	if i.BudgetBaseline != nil {
+		usage = usage.minus(*i.BudgetBaseline)
+	}
+	return usage
+}
+
+// updateBudget is called from UpdateStatus once the status is known. It
+// counts running time, compares usage with the session's budget every
+// budgetCheckInterval and enforces a crossed hard limit: the agent is
+// interrupted whenever it gets busy, and the session shows as
+// budget-exceeded until OverrideBudget.
+func (i *Instance) updateBudget() {
+	now := time.Now()
+	if i.Status == StatusRunning {
+		if !i.lastBusyPoll.IsZero() && now.Sub(i.lastBusyPoll) < maxBusyPollGap {
+			i.BusyTime += now.Sub(i.lastBusyPoll)
+		}
+		i.lastBusyPoll = now
+	} else {
+		i.lastBusyPoll = time.Time{}
+	}
+
+	if now.Sub(i.lastBudgetCheck) >= budgetCheckInterval {
+		i.lastBudgetCheck = now
+		i.evaluateBudget(GetBudgetSettings().For(i))
+	}
+	if i.BudgetExceeded == "" {
+		return
+	}
+
+	if i.Status == StatusRunning && now.Sub(i.lastBudgetInterrupt) >= budgetInterruptCooldown {
+		i.lastBudgetInterrupt = now
+		if err := i.tmuxSession.SendCtrlC(); err != nil {
+			log.Printf("[BUDGET] %s: failed to interrupt: %v", i.Title, err)
+		} else {
+			log.Printf("[BUDGET] %s: interrupted (%s)", i.Title, i.BudgetExceeded)
+		}
+	}
+	i.PendingApproval = nil
+	i.Status = StatusBudgetExceeded
+}
+
+// evaluateBudget sets BudgetWarning and BudgetExceeded from current usage.
+// An exceeded budget stays exceeded until overridden, even if usage later
+// reads lower, unless the session no longer has any hard limit.
+func (i *Instance) evaluateBudget(budget Budget) {
+	if budget.Hard.IsZero() {
+		i.BudgetExceeded = ""
+	}
+	if budget.IsZero() {
+		i.BudgetWarning = ""
+		return
+	}
+	level, reason := budget.Check(i.BudgetUsage())
+	switch level {
+	case BudgetHard:
+		if i.BudgetExceeded == "" {
+			log.Printf("[BUDGET] %s: hard limit: %s", i.Title, reason)
+			i.BudgetExceeded = reason
+		}
+		i.BudgetWarning = ""
+	case BudgetSoft:
+		if i.BudgetWarning == "" {
+			log.Printf("[BUDGET] %s: soft limit: %s", i.Title, reason)
+		}
+		i.BudgetWarning = reason
+	default:
+		i.BudgetWarning = ""
+	}
+}
+
+// BudgetError returns an error wrapping ErrBudgetExceeded if the session
+// crossed a hard limit and may not be sent prompts
+func (i *Instance) BudgetError() error {
+	if i.BudgetExceeded == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (override with: agent-deck session budget %s --override)", ErrBudgetExceeded, i.BudgetExceeded, i.Title)
+}
+
+// OverrideBudget lets a session continue past its budget. The exceeded mark
+// is cleared and usage is counted from zero again, so the same limits apply
+// to the work that follows.
+func (i *Instance) OverrideBudget() {
+	raw := i.rawBudgetUsage()
+	i.BudgetBaseline = &raw
+	i.BudgetExceeded = ""
+	i.BudgetWarning = ""
+	i.lastBudgetCheck = time.Time{}
+	if i.Status == StatusBudgetExceeded {
+		i.Status = StatusIdle
+	}
+}
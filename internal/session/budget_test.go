@@ -0,0 +1,184 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBudgetSettings_For(t *testing.T) {
+	writeTestConfig(t, `
+[budgets.default]
+soft = { cost = 5.0 }
+hard = { cost = 10.0, runtime_minutes = 120 }
+
+[budgets.groups."work"]
+hard = { turns = 200 }
+
+[budgets.groups."work/frontend"]
+hard = { cost = 3.0 }
+
+[budgets.sessions."landing-page"]
+soft = { tokens = 100000 }
+`)
+	settings := GetBudgetSettings()
+
+	inst := &Instance{ID: "abc", Title: "landing-page", GroupPath: "work/frontend"}
+	got := settings.For(inst)
+	want := Budget{
+		Soft: BudgetLimits{Cost: 5, Tokens: 100000},
+		Hard: BudgetLimits{Cost: 3, Turns: 200, RuntimeMinutes: 120},
+	}
+	if got != want {
+		t.Errorf("For(landing-page) = %+v, want %+v", got, want)
+	}
+
+	other := &Instance{ID: "def", Title: "scratch", GroupPath: "personal"}
+	if got := settings.For(other); got != settings.Default {
+		t.Errorf("For(scratch) = %+v, want the default %+v", got, settings.Default)
+	}
+}
+
+func TestBudget_Check(t *testing.T) {
+	budget := Budget{
+		Soft: BudgetLimits{Cost: 1},
+		Hard: BudgetLimits{Cost: 2, RuntimeMinutes: 30},
+	}
+	tests := []struct {
+		usage BudgetUsage
+		want  BudgetLevel
+	}{
+		{BudgetUsage{Cost: 0.5}, BudgetOK},
+		{BudgetUsage{Cost: 1}, BudgetSoft},
+		{BudgetUsage{Cost: 2.5}, BudgetHard},
+		{BudgetUsage{Cost: 0.1, Runtime: 45 * time.Minute}, BudgetHard},
+	}
+	for _, tt := range tests {
+		got, reason := budget.Check(tt.usage)
+		if got != tt.want {
+			t.Errorf("Check(%+v) = %v, want %v", tt.usage, got, tt.want)
+		}
+		if (got == BudgetOK) != (reason == "") {
+			t.Errorf("Check(%+v) reason = %q", tt.usage, reason)
+		}
+	}
+}
+
+func TestBudgetUsage_Minus(t *testing.T) {
+	u := BudgetUsage{Cost: 3, Tokens: 500, Turns: 2, Runtime: time.Hour}
+	base := BudgetUsage{Cost: 1, Tokens: 800, Turns: 1, Runtime: time.Minute}
+	got := u.minus(base)
+	want := BudgetUsage{Cost: 2, Tokens: 500, Turns: 1, Runtime: 59 * time.Minute}
+	if got != want {
+		t.Errorf("minus = %+v, want %+v", got, want)
+	}
+}
+
+func TestEvaluateBudget_HardIsStickyUntilOverride(t *testing.T) {
+	inst := &Instance{
+		Title:           "worker",
+		Tool:            "gemini",
+		Status:          StatusIdle,
+		GeminiAnalytics: &GeminiSessionAnalytics{InputTokens: 900, OutputTokens: 100, TotalTurns: 4},
+	}
+	budget := Budget{
+		Soft: BudgetLimits{Turns: 3},
+		Hard: BudgetLimits{Tokens: 1000},
+	}
+
+	inst.evaluateBudget(budget)
+	if inst.BudgetExceeded == "" {
+		t.Fatal("BudgetExceeded should be set at 1000 tokens")
+	}
+	if err := inst.BudgetError(); !errors.Is(err, ErrBudgetExceeded) || !strings.Contains(err.Error(), "--override") {
+		t.Errorf("BudgetError() = %v, want ErrBudgetExceeded with an override hint", err)
+	}
+
+	// Usage reading lower (a new conversation) does not lift the block
+	inst.GeminiAnalytics = &GeminiSessionAnalytics{InputTokens: 10}
+	inst.evaluateBudget(budget)
+	if inst.BudgetExceeded == "" {
+		t.Error("BudgetExceeded should stay set until overridden")
+	}
+
+	inst.GeminiAnalytics = &GeminiSessionAnalytics{InputTokens: 900, OutputTokens: 100, TotalTurns: 4}
+	inst.Status = StatusBudgetExceeded
+	inst.OverrideBudget()
+	if inst.BudgetError() != nil || inst.Status != StatusIdle {
+		t.Fatalf("after override: BudgetError() = %v, Status = %s", inst.BudgetError(), inst.Status)
+	}
+	if usage := inst.BudgetUsage(); usage.Tokens != 0 || usage.Turns != 0 {
+		t.Errorf("usage after override = %+v, want zero", usage)
+	}
+
+	// Further work counts from the override
+	inst.GeminiAnalytics = &GeminiSessionAnalytics{InputTokens: 1400, OutputTokens: 100, TotalTurns: 8}
+	inst.evaluateBudget(budget)
+	if inst.BudgetExceeded != "" {
+		t.Errorf("BudgetExceeded = %q, want unset at 500 tokens since override", inst.BudgetExceeded)
+	}
+	if inst.BudgetWarning == "" {
+		t.Error("BudgetWarning should be set at 4 turns since override")
+	}
+
+	// Removing the hard limits lifts the block
+	inst.BudgetExceeded = "cost $2.00 reached the $1.00 limit"
+	inst.evaluateBudget(Budget{})
+	if inst.BudgetExceeded != "" || inst.BudgetWarning != "" {
+		t.Errorf("with no budget: exceeded %q, warning %q", inst.BudgetExceeded, inst.BudgetWarning)
+	}
+}
+
+func TestRawBudgetUsage_PricesOpusAtOpusRates(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", configDir)
+	project := t.TempDir()
+	resolved, _ := filepath.EvalSymlinks(project)
+
+	inst := NewInstance("opus", project)
+	inst.Tool = "claude"
+	inst.ClaudeSessionID = "claude-opus"
+	writeTestTranscript(t, configDir, ConvertToClaudeDirName(resolved), "claude-opus.jsonl",
+		`{"type":"assistant","message":{"model":"claude-opus-4-1-20250805","usage":{"input_tokens":1000000,"output_tokens":100000}}}
+{"type":"assistant","message":{"model":"claude-3-5-haiku","usage":{"input_tokens":1000000}}}
+`)
+
+	// Opus: 1M input at $15 + 100k output at $75; haiku: 1M input at $0.80
+	usage := inst.rawBudgetUsage()
+	if usage.Cost < 23.29 || usage.Cost > 23.31 {
+		t.Errorf("Cost = %f, want 23.30", usage.Cost)
+	}
+	if usage.Turns != 2 || usage.Tokens != 2100000 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestRawBudgetUsage_CountsMultiBlockResponseOnce(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", configDir)
+	project := t.TempDir()
+	resolved, _ := filepath.EvalSymlinks(project)
+
+	inst := NewInstance("blocks", project)
+	inst.Tool = "claude"
+	inst.ClaudeSessionID = "claude-blocks"
+	// One response written as a text line and two tool_use lines, each
+	// repeating the response's usage
+	writeTestTranscript(t, configDir, ConvertToClaudeDirName(resolved), "claude-blocks.jsonl",
+		`{"type":"assistant","requestId":"req_1","message":{"id":"msg_1","model":"claude-opus-4-1-20250805","content":[{"type":"text","text":"Let me look"}],"usage":{"input_tokens":1000000,"output_tokens":100000}}}
+{"type":"assistant","requestId":"req_1","message":{"id":"msg_1","model":"claude-opus-4-1-20250805","content":[{"type":"tool_use","name":"Read"}],"usage":{"input_tokens":1000000,"output_tokens":100000}}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","content":"ok"}]}}
+{"type":"assistant","requestId":"req_1","message":{"id":"msg_1","model":"claude-opus-4-1-20250805","content":[{"type":"tool_use","name":"Grep"}],"usage":{"input_tokens":1000000,"output_tokens":100000}}}
+`)
+
+	// 1M input at $15 + 100k output at $75, once
+	usage := inst.rawBudgetUsage()
+	if usage.Cost < 22.49 || usage.Cost > 22.51 {
+		t.Errorf("Cost = %f, want 22.50", usage.Cost)
+	}
+	if usage.Turns != 1 || usage.Tokens != 1100000 {
+		t.Errorf("usage = %+v, want 1 turn and 1100000 tokens", usage)
+	}
+}
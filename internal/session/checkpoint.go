@@ -0,0 +1,146 @@
+package session
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// checkpointsInFlight tracks sessions with a checkpoint being written, so a
+// slow snapshot isn't started twice
+var (
+	checkpointsInFlight   = make(map[string]bool)
+	checkpointsInFlightMu sync.Mutex
+)
+
+// CheckpointRefPrefix returns the hidden ref namespace holding a session's
+// checkpoints: refs/agent-deck/<session-id>/
+func CheckpointRefPrefix(sessionID string) string {
+	return "refs/agent-deck/" + sessionID + "/"
+}
+
+// checkpointMessage builds the commit message for a checkpoint. The subject
+// is the prompt that produced it, flattened to one line; the body keeps the
+// full prompt.
+func checkpointMessage(prompt string) string {
+	subject := strings.Join(strings.Fields(prompt), " ")
+	if subject == "" {
+		return "(no prompt)"
+	}
+	if len([]rune(subject)) > 72 {
+		subject = string([]rune(subject)[:71]) + "…"
+	}
+	return subject + "\n\n" + strings.TrimSpace(prompt)
+}
+
+// CheckpointsEnabled returns true if the [checkpoints] config (global or
+// repo) turns checkpoints on for this session's git repository
+func (i *Instance) CheckpointsEnabled() bool {
+	return git.IsGitRepo(i.ProjectPath) && i.effectiveConfig().Checkpoints.Enabled
+}
+
+// Checkpoints returns this session's checkpoints, oldest first
+func (i *Instance) Checkpoints() ([]git.Checkpoint, error) {
+	if !git.IsGitRepo(i.ProjectPath) {
+		return nil, fmt.Errorf("%s is not a git repository", i.ProjectPath)
+	}
+	return git.ListCheckpoints(i.ProjectPath, CheckpointRefPrefix(i.ID))
+}
+
+// CreateCheckpoint snapshots the session's working tree, linked to the prompt
+// that produced it, and prunes checkpoints beyond [checkpoints]
+// max_per_session. Returns nil without error if nothing changed since the
+// last checkpoint.
+func (i *Instance) CreateCheckpoint(prompt string) (*git.Checkpoint, error) {
+	if !git.IsGitRepo(i.ProjectPath) {
+		return nil, fmt.Errorf("%s is not a git repository", i.ProjectPath)
+	}
+	prefix := CheckpointRefPrefix(i.ID)
+	cp, err := git.CreateCheckpoint(i.ProjectPath, prefix, checkpointMessage(prompt))
+	if err != nil || cp == nil {
+		return cp, err
+	}
+
+	existing, err := git.ListCheckpoints(i.ProjectPath, prefix)
+	if err != nil {
+		return cp, nil
+	}
+	for excess := len(existing) - i.effectiveConfig().Checkpoints.GetMaxPerSession(); excess > 0; excess-- {
+		if err := git.DeleteCheckpoint(i.ProjectPath, existing[0].Ref); err != nil {
+			log.Printf("[CHECKPOINT] Warning: %v", err)
+			break
+		}
+		existing = existing[1:]
+	}
+	return cp, nil
+}
+
+// checkpointAsync takes a checkpoint in the background when the agent
+// finishes a turn. Errors are logged: a failed snapshot must never affect
+// status detection.
+func (i *Instance) checkpointAsync() {
+	checkpointsInFlightMu.Lock()
+	if checkpointsInFlight[i.ID] {
+		checkpointsInFlightMu.Unlock()
+		return
+	}
+	checkpointsInFlight[i.ID] = true
+	checkpointsInFlightMu.Unlock()
+
+	// Capture fields now; the instance keeps changing on the status worker
+	snapshot := &Instance{ID: i.ID, Title: i.Title, ProjectPath: i.ProjectPath}
+	prompt := i.LatestPrompt
+
+	go func() {
+		defer func() {
+			checkpointsInFlightMu.Lock()
+			delete(checkpointsInFlight, snapshot.ID)
+			checkpointsInFlightMu.Unlock()
+		}()
+		if !snapshot.CheckpointsEnabled() {
+			return
+		}
+		cp, err := snapshot.CreateCheckpoint(prompt)
+		if err != nil {
+			log.Printf("[CHECKPOINT] %s: %v", snapshot.Title, err)
+			return
+		}
+		if cp != nil {
+			log.Printf("[CHECKPOINT] %s: checkpoint %d (%s)", snapshot.Title, cp.Number, cp.Commit[:7])
+		}
+	}()
+}
+
+// RollbackToCheckpoint restores the session's working tree to checkpoint n.
+// The current state is checkpointed first, so a rollback can itself be
+// rolled back; that checkpoint is returned (nil if it matched the last one).
+// The index and branch are untouched: restored files show up as changes.
+func (i *Instance) RollbackToCheckpoint(n int) (*git.Checkpoint, error) {
+	checkpoints, err := i.Checkpoints()
+	if err != nil {
+		return nil, err
+	}
+	var target *git.Checkpoint
+	for idx := range checkpoints {
+		if checkpoints[idx].Number == n {
+			target = &checkpoints[idx]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("checkpoint %d not found", n)
+	}
+
+	safety, err := i.CreateCheckpoint(fmt.Sprintf("Before rollback to checkpoint %d", n))
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkpoint current state: %w", err)
+	}
+	if err := git.RestoreCheckpoint(i.ProjectPath, target.Commit); err != nil {
+		return safety, err
+	}
+	git.InvalidateStatus(i.ProjectPath)
+	return safety, nil
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git/gittest"
+)
+
+func TestCheckpointMessage(t *testing.T) {
+	msg := checkpointMessage("  fix the\n  login bug  ")
+	if subject, body, _ := strings.Cut(msg, "\n\n"); subject != "fix the login bug" || body != "fix the\n  login bug" {
+		t.Errorf("checkpointMessage() = %q", msg)
+	}
+	if msg := checkpointMessage(""); msg != "(no prompt)" {
+		t.Errorf("checkpointMessage(\"\") = %q, want (no prompt)", msg)
+	}
+	if subject, _, _ := strings.Cut(checkpointMessage(strings.Repeat("x", 100)), "\n"); len([]rune(subject)) != 72 {
+		t.Errorf("long prompt subject has %d runes, want 72", len([]rune(subject)))
+	}
+}
+
+func TestInstance_CheckpointsAndRollback(t *testing.T) {
+	writeTestConfig(t, "[checkpoints]\nenabled = true\nmax_per_session = 3\n")
+	repo := gittest.NewRepo(t)
+	inst := NewInstance("cp", repo)
+	if !inst.CheckpointsEnabled() {
+		t.Fatal("CheckpointsEnabled() should be true with [checkpoints] enabled")
+	}
+
+	file := filepath.Join(repo, "shared.txt")
+	for _, content := range []string{"one", "two", "three", "four"} {
+		if err := os.WriteFile(file, []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := inst.CreateCheckpoint("write " + content); err != nil {
+			t.Fatalf("CreateCheckpoint() failed: %v", err)
+		}
+	}
+
+	// max_per_session = 3 prunes the oldest
+	checkpoints, err := inst.Checkpoints()
+	if err != nil || len(checkpoints) != 3 || checkpoints[0].Number != 2 {
+		t.Fatalf("Checkpoints() = %+v, %v; want checkpoints 2-4", checkpoints, err)
+	}
+	if checkpoints[0].Subject != "write two" {
+		t.Errorf("checkpoint 2 prompt = %q, want \"write two\"", checkpoints[0].Subject)
+	}
+
+	if err := os.WriteFile(file, []byte("unsaved\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := inst.RollbackToCheckpoint(2)
+	if err != nil {
+		t.Fatalf("RollbackToCheckpoint() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "two\n" {
+		t.Errorf("shared.txt = %q after rollback, want \"two\\n\"", data)
+	}
+	if saved == nil || saved.Number != 5 {
+		t.Fatalf("rollback should save the current state as checkpoint 5, got %+v", saved)
+	}
+
+	// The rollback can itself be rolled back
+	if _, err := inst.RollbackToCheckpoint(saved.Number); err != nil {
+		t.Fatalf("RollbackToCheckpoint(%d) failed: %v", saved.Number, err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "unsaved\n" {
+		t.Errorf("shared.txt = %q after undoing the rollback, want \"unsaved\\n\"", data)
+	}
+
+	if _, err := inst.RollbackToCheckpoint(1); err == nil {
+		t.Error("RollbackToCheckpoint() should fail for a pruned checkpoint")
+	}
+}
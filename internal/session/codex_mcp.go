@@ -0,0 +1,183 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// codexMCPServer is one [mcp_servers.<name>] table in Codex's config.toml
+type codexMCPServer struct {
+	Command     string            `toml:"command,omitempty"`
+	Args        []string          `toml:"args,omitempty"`
+	Env         map[string]string `toml:"env,omitempty"`
+	URL         string            `toml:"url,omitempty"`          // Streamable HTTP MCPs
+	HTTPHeaders map[string]string `toml:"http_headers,omitempty"` // Headers for url
+	// Headers whose value Codex reads from an environment variable
+	EnvHTTPHeaders map[string]string `toml:"env_http_headers,omitempty"`
+}
+
+// GetCodexConfigDir returns Codex's config directory ($CODEX_HOME or ~/.codex)
+func GetCodexConfigDir() string {
+	if dir := os.Getenv("CODEX_HOME"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".codex")
+}
+
+// GetCodexConfigPath returns the path to Codex's config.toml
+func GetCodexConfigPath() string {
+	return filepath.Join(GetCodexConfigDir(), "config.toml")
+}
+
+// readCodexMCPServers returns the raw mcp_servers tables from Codex's config.toml
+func readCodexMCPServers() map[string]interface{} {
+	var config struct {
+		MCPServers map[string]interface{} `toml:"mcp_servers"`
+	}
+	if _, err := toml.DecodeFile(GetCodexConfigPath(), &config); err != nil {
+		return nil
+	}
+	return config.MCPServers
+}
+
+// GetCodexMCPInfo reads MCP configuration from Codex's config.toml
+// Codex only has a user-level config, so all MCPs are reported as Global
+func GetCodexMCPInfo(projectPath string) *MCPInfo {
+	info := &MCPInfo{}
+	for name := range readCodexMCPServers() {
+		info.Global = append(info.Global, name)
+	}
+	sort.Strings(info.Global)
+	return info
+}
+
+// GetCodexMCPNames returns names of configured MCPs from Codex's config.toml
+func GetCodexMCPNames() []string {
+	return GetCodexMCPInfo("").Global
+}
+
+// codexServerFromConfig translates a Claude-format server entry to Codex's format
+func codexServerFromConfig(cfg MCPServerConfig) codexMCPServer {
+	if cfg.URL != "" {
+		server := codexMCPServer{URL: cfg.URL}
+		for header, value := range cfg.Headers {
+			if name, ok := envPlaceholder(value); ok {
+				if server.EnvHTTPHeaders == nil {
+					server.EnvHTTPHeaders = make(map[string]string)
+				}
+				server.EnvHTTPHeaders[header] = name
+				continue
+			}
+			if server.HTTPHeaders == nil {
+				server.HTTPHeaders = make(map[string]string)
+			}
+			server.HTTPHeaders[header] = value
+		}
+		return server
+	}
+	return codexMCPServer{Command: cfg.Command, Args: cfg.Args, Env: cfg.Env}
+}
+
+// WriteCodexMCPConfig replaces the [mcp_servers] tables in Codex's config.toml
+// with the enabled MCPs. Pooled MCPs are written as `nc -U <socket>` like for
+// Claude. Everything outside mcp_servers is kept as written, comments included.
+// Names that are not in config.toml keep their existing entry.
+func WriteCodexMCPConfig(enabledNames []string) error {
+	configFile := GetCodexConfigPath()
+
+	var existing string
+	if data, err := os.ReadFile(configFile); err == nil {
+		existing = string(data)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+
+	availableMCPs := GetAvailableMCPs()
+	pool := GetGlobalPool()
+	current := readCodexMCPServers()
+
+	servers := make(map[string]interface{})
+	for _, name := range enabledNames {
+		def, ok := availableMCPs[name]
+		if !ok {
+			if raw, found := current[name]; found {
+				servers[name] = raw
+			}
+			continue
+		}
+		if def.URL != "" && def.Transport == "sse" {
+			log.Printf("[MCP] ⚠️ %s: Codex does not support SSE MCPs, skipping", name)
+			continue
+		}
+		cfg, err := resolveMCPServerConfig(name, def, "", pool)
+		if err != nil {
+			return err
+		}
+		servers[name] = codexServerFromConfig(cfg)
+	}
+
+	var buf bytes.Buffer
+	if len(servers) > 0 {
+		enc := toml.NewEncoder(&buf)
+		enc.Indent = ""
+		if err := enc.Encode(map[string]interface{}{"mcp_servers": servers}); err != nil {
+			return fmt.Errorf("failed to encode mcp_servers: %w", err)
+		}
+	}
+
+	content := replaceTOMLTables(existing, "mcp_servers", buf.String())
+	var check map[string]interface{}
+	if _, err := toml.Decode(content, &check); err != nil {
+		return fmt.Errorf("failed to update %s: %w", configFile, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configFile), 0700); err != nil {
+		return fmt.Errorf("failed to create codex config dir: %w", err)
+	}
+	tmpPath := configFile + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmpPath, configFile); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// replaceTOMLTables drops every table under root from text and appends
+// replacement. Other tables, comments and formatting are left untouched.
+func replaceTOMLTables(text, root, replacement string) string {
+	var b strings.Builder
+	for _, s := range splitTOMLSections(text) {
+		if len(s.path) > 0 && s.path[0] == root {
+			// Comments after the last key belong to the next table
+			_, end := s.bodyRange()
+			for _, line := range s.lines[end:] {
+				b.WriteString(line)
+				b.WriteString("\n")
+			}
+			continue
+		}
+		b.WriteString(s.text())
+	}
+	kept := strings.TrimRight(b.String(), "\n")
+	if replacement == "" {
+		if kept == "" {
+			return ""
+		}
+		return kept + "\n"
+	}
+	if kept == "" {
+		return replacement
+	}
+	return kept + "\n\n" + replacement
+}
@@ -0,0 +1,100 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+const toolMCPsConfig = `[mcps.exa]
+command = "npx"
+args = ["-y", "exa-mcp-server"]
+env = { EXA_API_KEY = "key" }
+
+[mcps.docs]
+url = "https://docs.example.com/mcp"
+headers = { Authorization = "Bearer t" }
+
+[mcps.legacy]
+url = "https://legacy.example.com/sse"
+transport = "sse"
+`
+
+func TestWriteCodexMCPConfig(t *testing.T) {
+	writeTestConfig(t, toolMCPsConfig)
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+
+	existing := `# Codex settings
+model = "o4-mini"
+
+[mcp_servers.handmade]
+command = "my-server"
+
+[mcp_servers.exa]
+command = "old"
+
+# Keep this comment
+[profiles.fast]
+model = "gpt-4.1"
+`
+	configFile := filepath.Join(codexHome, "config.toml")
+	if err := os.WriteFile(configFile, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteCodexMCPConfig([]string{"exa", "docs", "legacy", "handmade"}); err != nil {
+		t.Fatalf("WriteCodexMCPConfig: %v", err)
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"# Codex settings", "# Keep this comment", "[profiles.fast]"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("config lost %q:\n%s", want, content)
+		}
+	}
+
+	var config struct {
+		Model      string                    `toml:"model"`
+		MCPServers map[string]codexMCPServer `toml:"mcp_servers"`
+	}
+	if _, err := toml.Decode(content, &config); err != nil {
+		t.Fatalf("written config does not parse: %v\n%s", err, content)
+	}
+	if config.Model != "o4-mini" {
+		t.Errorf("model = %q, want o4-mini", config.Model)
+	}
+	if exa := config.MCPServers["exa"]; exa.Command != "npx" || exa.Env["EXA_API_KEY"] != "key" {
+		t.Errorf("exa = %+v, want npx with env", exa)
+	}
+	if docs := config.MCPServers["docs"]; docs.URL != "https://docs.example.com/mcp" || docs.HTTPHeaders["Authorization"] != "Bearer t" {
+		t.Errorf("docs = %+v, want url with headers", docs)
+	}
+	if _, ok := config.MCPServers["legacy"]; ok {
+		t.Error("SSE MCP should be skipped for Codex")
+	}
+	if config.MCPServers["handmade"].Command != "my-server" {
+		t.Error("MCP not in config.toml should keep its existing entry")
+	}
+
+	names := GetCodexMCPNames()
+	if strings.Join(names, ",") != "docs,exa,handmade" {
+		t.Errorf("GetCodexMCPNames() = %v", names)
+	}
+
+	// Detaching everything removes the mcp_servers tables
+	if err := WriteCodexMCPConfig(nil); err != nil {
+		t.Fatalf("WriteCodexMCPConfig(nil): %v", err)
+	}
+	data, _ = os.ReadFile(configFile)
+	if strings.Contains(string(data), "mcp_servers") || !strings.Contains(string(data), "[profiles.fast]") {
+		t.Errorf("unexpected config after detaching all:\n%s", data)
+	}
+}
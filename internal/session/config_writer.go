@@ -0,0 +1,612 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlSection is one table of a TOML document as written on disk: the header
+// line (empty for the root table) followed by everything up to the next header.
+type tomlSection struct {
+	path   []string // table path; nil for the root table
+	array  bool     // [[array.of.tables]] sections are always kept verbatim
+	header string   // original header line
+	lines  []string // lines after the header
+}
+
+func (s *tomlSection) key() string {
+	return strings.Join(s.path, "\x00")
+}
+
+// bodyRange returns the [start, end) range of lines holding key/value pairs.
+// Comments before the first key and after the last key are outside the range.
+func (s *tomlSection) bodyRange() (int, int) {
+	start, end := -1, -1
+	for i, line := range s.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		end = i + 1
+	}
+	if start < 0 {
+		return len(s.lines), len(s.lines)
+	}
+	return start, end
+}
+
+// text returns the section as a standalone TOML document
+func (s *tomlSection) text() string {
+	var b strings.Builder
+	if s.header != "" {
+		b.WriteString(s.header)
+		b.WriteString("\n")
+	}
+	for _, line := range s.lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// mergeConfigTOML rewrites oldText so it decodes to the same values as newText
+// while keeping comments and formatting of every table that did not change.
+// Changed tables keep their header and surrounding comments; only the
+// key/value lines are replaced. Tables that only exist in newText are
+// appended unless all of their values are zero.
+func mergeConfigTOML(oldText, newText string) (string, error) {
+	var oldDoc, newDoc map[string]interface{}
+	if _, err := toml.Decode(oldText, &oldDoc); err != nil {
+		return "", fmt.Errorf("failed to parse existing config: %w", err)
+	}
+	if _, err := toml.Decode(newText, &newDoc); err != nil {
+		return "", fmt.Errorf("failed to parse encoded config: %w", err)
+	}
+
+	oldSections := splitTOMLSections(oldText)
+	newSections := splitTOMLSections(newText)
+
+	oldPaths := make(map[string]bool, len(oldSections))
+	for _, s := range oldSections {
+		oldPaths[s.key()] = true
+	}
+
+	// done marks new sections that are already represented in the output,
+	// either verbatim from the old file or by a rewrite
+	done := make(map[string]bool, len(newSections))
+	var out []string
+
+	ensureBlank := func() {
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+	}
+	emitNew := func(s *tomlSection) {
+		table, _ := tomlSubtree(newDoc, s.path).(map[string]interface{})
+		ensureBlank()
+		out = append(out, s.header)
+		out = append(out, filterZeroKeys(s.lines, table, nil)...)
+		done[s.key()] = true
+	}
+
+	// emitNewChildren writes new subtables of path that have no table of their
+	// own in the old file, e.g. env = { ... } inline tables re-encoded as [x.env]
+	emitNewChildren := func(path []string) {
+		for i := range newSections {
+			s := &newSections[i]
+			if done[s.key()] || s.array || !hasPathPrefix(s.path, path) || len(s.path) == len(path) {
+				continue
+			}
+			if hasOldAncestor(s.path, path, oldPaths) || !hasNonZeroDirect(newDoc, s) {
+				continue
+			}
+			emitNew(s)
+		}
+	}
+
+	// Tables with no counterpart anywhere on their path in the old file are
+	// placed after the last old table sharing their prefix (e.g. a new
+	// [mcps.foo] goes after the existing [mcps.*] tables), or at the end
+	anchored := make(map[int][]*tomlSection)
+	var trailing []*tomlSection
+	for i := range newSections {
+		s := &newSections[i]
+		if s.path == nil || s.array || hasOldAncestor(s.path, nil, oldPaths) || !hasNonZeroDirect(newDoc, s) {
+			continue
+		}
+		anchor, best := -1, 0
+		for j := range oldSections {
+			if oldSections[j].array {
+				continue
+			}
+			if n := commonPrefixLen(oldSections[j].path, s.path); n > 0 && n >= best {
+				anchor, best = j, n
+			}
+		}
+		if anchor < 0 {
+			trailing = append(trailing, s)
+		} else {
+			anchored[anchor] = append(anchored[anchor], s)
+		}
+	}
+	emitAnchored := func(list []*tomlSection) {
+		for _, s := range list {
+			if !done[s.key()] {
+				emitNew(s)
+				emitNewChildren(s.path)
+			}
+		}
+	}
+
+	for i := range oldSections {
+		s := &oldSections[i]
+		start, end := s.bodyRange()
+		if s.array {
+			out = append(out, sectionLines(s)...)
+			continue
+		}
+
+		var oldDirect map[string]interface{}
+		if _, err := toml.Decode(s.text(), &oldDirect); err != nil {
+			return "", fmt.Errorf("failed to parse table %q: %w", s.header, err)
+		}
+		oldTable, _ := tomlSubtree(oldDirect, s.path).(map[string]interface{})
+		newTable, exists := tomlSubtree(newDoc, s.path).(map[string]interface{})
+
+		switch {
+		case !exists:
+			// Table removed: drop it but keep trailing comments for the next table
+			emitAnchored(anchored[i])
+		case directKeysEquivalent(oldTable, newTable):
+			if s.header != "" {
+				out = append(out, s.header)
+			}
+			out = append(out, s.lines[:end]...)
+			// Inline tables in the old text already cover these subtables
+			for k, v := range oldTable {
+				if _, ok := v.(map[string]interface{}); ok {
+					markDone(newSections, append(append([]string{}, s.path...), k), done)
+				}
+			}
+			done[s.key()] = true
+			if s.path != nil {
+				emitNewChildren(s.path)
+			}
+			emitAnchored(anchored[i])
+		default:
+			if s.header != "" {
+				out = append(out, s.header)
+			}
+			out = append(out, s.lines[:start]...)
+			for j := range newSections {
+				if newSections[j].key() == s.key() {
+					out = append(out, filterZeroKeys(newSections[j].lines, newTable, oldTable)...)
+					done[s.key()] = true
+				}
+			}
+			if s.path != nil {
+				emitNewChildren(s.path)
+			}
+			emitAnchored(anchored[i])
+		}
+		tail := s.lines[end:]
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			// Don't stack blank lines where a table was removed
+			for len(tail) > 0 && strings.TrimSpace(tail[0]) == "" {
+				tail = tail[1:]
+			}
+		} else if len(tail) > 0 && strings.HasPrefix(strings.TrimSpace(tail[0]), "#") && end > start {
+			// Comments after the last key often describe the next table
+			ensureBlank()
+		}
+		out = append(out, tail...)
+		if len(tail) == 0 && end > start && i+1 < len(oldSections) {
+			ensureBlank()
+		}
+	}
+	emitAnchored(trailing)
+
+	result := strings.Join(trimTrailingBlank(out), "\n") + "\n"
+	return result, nil
+}
+
+// encodeUserConfig encodes config without indentation so encoded tables can
+// be spliced into a hand-edited file
+func encodeUserConfig(config *UserConfig) (string, error) {
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = ""
+	if err := encoder.Encode(config); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// splitTOMLSections splits a TOML document at table headers
+func splitTOMLSections(text string) []tomlSection {
+	sections := []tomlSection{{}}
+	depth := 0 // open brackets of multi-line arrays
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if depth == 0 {
+			if path, array, ok := parseTOMLHeader(line); ok {
+				sections = append(sections, tomlSection{path: path, array: array, header: line})
+				continue
+			}
+		}
+		cur := &sections[len(sections)-1]
+		cur.lines = append(cur.lines, line)
+		depth += bracketDelta(line)
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	return sections
+}
+
+// parseTOMLHeader parses a [table] or [[array]] header line into its key path
+func parseTOMLHeader(line string) ([]string, bool, bool) {
+	trimmed := strings.TrimSpace(stripTOMLComment(line))
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return nil, false, false
+	}
+	array := strings.HasPrefix(trimmed, "[[") && strings.HasSuffix(trimmed, "]]")
+	inner := strings.TrimSuffix(strings.TrimPrefix(trimmed, "["), "]")
+	if array {
+		inner = strings.TrimSuffix(strings.TrimPrefix(inner, "["), "]")
+	}
+
+	var path []string
+	var cur strings.Builder
+	var quote rune
+	for _, r := range inner {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '.':
+			path = append(path, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		case r == ',' || r == '[' || r == ']' || r == '=':
+			// Not a header (e.g. a nested array value)
+			return nil, false, false
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	path = append(path, strings.TrimSpace(cur.String()))
+	for _, p := range path {
+		if p == "" {
+			return nil, false, false
+		}
+	}
+	return path, array, true
+}
+
+// stripTOMLComment removes a trailing # comment outside of strings
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// bracketDelta counts unbalanced [ and ] outside of strings and comments
+func bracketDelta(line string) int {
+	delta := 0
+	var quote rune
+	for _, r := range stripTOMLComment(line) {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[':
+			delta++
+		case r == ']':
+			delta--
+		}
+	}
+	return delta
+}
+
+// sectionLines returns the section's original lines including its header
+func sectionLines(s *tomlSection) []string {
+	if s.header == "" {
+		return s.lines
+	}
+	return append([]string{s.header}, s.lines...)
+}
+
+// filterZeroKeys drops encoder noise: key lines whose new value is a zero
+// value the user did not set in the old table
+func filterZeroKeys(lines []string, table, existing map[string]interface{}) []string {
+	var kept []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if eq := strings.Index(trimmed, "="); eq > 0 {
+			key := strings.Trim(strings.TrimSpace(trimmed[:eq]), `"'`)
+			if _, set := existing[key]; !set {
+				if v, ok := table[key]; ok && isDefaultTOMLValue(key, v) {
+					continue
+				}
+			}
+		}
+		kept = append(kept, line)
+	}
+	return kept
+}
+
+// directKeysEquivalent reports whether the keys written directly in an old
+// table match the new table. Keys only present in the new table are ignored
+// when they hold zero values; subtables written as separate sections in the
+// old file are compared on their own.
+func directKeysEquivalent(old, new map[string]interface{}) bool {
+	for k, v := range old {
+		if !tomlValuesEquivalent(v, new[k]) {
+			return false
+		}
+	}
+	for k, v := range new {
+		if _, ok := old[k]; ok {
+			continue
+		}
+		if _, isTable := v.(map[string]interface{}); isTable {
+			continue
+		}
+		if !isDefaultTOMLValue(k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlValuesEquivalent(old, new interface{}) bool {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap || newIsMap {
+		if len(oldMap) == 0 && len(newMap) == 0 {
+			return true
+		}
+		for k, v := range oldMap {
+			if !tomlValuesEquivalent(v, newMap[k]) {
+				return false
+			}
+		}
+		for k, v := range newMap {
+			if _, ok := oldMap[k]; !ok && !isDefaultTOMLValue(k, v) {
+				return false
+			}
+		}
+		return true
+	}
+	if new == nil {
+		return isZeroTOMLValue(old)
+	}
+	if isZeroTOMLValue(old) && isZeroTOMLValue(new) {
+		return true
+	}
+	return reflect.DeepEqual(old, new)
+}
+
+func isZeroTOMLValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, child := range val {
+			if !isZeroTOMLValue(child) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		return len(val) == 0
+	}
+	return reflect.ValueOf(v).IsZero()
+}
+
+// hasNonZeroDirect reports whether a new section has any non-zero direct value
+func hasNonZeroDirect(doc map[string]interface{}, s *tomlSection) bool {
+	table, ok := tomlSubtree(doc, s.path).(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for k, v := range table {
+		if _, isTable := v.(map[string]interface{}); isTable {
+			continue
+		}
+		if !isDefaultTOMLValue(k, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func tomlSubtree(doc map[string]interface{}, path []string) interface{} {
+	var cur interface{} = doc
+	for _, p := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur, ok = m[p]
+		if !ok {
+			return nil
+		}
+	}
+	return cur
+}
+
+func hasPathPrefix(path, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasOldAncestor reports whether a table between root (exclusive) and path
+// (inclusive) exists in the old file, in which case that table handles path
+func hasOldAncestor(path, root []string, oldPaths map[string]bool) bool {
+	for n := len(root) + 1; n <= len(path); n++ {
+		if oldPaths[strings.Join(path[:n], "\x00")] {
+			return true
+		}
+	}
+	return false
+}
+
+func commonPrefixLen(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func markDone(sections []tomlSection, prefix []string, done map[string]bool) {
+	for i := range sections {
+		if hasPathPrefix(sections[i].path, prefix) {
+			done[sections[i].key()] = true
+		}
+	}
+}
+
+func trimTrailingBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// mergeExistingUserConfig merges config into the file at configPath, keeping
+// comments. Returns false when there is no file or the merge can't be
+// verified, in which case the caller rewrites the whole file.
+func mergeExistingUserConfig(configPath string, config *UserConfig) (string, bool) {
+	existing, err := os.ReadFile(configPath)
+	if err != nil || len(bytes.TrimSpace(existing)) == 0 {
+		return "", false
+	}
+	encoded, err := encodeUserConfig(config)
+	if err != nil {
+		return "", false
+	}
+	merged, err := mergeConfigTOML(string(existing), encoded)
+	if err != nil {
+		return "", false
+	}
+
+	// The merged file must decode to exactly what a full rewrite would
+	var want, got UserConfig
+	if _, err := toml.Decode(encoded, &want); err != nil {
+		return "", false
+	}
+	if _, err := toml.Decode(merged, &got); err != nil {
+		return "", false
+	}
+	if !reflect.DeepEqual(normalizeUserConfig(want), normalizeUserConfig(got)) {
+		return "", false
+	}
+	return merged, true
+}
+
+// normalizeUserConfig treats nil and empty maps/slices as equal so dropped
+// zero-value keys don't fail verification
+func normalizeUserConfig(c UserConfig) map[string]interface{} {
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	if err := encoder.Encode(c); err != nil {
+		return nil
+	}
+	var doc map[string]interface{}
+	if _, err := toml.Decode(buf.String(), &doc); err != nil {
+		return nil
+	}
+	pruneZeroTOMLValues(doc)
+	return doc
+}
+
+func pruneZeroTOMLValues(m map[string]interface{}) {
+	for k, v := range m {
+		if child, ok := v.(map[string]interface{}); ok {
+			pruneZeroTOMLValues(child)
+			if len(child) == 0 {
+				delete(m, k)
+			}
+			continue
+		}
+		if _, isBool := v.(bool); !isBool && isZeroTOMLValue(v) {
+			delete(m, k)
+		}
+	}
+}
+
+// explicitTOMLKeys holds keys of pointer fields in UserConfig. The encoder
+// only writes them when set, so their zero values are meaningful.
+var explicitTOMLKeys = pointerTOMLKeys(reflect.TypeOf(UserConfig{}), map[reflect.Type]bool{})
+
+func pointerTOMLKeys(t reflect.Type, seen map[reflect.Type]bool) map[string]bool {
+	keys := make(map[string]bool)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Map || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return keys
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("toml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() != reflect.Struct {
+			keys[name] = true
+		}
+		for k := range pointerTOMLKeys(field.Type, seen) {
+			keys[k] = true
+		}
+	}
+	return keys
+}
+
+// isDefaultTOMLValue reports whether a key holding v can be omitted
+func isDefaultTOMLValue(key string, v interface{}) bool {
+	if explicitTOMLKeys[key] {
+		return v == nil
+	}
+	return isZeroTOMLValue(v)
+}
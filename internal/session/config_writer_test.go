@@ -0,0 +1,168 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const commentedConfig = `# My agent-deck config
+default_tool = "claude"
+
+# Claude settings
+[claude]
+config_dir = "~/.claude-work"  # work account
+
+# Research MCPs
+[mcps.exa]
+command = "npx"
+args = ["-y", "exa-mcp-server"]
+env = { EXA_API_KEY = "vault:exa" }
+description = "Web search"
+
+[mcps.memory]
+# keeps notes between sessions
+command = "npx"
+args = [
+  "-y",
+  "@modelcontextprotocol/server-memory",
+]
+
+# [mcps.disabled]
+# command = "old"
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	ClearUserConfigCache()
+	t.Cleanup(ClearUserConfigCache)
+
+	dir := filepath.Join(home, ".agent-deck")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, UserConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSaveUserConfig_UnchangedKeepsFile(t *testing.T) {
+	path := writeTestConfig(t, commentedConfig)
+
+	config, err := LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveUserConfig(config); err != nil {
+		t.Fatalf("SaveUserConfig failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != commentedConfig {
+		t.Errorf("unchanged save rewrote the file:\n%s", data)
+	}
+}
+
+func TestSaveUserConfig_PreservesComments(t *testing.T) {
+	path := writeTestConfig(t, commentedConfig)
+
+	config, err := LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcps := make(map[string]MCPDef, len(config.MCPs))
+	for name, def := range config.MCPs {
+		mcps[name] = def
+	}
+	delete(mcps, "memory")
+	mcps["github"] = MCPDef{Command: "gh-mcp", Env: map[string]string{"TOKEN": "vault:gh"}}
+	exa := mcps["exa"]
+	exa.Description = "Exa search"
+	mcps["exa"] = exa
+	updated := *config
+	updated.MCPs = mcps
+
+	if err := SaveUserConfig(&updated); err != nil {
+		t.Fatalf("SaveUserConfig failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	text := string(data)
+
+	for _, want := range []string{
+		"# My agent-deck config",
+		"# Claude settings",
+		`config_dir = "~/.claude-work"  # work account`,
+		"# Research MCPs",
+		"# [mcps.disabled]",
+		"[mcps.github]",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("saved config missing %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"server-memory", "keeps notes", `url = ""`, "[mcp_pool]"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("saved config should not contain %q:\n%s", unwanted, text)
+		}
+	}
+
+	reloaded, err := ReloadUserConfig()
+	if err != nil {
+		t.Fatalf("saved config does not parse: %v\n%s", err, text)
+	}
+	if len(reloaded.MCPs) != 2 || reloaded.MCPs["exa"].Description != "Exa search" ||
+		reloaded.MCPs["exa"].Env["EXA_API_KEY"] != "vault:exa" || reloaded.MCPs["github"].Env["TOKEN"] != "vault:gh" {
+		t.Errorf("reloaded MCPs = %+v", reloaded.MCPs)
+	}
+	if reloaded.Claude.ConfigDir != "~/.claude-work" {
+		t.Errorf("claude.config_dir = %q", reloaded.Claude.ConfigDir)
+	}
+}
+
+func TestSaveUserConfig_ExplicitFalseIsKept(t *testing.T) {
+	writeTestConfig(t, "[preview]\n# show less\n")
+
+	config, err := LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hide := false
+	updated := *config
+	updated.Preview.ShowOutput = &hide
+	if err := SaveUserConfig(&updated); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := ReloadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Preview.ShowOutput == nil || *reloaded.Preview.ShowOutput {
+		t.Errorf("preview.show_output = %v, want explicit false", reloaded.Preview.ShowOutput)
+	}
+}
+
+func TestParseTOMLHeader(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+		ok   bool
+	}{
+		{"[mcps.exa]", "mcps/exa", true},
+		{`  [mcps."my server"]  # comment`, "mcps/my server", true},
+		{"[[tools]]", "tools", true},
+		{`["a", "b"],`, "", false},
+		{"key = 1", "", false},
+	}
+	for _, tt := range tests {
+		path, _, ok := parseTOMLHeader(tt.line)
+		if ok != tt.ok || strings.Join(path, "/") != tt.want {
+			t.Errorf("parseTOMLHeader(%q) = %v, %v; want %q, %v", tt.line, path, ok, tt.want, tt.ok)
+		}
+	}
+}
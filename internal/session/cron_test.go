@@ -0,0 +1,70 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@fortnightly",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	loc := time.UTC
+	// Wednesday
+	from := time.Date(2026, 1, 14, 10, 17, 30, 0, loc)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 14, 10, 18, 0, 0, loc)},
+		{"0 3 * * *", time.Date(2026, 1, 15, 3, 0, 0, 0, loc)},
+		{"@hourly", time.Date(2026, 1, 14, 11, 0, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2026, 1, 14, 10, 30, 0, 0, loc)},
+		{"5/20 * * * *", time.Date(2026, 1, 14, 10, 25, 0, 0, loc)},
+		{"0 9-17 * * mon-fri", time.Date(2026, 1, 14, 11, 0, 0, 0, loc)},
+		{"0 9 * * sat,sun", time.Date(2026, 1, 17, 9, 0, 0, 0, loc)},
+		{"0 9 * * 7", time.Date(2026, 1, 18, 9, 0, 0, 0, loc)},
+		{"0 0 1 * *", time.Date(2026, 2, 1, 0, 0, 0, 0, loc)},
+		{"0 0 1 jun *", time.Date(2026, 6, 1, 0, 0, 0, 0, loc)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, loc)},
+		// Both day fields restricted: either matches (the 20th or a Friday)
+		{"0 0 20 * fri", time.Date(2026, 1, 16, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%v) = %v, want %v", tt.expr, from, got, tt.want)
+		}
+	}
+}
+
+func TestCronSchedule_NextNever(t *testing.T) {
+	c, err := ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron() error = %v", err)
+	}
+	if got := c.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %v, want zero for a date that never exists", got)
+	}
+}
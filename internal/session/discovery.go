@@ -77,7 +77,7 @@ func GroupByProject(instances []*Instance) map[string][]*Instance {
 }
 
 // FilterByQuery filters sessions by title, project path, tool, or status
-// Supports status filters: "waiting", "running", "idle", "error"
+// Supports status filters: "waiting", "needs-approval", "budget-exceeded", "running", "idle", "error"
 func FilterByQuery(instances []*Instance, query string) []*Instance {
 	if query == "" {
 		return instances
@@ -87,10 +87,12 @@ func FilterByQuery(instances []*Instance, query string) []*Instance {
 
 	// Check for status filters
 	statusFilters := map[string]Status{
-		"waiting": StatusWaiting,
-		"running": StatusRunning,
-		"idle":    StatusIdle,
-		"error":   StatusError,
+		"waiting":         StatusWaiting,
+		"needs-approval":  StatusNeedsApproval,
+		"budget-exceeded": StatusBudgetExceeded,
+		"running":         StatusRunning,
+		"idle":            StatusIdle,
+		"error":           StatusError,
 	}
 
 	// If query matches a status filter exactly, filter by status
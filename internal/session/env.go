@@ -15,12 +15,14 @@ import (
 //  2. [shell].init_script (for direnv, nvm, etc.)
 //  3. Tool-specific env_file ([claude].env_file, [gemini].env_file, [tools.X].env_file)
 //  4. Inline env vars from [tools.X].env (highest priority)
+//
+// When HTTP MCPs attached to the session take headers from the secrets vault,
+// the resolved values are then exported as well (see MCPHeaderSecretEnv),
+// fetched from `agent-deck mcp env` so they appear neither on the command
+// line nor on disk.
 func (i *Instance) buildEnvSourceCommand() string {
 	var sources []string
-	config, _ := LoadUserConfig()
-	if config == nil {
-		return ""
-	}
+	config := i.effectiveConfig()
 
 	ignoreMissing := config.Shell.GetIgnoreMissingEnvFiles()
 
@@ -54,6 +56,10 @@ func (i *Instance) buildEnvSourceCommand() string {
 		sources = append(sources, inlineEnv)
 	}
 
+	if secrets := mcpSecretEnvCommand(i.ProjectPath, i.attachedMCPNames()); secrets != "" {
+		sources = append(sources, secrets)
+	}
+
 	if len(sources) == 0 {
 		return ""
 	}
@@ -142,10 +148,7 @@ func (i *Instance) getToolInlineEnv() string {
 
 // getToolEnvFile returns the env_file setting for the current tool.
 func (i *Instance) getToolEnvFile() string {
-	config, _ := LoadUserConfig()
-	if config == nil {
-		return ""
-	}
+	config := i.effectiveConfig()
 
 	switch i.Tool {
 	case "claude":
@@ -160,3 +163,46 @@ func (i *Instance) getToolEnvFile() string {
 	}
 	return ""
 }
+
+// mcpSecretEnvCommand returns a shell command that exports the vault secrets
+// in the headers of the named HTTP MCPs for projectPath, or "" when none of
+// them needs any
+func mcpSecretEnvCommand(projectPath string, names []string) string {
+	names = mcpsWithHeaderSecrets(names)
+	if len(names) == 0 {
+		return ""
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(`eval "$(%s mcp env --project %s --mcps %s)"`,
+		shellQuote(exe), shellQuote(projectPath), shellQuote(strings.Join(names, ",")))
+}
+
+// attachedMCPNames returns the MCPs written into this session's tool config
+func (i *Instance) attachedMCPNames() []string {
+	if info := i.GetMCPInfo(); info != nil {
+		return info.AllNames()
+	}
+	return nil
+}
+
+// shellQuote single-quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
+}
+
+// ShellExports renders env as export statements, one per line, sorted by name
+func ShellExports(env map[string]string) string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString("export " + name + "=" + shellQuote(env[name]) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,364 @@
+package session
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ToolSupportsFork returns true if conversations of the given tool can be forked
+func ToolSupportsFork(tool string) bool {
+	switch tool {
+	case "claude", "gemini", "codex", "opencode":
+		return true
+	}
+	return false
+}
+
+// ToolForkMethod describes how a tool's conversations are forked, or why
+// they can't be
+func ToolForkMethod(tool string) string {
+	switch tool {
+	case "claude":
+		return "native (claude --resume --fork-session)"
+	case "gemini":
+		return "copies the chat file to a new session and resumes it"
+	case "codex":
+		return "copies the rollout file to a new session and resumes it"
+	case "opencode":
+		return "copies the session and its messages to a new session ID and resumes it"
+	case "", "shell":
+		return "not supported: shell sessions have no conversation"
+	}
+	return fmt.Sprintf("not supported: %s sessions have no known conversation format", tool)
+}
+
+// ForkUnavailableReason returns why this session can't be forked right now,
+// or "" if it can. Sessions of other tools (e.g. a custom Claude wrapper)
+// fork like Claude once a Claude session ID is known.
+func (i *Instance) ForkUnavailableReason() string {
+	switch i.Tool {
+	case "gemini":
+		if i.GeminiSessionID == "" {
+			return "no Gemini session detected yet"
+		}
+		return ""
+	case "codex":
+		if i.CodexSessionID == "" {
+			return "no Codex session detected yet"
+		}
+		return ""
+	case "opencode":
+		if i.OpenCodeSessionID == "" {
+			return "no OpenCode session detected yet"
+		}
+		return ""
+	case "claude":
+	default:
+		if i.ClaudeSessionID == "" {
+			return strings.TrimPrefix(ToolForkMethod(i.Tool), "not supported: ")
+		}
+	}
+
+	// Claude sessions can fork if session ID is recent
+	if i.ClaudeSessionID == "" || time.Since(i.ClaudeDetectedAt) >= 5*time.Minute {
+		return "no active Claude session"
+	}
+	return ""
+}
+
+// forkInto creates the forked Instance for this session's tool, running in
+// workDir. Claude forks natively at start; Gemini, Codex and OpenCode
+// conversations are duplicated under a new session ID that the fork resumes.
+func (i *Instance) forkInto(newTitle, newGroupPath, workDir string, opts *ClaudeOptions) (*Instance, string, error) {
+	switch i.Tool {
+	case "gemini":
+		sessionID, err := forkGeminiChat(i.GeminiSessionID, workDir)
+		if err != nil {
+			return nil, "", err
+		}
+		forked := i.newForkedInstance(newTitle, newGroupPath, workDir, "gemini", nil)
+		forked.Tool = "gemini"
+		forked.GeminiSessionID = sessionID
+		forked.GeminiDetectedAt = time.Now()
+		forked.GeminiYoloMode = i.GeminiYoloMode
+		forked.GeminiModel = i.GeminiModel
+		return forked, forked.Command, nil
+
+	case "codex":
+		sessionID, err := forkCodexRollout(i.CodexSessionID)
+		if err != nil {
+			return nil, "", err
+		}
+		forked := i.newForkedInstance(newTitle, newGroupPath, workDir, "codex", nil)
+		forked.Tool = "codex"
+		forked.CodexSessionID = sessionID
+		forked.CodexDetectedAt = time.Now()
+		return forked, forked.Command, nil
+
+	case "opencode":
+		sessionID, err := forkOpenCodeSession(i.OpenCodeSessionID, workDir)
+		if err != nil {
+			return nil, "", err
+		}
+		forked := i.newForkedInstance(newTitle, newGroupPath, workDir, "opencode", nil)
+		forked.Tool = "opencode"
+		forked.OpenCodeSessionID = sessionID
+		forked.OpenCodeDetectedAt = time.Now()
+		return forked, forked.Command, nil
+	}
+
+	// Claude resolves --resume against the project directory of the working
+	// directory, so the conversation has to exist there too
+	if workDir != i.ProjectPath {
+		if err := copyClaudeSessionToProject(i.ClaudeSessionID, workDir); err != nil {
+			log.Printf("[FORK] Warning: %v", err)
+		}
+	}
+
+	cmd := i.forkCommand(workDir, opts)
+	return i.newForkedInstance(newTitle, newGroupPath, workDir, cmd, opts), cmd, nil
+}
+
+// forkGeminiChat copies a Gemini chat file into workDir's chats directory
+// under a new session ID and returns that ID
+func forkGeminiChat(sessionID, workDir string) (string, error) {
+	src := findGeminiSessionInAllProjects(sessionID)
+	if src == "" {
+		return "", fmt.Errorf("gemini chat file for session %s not found", sessionID)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read gemini chat: %w", err)
+	}
+
+	var chat map[string]json.RawMessage
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return "", fmt.Errorf("failed to parse gemini chat: %w", err)
+	}
+	newID := newSessionUUID()
+	chat["sessionId"], _ = json.Marshal(newID)
+	if _, ok := chat["projectHash"]; ok {
+		chat["projectHash"], _ = json.Marshal(HashProjectPath(workDir))
+	}
+	out, err := json.MarshalIndent(chat, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode gemini chat: %w", err)
+	}
+
+	// session-<start time>-<first 8 chars of the session ID>.json
+	name := filepath.Base(src)
+	if len(sessionID) >= 8 && strings.HasSuffix(name, sessionID[:8]+".json") {
+		name = strings.TrimSuffix(name, sessionID[:8]+".json")
+	} else {
+		name = "session-" + time.Now().Format("2006-01-02T15-04") + "-"
+	}
+	name += newID[:8] + ".json"
+
+	dstDir := GetGeminiSessionsDir(workDir)
+	if dstDir == "" {
+		return "", fmt.Errorf("cannot determine gemini chats directory for %s", workDir)
+	}
+	if err := os.MkdirAll(dstDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create gemini chats dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, name), out, 0600); err != nil {
+		return "", fmt.Errorf("failed to write gemini chat: %w", err)
+	}
+	return newID, nil
+}
+
+// findCodexRollout returns the Codex rollout file for a session ID, or ""
+// Rollouts live in $CODEX_HOME/sessions/YYYY/MM/DD/rollout-<time>-<uuid>.jsonl
+func findCodexRollout(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	var found string
+	_ = filepath.WalkDir(filepath.Join(GetCodexConfigDir(), "sessions"), func(path string, d os.DirEntry, err error) error {
+		if err != nil || found != "" {
+			return nil
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), sessionID+".jsonl") {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// forkCodexRollout copies a Codex rollout file under a new session ID, next
+// to the original, and returns that ID
+func forkCodexRollout(sessionID string) (string, error) {
+	src := findCodexRollout(sessionID)
+	if src == "" {
+		return "", fmt.Errorf("codex rollout file for session %s not found", sessionID)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read codex rollout: %w", err)
+	}
+
+	// The session_meta header (the first line) carries the session ID
+	newID := newSessionUUID()
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if !bytes.Contains(header, []byte(sessionID)) {
+		return "", fmt.Errorf("codex rollout %s has no session header", filepath.Base(src))
+	}
+	data = bytes.Replace(data, []byte(sessionID), []byte(newID), 1)
+
+	dst := filepath.Join(filepath.Dir(src), strings.TrimSuffix(filepath.Base(src), sessionID+".jsonl")+newID+".jsonl")
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write codex rollout: %w", err)
+	}
+
+	// Keep the original's mtime so session detection doesn't mistake the copy
+	// for the most recently active conversation
+	if info, err := os.Stat(src); err == nil {
+		_ = os.Chtimes(dst, info.ModTime(), info.ModTime())
+	}
+	return newID, nil
+}
+
+// getOpenCodeStorageDir returns the directory OpenCode keeps sessions in:
+// $XDG_DATA_HOME/opencode/storage, ~/.local/share/opencode/storage by default
+func getOpenCodeStorageDir() string {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, _ := os.UserHomeDir()
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataDir, "opencode", "storage")
+}
+
+// forkOpenCodeSession copies an OpenCode session, its messages and their
+// parts under new IDs and returns the new session ID. OpenCode's storage is
+// split across files that reference each other by ID:
+//
+//	session/<project>/<session>.json
+//	message/<session>/<message>.json
+//	part/<message>/<part>.json
+//
+// Message IDs are global (parts are filed under them), so they're renamed
+// too, and every reference to a renamed ID is rewritten in the copies.
+func forkOpenCodeSession(sessionID, workDir string) (string, error) {
+	storage := getOpenCodeStorageDir()
+	matches, _ := filepath.Glob(filepath.Join(storage, "session", "*", sessionID+".json"))
+	if len(matches) == 0 {
+		return "", fmt.Errorf("opencode session %s not found in %s", sessionID, storage)
+	}
+	src := matches[0]
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read opencode session: %w", err)
+	}
+
+	// Message IDs sort in creation order; new ones are issued in the same
+	// order so OpenCode replays the conversation as it was
+	messageDir := filepath.Join(storage, "message", sessionID)
+	messageFiles, _ := filepath.Glob(filepath.Join(messageDir, "msg_*.json"))
+	sort.Strings(messageFiles)
+
+	now := time.Now().UnixMilli()
+	newID := newOpenCodeID("ses", now, 1, true)
+	renames := []string{sessionID, newID}
+	messageIDs := make([]string, len(messageFiles))
+	newMessageIDs := make([]string, len(messageFiles))
+	for n, path := range messageFiles {
+		messageIDs[n] = strings.TrimSuffix(filepath.Base(path), ".json")
+		newMessageIDs[n] = newOpenCodeID("msg", now, uint64(n+1), false)
+		renames = append(renames, messageIDs[n], newMessageIDs[n])
+	}
+	rename := strings.NewReplacer(renames...)
+
+	copyFile := func(src, dst string) error {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return fmt.Errorf("failed to read opencode %s: %w", filepath.Base(filepath.Dir(src)), err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+			return fmt.Errorf("failed to create opencode storage dir: %w", err)
+		}
+		if err := os.WriteFile(dst, []byte(rename.Replace(string(data))), 0600); err != nil {
+			return fmt.Errorf("failed to write opencode %s: %w", filepath.Base(filepath.Dir(dst)), err)
+		}
+		return nil
+	}
+
+	for n, path := range messageFiles {
+		if err := copyFile(path, filepath.Join(storage, "message", newID, newMessageIDs[n]+".json")); err != nil {
+			return "", err
+		}
+		parts, _ := filepath.Glob(filepath.Join(storage, "part", messageIDs[n], "*.json"))
+		for _, part := range parts {
+			if err := copyFile(part, filepath.Join(storage, "part", newMessageIDs[n], filepath.Base(part))); err != nil {
+				return "", err
+			}
+		}
+	}
+	// Per-session extras, when the OpenCode version writes them
+	for _, kind := range []string{"session_diff", "todo"} {
+		extra := filepath.Join(storage, kind, sessionID+".json")
+		if _, err := os.Stat(extra); err == nil {
+			if err := copyFile(extra, filepath.Join(storage, kind, newID+".json")); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	// The session record goes last, so OpenCode never lists a partial copy
+	var session map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(rename.Replace(string(data))), &session); err != nil {
+		return "", fmt.Errorf("failed to parse opencode session: %w", err)
+	}
+	session["directory"], _ = json.Marshal(workDir)
+	// A share link belongs to the original conversation
+	delete(session, "share")
+	out, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode opencode session: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(src), newID+".json"), out, 0600); err != nil {
+		return "", fmt.Errorf("failed to write opencode session: %w", err)
+	}
+	return newID, nil
+}
+
+// newOpenCodeID returns an ID in OpenCode's format: a prefix, 6 bytes of hex
+// encoding the time in milliseconds and a counter, and 14 random base62
+// characters. Session IDs count down so the newest sorts first.
+func newOpenCodeID(prefix string, millis int64, counter uint64, descending bool) string {
+	stamp := uint64(millis)*0x1000 + counter
+	if descending {
+		stamp = ^stamp
+	}
+	const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	random := make([]byte, 14)
+	if _, err := rand.Read(random); err != nil {
+		copy(random, fmt.Sprintf("%014d", time.Now().UnixNano()))
+	}
+	for n := range random {
+		random[n] = base62[int(random[n])%len(base62)]
+	}
+	return fmt.Sprintf("%s_%012x%s", prefix, stamp&0xffffffffffff, random)
+}
+
+// newSessionUUID returns a random (version 4) UUID for a forked conversation
+func newSessionUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// Fallback to timestamp-based bytes
+		copy(b, fmt.Sprintf("%016x", time.Now().UnixNano()))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,249 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestForkUnavailableReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		inst     *Instance
+		wantFork bool
+		contains string
+	}{
+		{"claude fresh", &Instance{Tool: "claude", ClaudeSessionID: "abc", ClaudeDetectedAt: time.Now()}, true, ""},
+		{"claude stale", &Instance{Tool: "claude", ClaudeSessionID: "abc", ClaudeDetectedAt: time.Now().Add(-time.Hour)}, false, "no active Claude session"},
+		{"gemini", &Instance{Tool: "gemini", GeminiSessionID: "abc"}, true, ""},
+		{"gemini no id", &Instance{Tool: "gemini"}, false, "no Gemini session"},
+		{"codex", &Instance{Tool: "codex", CodexSessionID: "abc"}, true, ""},
+		{"codex no id", &Instance{Tool: "codex"}, false, "no Codex session"},
+		{"opencode", &Instance{Tool: "opencode", OpenCodeSessionID: "ses_1"}, true, ""},
+		{"opencode no id", &Instance{Tool: "opencode"}, false, "no OpenCode session"},
+		{"shell", &Instance{Tool: "shell"}, false, "no conversation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := tt.inst.ForkUnavailableReason()
+			if tt.inst.CanFork() != tt.wantFork {
+				t.Errorf("CanFork() = %v, want %v (reason %q)", !tt.wantFork, tt.wantFork, reason)
+			}
+			if !strings.Contains(reason, tt.contains) {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.contains)
+			}
+		})
+	}
+}
+
+func TestCreateForkedInstance_Gemini(t *testing.T) {
+	geminiConfigDirOverride = t.TempDir()
+	defer func() { geminiConfigDirOverride = "" }()
+
+	projectPath := t.TempDir()
+	sessionID := "4d8fcb4d-1111-2222-3333-444455556666"
+	chatsDir := GetGeminiSessionsDir(projectPath)
+	if err := os.MkdirAll(chatsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	chat := `{"sessionId":"` + sessionID + `","projectHash":"` + HashProjectPath(projectPath) + `",` +
+		`"startTime":"2025-12-26T15:09:00.000Z","lastUpdated":"2025-12-26T15:20:00.000Z",` +
+		`"messages":[{"type":"user","content":"hello"}]}`
+	if err := os.WriteFile(filepath.Join(chatsDir, "session-2025-12-26T15-09-4d8fcb4d.json"), []byte(chat), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := NewInstanceWithTool("gem", projectPath, "gemini")
+	parent.GeminiSessionID = sessionID
+	forked, _, err := parent.CreateForkedInstanceWithOptions("gem (fork)", "", nil)
+	if err != nil {
+		t.Fatalf("CreateForkedInstanceWithOptions() failed: %v", err)
+	}
+
+	if forked.Tool != "gemini" || forked.Command != "gemini" {
+		t.Errorf("forked Tool/Command = %q/%q, want gemini/gemini", forked.Tool, forked.Command)
+	}
+	if forked.GeminiSessionID == "" || forked.GeminiSessionID == sessionID {
+		t.Fatalf("forked GeminiSessionID = %q, want a new ID", forked.GeminiSessionID)
+	}
+	if forked.ForkedFromID != parent.ID {
+		t.Errorf("ForkedFromID = %q, want %q", forked.ForkedFromID, parent.ID)
+	}
+
+	sessions, err := ListGeminiSessions(projectPath)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("ListGeminiSessions() = %d sessions (%v), want original and fork", len(sessions), err)
+	}
+	copied := filepath.Join(chatsDir, "session-2025-12-26T15-09-"+forked.GeminiSessionID[:8]+".json")
+	info, err := parseGeminiSessionFile(copied)
+	if err != nil {
+		t.Fatalf("forked chat not written: %v", err)
+	}
+	if info.SessionID != forked.GeminiSessionID || info.MessageCount != 1 {
+		t.Errorf("forked chat = %+v, want session %s with 1 message", info, forked.GeminiSessionID)
+	}
+}
+
+func TestCreateForkedInstance_Codex(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+
+	sessionID := "0199a213-81c0-7800-8aa1-bbab2a035a53"
+	dayDir := filepath.Join(codexHome, "sessions", "2025", "10", "01")
+	if err := os.MkdirAll(dayDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dayDir, "rollout-2025-10-01T10-00-00-"+sessionID+".jsonl")
+	rollout := `{"timestamp":"2025-10-01T10:00:00Z","type":"session_meta","payload":{"id":"` + sessionID + `","cwd":"/tmp"}}` + "\n" +
+		`{"timestamp":"2025-10-01T10:00:05Z","type":"response_item","payload":{"type":"message","role":"user"}}` + "\n"
+	if err := os.WriteFile(src, []byte(rollout), 0600); err != nil {
+		t.Fatal(err)
+	}
+	srcTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(src, srcTime, srcTime); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := NewInstanceWithTool("cx", t.TempDir(), "codex")
+	parent.CodexSessionID = sessionID
+	forked, _, err := parent.CreateForkedInstanceWithOptions("cx (fork)", "", nil)
+	if err != nil {
+		t.Fatalf("CreateForkedInstanceWithOptions() failed: %v", err)
+	}
+	if forked.Tool != "codex" || forked.CodexSessionID == "" || forked.CodexSessionID == sessionID {
+		t.Fatalf("forked Tool/CodexSessionID = %q/%q, want codex with a new ID", forked.Tool, forked.CodexSessionID)
+	}
+	if !strings.Contains(forked.buildCodexCommand(forked.Command), "codex resume "+forked.CodexSessionID) {
+		t.Errorf("fork should resume its copied rollout, got: %s", forked.buildCodexCommand(forked.Command))
+	}
+
+	dst := findCodexRollout(forked.CodexSessionID)
+	if dst == "" || filepath.Dir(dst) != dayDir {
+		t.Fatalf("forked rollout = %q, want it next to the original", dst)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header struct {
+		Payload struct {
+			ID string `json:"id"`
+		} `json:"payload"`
+	}
+	first, _, _ := strings.Cut(string(data), "\n")
+	if err := json.Unmarshal([]byte(first), &header); err != nil || header.Payload.ID != forked.CodexSessionID {
+		t.Errorf("forked session_meta id = %q (%v), want %q", header.Payload.ID, err, forked.CodexSessionID)
+	}
+	if strings.Count(string(data), "\n") != 2 {
+		t.Errorf("forked rollout should keep both lines, got:\n%s", data)
+	}
+	if info, err := os.Stat(dst); err != nil || !info.ModTime().Equal(srcTime) {
+		t.Errorf("forked rollout should keep the original's mtime")
+	}
+}
+
+func TestCreateForkedInstance_OpenCode(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	storage := getOpenCodeStorageDir()
+
+	sessionID := "ses_6a1f00000000AAAAAAAAAAAAAA"
+	messages := []string{"msg_95e000000001BBBBBBBBBBBBBB", "msg_95e000000002CCCCCCCCCCCCCC"}
+	files := map[string]string{
+		"session/proj1/" + sessionID + ".json":               `{"id":"` + sessionID + `","projectID":"proj1","directory":"/old","title":"Fix tests","share":{"url":"https://example.com/s"}}`,
+		"message/" + sessionID + "/" + messages[0] + ".json": `{"id":"` + messages[0] + `","sessionID":"` + sessionID + `","role":"user"}`,
+		"message/" + sessionID + "/" + messages[1] + ".json": `{"id":"` + messages[1] + `","sessionID":"` + sessionID + `","role":"assistant","parentID":"` + messages[0] + `"}`,
+		"part/" + messages[0] + "/prt_1.json":                `{"id":"prt_1","sessionID":"` + sessionID + `","messageID":"` + messages[0] + `","type":"text","text":"hello"}`,
+		"part/" + messages[1] + "/prt_2.json":                `{"id":"prt_2","sessionID":"` + sessionID + `","messageID":"` + messages[1] + `","type":"text","text":"hi"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(storage, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	workDir := t.TempDir()
+	parent := NewInstanceWithTool("oc", workDir, "opencode")
+	parent.OpenCodeSessionID = sessionID
+	forked, _, err := parent.CreateForkedInstanceWithOptions("oc (fork)", "", nil)
+	if err != nil {
+		t.Fatalf("CreateForkedInstanceWithOptions() failed: %v", err)
+	}
+	newID := forked.OpenCodeSessionID
+	if forked.Tool != "opencode" || !strings.HasPrefix(newID, "ses_") || newID == sessionID || len(newID) != len(sessionID) {
+		t.Fatalf("forked Tool/OpenCodeSessionID = %q/%q, want opencode with a new ses_ ID", forked.Tool, newID)
+	}
+	if !strings.Contains(forked.buildOpenCodeCommand(forked.Command), "opencode -s "+newID) {
+		t.Errorf("fork should resume its copied session, got: %s", forked.buildOpenCodeCommand(forked.Command))
+	}
+
+	var session map[string]interface{}
+	data, err := os.ReadFile(filepath.Join(storage, "session", "proj1", newID+".json"))
+	if err != nil {
+		t.Fatalf("forked session not written: %v", err)
+	}
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatal(err)
+	}
+	if session["id"] != newID || session["directory"] != workDir || session["title"] != "Fix tests" || session["share"] != nil {
+		t.Errorf("forked session = %v, want new id, fork directory, same title and no share link", session)
+	}
+
+	copied, _ := filepath.Glob(filepath.Join(storage, "message", newID, "*.json"))
+	if len(copied) != 2 {
+		t.Fatalf("forked messages = %v, want 2", copied)
+	}
+	var ids []string
+	for _, path := range copied {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := string(data)
+		id := strings.TrimSuffix(filepath.Base(path), ".json")
+		ids = append(ids, id)
+		if id == messages[0] || id == messages[1] || strings.Contains(msg, sessionID) || !strings.Contains(msg, newID) {
+			t.Errorf("forked message %s should be renamed into the new session: %s", id, msg)
+		}
+		parts, _ := filepath.Glob(filepath.Join(storage, "part", id, "*.json"))
+		if len(parts) != 1 {
+			t.Errorf("forked message %s has %d parts, want 1", id, len(parts))
+		}
+	}
+	second, _ := os.ReadFile(copied[1])
+	if !strings.Contains(string(second), `"parentID":"`+ids[0]+`"`) {
+		t.Errorf("forked reply should point at the forked prompt: %s", second)
+	}
+
+	// The original is untouched
+	if parts, _ := filepath.Glob(filepath.Join(storage, "part", messages[0], "*.json")); len(parts) != 1 {
+		t.Errorf("original parts = %v, want 1", parts)
+	}
+}
+
+func TestCreateForkedInstance_Unsupported(t *testing.T) {
+	inst := NewInstanceWithTool("sh", "/tmp", "shell")
+	_, _, err := inst.CreateForkedInstanceWithOptions("sh (fork)", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "no conversation") {
+		t.Errorf("expected shell capability error, got %v", err)
+	}
+}
+
+func TestForkWithOptions_RejectsToolsWithTheirOwnFork(t *testing.T) {
+	for _, inst := range []*Instance{
+		{Tool: "gemini", GeminiSessionID: "abc"},
+		{Tool: "codex", CodexSessionID: "abc"},
+		{Tool: "opencode", OpenCodeSessionID: "ses_abc"},
+	} {
+		cmd, err := inst.ForkWithOptions("fork", "", nil)
+		if err == nil || !strings.Contains(err.Error(), "not a Claude session") {
+			t.Errorf("ForkWithOptions(%s) = %q, %v; want a not-Claude error", inst.Tool, cmd, err)
+		}
+	}
+}
@@ -175,6 +175,51 @@ func findGeminiSessionInAllProjects(sessionID string) string {
 	return bestPath
 }
 
+// geminiSessionMessages is a Gemini session file with the usage of its messages
+type geminiSessionMessages struct {
+	SessionID   string          `json:"sessionId"`
+	StartTime   string          `json:"startTime"`
+	LastUpdated string          `json:"lastUpdated"`
+	Messages    []geminiMessage `json:"messages"`
+}
+
+// geminiMessage is one message of a Gemini session file
+type geminiMessage struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Model     string    `json:"model,omitempty"`
+	Tokens    struct {
+		Input  int `json:"input"`
+		Output int `json:"output"`
+	} `json:"tokens"`
+	ToolCalls []struct {
+		Name string `json:"name"`
+	} `json:"toolCalls,omitempty"`
+}
+
+// cost returns the estimated cost of the message at its model's rate
+func (m *geminiMessage) cost() float64 {
+	model := m.Model
+	if model == "" {
+		model = "default"
+	}
+	return (&GeminiSessionAnalytics{InputTokens: m.Tokens.Input, OutputTokens: m.Tokens.Output}).CalculateCost(model)
+}
+
+// readGeminiSessionMessages reads the messages of a Gemini session file
+func readGeminiSessionMessages(filePath string) (*geminiSessionMessages, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	var session geminiSessionMessages
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session for analytics: %w", err)
+	}
+	return &session, nil
+}
+
 // UpdateGeminiAnalyticsFromDisk updates the analytics struct from the session file on disk.
 // Uses mtime caching to skip re-parsing unchanged files (important for 40MB+ session files).
 func UpdateGeminiAnalyticsFromDisk(projectPath, sessionID string, analytics *GeminiSessionAnalytics) error {
@@ -207,27 +252,9 @@ func UpdateGeminiAnalyticsFromDisk(projectPath, sessionID string, analytics *Gem
 		return nil
 	}
 
-	data, err := os.ReadFile(filePath)
+	session, err := readGeminiSessionMessages(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to read session file: %w", err)
-	}
-
-	var session struct {
-		SessionID   string `json:"sessionId"`
-		StartTime   string `json:"startTime"`
-		LastUpdated string `json:"lastUpdated"`
-		Messages    []struct {
-			Type   string `json:"type"`
-			Model  string `json:"model,omitempty"`
-			Tokens struct {
-				Input  int `json:"input"`
-				Output int `json:"output"`
-			} `json:"tokens"`
-		} `json:"messages"`
-	}
-
-	if err := json.Unmarshal(data, &session); err != nil {
-		return fmt.Errorf("failed to parse session for analytics: %w", err)
+		return err
 	}
 
 	// Parse timestamps
@@ -251,12 +278,21 @@ func UpdateGeminiAnalyticsFromDisk(projectPath, sessionID string, analytics *Gem
 	analytics.OutputTokens = 0
 	analytics.TotalTurns = 0
 	analytics.Model = ""
+	analytics.Activity = nil
 	for _, msg := range session.Messages {
 		if msg.Type == "gemini" {
 			analytics.InputTokens += msg.Tokens.Input
 			analytics.OutputTokens += msg.Tokens.Output
 			analytics.TotalTurns++
 
+			if !msg.Timestamp.IsZero() {
+				analytics.Activity = append(analytics.Activity, UsagePoint{
+					Time:   msg.Timestamp,
+					Tokens: msg.Tokens.Input + msg.Tokens.Output,
+					Cost:   msg.cost(),
+				})
+			}
+
 			// For Gemini, the input tokens of the last message represent the total context size
 			// including history and current prompt.
 			analytics.CurrentContextTokens = msg.Tokens.Input
@@ -25,6 +25,9 @@ type GeminiSessionAnalytics struct {
 	// Model detected from session file messages
 	Model string `json:"model,omitempty"`
 
+	// Usage of each agent message, for activity over time
+	Activity []UsagePoint `json:"-"`
+
 	// In-memory cache: last file modification time (skip re-parse if unchanged)
 	LastFileModTime time.Time `json:"-"`
 }
@@ -63,3 +66,16 @@ func (a *GeminiSessionAnalytics) CalculateCost(model string) float64 {
 
 	return inputM*pricing.Input + outputM*pricing.Output
 }
+
+// cost returns EstimatedCost, or an estimate at the detected model's
+// pricing when none was recorded
+func (a *GeminiSessionAnalytics) cost() float64 {
+	if a.EstimatedCost > 0 {
+		return a.EstimatedCost
+	}
+	model := a.Model
+	if model == "" {
+		model = "default"
+	}
+	return a.CalculateCost(model)
+}
@@ -2,7 +2,6 @@ package session
 
 import (
 	"bufio"
-	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
@@ -16,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/tmux"
 )
 
@@ -28,6 +28,12 @@ const (
 	StatusIdle     Status = "idle"
 	StatusError    Status = "error"
 	StatusStarting Status = "starting" // Session is being created (tmux initializing)
+
+	// StatusNeedsApproval: waiting on a tool permission dialog (see approval.go)
+	StatusNeedsApproval Status = "needs-approval"
+
+	// StatusBudgetExceeded: crossed a hard budget limit (see budget.go)
+	StatusBudgetExceeded Status = "budget-exceeded"
 )
 
 // Instance represents a single agent/shell session
@@ -39,10 +45,17 @@ type Instance struct {
 	ParentSessionID   string `json:"parent_session_id,omitempty"`    // Links to parent session (makes this a sub-session)
 	ParentProjectPath string `json:"parent_project_path,omitempty"` // Parent's project path (for --add-dir access)
 
+	// Fork lineage (set when this session was forked from another conversation)
+	ForkedFromID       string    `json:"forked_from_id,omitempty"`        // Session this one was forked from
+	ForkedFromClaudeID string    `json:"forked_from_claude_id,omitempty"` // Claude conversation it was forked from
+	ForkedAt           time.Time `json:"forked_at,omitempty"`             // When the fork was created
+
 	// Git worktree support
 	WorktreePath     string `json:"worktree_path,omitempty"`      // Path to worktree (if session is in worktree)
 	WorktreeRepoRoot string `json:"worktree_repo_root,omitempty"` // Original repo root
 	WorktreeBranch   string `json:"worktree_branch,omitempty"`    // Branch name in worktree
+	BootstrapPending bool   `json:"bootstrap_pending,omitempty"`  // Worktree bootstrap command still has to run
+	BootstrapError   string `json:"bootstrap_error,omitempty"`    // Why the last worktree bootstrap failed
 
 	Command        string    `json:"command"`
 	Tool           string    `json:"tool"`
@@ -75,6 +88,23 @@ type Instance struct {
 	LatestPrompt        string    `json:"latest_prompt,omitempty"`
 	lastPromptModTime   time.Time // mtime cache for updateGeminiLatestPrompt (not serialized)
 
+	// Prompts waiting to be sent once the agent is ready (see prompt_queue.go)
+	PromptQueue    []QueuedPrompt `json:"prompt_queue,omitempty"`
+	queueDelivered bool           // A queued prompt was sent since TakeQueueDelivered
+
+	// Permission dialog the agent is blocked on, set with StatusNeedsApproval
+	PendingApproval  *ApprovalRequest `json:"pending_approval,omitempty"`
+	lastAutoApproved time.Time        // When the allowlist last answered a dialog
+
+	// Budget guardrails (see budget.go)
+	BusyTime            time.Duration `json:"busy_time,omitempty"`       // Time spent running, for runtime limits
+	BudgetBaseline      *BudgetUsage  `json:"budget_baseline,omitempty"` // Usage at the last override
+	BudgetExceeded      string        `json:"budget_exceeded,omitempty"` // Hard limit crossed; blocks prompts
+	BudgetWarning       string        `json:"-"`                         // Soft limit crossed
+	lastBusyPoll        time.Time     // Last poll that saw the agent running
+	lastBudgetCheck     time.Time     // Last comparison of usage with the budget
+	lastBudgetInterrupt time.Time     // Last Ctrl+C sent over budget
+
 	// MCP tracking - which MCPs were loaded when session started/restarted
 	// Used to detect pending MCPs (added after session start) and stale MCPs (removed but still running)
 	LoadedMCPNames []string `json:"loaded_mcp_names,omitempty"`
@@ -272,9 +302,8 @@ func (i *Instance) buildClaudeCommandWithMessage(baseCommand, message string) st
 	// Get options - either from instance or create defaults from config
 	opts := i.GetClaudeOptions()
 	if opts == nil {
-		// Fall back to config defaults
-		userConfig, _ := LoadUserConfig()
-		opts = NewClaudeOptions(userConfig)
+		// Fall back to config defaults (including the repo's .agent-deck.toml)
+		opts = NewClaudeOptions(&i.effectiveConfig().UserConfig)
 	}
 
 	// If baseCommand is just "claude", build the appropriate command
@@ -410,11 +439,8 @@ func (i *Instance) buildGeminiCommand(baseCommand string) string {
 	if i.GeminiYoloMode != nil {
 		yoloMode = *i.GeminiYoloMode
 	} else {
-		// Check global config
-		userConfig, _ := LoadUserConfig()
-		if userConfig != nil {
-			yoloMode = userConfig.Gemini.YoloMode
-		}
+		// Check config (global, or the repo's .agent-deck.toml)
+		yoloMode = i.effectiveConfig().Gemini.YoloMode
 	}
 
 	yoloFlag := ""
@@ -749,8 +775,10 @@ func (i *Instance) queryCodexSession() string {
 			}
 		}
 
-		// Pick the most recently modified session
-		if bestMatch == "" || info.ModTime().After(bestMatchTime) {
+		// Pick the most recently modified session, keeping the current one
+		// on a tie (e.g. a fork's copy of this rollout)
+		if bestMatch == "" || info.ModTime().After(bestMatchTime) ||
+			(info.ModTime().Equal(bestMatchTime) && matches == i.CodexSessionID) {
 			bestMatch = matches
 			bestMatchTime = info.ModTime()
 		}
@@ -936,6 +964,11 @@ func (i *Instance) Start() error {
 		return fmt.Errorf("tmux session not initialized")
 	}
 
+	// Worktree setup has to finish before the agent sees the checkout
+	if err := i.runWorktreeBootstrap(); err != nil {
+		return err
+	}
+
 	// Build command based on tool type
 	// Priority: built-in tools (claude, gemini, opencode, codex) → custom tools from config.toml → raw command
 	var command string
@@ -1011,6 +1044,11 @@ func (i *Instance) StartWithMessage(message string) error {
 		return fmt.Errorf("tmux session not initialized")
 	}
 
+	// Worktree setup has to finish before the agent sees the checkout
+	if err := i.runWorktreeBootstrap(); err != nil {
+		return err
+	}
+
 	// Start session normally (no embedded message logic)
 	// Priority: built-in tools (claude, gemini) → custom tools from config.toml → raw command
 	var command string
@@ -1071,12 +1109,18 @@ func (i *Instance) StartWithMessage(message string) error {
 // Exception: If Claude already finished processing "." from session capture,
 // we may see "waiting" immediately - detect this by checking for input prompt
 func (i *Instance) sendMessageWhenReady(message string) error {
+	if err := i.waitUntilReady(); err != nil {
+		return err
+	}
+	return i.sendKeys(message)
+}
+
+// waitUntilReady blocks until the agent is ready for input (see sendMessageWhenReady)
+func (i *Instance) waitUntilReady() error {
 	if i.tmuxSession == nil {
 		return fmt.Errorf("tmux session not initialized")
 	}
 
-	sessionName := i.tmuxSession.Name
-
 	// Track state transitions: we need to see "active" before accepting "waiting"
 	// This ensures we don't send the message during initial startup (false "waiting")
 	sawActive := false
@@ -1113,24 +1157,84 @@ func (i *Instance) sendMessageWhenReady(message string) error {
 		if (sawActive && status == "waiting") || alreadyReady {
 			// Small delay to ensure UI is fully rendered
 			time.Sleep(300 * time.Millisecond)
+			return nil
+		}
+	}
 
-			// Send the message using tmux send-keys
-			// -l flag for literal text, then Enter separately
-			cmd := exec.Command("tmux", "send-keys", "-l", "-t", sessionName, message)
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to send message: %w", err)
-			}
+	return fmt.Errorf("timeout waiting for agent to be ready")
+}
 
-			cmd = exec.Command("tmux", "send-keys", "-t", sessionName, "Enter")
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to send Enter: %w", err)
-			}
+// sendKeys types message into the session and presses Enter
+func (i *Instance) sendKeys(message string) error {
+	if i.tmuxSession == nil {
+		return fmt.Errorf("tmux session not initialized")
+	}
+	sessionName := i.tmuxSession.Name
+
+	// Send the message using tmux send-keys
+	// -l flag for literal text, then Enter separately
+	cmd := exec.Command("tmux", "send-keys", "-l", "-t", sessionName, message)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	cmd = exec.Command("tmux", "send-keys", "-t", sessionName, "Enter")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to send Enter: %w", err)
+	}
+
+	return nil
+}
 
+// turnPollInterval is how often WaitForTurn checks the session
+const turnPollInterval = time.Second
+
+// A turn is over after this many consecutive polls without activity. If the
+// agent never shows activity (a very quick reply), the longer
+// turnQuietPolls applies instead.
+const (
+	turnIdlePolls  = 3
+	turnQuietPolls = 30
+)
+
+// WaitForTurn blocks until the agent has worked on a prompt that was just
+// sent and gone quiet again, or the deadline passes. A Claude turn that
+// stops at a permission dialog is reported as an error rather than done.
+func (i *Instance) WaitForTurn(deadline time.Time) error {
+	if i.tmuxSession == nil {
+		return fmt.Errorf("could not determine tmux session")
+	}
+
+	sawActive := false
+	idle := 0
+	for time.Now().Before(deadline) {
+		time.Sleep(turnPollInterval)
+		if !i.tmuxSession.Exists() {
+			return fmt.Errorf("session '%s' exited before finishing", i.Title)
+		}
+		status, err := i.tmuxSession.GetStatus()
+		if err != nil {
+			continue
+		}
+		if status == "active" {
+			sawActive = true
+			idle = 0
+			continue
+		}
+		idle++
+		if (sawActive && idle >= turnIdlePolls) || idle >= turnQuietPolls {
+			if i.Tool == "claude" {
+				if content, err := i.tmuxSession.CapturePane(); err == nil {
+					if req, ok := tmux.ParsePermissionPrompt(content); ok {
+						pending := ApprovalRequest{Tool: req.Tool, Command: req.Command}
+						return fmt.Errorf("waiting for permission to run %s (answer with: agent-deck session approve|deny %s)", pending, i.ID)
+					}
+				}
+			}
 			return nil
 		}
 	}
-
-	return fmt.Errorf("timeout waiting for agent to be ready")
+	return fmt.Errorf("not finished by %s", deadline.Format("15:04:05"))
 }
 
 // errorRecheckInterval - how often to recheck sessions that don't exist
@@ -1190,6 +1294,7 @@ func (i *Instance) UpdateStatus() error {
 	}
 
 	// Map tmux status to instance status
+	prevStatus := i.Status
 	switch status {
 	case "active":
 		i.Status = StatusRunning
@@ -1200,6 +1305,7 @@ func (i *Instance) UpdateStatus() error {
 	default:
 		i.Status = StatusError
 	}
+	i.updateApproval(prevStatus)
 
 	// Update tool detection dynamically (enables fork when Claude starts)
 	if detectedTool := i.tmuxSession.DetectTool(); detectedTool != "" {
@@ -1220,6 +1326,13 @@ func (i *Instance) UpdateStatus() error {
 		i.UpdateCodexSession(nil)
 	}
 
+	// Checkpoint the working tree when the agent finishes a turn
+	if prevStatus == StatusRunning && (i.Status == StatusWaiting || i.Status == StatusIdle) {
+		i.checkpointAsync()
+	}
+	i.updateBudget()
+	i.updatePromptQueue()
+
 	return nil
 }
 
@@ -1245,10 +1358,8 @@ func (i *Instance) UpdateClaudeSession(excludeIDs map[string]bool) {
 	if i.ClaudeSessionID != "" {
 		jsonlPath := i.GetJSONLPath()
 		if jsonlPath != "" {
-			if data, err := os.ReadFile(jsonlPath); err == nil {
-				if prompt, err := parseClaudeLatestUserPrompt(data); err == nil && prompt != "" {
-					i.LatestPrompt = prompt
-				}
+			if prompt, err := claudeLatestPrompt(jsonlPath); err == nil && prompt != "" {
+				i.LatestPrompt = prompt
 			}
 		}
 	}
@@ -1313,8 +1424,18 @@ func (i *Instance) syncGeminiSessionFromDisk() {
 		return
 	}
 
-	// Pick the most recent session (list is sorted by LastUpdated desc)
+	// Pick the most recent session (list is sorted by LastUpdated desc),
+	// keeping the current one on a tie (e.g. a fork's copy of this chat)
 	mostRecent := sessions[0]
+	for _, s := range sessions {
+		if !s.LastUpdated.Equal(mostRecent.LastUpdated) {
+			break
+		}
+		if s.SessionID == i.GeminiSessionID {
+			mostRecent = s
+			break
+		}
+	}
 	if mostRecent.SessionID != i.GeminiSessionID {
 		log.Printf("[GEMINI] Updating session ID: %s -> %s", i.GeminiSessionID, mostRecent.SessionID)
 	}
@@ -1535,6 +1656,23 @@ func (i *Instance) GetLastResponse() (*ResponseOutput, error) {
 // GetJSONLPath returns the path to the Claude session JSONL file for analytics
 // Returns empty string if this is not a Claude session or no session ID is available
 func (i *Instance) GetJSONLPath() string {
+	sessionFile := i.ClaudeTranscriptPath()
+	if sessionFile == "" {
+		return ""
+	}
+
+	// Verify file exists before returning
+	if _, err := os.Stat(sessionFile); os.IsNotExist(err) {
+		return ""
+	}
+
+	return sessionFile
+}
+
+// ClaudeTranscriptPath returns where Claude writes this session's JSONL
+// transcript, whether or not it exists yet (Claude creates it on the first prompt).
+// Returns empty string if this is not a Claude session or no session ID is available
+func (i *Instance) ClaudeTranscriptPath() string {
 	if i.Tool != "claude" || i.ClaudeSessionID == "" {
 		return ""
 	}
@@ -1554,14 +1692,7 @@ func (i *Instance) GetJSONLPath() string {
 	projectDir := filepath.Join(configDir, "projects", projectDirName)
 
 	// Build the JSONL file path
-	sessionFile := filepath.Join(projectDir, i.ClaudeSessionID+".jsonl")
-
-	// Verify file exists before returning
-	if _, err := os.Stat(sessionFile); os.IsNotExist(err) {
-		return ""
-	}
-
-	return sessionFile
+	return filepath.Join(projectDir, i.ClaudeSessionID+".jsonl")
 }
 
 // getClaudeLastResponse extracts the last assistant message from Claude's JSONL file
@@ -1593,187 +1724,7 @@ func (i *Instance) getClaudeLastResponse() (*ResponseOutput, error) {
 		return nil, fmt.Errorf("session file not found: %s", sessionFile)
 	}
 
-	// Read and parse the JSONL file
-	data, err := os.ReadFile(sessionFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read session file: %w", err)
-	}
-
-	return parseClaudeLastAssistantMessage(data, filepath.Base(sessionFile))
-}
-
-// parseClaudeLastAssistantMessage parses a Claude JSONL file to extract the last assistant message
-func parseClaudeLastAssistantMessage(data []byte, sessionID string) (*ResponseOutput, error) {
-	// JSONL record structure (same as global_search.go)
-	type claudeMessage struct {
-		Role    string          `json:"role"`
-		Content json.RawMessage `json:"content"`
-	}
-	type claudeRecord struct {
-		SessionID string          `json:"sessionId"`
-		Type      string          `json:"type"`
-		Message   json.RawMessage `json:"message"`
-		Timestamp string          `json:"timestamp"`
-	}
-
-	var lastAssistantContent string
-	var lastTimestamp string
-	var foundSessionID string
-
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-	// Handle large lines
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-
-		var record claudeRecord
-		if err := json.Unmarshal(line, &record); err != nil {
-			continue // Skip malformed lines
-		}
-
-		// Capture session ID
-		if foundSessionID == "" && record.SessionID != "" {
-			foundSessionID = record.SessionID
-		}
-
-		// Only care about messages
-		if len(record.Message) == 0 {
-			continue
-		}
-
-		var msg claudeMessage
-		if err := json.Unmarshal(record.Message, &msg); err != nil {
-			continue
-		}
-
-		// Only care about assistant messages
-		if msg.Role != "assistant" {
-			continue
-		}
-
-		// Extract content (can be string or array of blocks)
-		var contentStr string
-		var extractedText string
-		if err := json.Unmarshal(msg.Content, &contentStr); err == nil {
-			// Simple string content
-			extractedText = contentStr
-		} else {
-			// Try as array of content blocks
-			var blocks []map[string]interface{}
-			if err := json.Unmarshal(msg.Content, &blocks); err == nil {
-				var sb strings.Builder
-				for _, block := range blocks {
-					// Check for text type blocks
-					if blockType, ok := block["type"].(string); ok && blockType == "text" {
-						if text, ok := block["text"].(string); ok {
-							sb.WriteString(text)
-							sb.WriteString("\n")
-						}
-					}
-				}
-				extractedText = strings.TrimSpace(sb.String())
-			}
-		}
-		// Only update if we found actual text content
-		if extractedText != "" {
-			lastAssistantContent = extractedText
-			lastTimestamp = record.Timestamp
-		}
-	}
-
-	if lastAssistantContent == "" {
-		return nil, fmt.Errorf("no assistant response found in session")
-	}
-
-	return &ResponseOutput{
-		Tool:      "claude",
-		Role:      "assistant",
-		Content:   lastAssistantContent,
-		Timestamp: lastTimestamp,
-		SessionID: foundSessionID,
-	}, nil
-}
-
-// parseClaudeLatestUserPrompt parses a Claude JSONL file to extract the last user message
-func parseClaudeLatestUserPrompt(data []byte) (string, error) {
-	// JSONL record structure
-	type claudeMessage struct {
-		Role    string          `json:"role"`
-		Content json.RawMessage `json:"content"`
-	}
-	type claudeRecord struct {
-		Message json.RawMessage `json:"message"`
-	}
-
-	var latestPrompt string
-
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-	// Handle large lines
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-
-		var record claudeRecord
-		if err := json.Unmarshal(line, &record); err != nil {
-			continue // Skip malformed lines
-		}
-
-		// Only care about messages
-		if len(record.Message) == 0 {
-			continue
-		}
-
-		var msg claudeMessage
-		if err := json.Unmarshal(record.Message, &msg); err != nil {
-			continue
-		}
-
-		// Only care about user messages
-		if msg.Role != "user" {
-			continue
-		}
-
-		// Extract content (can be string or array of blocks)
-		var contentStr string
-		var extractedText string
-		if err := json.Unmarshal(msg.Content, &contentStr); err == nil {
-			// Simple string content
-			extractedText = contentStr
-		} else {
-			// Try as array of content blocks
-			var blocks []map[string]interface{}
-			if err := json.Unmarshal(msg.Content, &blocks); err == nil {
-				var sb strings.Builder
-				for _, block := range blocks {
-					if blockType, ok := block["type"].(string); ok && blockType == "text" {
-						if text, ok := block["text"].(string); ok {
-							sb.WriteString(text)
-							sb.WriteString(" ")
-						}
-					}
-				}
-				extractedText = strings.TrimSpace(sb.String())
-			}
-		}
-
-		// Sanitize: strip newlines and extra spaces for single-line display
-		if extractedText != "" {
-			content := strings.ReplaceAll(extractedText, "\n", " ")
-			latestPrompt = strings.Join(strings.Fields(content), " ")
-		}
-	}
-
-	return latestPrompt, nil
+	return claudeLastResponse(sessionFile)
 }
 
 // parseGeminiLatestUserPrompt parses a Gemini JSON file to extract the last user message
@@ -2039,7 +1990,7 @@ func (i *Instance) Restart() error {
 
 	// Regenerate .mcp.json before restart to use socket pool if available
 	// Skip if MCP dialog just wrote the config (avoids race condition)
-	if i.Tool == "claude" && !skipRegen {
+	if (i.Tool == "claude" || i.Tool == "codex" || i.Tool == "opencode") && !skipRegen {
 		if err := i.regenerateMCPConfig(); err != nil {
 			log.Printf("[MCP-DEBUG] Warning: MCP config regeneration failed: %v", err)
 			// Continue with restart - Claude will use existing .mcp.json or defaults
@@ -2210,6 +2161,11 @@ func (i *Instance) Restart() error {
 
 	log.Printf("[MCP-DEBUG] Using fallback: recreate tmux session")
 
+	// A worktree whose bootstrap failed gets it retried before the agent starts
+	if err := i.runWorktreeBootstrap(); err != nil {
+		return err
+	}
+
 	// Fallback: recreate tmux session (for dead sessions or unknown ID)
 	i.tmuxSession = tmux.NewSession(i.Title, i.ProjectPath)
 	i.tmuxSession.InstanceID = i.ID // Pass instance ID for activity hooks
@@ -2312,11 +2268,9 @@ func (i *Instance) buildClaudeResumeCommand() string {
 		configDirPrefix = fmt.Sprintf("CLAUDE_CONFIG_DIR=%s ", configDir)
 	}
 
-	// Check if dangerous mode is enabled in user config
-	dangerousMode := false
-	if userConfig, err := LoadUserConfig(); err == nil && userConfig != nil {
-		dangerousMode = userConfig.Claude.GetDangerousMode()
-	}
+	// Check if dangerous mode is enabled in config (global, or the repo's .agent-deck.toml)
+	ec := i.effectiveConfig()
+	dangerousMode := ec.Claude.GetDangerousMode()
 
 	// Check if session has actual conversation data
 	// If not, use --session-id instead of --resume to avoid "No conversation found" error
@@ -2404,17 +2358,9 @@ func (i *Instance) CanRestart() bool {
 }
 
 // CanFork returns true if this session can be forked
+// See ForkUnavailableReason for why it can't
 func (i *Instance) CanFork() bool {
-	// Gemini CLI doesn't support forking
-	if i.Tool == "gemini" {
-		return false
-	}
-
-	// Claude sessions can fork if session ID is recent
-	if i.ClaudeSessionID == "" {
-		return false
-	}
-	return time.Since(i.ClaudeDetectedAt) < 5*time.Minute
+	return i.ForkUnavailableReason() == ""
 }
 
 // Fork returns the command to create a forked Claude session
@@ -2429,12 +2375,20 @@ func (i *Instance) Fork(newTitle, newGroupPath string) (string, error) {
 // Uses capture-resume pattern: starts fork in print mode to get new session ID,
 // stores in tmux environment, then resumes interactively
 func (i *Instance) ForkWithOptions(newTitle, newGroupPath string, opts *ClaudeOptions) (string, error) {
-	if !i.CanFork() {
-		return "", fmt.Errorf("cannot fork: no active Claude session")
+	// Tools with a fork method of their own are forked by
+	// CreateForkedInstanceWithOptions, which copies their conversation
+	if i.Tool != "claude" && ToolSupportsFork(i.Tool) {
+		return "", fmt.Errorf("cannot fork: not a Claude session (tool: %s)", i.Tool)
 	}
+	if reason := i.ForkUnavailableReason(); reason != "" {
+		return "", fmt.Errorf("cannot fork: %s", reason)
+	}
+	return i.forkCommand(i.ProjectPath, opts), nil
+}
 
-	workDir := i.ProjectPath
-
+// forkCommand builds the capture-resume command that forks this Claude
+// conversation into a new session running in workDir
+func (i *Instance) forkCommand(workDir string, opts *ClaudeOptions) string {
 	// IMPORTANT: For capture-resume commands (which contain $(...) syntax), we MUST use
 	// "claude" binary + CLAUDE_CONFIG_DIR, NOT a custom command alias like "cdw".
 	// Reason: Commands with $(...) get wrapped in `bash -c` for fish compatibility (#47),
@@ -2451,8 +2405,7 @@ func (i *Instance) ForkWithOptions(newTitle, newGroupPath string, opts *ClaudeOp
 
 	// If no options provided, use defaults from config
 	if opts == nil {
-		userConfig, _ := LoadUserConfig()
-		opts = NewClaudeOptions(userConfig)
+		opts = NewClaudeOptions(&i.effectiveConfig().UserConfig)
 	}
 
 	// Build extra flags from options (for fork, we use ToArgsForFork which excludes session mode)
@@ -2469,7 +2422,7 @@ func (i *Instance) ForkWithOptions(newTitle, newGroupPath string, opts *ClaudeOp
 		workDir,
 		bashExportPrefix, i.ClaudeSessionID, extraFlags)
 
-	return cmd, nil
+	return cmd
 }
 
 // GetActualWorkDir returns the actual working directory from tmux, or falls back to ProjectPath
@@ -2489,15 +2442,134 @@ func (i *Instance) CreateForkedInstance(newTitle, newGroupPath string) (*Instanc
 }
 
 // CreateForkedInstanceWithOptions creates a new Instance configured for forking with custom options
+// opts only applies to Claude sessions
 func (i *Instance) CreateForkedInstanceWithOptions(newTitle, newGroupPath string, opts *ClaudeOptions) (*Instance, string, error) {
-	cmd, err := i.ForkWithOptions(newTitle, newGroupPath, opts)
+	if reason := i.ForkUnavailableReason(); reason != "" {
+		return nil, "", fmt.Errorf("cannot fork: %s", reason)
+	}
+
+	// Create new instance with the PARENT's project path
+	// This ensures the forked session is in the same project directory as parent
+	return i.forkInto(newTitle, newGroupPath, i.ProjectPath, opts)
+}
+
+// CreateForkedInstanceInWorktree creates a forked instance that runs in a new
+// git worktree. branchName is created from the parent's current HEAD, so
+// parallel forks don't edit the same checkout. location is "sibling" or
+// "subdirectory"; empty uses the [worktree] default_location setting.
+func (i *Instance) CreateForkedInstanceInWorktree(newTitle, newGroupPath, branchName, location string, opts *ClaudeOptions) (*Instance, string, error) {
+	if reason := i.ForkUnavailableReason(); reason != "" {
+		return nil, "", fmt.Errorf("cannot fork: %s", reason)
+	}
+	if err := git.ValidateBranchName(branchName); err != nil {
+		return nil, "", fmt.Errorf("invalid branch name: %w", err)
+	}
+	if !git.IsGitRepo(i.ProjectPath) {
+		return nil, "", fmt.Errorf("cannot fork into worktree: %s is not a git repository", i.ProjectPath)
+	}
+
+	// Name the worktree after the main repo, even when the parent is itself in a worktree
+	repoRoot := i.WorktreeRepoRoot
+	checkoutRoot, err := git.GetRepoRoot(i.ProjectPath)
 	if err != nil {
 		return nil, "", err
 	}
+	if repoRoot == "" {
+		repoRoot = checkoutRoot
+	}
+	if git.BranchExists(repoRoot, branchName) {
+		return nil, "", fmt.Errorf("branch '%s' already exists", branchName)
+	}
 
-	// Create new instance with the PARENT's project path
-	// This ensures the forked session is in the same Claude project directory as parent
-	forked := NewInstance(newTitle, i.ProjectPath)
+	if location == "" {
+		location = GetRepoWorktreeSettings(repoRoot).DefaultLocation
+	}
+	worktreePath := git.GenerateWorktreePath(repoRoot, branchName, location)
+	if _, err := os.Stat(worktreePath); err == nil {
+		return nil, "", fmt.Errorf("worktree already exists at %s", worktreePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	// Running git in the parent's checkout branches from its HEAD, not the main repo's
+	if err := git.CreateWorktree(i.ProjectPath, worktreePath, branchName); err != nil {
+		return nil, "", err
+	}
+
+	// Keep the parent's subdirectory if it isn't at the checkout root
+	workDir := worktreePath
+	projectPath := i.ProjectPath
+	if resolved, err := filepath.EvalSymlinks(projectPath); err == nil {
+		projectPath = resolved
+	}
+	if rel, err := filepath.Rel(checkoutRoot, projectPath); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		workDir = filepath.Join(worktreePath, rel)
+	}
+
+	forked, cmd, err := i.forkInto(newTitle, newGroupPath, workDir, opts)
+	if err != nil {
+		_ = git.RemoveWorktree(repoRoot, worktreePath, true)
+		_ = git.DeleteBranch(repoRoot, branchName, true)
+		return nil, "", err
+	}
+	forked.WorktreePath = worktreePath
+	forked.WorktreeRepoRoot = repoRoot
+	forked.WorktreeBranch = branchName
+	if err := forked.PrepareWorktree(); err != nil {
+		_ = forked.RemoveForkWorktree()
+		return nil, "", err
+	}
+	return forked, cmd, nil
+}
+
+// RemoveForkWorktree removes the worktree and branch created for a fork that
+// is discarded before it was saved, e.g. because it failed to start
+func (i *Instance) RemoveForkWorktree() error {
+	if !i.IsWorktree() {
+		return nil
+	}
+	if err := git.RemoveWorktree(i.WorktreeRepoRoot, i.WorktreePath, true); err != nil {
+		return err
+	}
+	return git.DeleteBranch(i.WorktreeRepoRoot, i.WorktreeBranch, true)
+}
+
+// copyClaudeSessionToProject copies a Claude conversation file into the
+// Claude project directory for workDir
+func copyClaudeSessionToProject(sessionID, workDir string) error {
+	src := findSessionFileInAllProjects(sessionID)
+	if src == "" {
+		return fmt.Errorf("conversation file for session %s not found", sessionID)
+	}
+
+	resolvedPath := workDir
+	if resolved, err := filepath.EvalSymlinks(workDir); err == nil {
+		resolvedPath = resolved
+	}
+	projectsDir := filepath.Dir(filepath.Dir(src))
+	dstDir := filepath.Join(projectsDir, ConvertToClaudeDirName(resolvedPath))
+	dst := filepath.Join(dstDir, filepath.Base(src))
+	if dst == src {
+		return nil
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read conversation: %w", err)
+	}
+	if err := os.MkdirAll(dstDir, 0700); err != nil {
+		return fmt.Errorf("failed to create Claude project dir: %w", err)
+	}
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		return fmt.Errorf("failed to copy conversation: %w", err)
+	}
+	return nil
+}
+
+// newForkedInstance creates the Instance for a fork running cmd in workDir
+func (i *Instance) newForkedInstance(newTitle, newGroupPath, workDir, cmd string, opts *ClaudeOptions) *Instance {
+	forked := NewInstance(newTitle, workDir)
 	if newGroupPath != "" {
 		forked.GroupPath = newGroupPath
 	} else {
@@ -2505,6 +2577,9 @@ func (i *Instance) CreateForkedInstanceWithOptions(newTitle, newGroupPath string
 	}
 	forked.Command = cmd
 	forked.Tool = "claude"
+	forked.ForkedFromID = i.ID
+	forked.ForkedFromClaudeID = i.ClaudeSessionID
+	forked.ForkedAt = time.Now()
 
 	// Store options in the new instance for persistence
 	if opts != nil {
@@ -2514,7 +2589,7 @@ func (i *Instance) CreateForkedInstanceWithOptions(newTitle, newGroupPath string
 		}
 	}
 
-	return forked, cmd, nil
+	return forked
 }
 
 // Exists checks if the tmux session still exists
@@ -2585,6 +2660,10 @@ func (i *Instance) GetMCPInfo() *MCPInfo {
 		return GetMCPInfo(i.ProjectPath)
 	case "gemini":
 		return GetGeminiMCPInfo(i.ProjectPath)
+	case "codex":
+		return GetCodexMCPInfo(i.ProjectPath)
+	case "opencode":
+		return GetOpenCodeMCPInfo(i.ProjectPath)
 	default:
 		return nil
 	}
@@ -2608,11 +2687,24 @@ func (i *Instance) CaptureLoadedMCPs() {
 	i.LoadedMCPNames = mcpInfo.AllNames()
 }
 
-// regenerateMCPConfig regenerates .mcp.json with current pool status
+// regenerateMCPConfig regenerates .mcp.json (or the Codex/OpenCode MCP config) with current pool status
 // If socket pool is running, MCPs will use socket configs (nc -U /tmp/...)
 // Otherwise, MCPs will use stdio configs (npx ...)
 // Returns error if .mcp.json write fails
 func (i *Instance) regenerateMCPConfig() error {
+	// Codex and OpenCode keep attached MCPs in their own config files
+	if i.Tool == "codex" || i.Tool == "opencode" {
+		names := GetToolMCPNames(i.Tool, i.ProjectPath)
+		if len(names) == 0 {
+			return nil
+		}
+		if err := WriteToolMCPs(i.Tool, i.ProjectPath, names); err != nil {
+			return fmt.Errorf("failed to regenerate %s MCP config: %w", i.Tool, err)
+		}
+		log.Printf("[MCP-DEBUG] Regenerated %s MCP config for %s with %d MCPs", i.Tool, i.Title, len(names))
+		return nil
+	}
+
 	ClearMCPCache(i.ProjectPath) // Force fresh read from disk (not stale 30s cache)
 	mcpInfo := GetMCPInfo(i.ProjectPath)
 	if mcpInfo == nil {
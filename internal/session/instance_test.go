@@ -8,6 +8,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/git/gittest"
 )
 
 // TestNewSessionStatusFlicker tests for green flicker on new session creation
@@ -243,6 +246,9 @@ func TestInstance_CreateForkedInstance(t *testing.T) {
 	if forked.Tool != "claude" {
 		t.Errorf("Forked tool = %s, want claude", forked.Tool)
 	}
+	if forked.ForkedFromID != inst.ID || forked.ForkedFromClaudeID != "abc-123" || forked.ForkedAt.IsZero() {
+		t.Errorf("Forked lineage = %q/%q/%v, want %q/abc-123/now", forked.ForkedFromID, forked.ForkedFromClaudeID, forked.ForkedAt, inst.ID)
+	}
 
 	// Test with custom group path
 	forked2, _, err := inst.CreateForkedInstance("forked2", "custom-group")
@@ -254,6 +260,88 @@ func TestInstance_CreateForkedInstance(t *testing.T) {
 	}
 }
 
+// TestInstance_CreateForkedInstanceInWorktree tests forking into a new git worktree
+func TestInstance_CreateForkedInstanceInWorktree(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	ClearUserConfigCache()
+	t.Cleanup(ClearUserConfigCache)
+
+	repo := filepath.Join(home, "repo")
+	gittest.InitRepo(t, repo)
+	if err := os.MkdirAll(filepath.Join(repo, "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo, _ = filepath.EvalSymlinks(repo)
+
+	// Parent runs in a subdirectory and has a conversation on disk
+	parentDir := filepath.Join(repo, "app")
+	projectsDir := filepath.Join(home, ".claude", "projects")
+	parentProject := filepath.Join(projectsDir, ConvertToClaudeDirName(parentDir))
+	if err := os.MkdirAll(parentProject, 0700); err != nil {
+		t.Fatal(err)
+	}
+	conversation := `{"sessionId":"abc-123","type":"user"}` + "\n"
+	if err := os.WriteFile(filepath.Join(parentProject, "abc-123.jsonl"), []byte(conversation), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := NewInstance("original", parentDir)
+	inst.ClaudeSessionID = "abc-123"
+	inst.ClaudeDetectedAt = time.Now()
+
+	forked, cmd, err := inst.CreateForkedInstanceInWorktree("forked", "", "fork/try-b", "sibling", nil)
+	if err != nil {
+		t.Fatalf("CreateForkedInstanceInWorktree() failed: %v", err)
+	}
+
+	wantWorktree := repo + "-fork-try-b"
+	if forked.WorktreePath != wantWorktree || forked.WorktreeBranch != "fork/try-b" || forked.WorktreeRepoRoot != repo {
+		t.Errorf("worktree fields = (%q, %q, %q), want (%q, fork/try-b, %q)",
+			forked.WorktreePath, forked.WorktreeBranch, forked.WorktreeRepoRoot, wantWorktree, repo)
+	}
+	wantDir := filepath.Join(wantWorktree, "app")
+	if forked.ProjectPath != wantDir {
+		t.Errorf("ProjectPath = %q, want %q", forked.ProjectPath, wantDir)
+	}
+	if !strings.Contains(cmd, "cd '"+wantDir+"'") || !strings.Contains(cmd, "--resume abc-123 --fork-session") {
+		t.Errorf("fork command should resume parent in the worktree, got: %s", cmd)
+	}
+
+	// The parent conversation must be resolvable from the worktree's Claude project
+	copied := filepath.Join(projectsDir, ConvertToClaudeDirName(wantDir), "abc-123.jsonl")
+	if data, err := os.ReadFile(copied); err != nil || string(data) != conversation {
+		t.Errorf("conversation not copied to %s: %v", copied, err)
+	}
+
+	// The branch already exists now, so a second fork on it fails
+	if _, _, err := inst.CreateForkedInstanceInWorktree("again", "", "fork/try-b", "sibling", nil); err == nil {
+		t.Error("forking onto an existing branch should fail")
+	}
+}
+
+// TestInstance_CreateForkedInstanceInWorktree_CleansUp tests that a fork whose
+// worktree can't be set up leaves no worktree or branch behind
+func TestInstance_CreateForkedInstanceInWorktree_CleansUp(t *testing.T) {
+	writeTestConfig(t, "[worktree]\ncopy_files = [\"/etc/hosts\"]\n")
+	repo := gittest.NewRepo(t)
+
+	inst := NewInstance("original", repo)
+	inst.ClaudeSessionID = "abc-123"
+	inst.ClaudeDetectedAt = time.Now()
+	if _, _, err := inst.CreateForkedInstanceInWorktree("forked", "", "fork/broken", "sibling", nil); err == nil {
+		t.Fatal("fork should fail when its worktree files can't be set up")
+	}
+
+	if _, err := os.Stat(git.GenerateWorktreePath(repo, "fork/broken", "sibling")); !os.IsNotExist(err) {
+		t.Errorf("worktree should be removed, stat: %v", err)
+	}
+	if git.BranchExists(repo, "fork/broken") {
+		t.Error("branch fork/broken should be deleted")
+	}
+}
+
 // TestInstance_CreateForkedInstance_ExplicitConfig tests CreateForkedInstance with explicit config
 func TestInstance_CreateForkedInstance_ExplicitConfig(t *testing.T) {
 	// Isolate from user's environment (don't pick up their config.toml)
@@ -1088,21 +1176,22 @@ func TestInstance_RegenerateMCPConfig_WriteFailure(t *testing.T) {
 }
 
 func TestInstance_CanFork_Gemini(t *testing.T) {
-	// Test 1: Gemini tool with valid session ID should NOT be forkable
-	// Gemini CLI has NO --fork-session flag (unlike Claude)
+	// Test 1: Gemini forks by copying its chat, so it needs a Gemini session ID
 	inst := NewInstanceWithTool("test", "/tmp/test", "gemini")
+	if inst.CanFork() {
+		t.Error("CanFork() should be false for Gemini without a session ID")
+	}
+
 	inst.GeminiSessionID = "abc-123-def"
 	inst.GeminiDetectedAt = time.Now()
-
-	if inst.CanFork() {
-		t.Error("CanFork() should be false for Gemini (not supported by Gemini CLI)")
+	if !inst.CanFork() {
+		t.Errorf("CanFork() should be true for Gemini with a session ID, reason: %s", inst.ForkUnavailableReason())
 	}
 
-	// Test 2: Even if ClaudeSessionID were somehow set, Gemini tool should not fork
-	// This tests the explicit tool check
+	// Test 2: A ClaudeSessionID doesn't make a Gemini session fork like Claude
+	inst.GeminiSessionID = ""
 	inst.ClaudeSessionID = "claude-session-xyz"
 	inst.ClaudeDetectedAt = time.Now()
-
 	if inst.CanFork() {
 		t.Error("CanFork() should be false for Gemini tool even with ClaudeSessionID set")
 	}
@@ -0,0 +1,211 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// How a session in a lineage tree relates to its parent
+const (
+	LineageRoot       = "root"
+	LineageFork       = "fork"
+	LineageSubSession = "sub-session"
+)
+
+// LineageNode is one session in a conversation's genealogy
+type LineageNode struct {
+	Session  *Instance
+	Relation string // LineageRoot, LineageFork or LineageSubSession
+	Depth    int
+	Parent   *LineageNode
+	Children []*LineageNode
+}
+
+// IsFork returns true if this session was forked from another conversation
+func (inst *Instance) IsFork() bool {
+	return inst.ForkedFromID != "" || inst.ForkedFromClaudeID != ""
+}
+
+// lineageIndex looks up lineage parents among a set of sessions
+type lineageIndex struct {
+	byID     map[string]*Instance
+	byClaude map[string]*Instance
+}
+
+func newLineageIndex(instances []*Instance) *lineageIndex {
+	idx := &lineageIndex{
+		byID:     make(map[string]*Instance, len(instances)),
+		byClaude: make(map[string]*Instance, len(instances)),
+	}
+	for _, inst := range instances {
+		idx.byID[inst.ID] = inst
+		if inst.ClaudeSessionID != "" {
+			idx.byClaude[inst.ClaudeSessionID] = inst
+		}
+	}
+	return idx
+}
+
+// parent returns the session inst was forked from (or, failing that, is a
+// sub-session of) and the relation. A fork whose source session was deleted
+// is matched by the Claude conversation it was forked from.
+func (idx *lineageIndex) parent(inst *Instance) (*Instance, string) {
+	if inst.IsFork() {
+		if p, ok := idx.byID[inst.ForkedFromID]; ok && p != inst {
+			return p, LineageFork
+		}
+		if p, ok := idx.byClaude[inst.ForkedFromClaudeID]; ok && p != inst {
+			return p, LineageFork
+		}
+	}
+	if inst.ParentSessionID != "" {
+		if p, ok := idx.byID[inst.ParentSessionID]; ok && p != inst {
+			return p, LineageSubSession
+		}
+	}
+	return nil, ""
+}
+
+// BuildLineage returns the full lineage tree containing the session with the
+// given ID, rooted at its oldest known ancestor. Returns nil if id is unknown.
+func BuildLineage(instances []*Instance, id string) *LineageNode {
+	idx := newLineageIndex(instances)
+	inst, ok := idx.byID[id]
+	if !ok {
+		return nil
+	}
+
+	// Walk up to the root, stopping on cycles
+	seen := map[string]bool{inst.ID: true}
+	for {
+		p, _ := idx.parent(inst)
+		if p == nil || seen[p.ID] {
+			break
+		}
+		seen[p.ID] = true
+		inst = p
+	}
+
+	children := make(map[string][]*Instance)
+	relations := make(map[string]string)
+	for _, child := range instances {
+		if p, rel := idx.parent(child); p != nil {
+			children[p.ID] = append(children[p.ID], child)
+			relations[child.ID] = rel
+		}
+	}
+	for _, list := range children {
+		sort.SliceStable(list, func(a, b int) bool {
+			return list[a].CreatedAt.Before(list[b].CreatedAt)
+		})
+	}
+
+	root := &LineageNode{Session: inst, Relation: LineageRoot}
+	built := map[string]bool{inst.ID: true}
+	var build func(n *LineageNode)
+	build = func(n *LineageNode) {
+		for _, child := range children[n.Session.ID] {
+			if built[child.ID] {
+				continue
+			}
+			built[child.ID] = true
+			c := &LineageNode{Session: child, Relation: relations[child.ID], Depth: n.Depth + 1, Parent: n}
+			n.Children = append(n.Children, c)
+			build(c)
+		}
+	}
+	build(root)
+	return root
+}
+
+// Walk returns the node and all its descendants in display (pre-)order
+func (n *LineageNode) Walk() []*LineageNode {
+	nodes := []*LineageNode{n}
+	for _, c := range n.Children {
+		nodes = append(nodes, c.Walk()...)
+	}
+	return nodes
+}
+
+// Sessions returns the sessions in this branch: the node and all its descendants
+func (n *LineageNode) Sessions() []*Instance {
+	nodes := n.Walk()
+	sessions := make([]*Instance, len(nodes))
+	for i, node := range nodes {
+		sessions[i] = node.Session
+	}
+	return sessions
+}
+
+// Find returns the node for the session with the given ID, or nil
+func (n *LineageNode) Find(id string) *LineageNode {
+	for _, node := range n.Walk() {
+		if node.Session.ID == id {
+			return node
+		}
+	}
+	return nil
+}
+
+// TreePrefix returns the box-drawing prefix ("│  ├─ ") that places the node
+// under its parent when nodes are printed in Walk order
+func (n *LineageNode) TreePrefix() string {
+	if n.Parent == nil {
+		return ""
+	}
+	var parts []string
+	for a := n.Parent; a.Parent != nil; a = a.Parent {
+		if a.isLastChild() {
+			parts = append(parts, "   ")
+		} else {
+			parts = append(parts, "│  ")
+		}
+	}
+	// Ancestors were collected bottom-up
+	for l, r := 0, len(parts)-1; l < r; l, r = l+1, r-1 {
+		parts[l], parts[r] = parts[r], parts[l]
+	}
+	if n.isLastChild() {
+		parts = append(parts, "└─ ")
+	} else {
+		parts = append(parts, "├─ ")
+	}
+	return strings.Join(parts, "")
+}
+
+// isLastChild returns true if the node is its parent's last child
+func (n *LineageNode) isLastChild() bool {
+	siblings := n.Parent.Children
+	return siblings[len(siblings)-1] == n
+}
+
+// EstimatedCost returns the session's estimated cost in USD from its tool's
+// analytics (0 if unknown). Reads the session files, so avoid calling it from
+// render paths.
+func (inst *Instance) EstimatedCost() float64 {
+	_, cost, _ := sessionUsage(inst, inst.CreatedAt)
+	return cost
+}
+
+// DiffLastResponses returns a unified diff of the last assistant responses
+// of two sessions, e.g. a fork and the conversation it was forked from
+func DiffLastResponses(a, b *Instance) (string, error) {
+	ra, err := a.GetLastResponse()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", a.Title, err)
+	}
+	rb, err := b.GetLastResponse()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", b.Title, err)
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimRight(ra.Content, "\n") + "\n"),
+		B:        difflib.SplitLines(strings.TrimRight(rb.Content, "\n") + "\n"),
+		FromFile: a.Title,
+		ToFile:   b.Title,
+		Context:  3,
+	})
+}
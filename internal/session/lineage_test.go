@@ -0,0 +1,135 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newLineageTestSession creates a session created at minute m for stable ordering
+func newLineageTestSession(title string, m int) *Instance {
+	inst := NewInstance(title, "/tmp/project")
+	inst.CreatedAt = time.Date(2026, 1, 1, 12, m, 0, 0, time.UTC)
+	return inst
+}
+
+func TestBuildLineage(t *testing.T) {
+	root := newLineageTestSession("root", 0)
+	root.ClaudeSessionID = "claude-root"
+	forkB := newLineageTestSession("fork-b", 2)
+	forkB.ForkedFromID = root.ID
+	forkA := newLineageTestSession("fork-a", 1)
+	forkA.ForkedFromID = root.ID
+	forkA.ClaudeSessionID = "claude-a"
+	sub := newLineageTestSession("sub", 3)
+	sub.ParentSessionID = forkA.ID
+	// Source session was deleted and re-added: matched by its Claude conversation
+	orphan := newLineageTestSession("orphan-fork", 4)
+	orphan.ForkedFromID = "deleted-id"
+	orphan.ForkedFromClaudeID = "claude-a"
+	unrelated := newLineageTestSession("unrelated", 5)
+
+	instances := []*Instance{sub, forkB, unrelated, root, orphan, forkA}
+	tree := BuildLineage(instances, sub.ID)
+	if tree == nil || tree.Session != root {
+		t.Fatalf("lineage of a nested session should be rooted at the oldest ancestor, got %+v", tree)
+	}
+
+	var got []string
+	for _, n := range tree.Walk() {
+		got = append(got, n.TreePrefix()+n.Session.Title+" ("+n.Relation+")")
+	}
+	want := []string{
+		"root (root)",
+		"├─ fork-a (fork)",
+		"│  ├─ sub (sub-session)",
+		"│  └─ orphan-fork (fork)",
+		"└─ fork-b (fork)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	branch := tree.Find(forkA.ID)
+	if n := len(branch.Sessions()); n != 3 {
+		t.Errorf("fork-a branch should hold 3 sessions, got %d", n)
+	}
+
+	if BuildLineage(instances, "missing") != nil {
+		t.Error("unknown session should have no lineage")
+	}
+	if single := BuildLineage(instances, unrelated.ID); len(single.Walk()) != 1 {
+		t.Error("a session without forks is a tree of one")
+	}
+}
+
+func TestBuildLineage_Cycle(t *testing.T) {
+	a := newLineageTestSession("a", 0)
+	b := newLineageTestSession("b", 1)
+	a.ParentSessionID = b.ID
+	b.ForkedFromID = a.ID
+
+	tree := BuildLineage([]*Instance{a, b}, a.ID)
+	if tree == nil || len(tree.Walk()) != 2 {
+		t.Fatalf("cyclic links should still give a finite tree, got %+v", tree)
+	}
+}
+
+func TestDiffLastResponses(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", configDir)
+	project := t.TempDir()
+
+	newClaudeSession := func(title, claudeID, response string) *Instance {
+		inst := NewInstance(title, project)
+		inst.Tool = "claude"
+		inst.ClaudeSessionID = claudeID
+		resolved, _ := filepath.EvalSymlinks(project)
+		dir := filepath.Join(configDir, "projects", ConvertToClaudeDirName(resolved))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		line := `{"type":"assistant","message":{"role":"assistant","content":"` + response + `"}}` + "\n"
+		if err := os.WriteFile(filepath.Join(dir, claudeID+".jsonl"), []byte(line), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return inst
+	}
+
+	source := newClaudeSession("source", "claude-1", `Use a mutex.\nThen add a test.`)
+	fork := newClaudeSession("fork", "claude-2", `Use a channel.\nThen add a test.`)
+
+	diff, err := DiffLastResponses(source, fork)
+	if err != nil {
+		t.Fatalf("DiffLastResponses() failed: %v", err)
+	}
+	for _, want := range []string{"--- source", "+++ fork", "-Use a mutex.", "+Use a channel.", " Then add a test."} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+
+	if diff, _ := DiffLastResponses(source, source); diff != "" {
+		t.Errorf("identical responses should give an empty diff, got %q", diff)
+	}
+}
+
+func TestInstanceEstimatedCost(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", configDir)
+	project := t.TempDir()
+	resolved, _ := filepath.EvalSymlinks(project)
+
+	inst := NewInstance("costly", project)
+	inst.Tool = "claude"
+	inst.ClaudeSessionID = "claude-cost"
+	writeTestTranscript(t, configDir, ConvertToClaudeDirName(resolved), "claude-cost.jsonl",
+		`{"type":"assistant","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":1000000,"output_tokens":100000}}}`+"\n")
+
+	// 1M input at $3 + 100k output at $15
+	if cost := inst.EstimatedCost(); cost < 4.49 || cost > 4.51 {
+		t.Errorf("EstimatedCost() = %f, want 4.50", cost)
+	}
+}
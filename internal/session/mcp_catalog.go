@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"sort"
 	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
 )
 
 // MCPServerConfig represents an MCP server configuration (Claude's format)
@@ -22,26 +24,163 @@ type MCPServerConfig struct {
 }
 
 // getExternalSocketPath returns the socket path if an external pool socket exists and is alive
-// This allows CLI commands to use sockets created by the TUI without needing pool initialization
+// This allows CLI commands to use sockets created by the TUI without needing pool initialization.
+// If the socket is missing and a standalone pool process is running, it asks the pool to start the MCP.
 func getExternalSocketPath(mcpName string) string {
 	socketPath := filepath.Join("/tmp", fmt.Sprintf("agentdeck-mcp-%s.sock", mcpName))
 
 	// Check if socket file exists
 	if _, err := os.Stat(socketPath); os.IsNotExist(err) {
-		return ""
+		return acquireFromPoolDaemon(mcpName)
 	}
 
 	// Check if socket is alive (accepting connections)
 	conn, err := net.DialTimeout("unix", socketPath, 500*time.Millisecond)
 	if err != nil {
 		log.Printf("[MCP-POOL] Socket %s exists but not alive: %v", socketPath, err)
-		return ""
+		return acquireFromPoolDaemon(mcpName)
 	}
 	conn.Close()
 
 	return socketPath
 }
 
+// stdioServerConfig builds the stdio entry for an MCP. MCPs whose env uses
+// secret references are launched through `agent-deck mcp exec`, which resolves
+// them at launch, so the secret values never land in the generated file.
+func stdioServerConfig(name string, def MCPDef, projectPath string) MCPServerConfig {
+	args := def.Args
+	if args == nil {
+		args = []string{}
+	}
+	env := def.Env
+	if env == nil {
+		env = map[string]string{}
+	}
+
+	if def.HasSecretRefs() {
+		exe, err := os.Executable()
+		if err == nil {
+			launcherArgs := []string{"mcp", "exec"}
+			if projectPath != "" {
+				launcherArgs = append(launcherArgs, "--project", projectPath)
+			}
+			launcherArgs = append(launcherArgs, name)
+			return MCPServerConfig{
+				Type:    "stdio",
+				Command: exe,
+				Args:    launcherArgs,
+				Env:     map[string]string{},
+			}
+		}
+		// No launcher available. Resolving inline would write secret values
+		// into the config, so the MCP starts without them.
+		log.Printf("[MCP] ⚠️ %s: can't locate agent-deck to launch it with secrets: %v", name, err)
+		env = make(map[string]string, len(def.Env))
+		for k, v := range def.Env {
+			if !IsSecretRef(v) {
+				env[k] = v
+			}
+		}
+	}
+
+	return MCPServerConfig{
+		Type:    "stdio",
+		Command: def.Command,
+		Args:    args,
+		Env:     env,
+	}
+}
+
+// resolveMCPServerConfig decides how a session connects to an MCP: the HTTP
+// endpoint for URL-based MCPs, the pool's Unix socket when the MCP is pooled,
+// or a stdio launch otherwise. The result is in Claude's format; writers for
+// other tools translate it.
+func resolveMCPServerConfig(name string, def MCPDef, projectPath string, pool *mcppool.Pool) (MCPServerConfig, error) {
+	// Check if this is an HTTP/SSE MCP (has URL configured)
+	if def.URL != "" {
+		// Start HTTP server if configured
+		if def.HasAutoStartServer() {
+			if err := StartHTTPServer(name, &def); err != nil {
+				log.Printf("[MCP] ⚠️ %s: failed to start HTTP server: %v", name, err)
+				// Continue anyway - server might be external or user will troubleshoot
+			}
+		}
+
+		transport := def.Transport
+		if transport == "" {
+			transport = "http" // default to http if URL is set
+		}
+		log.Printf("[MCP] ✓ %s: using %s transport at %s", name, transport, def.URL)
+		return MCPServerConfig{
+			Type:    transport,
+			URL:     def.URL,
+			Headers: secretHeaderPlaceholders(name, def.Headers),
+		}, nil
+	}
+
+	// Project-scoped secrets can't go through the shared pool (it runs with global secrets)
+	if mcpUsesProjectSecrets(&def, projectPath) {
+		log.Printf("[MCP-POOL] %s: uses project secrets, using stdio", name)
+		return stdioServerConfig(name, def, projectPath), nil
+	}
+
+	// Check if pool exists and should pool this MCP (stdio only)
+	if pool != nil && pool.ShouldPool(name) {
+		// Check if socket is ready NOW - don't block waiting (Issue #36)
+		if pool.IsRunning(name) {
+			// Use Unix socket (nc connects to socket proxy)
+			socketPath := pool.GetSocketPath(name)
+			log.Printf("[MCP-POOL] ✓ %s: using socket %s", name, socketPath)
+			return MCPServerConfig{
+				Command: "nc",
+				Args:    []string{"-U", socketPath},
+			}, nil
+		}
+
+		// Socket not ready - check fallback policy
+		if !pool.FallbackEnabled() {
+			log.Printf("[MCP-POOL] ✗ %s: socket not ready, fallback disabled", name)
+			return MCPServerConfig{}, fmt.Errorf("MCP '%s' socket not ready. Options:\n"+
+				"  1. Enable fallback: set fallback_to_stdio = true in config.toml\n"+
+				"  2. Wait for pool to initialize and try again\n"+
+				"  3. Check MCP is running: ls /tmp/agentdeck-mcp-%s.sock", name, name)
+		}
+		log.Printf("[MCP-POOL] ⚠️ %s: socket not ready - falling back to stdio", name)
+	} else if pool != nil && !pool.ShouldPool(name) {
+		// MCP is explicitly excluded from pool - use stdio
+		log.Printf("[MCP-POOL] %s: excluded from pool, using stdio", name)
+	} else if pool == nil {
+		// Pool not initialized (CLI mode) - try to discover external sockets from TUI
+		config, _ := LoadUserConfig()
+		if config != nil && config.MCPPool.Enabled {
+			// Try to find existing socket from TUI's pool
+			if socketPath := getExternalSocketPath(name); socketPath != "" {
+				log.Printf("[MCP-POOL] ✓ %s: discovered external socket %s", name, socketPath)
+				return MCPServerConfig{
+					Command: "nc",
+					Args:    []string{"-U", socketPath},
+				}, nil
+			}
+			// Socket not found - check fallback policy
+			if !config.MCPPool.FallbackStdio {
+				log.Printf("[MCP-POOL] ✗ %s: pool enabled but socket not found - fallback disabled", name)
+				return MCPServerConfig{}, fmt.Errorf("MCP '%s' socket not found. Options:\n"+
+					"  1. Enable fallback: set fallback_to_stdio = true in config.toml\n"+
+					"  2. Start TUI to initialize pool: agent-deck\n"+
+					"  3. Check socket exists: ls /tmp/agentdeck-mcp-%s.sock", name, name)
+			}
+			log.Printf("[MCP-POOL] ⚠️ %s: socket not found, falling back to stdio", name)
+		} else {
+			log.Printf("[MCP-POOL] %s: pool disabled, using stdio", name)
+		}
+	}
+
+	// Fallback to stdio mode (pool disabled, excluded, or socket failed with fallback enabled)
+	log.Printf("[MCP-POOL] ⚠️ %s: using stdio (NOT pooled)", name)
+	return stdioServerConfig(name, def, projectPath), nil
+}
+
 // WriteMCPJsonFromConfig writes enabled MCPs from config.toml to project's .mcp.json
 func WriteMCPJsonFromConfig(projectPath string, enabledNames []string) error {
 	mcpFile := filepath.Join(projectPath, ".mcp.json")
@@ -57,98 +196,11 @@ func WriteMCPJsonFromConfig(projectPath string, enabledNames []string) error {
 
 	for _, name := range enabledNames {
 		if def, ok := availableMCPs[name]; ok {
-			// Check if this is an HTTP/SSE MCP (has URL configured)
-			if def.URL != "" {
-				// Start HTTP server if configured
-				if def.HasAutoStartServer() {
-					if err := StartHTTPServer(name, &def); err != nil {
-						log.Printf("[MCP] ⚠️ %s: failed to start HTTP server: %v", name, err)
-						// Continue anyway - server might be external or user will troubleshoot
-					}
-				}
-
-				transport := def.Transport
-				if transport == "" {
-					transport = "http" // default to http if URL is set
-				}
-				mcpConfig.MCPServers[name] = MCPServerConfig{
-					Type:    transport,
-					URL:     def.URL,
-					Headers: def.Headers,
-				}
-				log.Printf("[MCP] ✓ %s: using %s transport at %s", name, transport, def.URL)
-				continue
+			cfg, err := resolveMCPServerConfig(name, def, projectPath, pool)
+			if err != nil {
+				return err
 			}
-
-			// Check if pool exists and should pool this MCP (stdio only)
-			if pool != nil && pool.ShouldPool(name) {
-				// Check if socket is ready NOW - don't block waiting (Issue #36)
-				if pool.IsRunning(name) {
-					// Use Unix socket (nc connects to socket proxy)
-					socketPath := pool.GetSocketPath(name)
-					mcpConfig.MCPServers[name] = MCPServerConfig{
-						Command: "nc",
-						Args:    []string{"-U", socketPath},
-					}
-					log.Printf("[MCP-POOL] ✓ %s: using socket %s", name, socketPath)
-					continue
-				}
-
-				// Socket not ready - check fallback policy
-				if !pool.FallbackEnabled() {
-					log.Printf("[MCP-POOL] ✗ %s: socket not ready, fallback disabled", name)
-					return fmt.Errorf("MCP '%s' socket not ready. Options:\n"+
-						"  1. Enable fallback: set fallback_to_stdio = true in config.toml\n"+
-						"  2. Wait for pool to initialize and try again\n"+
-						"  3. Check MCP is running: ls /tmp/agentdeck-mcp-%s.sock", name, name)
-				}
-				log.Printf("[MCP-POOL] ⚠️ %s: socket not ready - falling back to stdio", name)
-			} else if pool != nil && !pool.ShouldPool(name) {
-				// MCP is explicitly excluded from pool - use stdio
-				log.Printf("[MCP-POOL] %s: excluded from pool, using stdio", name)
-			} else if pool == nil {
-				// Pool not initialized (CLI mode) - try to discover external sockets from TUI
-				config, _ := LoadUserConfig()
-				if config != nil && config.MCPPool.Enabled {
-					// Try to find existing socket from TUI's pool
-					if socketPath := getExternalSocketPath(name); socketPath != "" {
-						mcpConfig.MCPServers[name] = MCPServerConfig{
-							Command: "nc",
-							Args:    []string{"-U", socketPath},
-						}
-						log.Printf("[MCP-POOL] ✓ %s: discovered external socket %s", name, socketPath)
-						continue
-					}
-					// Socket not found - check fallback policy
-					if !config.MCPPool.FallbackStdio {
-						log.Printf("[MCP-POOL] ✗ %s: pool enabled but socket not found - fallback disabled", name)
-						return fmt.Errorf("MCP '%s' socket not found. Options:\n"+
-							"  1. Enable fallback: set fallback_to_stdio = true in config.toml\n"+
-							"  2. Start TUI to initialize pool: agent-deck\n"+
-							"  3. Check socket exists: ls /tmp/agentdeck-mcp-%s.sock", name, name)
-					}
-					log.Printf("[MCP-POOL] ⚠️ %s: socket not found, falling back to stdio", name)
-				} else {
-					log.Printf("[MCP-POOL] %s: pool disabled, using stdio", name)
-				}
-			}
-
-			// Fallback to stdio mode (pool disabled, excluded, or socket failed with fallback enabled)
-			args := def.Args
-			if args == nil {
-				args = []string{}
-			}
-			env := def.Env
-			if env == nil {
-				env = map[string]string{}
-			}
-			mcpConfig.MCPServers[name] = MCPServerConfig{
-				Type:    "stdio",
-				Command: def.Command,
-				Args:    args,
-				Env:     env,
-			}
-			log.Printf("[MCP-POOL] ⚠️ %s: using stdio (NOT pooled)", name)
+			mcpConfig.MCPServers[name] = cfg
 		}
 	}
 
@@ -210,7 +262,7 @@ func WriteGlobalMCP(enabledNames []string) error {
 				mcpServers[name] = MCPServerConfig{
 					Type:    transport,
 					URL:     def.URL,
-					Headers: def.Headers,
+					Headers: secretHeaderPlaceholders(name, def.Headers),
 				}
 				log.Printf("[MCP] ✓ Global %s: using %s transport at %s", name, transport, def.URL)
 				continue
@@ -270,20 +322,7 @@ func WriteGlobalMCP(enabledNames []string) error {
 			}
 
 			// Fallback to stdio mode (pool disabled, excluded, or socket failed with fallback enabled)
-			args := def.Args
-			if args == nil {
-				args = []string{}
-			}
-			env := def.Env
-			if env == nil {
-				env = map[string]string{}
-			}
-			mcpServers[name] = MCPServerConfig{
-				Type:    "stdio",
-				Command: def.Command,
-				Args:    args,
-				Env:     env,
-			}
+			mcpServers[name] = stdioServerConfig(name, def, "")
 			log.Printf("[MCP-POOL] ⚠️ Global %s: using stdio (NOT pooled)", name)
 		}
 	}
@@ -469,7 +508,7 @@ func WriteUserMCP(enabledNames []string) error {
 				mcpServers[name] = MCPServerConfig{
 					Type:    transport,
 					URL:     def.URL,
-					Headers: def.Headers,
+					Headers: secretHeaderPlaceholders(name, def.Headers),
 				}
 				log.Printf("[MCP] ✓ User %s: using %s transport at %s", name, transport, def.URL)
 				continue
@@ -529,20 +568,7 @@ func WriteUserMCP(enabledNames []string) error {
 			}
 
 			// Fallback to stdio mode (pool disabled, excluded, or socket failed with fallback enabled)
-			args := def.Args
-			if args == nil {
-				args = []string{}
-			}
-			env := def.Env
-			if env == nil {
-				env = map[string]string{}
-			}
-			mcpServers[name] = MCPServerConfig{
-				Type:    "stdio",
-				Command: def.Command,
-				Args:    args,
-				Env:     env,
-			}
+			mcpServers[name] = stdioServerConfig(name, def, "")
 			log.Printf("[MCP-POOL] ⚠️ User %s: using stdio (NOT pooled)", name)
 		}
 	}
@@ -0,0 +1,316 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MCP import sources
+const (
+	MCPSourceClaude  = "claude"
+	MCPSourceProject = "project"
+	MCPSourceGemini  = "gemini"
+)
+
+// MCPImportSources lists the sources checked by "mcp import" by default
+var MCPImportSources = []string{MCPSourceClaude, MCPSourceProject, MCPSourceGemini}
+
+// ImportedMCP is an MCP definition found in another tool's config
+type ImportedMCP struct {
+	Name   string
+	Source string // MCPSource* constant
+	Path   string // file the definition was read from
+	Def    MCPDef
+}
+
+// MCP import statuses
+const (
+	MCPImportAdded     = "added"
+	MCPImportDuplicate = "duplicate" // same command+args (or URL) already defined
+	MCPImportConflict  = "conflict"  // name taken by a different definition
+)
+
+// MCPImportResult describes what happened to one imported MCP
+type MCPImportResult struct {
+	ImportedMCP
+	Status      string
+	DuplicateOf string // existing MCP name for duplicates and conflicts
+}
+
+var mcpNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateMCPName checks that name can be used as a [mcps.NAME] key and socket name
+func ValidateMCPName(name string) error {
+	if !mcpNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid MCP name %q: use letters, digits, '-', '_' and '.'", name)
+	}
+	return nil
+}
+
+// ValidateMCPDef checks that an MCP definition is usable
+func ValidateMCPDef(def MCPDef) error {
+	switch def.Transport {
+	case "", "stdio", "http", "sse":
+	default:
+		return fmt.Errorf("invalid transport %q: must be stdio, http or sse", def.Transport)
+	}
+	if def.URL == "" && def.Command == "" {
+		return fmt.Errorf("MCP needs a command (stdio) or a url (http/sse)")
+	}
+	if def.URL == "" && (def.Transport == "http" || def.Transport == "sse") {
+		return fmt.Errorf("transport %q requires a url", def.Transport)
+	}
+	return nil
+}
+
+// SetUserMCP adds or replaces an MCP in config.toml, keeping comments
+func SetUserMCP(name string, def MCPDef) error {
+	if err := ValidateMCPName(name); err != nil {
+		return err
+	}
+	if err := ValidateMCPDef(def); err != nil {
+		return err
+	}
+	return updateUserMCPs(func(mcps map[string]MCPDef) error {
+		mcps[name] = def
+		return nil
+	})
+}
+
+// RemoveUserMCP removes an MCP from config.toml, keeping comments
+func RemoveUserMCP(name string) error {
+	return updateUserMCPs(func(mcps map[string]MCPDef) error {
+		if _, ok := mcps[name]; !ok {
+			return fmt.Errorf("MCP %q not found in config.toml", name)
+		}
+		delete(mcps, name)
+		return nil
+	})
+}
+
+// updateUserMCPs applies fn to a copy of the configured MCPs and saves the result
+func updateUserMCPs(fn func(mcps map[string]MCPDef) error) error {
+	config, err := ReloadUserConfig()
+	if err != nil {
+		return err
+	}
+
+	// Copy so the cached (or default) config is never mutated
+	updated := *config
+	updated.MCPs = make(map[string]MCPDef, len(config.MCPs)+1)
+	for name, def := range config.MCPs {
+		updated.MCPs[name] = def
+	}
+	if err := fn(updated.MCPs); err != nil {
+		return err
+	}
+	return SaveUserConfig(&updated)
+}
+
+// ImportMCPs merges imported definitions into config.toml. Definitions whose
+// command and args (or URL) match an existing MCP are skipped, as are names
+// already used by a different definition. With dryRun nothing is written.
+func ImportMCPs(imported []ImportedMCP, dryRun bool) ([]MCPImportResult, error) {
+	config, err := ReloadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]string)
+	for name, def := range config.MCPs {
+		byKey[mcpDedupKey(def)] = name
+	}
+
+	results := make([]MCPImportResult, 0, len(imported))
+	added := make(map[string]MCPDef)
+	for _, m := range imported {
+		result := MCPImportResult{ImportedMCP: m}
+		key := mcpDedupKey(m.Def)
+		if existing, ok := byKey[key]; ok {
+			result.Status = MCPImportDuplicate
+			result.DuplicateOf = existing
+		} else if _, taken := config.MCPs[m.Name]; taken {
+			result.Status = MCPImportConflict
+			result.DuplicateOf = m.Name
+		} else if _, taken := added[m.Name]; taken {
+			result.Status = MCPImportConflict
+			result.DuplicateOf = m.Name
+		} else {
+			result.Status = MCPImportAdded
+			added[m.Name] = m.Def
+			byKey[key] = m.Name
+		}
+		results = append(results, result)
+	}
+
+	if dryRun || len(added) == 0 {
+		return results, nil
+	}
+	err = updateUserMCPs(func(mcps map[string]MCPDef) error {
+		for name, def := range added {
+			mcps[name] = def
+		}
+		return nil
+	})
+	return results, err
+}
+
+// mcpDedupKey identifies an MCP by what it runs rather than by name
+func mcpDedupKey(def MCPDef) string {
+	if def.URL != "" {
+		return "url\x00" + strings.TrimRight(def.URL, "/")
+	}
+	return "cmd\x00" + def.Command + "\x00" + strings.Join(def.Args, "\x00")
+}
+
+// DiscoverImportableMCPs reads MCP definitions from the given sources.
+// projectDir is searched for .mcp.json (and its parents up to the repo root).
+// Missing files are skipped; unreadable files return an error.
+func DiscoverImportableMCPs(sources []string, projectDir string) ([]ImportedMCP, error) {
+	var all []ImportedMCP
+	for _, source := range sources {
+		var paths []string
+		switch source {
+		case MCPSourceClaude:
+			paths = claudeConfigFiles()
+		case MCPSourceProject:
+			paths = projectMCPFiles(projectDir)
+		case MCPSourceGemini:
+			paths = []string{filepath.Join(GetGeminiConfigDir(), "settings.json")}
+		default:
+			return nil, fmt.Errorf("unknown import source %q (use %s)", source, strings.Join(MCPImportSources, ", "))
+		}
+
+		for _, path := range paths {
+			found, err := readMCPServersFile(path, source)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, found...)
+		}
+	}
+	return all, nil
+}
+
+// claudeConfigFiles returns ~/.claude.json plus the profile config when
+// CLAUDE_CONFIG_DIR points elsewhere
+func claudeConfigFiles() []string {
+	var paths []string
+	if root := GetUserMCPRootPath(); root != "" {
+		paths = append(paths, root)
+	}
+	profile := filepath.Join(GetClaudeConfigDir(), ".claude.json")
+	if len(paths) == 0 || filepath.Clean(profile) != filepath.Clean(paths[0]) {
+		paths = append(paths, profile)
+	}
+	return paths
+}
+
+// projectMCPFiles returns .mcp.json files from dir up to the enclosing git root
+func projectMCPFiles(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for {
+		path := filepath.Join(dir, ".mcp.json")
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return paths
+}
+
+// importedServerConfig is the union of Claude/.mcp.json and Gemini server fields
+type importedServerConfig struct {
+	MCPServerConfig
+	HTTPURL     string `json:"httpUrl"`     // Gemini streamable HTTP
+	Description string `json:"description"` // Gemini
+}
+
+// readMCPServersFile parses the top-level "mcpServers" object of a JSON config
+func readMCPServersFile(path, source string) ([]ImportedMCP, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var config struct {
+		MCPServers map[string]importedServerConfig `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(config.MCPServers))
+	for name := range config.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var found []ImportedMCP
+	for _, name := range names {
+		server := config.MCPServers[name]
+		if isAgentDeckManagedServer(server.MCPServerConfig) || ValidateMCPName(name) != nil {
+			continue
+		}
+		def := MCPDef{
+			Command:     server.Command,
+			Args:        server.Args,
+			Env:         server.Env,
+			Description: server.Description,
+			URL:         server.URL,
+			Headers:     server.Headers,
+		}
+		switch {
+		case server.HTTPURL != "":
+			def.URL = server.HTTPURL
+			def.Transport = "http"
+		case def.URL != "" && server.Type == "sse":
+			def.Transport = "sse"
+		case def.URL != "" && source == MCPSourceGemini:
+			def.Transport = "sse" // Gemini's "url" is SSE
+		case def.URL != "":
+			def.Transport = "http"
+		}
+		if ValidateMCPDef(def) != nil {
+			continue
+		}
+		found = append(found, ImportedMCP{Name: name, Source: source, Path: path, Def: def})
+	}
+	return found, nil
+}
+
+// isAgentDeckManagedServer reports entries agent-deck wrote itself: pool
+// socket bridges and the secret-resolving launcher
+func isAgentDeckManagedServer(server MCPServerConfig) bool {
+	if server.Command == "nc" && len(server.Args) == 2 && server.Args[0] == "-U" &&
+		strings.Contains(server.Args[1], "agentdeck-mcp-") {
+		return true
+	}
+	if len(server.Args) >= 2 && server.Args[0] == "mcp" && server.Args[1] == "exec" &&
+		strings.HasPrefix(filepath.Base(server.Command), "agent-deck") {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportMCPs(t *testing.T) {
+	path := writeTestConfig(t, commentedConfig)
+	home := os.Getenv("HOME")
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+
+	claudeJSON := `{
+  "mcpServers": {
+    "exa-search": {"command": "npx", "args": ["-y", "exa-mcp-server"]},
+    "github": {"type": "stdio", "command": "gh-mcp", "env": {"TOKEN": "x"}},
+    "pooled": {"command": "nc", "args": ["-U", "/tmp/agentdeck-mcp-pooled.sock"]}
+  },
+  "projects": {"/x": {"mcpServers": {"ignored": {"command": "nope"}}}}
+}`
+	if err := os.WriteFile(filepath.Join(home, ".claude.json"), []byte(claudeJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	project := t.TempDir()
+	if err := os.Mkdir(filepath.Join(project, ".git"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	projectJSON := `{"mcpServers": {"docs": {"type": "http", "url": "https://docs.example.com/mcp"}, "github": {"command": "other-gh"}}}`
+	if err := os.WriteFile(filepath.Join(project, ".mcp.json"), []byte(projectJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	origGemini := geminiConfigDirOverride
+	geminiConfigDirOverride = filepath.Join(home, ".gemini")
+	t.Cleanup(func() { geminiConfigDirOverride = origGemini })
+	if err := os.MkdirAll(geminiConfigDirOverride, 0700); err != nil {
+		t.Fatal(err)
+	}
+	geminiJSON := `{"theme": "dark", "mcpServers": {"stream": {"httpUrl": "https://stream.example.com/mcp"}}}`
+	if err := os.WriteFile(filepath.Join(geminiConfigDirOverride, "settings.json"), []byte(geminiJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := DiscoverImportableMCPs(MCPImportSources, project)
+	if err != nil {
+		t.Fatalf("DiscoverImportableMCPs failed: %v", err)
+	}
+	if len(found) != 5 {
+		t.Fatalf("found %d MCPs, want 5 (pool bridge skipped): %+v", len(found), found)
+	}
+
+	results, err := ImportMCPs(found, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"claude/exa-search": MCPImportDuplicate,
+		"claude/github":     MCPImportAdded,
+		"project/docs":      MCPImportAdded,
+		"project/github":    MCPImportConflict,
+		"gemini/stream":     MCPImportAdded,
+	}
+	for _, r := range results {
+		if got := want[r.Source+"/"+r.Name]; got != r.Status {
+			t.Errorf("%s/%s status = %s, want %s", r.Source, r.Name, r.Status, got)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != commentedConfig {
+		t.Fatal("dry run modified config.toml")
+	}
+
+	if _, err := ImportMCPs(found, false); err != nil {
+		t.Fatal(err)
+	}
+	mcps := GetAvailableMCPs()
+	if len(mcps) != 5 {
+		t.Errorf("config has %d MCPs after import, want 5", len(mcps))
+	}
+	if mcps["stream"].Transport != "http" || mcps["docs"].URL != "https://docs.example.com/mcp" {
+		t.Errorf("imported HTTP MCPs = %+v / %+v", mcps["stream"], mcps["docs"])
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "# Research MCPs") {
+		t.Error("import lost config comments")
+	}
+}
+
+func TestSetAndRemoveUserMCP(t *testing.T) {
+	writeTestConfig(t, commentedConfig)
+
+	if err := SetUserMCP("bad name", MCPDef{Command: "x"}); err == nil {
+		t.Error("expected invalid name error")
+	}
+	if err := SetUserMCP("empty", MCPDef{}); err == nil {
+		t.Error("expected missing command error")
+	}
+	if err := SetUserMCP("local", MCPDef{Command: "./server", Args: []string{"--stdio"}}); err != nil {
+		t.Fatalf("SetUserMCP failed: %v", err)
+	}
+	if def := GetMCPDef("local"); def == nil || def.Command != "./server" {
+		t.Fatalf("GetMCPDef(local) = %+v", def)
+	}
+
+	if err := RemoveUserMCP("local"); err != nil {
+		t.Fatalf("RemoveUserMCP failed: %v", err)
+	}
+	if GetMCPDef("local") != nil {
+		t.Error("MCP still present after remove")
+	}
+	if err := RemoveUserMCP("local"); err == nil {
+		t.Error("expected error removing missing MCP")
+	}
+}
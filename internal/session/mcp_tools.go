@@ -0,0 +1,66 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// ToolSupportsMCP returns true if agent-deck can attach MCPs to sessions of tool
+func ToolSupportsMCP(tool string) bool {
+	switch tool {
+	case "claude", "gemini", "codex", "opencode":
+		return true
+	}
+	return false
+}
+
+// ToolMCPScope returns the only scope ("local" or "global") a tool keeps its
+// MCPs in, or "" for Claude, which has local, global and user scopes.
+func ToolMCPScope(tool string) string {
+	switch tool {
+	case "gemini", "codex":
+		return "global"
+	case "opencode":
+		return "local"
+	}
+	return ""
+}
+
+// ToolMCPConfigPath returns the file MCPs are written to for a single-scope tool
+func ToolMCPConfigPath(tool, projectPath string) string {
+	switch tool {
+	case "gemini":
+		return filepath.Join(GetGeminiConfigDir(), "settings.json")
+	case "codex":
+		return GetCodexConfigPath()
+	case "opencode":
+		return GetOpenCodeConfigPath(projectPath)
+	}
+	return ""
+}
+
+// GetToolMCPNames returns the MCPs attached in a single-scope tool's config
+func GetToolMCPNames(tool, projectPath string) []string {
+	switch tool {
+	case "gemini":
+		return GetGeminiMCPNames()
+	case "codex":
+		return GetCodexMCPNames()
+	case "opencode":
+		return GetOpenCodeMCPInfo(projectPath).Local()
+	}
+	return nil
+}
+
+// WriteToolMCPs writes the attached MCPs to a single-scope tool's config
+func WriteToolMCPs(tool, projectPath string, enabledNames []string) error {
+	switch tool {
+	case "gemini":
+		return WriteGeminiMCPSettings(enabledNames)
+	case "codex":
+		return WriteCodexMCPConfig(enabledNames)
+	case "opencode":
+		return WriteOpenCodeMCPConfig(projectPath, enabledNames)
+	}
+	return fmt.Errorf("%s sessions have no single MCP config", tool)
+}
@@ -15,6 +15,7 @@ type NotificationEntry struct {
 	Title        string
 	AssignedKey  string
 	WaitingSince time.Time
+	BudgetAlert  bool // Crossed a soft or hard budget limit
 }
 
 // NotificationManager tracks waiting sessions for the notification bar
@@ -158,7 +159,11 @@ func (nm *NotificationManager) FormatBar() string {
 	var parts []string
 	for _, e := range nm.entries {
 		// No truncation - show full title, tmux will handle overflow
-		parts = append(parts, fmt.Sprintf("[%s] %s", e.AssignedKey, e.Title))
+		part := fmt.Sprintf("[%s] %s", e.AssignedKey, e.Title)
+		if e.BudgetAlert {
+			part += " ⚠"
+		}
+		parts = append(parts, part)
 	}
 
 	return "⚡ " + strings.Join(parts, " ")
@@ -170,10 +175,13 @@ func (nm *NotificationManager) SyncFromInstances(instances []*Instance, currentS
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
 
-	// Build set of currently waiting sessions (excluding current)
+	// Build set of currently waiting sessions (excluding current). Sessions
+	// past a budget limit are shown too, even while running.
 	waitingSet := make(map[string]*Instance)
 	for _, inst := range instances {
-		if inst.Status == StatusWaiting && inst.ID != currentSessionID {
+		needsUser := inst.Status == StatusWaiting || inst.Status == StatusNeedsApproval ||
+			inst.BudgetExceeded != "" || inst.BudgetWarning != ""
+		if needsUser && inst.ID != currentSessionID {
 			waitingSet[inst.ID] = inst
 		}
 	}
@@ -181,7 +189,8 @@ func (nm *NotificationManager) SyncFromInstances(instances []*Instance, currentS
 	// Remove entries that are no longer waiting
 	newEntries := make([]*NotificationEntry, 0)
 	for _, e := range nm.entries {
-		if _, stillWaiting := waitingSet[e.SessionID]; stillWaiting {
+		if inst, stillWaiting := waitingSet[e.SessionID]; stillWaiting {
+			e.BudgetAlert = inst.BudgetExceeded != "" || inst.BudgetWarning != ""
 			newEntries = append(newEntries, e)
 			delete(waitingSet, e.SessionID) // Don't re-add
 		} else {
@@ -201,6 +210,7 @@ func (nm *NotificationManager) SyncFromInstances(instances []*Instance, currentS
 			TmuxName:     tmuxName,
 			Title:        inst.Title,
 			WaitingSince: inst.GetWaitingSince(),
+			BudgetAlert:  inst.BudgetExceeded != "" || inst.BudgetWarning != "",
 		}
 		nm.entries = append(nm.entries, entry)
 		added = append(added, inst.ID)
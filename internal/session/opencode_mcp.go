@@ -0,0 +1,145 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// openCodeMCPServer is one entry of the "mcp" object in opencode.json
+type openCodeMCPServer struct {
+	Type        string            `json:"type"` // "local" or "remote"
+	Command     []string          `json:"command,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Enabled     bool              `json:"enabled"`
+}
+
+// GetOpenCodeConfigPath returns the project-level opencode.json for projectPath
+func GetOpenCodeConfigPath(projectPath string) string {
+	return filepath.Join(projectPath, "opencode.json")
+}
+
+// readOpenCodeConfig reads opencode.json as raw JSON so unknown fields survive a rewrite
+func readOpenCodeConfig(projectPath string) map[string]json.RawMessage {
+	data, err := os.ReadFile(GetOpenCodeConfigPath(projectPath))
+	if err != nil {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	return raw
+}
+
+// readOpenCodeMCPServers returns the raw "mcp" entries from the project's opencode.json
+func readOpenCodeMCPServers(projectPath string) map[string]json.RawMessage {
+	var servers map[string]json.RawMessage
+	if raw, ok := readOpenCodeConfig(projectPath)["mcp"]; ok {
+		_ = json.Unmarshal(raw, &servers)
+	}
+	return servers
+}
+
+// GetOpenCodeMCPInfo reads MCP configuration from the project's opencode.json
+// OpenCode MCPs are managed per project, so they are reported as LocalMCPs
+func GetOpenCodeMCPInfo(projectPath string) *MCPInfo {
+	info := &MCPInfo{}
+	names := make([]string, 0)
+	for name := range readOpenCodeMCPServers(projectPath) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		info.LocalMCPs = append(info.LocalMCPs, LocalMCP{Name: name, SourcePath: projectPath})
+	}
+	return info
+}
+
+// openCodeServerFromConfig translates a Claude-format server entry to OpenCode's format
+func openCodeServerFromConfig(cfg MCPServerConfig) openCodeMCPServer {
+	if cfg.URL != "" {
+		var headers map[string]string
+		if cfg.Headers != nil {
+			// OpenCode substitutes {env:VAR} rather than ${VAR}
+			headers = make(map[string]string, len(cfg.Headers))
+			for header, value := range cfg.Headers {
+				if name, ok := envPlaceholder(value); ok {
+					value = "{env:" + name + "}"
+				}
+				headers[header] = value
+			}
+		}
+		return openCodeMCPServer{Type: "remote", URL: cfg.URL, Headers: headers, Enabled: true}
+	}
+	return openCodeMCPServer{
+		Type:        "local",
+		Command:     append([]string{cfg.Command}, cfg.Args...),
+		Environment: cfg.Env,
+		Enabled:     true,
+	}
+}
+
+// WriteOpenCodeMCPConfig writes enabled MCPs to the project's opencode.json
+// Preserves other config fields; pooled MCPs use `nc -U <socket>` like Claude.
+// Names that are not in config.toml keep their existing entry.
+func WriteOpenCodeMCPConfig(projectPath string, enabledNames []string) error {
+	configFile := GetOpenCodeConfigPath(projectPath)
+
+	rawConfig := readOpenCodeConfig(projectPath)
+	if rawConfig == nil {
+		rawConfig = make(map[string]json.RawMessage)
+	}
+	current := readOpenCodeMCPServers(projectPath)
+
+	availableMCPs := GetAvailableMCPs()
+	pool := GetGlobalPool()
+
+	servers := make(map[string]interface{})
+	for _, name := range enabledNames {
+		def, ok := availableMCPs[name]
+		if !ok {
+			if raw, found := current[name]; found {
+				servers[name] = raw
+			}
+			continue
+		}
+		cfg, err := resolveMCPServerConfig(name, def, projectPath, pool)
+		if err != nil {
+			return err
+		}
+		servers[name] = openCodeServerFromConfig(cfg)
+	}
+
+	if len(servers) == 0 {
+		delete(rawConfig, "mcp")
+	} else {
+		data, err := json.Marshal(servers)
+		if err != nil {
+			return fmt.Errorf("failed to marshal mcp: %w", err)
+		}
+		rawConfig["mcp"] = data
+	}
+	if _, ok := rawConfig["$schema"]; !ok {
+		rawConfig["$schema"] = json.RawMessage(`"https://opencode.ai/config.json"`)
+	}
+
+	newData, err := json.MarshalIndent(rawConfig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal opencode.json: %w", err)
+	}
+
+	tmpPath := configFile + ".tmp"
+	if err := os.WriteFile(tmpPath, newData, 0644); err != nil {
+		return fmt.Errorf("failed to write opencode.json: %w", err)
+	}
+	if err := os.Rename(tmpPath, configFile); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save opencode.json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOpenCodeMCPConfig(t *testing.T) {
+	writeTestConfig(t, toolMCPsConfig)
+	project := t.TempDir()
+
+	existing := `{
+  "theme": "tokyonight",
+  "mcp": {
+    "handmade": {"type": "local", "command": ["my-server"], "enabled": true},
+    "exa": {"type": "local", "command": ["old"], "enabled": true}
+  }
+}`
+	configFile := filepath.Join(project, "opencode.json")
+	if err := os.WriteFile(configFile, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteOpenCodeMCPConfig(project, []string{"exa", "docs", "handmade"}); err != nil {
+		t.Fatalf("WriteOpenCodeMCPConfig: %v", err)
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Theme string                       `json:"theme"`
+		MCP   map[string]openCodeMCPServer `json:"mcp"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("written config does not parse: %v", err)
+	}
+	if config.Theme != "tokyonight" {
+		t.Errorf("theme = %q, other fields should be preserved", config.Theme)
+	}
+
+	exa := config.MCP["exa"]
+	if exa.Type != "local" || len(exa.Command) != 3 || exa.Command[0] != "npx" || exa.Environment["EXA_API_KEY"] != "key" || !exa.Enabled {
+		t.Errorf("exa = %+v, want local npx command with environment", exa)
+	}
+	docs := config.MCP["docs"]
+	if docs.Type != "remote" || docs.URL != "https://docs.example.com/mcp" || docs.Headers["Authorization"] != "Bearer t" {
+		t.Errorf("docs = %+v, want remote with headers", docs)
+	}
+	if config.MCP["handmade"].Command[0] != "my-server" {
+		t.Error("MCP not in config.toml should keep its existing entry")
+	}
+
+	info := GetOpenCodeMCPInfo(project)
+	if names := info.Local(); len(names) != 3 || names[0] != "docs" {
+		t.Errorf("GetOpenCodeMCPInfo().Local() = %v", names)
+	}
+}
@@ -0,0 +1,424 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// defaultPipelineStepTimeout bounds how long one step may take unless the
+// step sets its own timeout
+const defaultPipelineStepTimeout = 30 * time.Minute
+
+// pipelineStepNameRe keeps step names usable as {{.Steps.<name>}} in templates
+var pipelineStepNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Pipeline is a sequence of prompts handed from session to session, read from
+// a TOML file. Prompts and conditions are Go templates that can use the
+// pipeline's vars and the output of earlier steps.
+type Pipeline struct {
+	Name  string            `toml:"name" json:"name"`
+	Vars  map[string]string `toml:"vars" json:"vars,omitempty"`
+	Steps []PipelineStep    `toml:"steps" json:"steps"`
+	// Directory of the definition file; relative template paths resolve against it
+	Dir string `toml:"-" json:"dir"`
+}
+
+// PipelineStep sends one prompt to an existing session or to a new session
+// created from Template
+type PipelineStep struct {
+	Name     string            `toml:"name" json:"name"`
+	Session  string            `toml:"session" json:"session,omitempty"` // Title or ID of an existing session
+	Template *PipelineTemplate `toml:"template" json:"template,omitempty"`
+	Prompt   string            `toml:"prompt" json:"prompt"`
+	When     string            `toml:"when" json:"when,omitempty"`       // Skip the step unless this renders to something other than "", "false" or "0"
+	Timeout  string            `toml:"timeout" json:"timeout,omitempty"` // e.g. "45m" (default 30m)
+}
+
+// PipelineTemplate describes a session a step creates for itself
+type PipelineTemplate struct {
+	Tool  string `toml:"tool" json:"tool"`
+	Path  string `toml:"path" json:"path,omitempty"`   // Default: the definition file's directory
+	Title string `toml:"title" json:"title,omitempty"` // Default: <pipeline>-<step>
+	Group string `toml:"group" json:"group,omitempty"` // Default: pipeline-<pipeline>
+}
+
+// Pipeline run and step states
+const (
+	PipelineRunning     = "running"
+	PipelineDone        = "done"
+	PipelineFailed      = "failed"
+	PipelineInterrupted = "interrupted" // Running, but the process executing it is gone
+	PipelinePending     = "pending"
+	PipelineSkipped     = "skipped"
+)
+
+// PipelineRun is the persisted state of one execution of a pipeline, so a run
+// can be resumed after a crash and followed from the TUI. Runs are stored as
+// JSON in the profile's pipelines/ directory.
+type PipelineRun struct {
+	ID         string              `json:"id"`
+	File       string              `json:"file"`
+	Pipeline   Pipeline            `json:"pipeline"` // Copy of the definition the run started with
+	Status     string              `json:"status"`
+	Error      string              `json:"error,omitempty"`
+	PID        int                 `json:"pid"` // Process executing the run
+	StartedAt  time.Time           `json:"started_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	FinishedAt time.Time           `json:"finished_at,omitempty"`
+	Steps      []PipelineStepState `json:"steps"`
+}
+
+// PipelineStepState is the progress of one step of a run
+type PipelineStepState struct {
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	SessionID  string    `json:"session_id,omitempty"`
+	Prompt     string    `json:"prompt,omitempty"` // Rendered prompt
+	Sent       bool      `json:"sent"`             // Prompt reached the session; resume waits instead of resending
+	Output     string    `json:"output,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+}
+
+// PipelineStepData is what templates see of a step: {{.Steps.plan.Output}}
+type PipelineStepData struct {
+	Output string
+	Status string
+}
+
+// pipelineTemplateFuncs are available in prompts and conditions
+var pipelineTemplateFuncs = template.FuncMap{
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"trim":      strings.TrimSpace,
+}
+
+// LoadPipelineFile reads and validates a pipeline definition
+func LoadPipelineFile(path string) (*Pipeline, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Pipeline
+	if _, err := toml.DecodeFile(absPath, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	p.Dir = filepath.Dir(absPath)
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(absPath), filepath.Ext(absPath))
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks step names, targets, timeouts and template syntax. Prompts
+// and conditions are rendered once with empty outputs so unknown vars and
+// steps are reported before anything is sent.
+func (p *Pipeline) Validate() error {
+	if len(p.Steps) == 0 {
+		return errors.New("pipeline has no steps")
+	}
+	seen := make(map[string]bool)
+	empty := newPipelineRun(p, "", "")
+	for idx, step := range p.Steps {
+		label := fmt.Sprintf("step %d", idx+1)
+		if step.Name != "" {
+			label = fmt.Sprintf("step '%s'", step.Name)
+		}
+		if !pipelineStepNameRe.MatchString(step.Name) {
+			return fmt.Errorf("%s: name must be letters, digits and underscores", label)
+		}
+		if seen[step.Name] {
+			return fmt.Errorf("%s: duplicate step name", label)
+		}
+		seen[step.Name] = true
+
+		if (step.Session == "") == (step.Template == nil) {
+			return fmt.Errorf("%s: set either session or [template]", label)
+		}
+		if step.Template != nil && step.Template.Tool == "" {
+			return fmt.Errorf("%s: template needs a tool", label)
+		}
+		if strings.TrimSpace(step.Prompt) == "" {
+			return fmt.Errorf("%s: prompt is required", label)
+		}
+		if _, err := step.StepTimeout(); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		if _, err := empty.RenderPrompt(idx); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		if _, err := empty.ShouldRun(idx); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// StepTimeout returns how long the step may take
+func (s PipelineStep) StepTimeout() (time.Duration, error) {
+	if s.Timeout == "" {
+		return defaultPipelineStepTimeout, nil
+	}
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", s.Timeout)
+	}
+	return d, nil
+}
+
+// TemplateSession returns the tool, path, title and group of the session a
+// template step creates, with defaults filled in
+func (p *Pipeline) TemplateSession(step PipelineStep) (tool, path, title, group string) {
+	t := step.Template
+	path = t.Path
+	if path == "" {
+		path = p.Dir
+	} else if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(p.Dir, path)
+	}
+	title = t.Title
+	if title == "" {
+		title = fmt.Sprintf("%s-%s", p.Name, step.Name)
+	}
+	group = t.Group
+	if group == "" {
+		group = "pipeline-" + p.Name
+	}
+	return t.Tool, filepath.Clean(path), title, group
+}
+
+// NewPipelineRun creates the state of a new run of p
+func NewPipelineRun(p *Pipeline, file string) *PipelineRun {
+	return newPipelineRun(p, time.Now().Format("20060102-150405"), file)
+}
+
+// newPipelineRun creates run state with every step pending
+func newPipelineRun(p *Pipeline, stamp, file string) *PipelineRun {
+	run := &PipelineRun{
+		ID:        p.Name + "-" + stamp,
+		File:      file,
+		Pipeline:  *p,
+		Status:    PipelineRunning,
+		PID:       os.Getpid(),
+		StartedAt: time.Now(),
+	}
+	for _, step := range p.Steps {
+		run.Steps = append(run.Steps, PipelineStepState{Name: step.Name, Status: PipelinePending})
+	}
+	return run
+}
+
+// templateData is what prompts and conditions are rendered with
+func (r *PipelineRun) templateData() map[string]interface{} {
+	steps := make(map[string]PipelineStepData, len(r.Steps))
+	for _, s := range r.Steps {
+		steps[s.Name] = PipelineStepData{Output: s.Output, Status: s.Status}
+	}
+	vars := r.Pipeline.Vars
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	return map[string]interface{}{
+		"Vars":     vars,
+		"Steps":    steps,
+		"Pipeline": r.Pipeline.Name,
+	}
+}
+
+// render executes a prompt or condition template against the run's state
+func (r *PipelineRun) render(name, text string) (string, error) {
+	tmpl, err := template.New(name).Funcs(pipelineTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r.templateData()); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderPrompt renders step idx's prompt with the outputs so far
+func (r *PipelineRun) RenderPrompt(idx int) (string, error) {
+	prompt, err := r.render("prompt", r.Pipeline.Steps[idx].Prompt)
+	if err != nil {
+		return "", fmt.Errorf("prompt: %w", err)
+	}
+	return strings.TrimSpace(prompt), nil
+}
+
+// ShouldRun evaluates step idx's condition; steps without one always run
+func (r *PipelineRun) ShouldRun(idx int) (bool, error) {
+	when := r.Pipeline.Steps[idx].When
+	if when == "" {
+		return true, nil
+	}
+	result, err := r.render("when", when)
+	if err != nil {
+		return false, fmt.Errorf("when: %w", err)
+	}
+	switch strings.TrimSpace(result) {
+	case "", "false", "0", "<no value>":
+		return false, nil
+	}
+	return true, nil
+}
+
+// NextStep returns the index of the first step that hasn't finished, or -1
+func (r *PipelineRun) NextStep() int {
+	for idx, s := range r.Steps {
+		if s.Status != PipelineDone && s.Status != PipelineSkipped {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Interrupted reports whether the run is marked running but the process
+// executing it has exited (crash, killed terminal)
+func (r *PipelineRun) Interrupted() bool {
+	if r.Status != PipelineRunning {
+		return false
+	}
+	return !processAlive(r.PID)
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// DisplayStatus is Status, with runs whose process is gone shown as interrupted
+func (r *PipelineRun) DisplayStatus() string {
+	if r.Interrupted() {
+		return PipelineInterrupted
+	}
+	return r.Status
+}
+
+// GetPipelineRunsDir returns the directory pipeline runs are stored in for a profile
+func GetPipelineRunsDir(profile string) (string, error) {
+	profileDir, err := GetProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(profileDir, "pipelines"), nil
+}
+
+// pipelineRunPath returns the state file path of a run
+func pipelineRunPath(profile, id string) (string, error) {
+	dir, err := GetPipelineRunsDir(profile)
+	if err != nil {
+		return "", err
+	}
+	id = filepath.Base(id)
+	if id == "." || id == ".." || id == "" {
+		return "", fmt.Errorf("invalid pipeline run: %q", id)
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+// Save writes the run state; it is called after every step transition
+func (r *PipelineRun) Save(profile string) error {
+	path, err := pipelineRunPath(profile, r.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create pipelines dir: %w", err)
+	}
+	r.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pipeline run: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write pipeline run: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save pipeline run: %w", err)
+	}
+	return nil
+}
+
+// LoadPipelineRun reads a run's state by ID
+func LoadPipelineRun(profile, id string) (*PipelineRun, error) {
+	path, err := pipelineRunPath(profile, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("pipeline run '%s' not found", id)
+		}
+		return nil, err
+	}
+	var run PipelineRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline run '%s': %w", id, err)
+	}
+	return &run, nil
+}
+
+// ListPipelineRuns returns all runs of a profile, newest first
+func ListPipelineRuns(profile string) ([]*PipelineRun, error) {
+	dir, err := GetPipelineRunsDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var runs []*PipelineRun
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		run, err := LoadPipelineRun(profile, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(a, b int) bool {
+		return runs[a].StartedAt.After(runs[b].StartedAt)
+	})
+	return runs, nil
+}
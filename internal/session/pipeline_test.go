@@ -0,0 +1,143 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePipelineFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "review.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPipelineFile(t *testing.T) {
+	path := writePipelineFile(t, `
+[vars]
+task = "add retries"
+
+[[steps]]
+name = "plan"
+session = "planner"
+prompt = "Plan: {{.Vars.task}}"
+
+[[steps]]
+name = "implement"
+template = { tool = "claude", path = "src" }
+prompt = "Implement:\n{{.Steps.plan.Output}}"
+timeout = "1h"
+
+[[steps]]
+name = "fix"
+session = "implementer"
+when = '{{contains .Steps.implement.Output "FAILED"}}'
+prompt = "Fix it"
+`)
+	p, err := LoadPipelineFile(path)
+	if err != nil {
+		t.Fatalf("LoadPipelineFile() error = %v", err)
+	}
+	if p.Name != "review" {
+		t.Errorf("Name = %q, want the file name when unset", p.Name)
+	}
+
+	tool, dir, title, group := p.TemplateSession(p.Steps[1])
+	if tool != "claude" || dir != filepath.Join(filepath.Dir(path), "src") || title != "review-implement" || group != "pipeline-review" {
+		t.Errorf("TemplateSession() = %q, %q, %q, %q", tool, dir, title, group)
+	}
+
+	invalid := map[string]string{
+		"no steps":        `name = "x"`,
+		"bad name":        "[[steps]]\nname = \"my-step\"\nsession = \"a\"\nprompt = \"p\"",
+		"no target":       "[[steps]]\nname = \"a\"\nprompt = \"p\"",
+		"both targets":    "[[steps]]\nname = \"a\"\nsession = \"s\"\ntemplate = { tool = \"claude\" }\nprompt = \"p\"",
+		"unknown var":     "[[steps]]\nname = \"a\"\nsession = \"s\"\nprompt = \"{{.Vars.nope}}\"",
+		"unknown step":    "[[steps]]\nname = \"a\"\nsession = \"s\"\nprompt = \"{{.Steps.nope.Output}}\"",
+		"bad timeout":     "[[steps]]\nname = \"a\"\nsession = \"s\"\nprompt = \"p\"\ntimeout = \"soon\"",
+		"duplicate names": "[[steps]]\nname = \"a\"\nsession = \"s\"\nprompt = \"p\"\n[[steps]]\nname = \"a\"\nsession = \"s\"\nprompt = \"p\"",
+	}
+	for name, content := range invalid {
+		if _, err := LoadPipelineFile(writePipelineFile(t, content)); err == nil {
+			t.Errorf("%s: LoadPipelineFile() should fail", name)
+		}
+	}
+}
+
+func TestPipelineRunTemplates(t *testing.T) {
+	p := &Pipeline{
+		Name: "review",
+		Vars: map[string]string{"task": "add retries"},
+		Steps: []PipelineStep{
+			{Name: "plan", Session: "planner", Prompt: "Plan: {{.Vars.task}}"},
+			{Name: "review", Session: "reviewer", Prompt: "Review:\n{{.Steps.plan.Output}}"},
+			{Name: "fix", Session: "planner", Prompt: "Fix", When: `{{contains .Steps.review.Output "CHANGES REQUESTED"}}`},
+		},
+	}
+	run := NewPipelineRun(p, "review.toml")
+	if !strings.HasPrefix(run.ID, "review-") || run.NextStep() != 0 {
+		t.Fatalf("new run = %+v", run)
+	}
+
+	if prompt, _ := run.RenderPrompt(0); prompt != "Plan: add retries" {
+		t.Errorf("RenderPrompt(0) = %q", prompt)
+	}
+
+	run.Steps[0].Status = PipelineDone
+	run.Steps[0].Output = "1. retry on 503"
+	if prompt, _ := run.RenderPrompt(1); prompt != "Review:\n1. retry on 503" {
+		t.Errorf("RenderPrompt(1) = %q, want the plan step's output", prompt)
+	}
+	if run.NextStep() != 1 {
+		t.Errorf("NextStep() = %d, want 1", run.NextStep())
+	}
+
+	run.Steps[1].Status = PipelineDone
+	run.Steps[1].Output = "LGTM"
+	if ok, err := run.ShouldRun(2); ok || err != nil {
+		t.Errorf("ShouldRun(2) = %v, %v; want false for an approving review", ok, err)
+	}
+	run.Steps[1].Output = "CHANGES REQUESTED: handle 429 too"
+	if ok, err := run.ShouldRun(2); !ok || err != nil {
+		t.Errorf("ShouldRun(2) = %v, %v; want true when changes were requested", ok, err)
+	}
+}
+
+func TestPipelineRunPersistence(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	p := &Pipeline{Name: "persist", Steps: []PipelineStep{{Name: "one", Session: "s", Prompt: "p"}}}
+	run := NewPipelineRun(p, "persist.toml")
+	run.Steps[0].Status = PipelineRunning
+	run.Steps[0].Sent = true
+	if err := run.Save("_test"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadPipelineRun("_test", run.ID)
+	if err != nil {
+		t.Fatalf("LoadPipelineRun() error = %v", err)
+	}
+	if !loaded.Steps[0].Sent || loaded.Pipeline.Steps[0].Prompt != "p" {
+		t.Errorf("loaded run = %+v, want the saved step state and definition", loaded)
+	}
+	// Saved by this (live) process
+	if loaded.Interrupted() {
+		t.Error("a run owned by a live process should not be interrupted")
+	}
+	loaded.PID = 0
+	if loaded.DisplayStatus() != PipelineInterrupted {
+		t.Errorf("DisplayStatus() = %q, want interrupted when the runner is gone", loaded.DisplayStatus())
+	}
+
+	runs, err := ListPipelineRuns("_test")
+	if err != nil || len(runs) != 1 || runs[0].ID != run.ID {
+		t.Errorf("ListPipelineRuns() = %v, %v", runs, err)
+	}
+	if _, err := LoadPipelineRun("_test", "missing"); err == nil {
+		t.Error("LoadPipelineRun() of a missing run should fail")
+	}
+}
@@ -0,0 +1,386 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
+	"github.com/asheshgoplani/agent-deck/internal/platform"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// poolDaemonRefreshInterval is how often the standalone pool recounts session references
+const poolDaemonRefreshInterval = 15 * time.Second
+
+// PoolDaemonRequest is a command sent to the standalone pool process over its control socket
+type PoolDaemonRequest struct {
+	Command string `json:"command"` // status, stop, restart, acquire
+	Name    string `json:"name,omitempty"`
+}
+
+// PoolDaemonServer describes one MCP managed by the standalone pool
+type PoolDaemonServer struct {
+	Name       string    `json:"name"`
+	SocketPath string    `json:"socket_path"`
+	Status     string    `json:"status"`
+	Clients    int       `json:"clients"`
+	Sessions   int       `json:"sessions"`
+	IdleSince  time.Time `json:"idle_since,omitempty"`
+	External   bool      `json:"external,omitempty"`
+
+	Health *mcppool.ProbeResult `json:"health,omitempty"` // Latest protocol-level probe
+}
+
+// PoolDaemonResponse is the reply to a PoolDaemonRequest
+type PoolDaemonResponse struct {
+	OK          bool               `json:"ok"`
+	Error       string             `json:"error,omitempty"`
+	PID         int                `json:"pid,omitempty"`
+	StartedAt   time.Time          `json:"started_at,omitempty"`
+	IdleTimeout int                `json:"idle_timeout_seconds,omitempty"`
+	SocketPath  string             `json:"socket_path,omitempty"`
+	Servers     []PoolDaemonServer `json:"servers,omitempty"`
+}
+
+// GetPoolDaemonSocketPath returns the control socket path of the standalone pool (~/.agent-deck/pool.sock)
+func GetPoolDaemonSocketPath() (string, error) {
+	dir, err := GetAgentDeckDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pool.sock"), nil
+}
+
+// SendPoolDaemonRequest sends a single request to the standalone pool and waits for its reply
+func SendPoolDaemonRequest(req PoolDaemonRequest, timeout time.Duration) (*PoolDaemonResponse, error) {
+	socketPath, err := GetPoolDaemonSocketPath()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", socketPath, 500*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("pool process not running: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send pool request: %w", err)
+	}
+
+	var resp PoolDaemonResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read pool response: %w", err)
+	}
+	if !resp.OK {
+		return &resp, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// IsPoolDaemonRunning reports whether a standalone pool process is answering on its control socket
+func IsPoolDaemonRunning() bool {
+	socketPath, err := GetPoolDaemonSocketPath()
+	if err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("unix", socketPath, 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// acquireFromPoolDaemon asks the standalone pool to make sure an MCP is running
+// and returns its socket path, or "" if no pool process is available.
+func acquireFromPoolDaemon(name string) string {
+	if !IsPoolDaemonRunning() {
+		return ""
+	}
+	resp, err := SendPoolDaemonRequest(PoolDaemonRequest{Command: "acquire", Name: name}, 15*time.Second)
+	if err != nil {
+		log.Printf("[Pool] Standalone pool could not start %s: %v", name, err)
+		return ""
+	}
+	for _, srv := range resp.Servers {
+		if srv.Name == name {
+			return srv.SocketPath
+		}
+	}
+	return ""
+}
+
+// CollectMCPReferences counts, across all profiles, how many running sessions
+// have each MCP loaded (per Instance.LoadedMCPNames).
+func CollectMCPReferences() map[string]int {
+	refs := make(map[string]int)
+
+	profiles, err := ListProfiles()
+	if err != nil || len(profiles) == 0 {
+		profiles = []string{DefaultProfile}
+	}
+
+	tmux.RefreshSessionCache()
+	for _, profile := range profiles {
+		storage, err := NewStorageWithProfile(profile)
+		if err != nil {
+			log.Printf("[Pool] Failed to open profile %s: %v", profile, err)
+			continue
+		}
+		instances, _, err := storage.LoadWithGroups()
+		if err != nil {
+			log.Printf("[Pool] Failed to load sessions for profile %s: %v", profile, err)
+			continue
+		}
+		for _, inst := range instances {
+			if len(inst.LoadedMCPNames) == 0 || !inst.Exists() {
+				continue
+			}
+			for _, name := range inst.LoadedMCPNames {
+				refs[name]++
+			}
+		}
+	}
+	return refs
+}
+
+// PoolDaemon is the standalone MCP pool process. It owns the socket proxies,
+// tracks which MCPs running sessions use, and stops MCPs nobody references.
+type PoolDaemon struct {
+	pool        *mcppool.Pool
+	idleTimeout time.Duration
+	socketPath  string
+	startedAt   time.Time
+
+	// refMu serializes reference refreshes with acquire requests so an MCP
+	// that was just acquired is not stopped by a stale idle check
+	refMu sync.Mutex
+}
+
+// RunPoolDaemon runs the standalone pool until ctx is cancelled or a stop request arrives
+func RunPoolDaemon(ctx context.Context) error {
+	config, err := ReloadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !config.MCPPool.Enabled {
+		return errors.New("MCP pool is disabled (set [mcp_pool] enabled = true in config.toml)")
+	}
+	if !platform.SupportsUnixSockets() {
+		return fmt.Errorf("MCP socket pooling is not supported on platform '%s'", platform.Detect())
+	}
+	if IsPoolDaemonRunning() {
+		return errors.New("pool process is already running")
+	}
+
+	socketPath, err := GetPoolDaemonSocketPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return fmt.Errorf("failed to create agent-deck directory: %w", err)
+	}
+	// Any socket left here is stale (IsPoolDaemonRunning just failed to connect)
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pool, err := mcppool.NewPool(ctx, &mcppool.PoolConfig{
+		Enabled:       true,
+		PoolAll:       config.MCPPool.PoolAll,
+		ExcludeMCPs:   config.MCPPool.ExcludeMCPs,
+		PoolMCPs:      config.MCPPool.PoolMCPs,
+		FallbackStdio: true, // See InitializeGlobalPool (Issue #36)
+		// Sockets of a TUI-owned pool die with the TUI, so the pool runs
+		// every MCP it can under its own process
+		TakeOverSockets: true,
+	})
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	d := &PoolDaemon{
+		pool:        pool,
+		idleTimeout: config.MCPPool.GetIdleTimeout(),
+		socketPath:  socketPath,
+		startedAt:   time.Now(),
+	}
+
+	log.Printf("[Pool] Standalone pool started (pid %d, idle timeout %v)", os.Getpid(), d.idleTimeout)
+
+	// Start every pooled MCP, taking over the sockets of a TUI-owned pool.
+	// Sockets of MCPs no longer in config.toml can't be restarted and are
+	// only adopted until their owner exits.
+	for name, def := range GetAvailableMCPs() {
+		if def.URL != "" || !d.pool.ShouldPool(name) {
+			continue
+		}
+		if err := startPooledMCP(d.pool, name, def); err != nil {
+			log.Printf("[Pool] ✗ Failed to start socket proxy for %s: %v", name, err)
+		}
+	}
+	d.pool.DiscoverExistingSockets()
+	d.pool.StartHealthMonitor()
+	d.refresh()
+
+	go d.serve(listener, cancel)
+
+	ticker := time.NewTicker(poolDaemonRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			listener.Close()
+			os.Remove(socketPath)
+			log.Printf("[Pool] Standalone pool stopping")
+			return d.pool.Shutdown()
+		case <-ticker.C:
+			d.refresh()
+		}
+	}
+}
+
+// refresh recounts session references and stops MCPs that have been idle too long
+func (d *PoolDaemon) refresh() {
+	refs := CollectMCPReferences()
+
+	d.refMu.Lock()
+	defer d.refMu.Unlock()
+
+	d.pool.SetReferences(refs)
+	if d.idleTimeout > 0 {
+		if stopped := d.pool.StopIdle(d.idleTimeout); len(stopped) > 0 {
+			log.Printf("[Pool] Idle shutdown: %v", stopped)
+		}
+	}
+}
+
+// serve accepts control connections until the listener is closed
+func (d *PoolDaemon) serve(listener net.Listener, stop context.CancelFunc) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handleConn(conn, stop)
+	}
+}
+
+func (d *PoolDaemon) handleConn(conn net.Conn, stop context.CancelFunc) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return // Liveness probe (IsPoolDaemonRunning) connects and closes
+	}
+
+	var req PoolDaemonRequest
+	resp := &PoolDaemonResponse{OK: true}
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp = &PoolDaemonResponse{Error: fmt.Sprintf("invalid request: %v", err)}
+	} else if err := d.handle(req); err != nil {
+		resp = &PoolDaemonResponse{Error: err.Error()}
+	}
+	d.fillStatus(resp)
+	_ = json.NewEncoder(conn).Encode(resp)
+
+	if req.Command == "stop" && resp.OK {
+		stop()
+	}
+}
+
+// handle executes a single control request
+func (d *PoolDaemon) handle(req PoolDaemonRequest) error {
+	switch req.Command {
+	case "status", "stop":
+		return nil
+	case "restart":
+		if req.Name == "" {
+			return errors.New("MCP name is required")
+		}
+		d.refMu.Lock()
+		defer d.refMu.Unlock()
+		if err := d.pool.Stop(req.Name); err != nil {
+			log.Printf("[Pool] %s was not running, starting fresh", req.Name)
+		}
+		return d.start(req.Name)
+	case "acquire":
+		if req.Name == "" {
+			return errors.New("MCP name is required")
+		}
+		d.refMu.Lock()
+		defer d.refMu.Unlock()
+		if d.pool.IsRunning(req.Name) && !d.pool.IsExternal(req.Name) {
+			return nil
+		}
+		return d.start(req.Name)
+	default:
+		return fmt.Errorf("unknown command '%s'", req.Command)
+	}
+}
+
+// start launches a socket proxy for an MCP using the current config.toml definition
+func (d *PoolDaemon) start(name string) error {
+	// Re-read config so MCPs added since the pool started are available
+	_, _ = ReloadUserConfig()
+	def, ok := GetAvailableMCPs()[name]
+	if !ok {
+		return fmt.Errorf("MCP '%s' not found in config.toml", name)
+	}
+	if def.URL != "" {
+		return fmt.Errorf("MCP '%s' is an HTTP MCP (use 'agent-deck mcp server')", name)
+	}
+	if !d.pool.ShouldPool(name) {
+		return fmt.Errorf("MCP '%s' is excluded from the pool", name)
+	}
+	if err := startPooledMCP(d.pool, name, def); err != nil {
+		return fmt.Errorf("failed to start %s: %w", name, err)
+	}
+	log.Printf("[Pool] ✓ Socket proxy started: %s", name)
+	return nil
+}
+
+// fillStatus adds pool state to a response
+func (d *PoolDaemon) fillStatus(resp *PoolDaemonResponse) {
+	resp.PID = os.Getpid()
+	resp.StartedAt = d.startedAt
+	resp.IdleTimeout = int(d.idleTimeout / time.Second)
+	resp.SocketPath = d.socketPath
+
+	servers := d.pool.ListServers()
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	resp.Servers = make([]PoolDaemonServer, 0, len(servers))
+	for _, srv := range servers {
+		resp.Servers = append(resp.Servers, PoolDaemonServer{
+			Name:       srv.Name,
+			SocketPath: srv.SocketPath,
+			Status:     srv.Status,
+			Clients:    srv.Clients,
+			Sessions:   srv.Refs,
+			IdleSince:  srv.IdleSince,
+			External:   srv.External,
+			Health:     srv.Probe,
+		})
+	}
+}
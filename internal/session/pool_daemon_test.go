@@ -0,0 +1,110 @@
+package session
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
+)
+
+// startTestPoolDaemon serves the control protocol on ~/.agent-deck/pool.sock under a temp HOME
+func startTestPoolDaemon(t *testing.T) (stopped <-chan struct{}) {
+	t.Helper()
+
+	// Short path: Unix socket paths are limited to ~104 bytes on macOS
+	tempHome, err := os.MkdirTemp("/tmp", "adpool")
+	if err != nil {
+		t.Fatalf("MkdirTemp failed: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempHome) })
+	t.Setenv("HOME", tempHome)
+
+	socketPath, err := GetPoolDaemonSocketPath()
+	if err != nil {
+		t.Fatalf("GetPoolDaemonSocketPath failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	pool, _ := mcppool.NewPool(context.Background(), &mcppool.PoolConfig{Enabled: true})
+	t.Cleanup(func() { _ = pool.Shutdown() })
+
+	d := &PoolDaemon{pool: pool, idleTimeout: time.Minute, socketPath: socketPath, startedAt: time.Now()}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go d.serve(listener, cancel)
+	return ctx.Done()
+}
+
+func TestPoolDaemon_NotRunning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if IsPoolDaemonRunning() {
+		t.Fatal("expected no pool process")
+	}
+	if _, err := SendPoolDaemonRequest(PoolDaemonRequest{Command: "status"}, time.Second); err == nil {
+		t.Error("expected error when pool process is not running")
+	}
+	if path := acquireFromPoolDaemon("exa"); path != "" {
+		t.Errorf("acquireFromPoolDaemon() = %q, want empty", path)
+	}
+}
+
+func TestPoolDaemon_ControlProtocol(t *testing.T) {
+	stopped := startTestPoolDaemon(t)
+
+	if !IsPoolDaemonRunning() {
+		t.Fatal("expected pool process to be detected")
+	}
+
+	resp, err := SendPoolDaemonRequest(PoolDaemonRequest{Command: "status"}, time.Second)
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if resp.PID != os.Getpid() || resp.IdleTimeout != 60 {
+		t.Errorf("status = pid %d idle %d, want pid %d idle 60", resp.PID, resp.IdleTimeout, os.Getpid())
+	}
+
+	if _, err := SendPoolDaemonRequest(PoolDaemonRequest{Command: "bogus"}, time.Second); err == nil {
+		t.Error("expected error for unknown command")
+	}
+	if _, err := SendPoolDaemonRequest(PoolDaemonRequest{Command: "restart"}, time.Second); err == nil {
+		t.Error("expected error for restart without MCP name")
+	}
+
+	if _, err := SendPoolDaemonRequest(PoolDaemonRequest{Command: "stop"}, time.Second); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("stop request did not cancel the pool")
+	}
+}
+
+func TestMCPPoolSettings_GetIdleTimeout(t *testing.T) {
+	tests := []struct {
+		value int
+		want  time.Duration
+	}{
+		{0, 600 * time.Second},
+		{-1, 0},
+		{30, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		s := MCPPoolSettings{IdleTimeout: tt.value}
+		if got := s.GetIdleTimeout(); got != tt.want {
+			t.Errorf("GetIdleTimeout(%d) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -49,6 +50,16 @@ func InitializeGlobalPool(ctx context.Context, config *UserConfig, sessions []*I
 	}
 
 	log.Printf("[Pool] Platform '%s' detected - socket pooling supported", detectedPlatform)
+
+	// A standalone pool process (agent-deck mcp pool start) owns the sockets.
+	// Don't start a second pool here: session configs discover its sockets via
+	// getExternalSocketPath, and quitting this instance can't stop MCPs in use.
+	if IsPoolDaemonRunning() {
+		log.Printf("[Pool] Standalone pool process is running - using its sockets")
+		globalHTTPPool = startHTTPPool(ctx, GetAvailableMCPs())
+		return nil, nil
+	}
+
 	log.Printf("[Pool] Pool enabled, creating pool...")
 
 	// Create pool config
@@ -106,7 +117,7 @@ func InitializeGlobalPool(ctx context.Context, config *UserConfig, sessions []*I
 
 		// Start socket proxy for this MCP
 		log.Printf("[Pool] Starting socket proxy for %s...", mcpName)
-		if err := pool.Start(mcpName, def.Command, def.Args, def.Env); err != nil {
+		if err := startPooledMCP(pool, mcpName, def); err != nil {
 			log.Printf("[Pool] ✗ Failed to start socket proxy for %s: %v", mcpName, err)
 		} else {
 			log.Printf("[Pool] ✓ Socket proxy started: %s", mcpName)
@@ -121,6 +132,23 @@ func InitializeGlobalPool(ctx context.Context, config *UserConfig, sessions []*I
 
 	globalPool = pool
 
+	globalHTTPPool = startHTTPPool(ctx, availableMCPs)
+
+	return pool, nil
+}
+
+// startPooledMCP starts a socket proxy with secret references in env resolved.
+// Pooled MCPs are shared across projects, so only global secrets apply.
+func startPooledMCP(pool *mcppool.Pool, name string, def MCPDef) error {
+	env, err := ResolveMCPEnv(&def, "")
+	if err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	return pool.Start(name, def.Command, def.Args, env)
+}
+
+// startHTTPPool creates the HTTP pool and starts servers for HTTP/SSE MCPs with auto-start servers
+func startHTTPPool(ctx context.Context, availableMCPs map[string]MCPDef) *mcppool.HTTPPool {
 	// Initialize HTTP pool for HTTP/SSE MCPs with auto-start servers
 	httpPool := mcppool.NewHTTPPool(ctx)
 	httpStarted := 0
@@ -144,9 +172,7 @@ func InitializeGlobalPool(ctx context.Context, config *UserConfig, sessions []*I
 		log.Printf("[HTTP-Pool] Started %d HTTP servers", httpStarted)
 		httpPool.StartHealthMonitor()
 	}
-	globalHTTPPool = httpPool
-
-	return pool, nil
+	return httpPool
 }
 
 // GetGlobalPool returns the global socket pool instance (may be nil if disabled)
@@ -163,6 +189,38 @@ func GetGlobalHTTPPool() *mcppool.HTTPPool {
 	return globalHTTPPool
 }
 
+// GetMCPHealth returns the latest health probe per MCP name from the in-process
+// socket and HTTP pools, or from the standalone pool process when it owns the sockets.
+// MCPs that have not been probed yet are absent.
+func GetMCPHealth() map[string]*mcppool.ProbeResult {
+	health := make(map[string]*mcppool.ProbeResult)
+
+	if pool := GetGlobalPool(); pool != nil {
+		for _, info := range pool.ListServers() {
+			if info.Probe != nil {
+				health[info.Name] = info.Probe
+			}
+		}
+	} else if IsPoolDaemonRunning() {
+		if resp, err := SendPoolDaemonRequest(PoolDaemonRequest{Command: "status"}, time.Second); err == nil {
+			for _, srv := range resp.Servers {
+				if srv.Health != nil {
+					health[srv.Name] = srv.Health
+				}
+			}
+		}
+	}
+
+	if httpPool := GetGlobalHTTPPool(); httpPool != nil {
+		for _, info := range httpPool.ListServers() {
+			if info.Probe != nil {
+				health[info.Name] = info.Probe
+			}
+		}
+	}
+	return health
+}
+
 // GetGlobalPoolRunningCount returns the number of running MCPs in the global pool
 func GetGlobalPoolRunningCount() int {
 	globalPoolMu.RLock()
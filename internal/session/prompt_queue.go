@@ -0,0 +1,306 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// QueuedPrompt is a prompt waiting to be sent to a session. Queued prompts
+// are delivered one at a time, each once the agent is back to waiting.
+type QueuedPrompt struct {
+	ID       string    `json:"id"`
+	Text     string    `json:"text"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// inputReadyPolls is how many status polls in a row a running agent must be
+// waiting for input before a prompt is typed into it
+const inputReadyPolls = 3
+
+// queueTurnTimeout bounds how long delivery waits for a delivered prompt to
+// start a turn before sending the next one anyway
+const queueTurnTimeout = 2 * time.Minute
+
+// Prompt queue state shared by every copy of an Instance: the TUI replaces
+// its instances on reload, and a delivery in progress must not be repeated
+// by the new copy
+var (
+	promptQueueMu sync.Mutex
+	// Sessions with a delivery in progress
+	queueDeliveryInFlight = make(map[string]bool)
+	// Sessions whose last delivered prompt hasn't started a turn yet
+	queueAwaitingTurn = make(map[string]time.Time)
+	// Prompts already delivered, so a stale copy of the queue drops them
+	deliveredPrompts = make(map[string]time.Time)
+)
+
+// newQueuedPromptID returns a short random ID for a queued prompt
+func newQueuedPromptID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// EnqueuePrompt adds a prompt to the end of the session's queue
+func (i *Instance) EnqueuePrompt(text string) QueuedPrompt {
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+	prompt := QueuedPrompt{ID: newQueuedPromptID(), Text: text, QueuedAt: time.Now()}
+	i.PromptQueue = append(i.PromptQueue, prompt)
+	return prompt
+}
+
+// QueuedPrompts returns a copy of the session's queue, next prompt first
+func (i *Instance) QueuedPrompts() []QueuedPrompt {
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+	if len(i.PromptQueue) == 0 {
+		return nil
+	}
+	return append([]QueuedPrompt(nil), i.PromptQueue...)
+}
+
+// queueIndex returns the position of a prompt in the queue, or -1.
+// Caller must hold promptQueueMu.
+func (i *Instance) queueIndex(id string) int {
+	for idx, p := range i.PromptQueue {
+		if p.ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+// RemoveQueuedPrompt drops a prompt from the queue. Returns false if it is
+// no longer queued (e.g. it was just delivered).
+func (i *Instance) RemoveQueuedPrompt(id string) bool {
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+	idx := i.queueIndex(id)
+	if idx < 0 {
+		return false
+	}
+	i.PromptQueue = append(i.PromptQueue[:idx], i.PromptQueue[idx+1:]...)
+	return true
+}
+
+// UpdateQueuedPrompt replaces the text of a queued prompt
+func (i *Instance) UpdateQueuedPrompt(id, text string) bool {
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+	idx := i.queueIndex(id)
+	if idx < 0 {
+		return false
+	}
+	i.PromptQueue[idx].Text = text
+	return true
+}
+
+// MoveQueuedPrompt moves a prompt delta places towards the end of the queue
+// (negative moves it towards the front)
+func (i *Instance) MoveQueuedPrompt(id string, delta int) bool {
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+	idx := i.queueIndex(id)
+	target := idx + delta
+	if idx < 0 || target < 0 || target >= len(i.PromptQueue) {
+		return false
+	}
+	prompt := i.PromptQueue[idx]
+	i.PromptQueue = append(i.PromptQueue[:idx], i.PromptQueue[idx+1:]...)
+	i.PromptQueue = append(i.PromptQueue[:target], append([]QueuedPrompt{prompt}, i.PromptQueue[target:]...)...)
+	return true
+}
+
+// ClearPromptQueue drops every queued prompt and returns how many there were
+func (i *Instance) ClearPromptQueue() int {
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+	n := len(i.PromptQueue)
+	i.PromptQueue = nil
+	return n
+}
+
+// TakeQueueDelivered returns true once after a queued prompt was delivered,
+// so the owner of the instance knows to persist the shorter queue
+func (i *Instance) TakeQueueDelivered() bool {
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+	delivered := i.queueDelivered
+	i.queueDelivered = false
+	return delivered
+}
+
+// dropDeliveredPrompts removes prompts another copy of this instance has
+// already sent. Caller must hold promptQueueMu.
+func (i *Instance) dropDeliveredPrompts() {
+	kept := i.PromptQueue[:0]
+	for _, p := range i.PromptQueue {
+		if _, sent := deliveredPrompts[p.ID]; sent {
+			i.queueDelivered = true
+			continue
+		}
+		kept = append(kept, p)
+	}
+	i.PromptQueue = kept
+	for id, at := range deliveredPrompts {
+		if time.Since(at) > time.Hour {
+			delete(deliveredPrompts, id)
+		}
+	}
+}
+
+// markPromptsSynced adds the prompts queued in a sessions file that was just
+// read or written to synced
+func markPromptsSynced(synced map[string]bool, instances []*InstanceData) {
+	for _, data := range instances {
+		for _, p := range data.PromptQueue {
+			synced[p.ID] = true
+		}
+	}
+}
+
+// mergePromptQueues brings changes other processes made to the queues on
+// disk into instances before they are saved: prompts they added are appended
+// and prompts they removed or delivered are dropped. synced holds the prompts
+// that were on disk when this process last read or wrote the file, so changes
+// made here since then are kept.
+func mergePromptQueues(instances []*Instance, disk []*InstanceData, synced map[string]bool) {
+	onDisk := make(map[string][]QueuedPrompt, len(disk))
+	for _, data := range disk {
+		onDisk[data.ID] = data.PromptQueue
+	}
+
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+	for _, inst := range instances {
+		diskQueue, ok := onDisk[inst.ID]
+		if !ok {
+			continue
+		}
+		diskIDs := make(map[string]bool, len(diskQueue))
+		for _, p := range diskQueue {
+			diskIDs[p.ID] = true
+		}
+
+		merged := make([]QueuedPrompt, 0, len(inst.PromptQueue))
+		queued := make(map[string]bool, len(inst.PromptQueue))
+		for _, p := range inst.PromptQueue {
+			if synced[p.ID] && !diskIDs[p.ID] {
+				continue // Removed or delivered by another process
+			}
+			merged = append(merged, p)
+			queued[p.ID] = true
+		}
+		for _, p := range diskQueue {
+			if !synced[p.ID] && !queued[p.ID] {
+				merged = append(merged, p) // Added by another process
+			}
+		}
+		if len(merged) == 0 {
+			merged = nil
+		}
+		inst.PromptQueue = merged
+	}
+}
+
+// updatePromptQueue is called from UpdateStatus. Once a delivered prompt has
+// started a turn (the session is running) and the agent is waiting again,
+// the next queued prompt is sent in the background.
+func (i *Instance) updatePromptQueue() {
+	promptQueueMu.Lock()
+	defer promptQueueMu.Unlock()
+
+	if i.Status == StatusRunning {
+		delete(queueAwaitingTurn, i.ID)
+	}
+	if len(i.PromptQueue) == 0 || queueDeliveryInFlight[i.ID] {
+		return
+	}
+	i.dropDeliveredPrompts()
+	if len(i.PromptQueue) == 0 {
+		return
+	}
+	if i.Status != StatusWaiting && i.Status != StatusIdle {
+		return
+	}
+	if sentAt, ok := queueAwaitingTurn[i.ID]; ok && time.Since(sentAt) < queueTurnTimeout {
+		return
+	}
+
+	queueDeliveryInFlight[i.ID] = true
+	prompt := i.PromptQueue[0]
+	go i.deliverQueuedPrompt(prompt)
+}
+
+// deliverQueuedPrompt sends the head of the queue once the agent is ready and
+// removes it. On failure the prompt stays queued for the next attempt.
+func (i *Instance) deliverQueuedPrompt(prompt QueuedPrompt) {
+	defer func() {
+		promptQueueMu.Lock()
+		delete(queueDeliveryInFlight, i.ID)
+		promptQueueMu.Unlock()
+	}()
+
+	if err := i.waitForInput(); err != nil {
+		log.Printf("[QUEUE] %s: not delivering queued prompt: %v", i.Title, err)
+		return
+	}
+
+	// The prompt may have been removed or edited while we waited
+	promptQueueMu.Lock()
+	idx := i.queueIndex(prompt.ID)
+	if idx != 0 {
+		promptQueueMu.Unlock()
+		return
+	}
+	prompt = i.PromptQueue[0]
+	promptQueueMu.Unlock()
+
+	if err := i.sendKeys(prompt.Text); err != nil {
+		log.Printf("[QUEUE] %s: %v", i.Title, err)
+		return
+	}
+
+	promptQueueMu.Lock()
+	if idx := i.queueIndex(prompt.ID); idx >= 0 {
+		i.PromptQueue = append(i.PromptQueue[:idx], i.PromptQueue[idx+1:]...)
+	}
+	deliveredPrompts[prompt.ID] = time.Now()
+	queueAwaitingTurn[i.ID] = time.Now()
+	i.queueDelivered = true
+	promptQueueMu.Unlock()
+	log.Printf("[QUEUE] %s: delivered queued prompt %s", i.Title, prompt.ID)
+}
+
+// waitForInput blocks until an agent that is already running waits for input.
+// Unlike waitUntilReady, which follows a session that was just started
+// through loading, it doesn't need to see the agent active first, and a
+// waiting session the user has already seen (idle) counts as waiting.
+func (i *Instance) waitForInput() error {
+	if i.tmuxSession == nil {
+		return fmt.Errorf("tmux session not initialized")
+	}
+
+	waitingCount := 0
+	maxAttempts := 150 // 30 seconds max (150 * 200ms)
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		time.Sleep(200 * time.Millisecond)
+
+		status, err := i.tmuxSession.GetStatus()
+		if err != nil || (status != "waiting" && status != "idle") {
+			waitingCount = 0
+			continue
+		}
+		waitingCount++
+		if waitingCount >= inputReadyPolls {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("timeout waiting for agent to wait for input")
+}
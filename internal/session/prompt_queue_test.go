@@ -0,0 +1,133 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func queueTexts(inst *Instance) []string {
+	var texts []string
+	for _, p := range inst.QueuedPrompts() {
+		texts = append(texts, p.Text)
+	}
+	return texts
+}
+
+func TestPromptQueueEditing(t *testing.T) {
+	inst := &Instance{ID: "queue-edit", Title: "queue"}
+	first := inst.EnqueuePrompt("first")
+	second := inst.EnqueuePrompt("second")
+	third := inst.EnqueuePrompt("third")
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("queued prompts need distinct IDs, got %q and %q", first.ID, second.ID)
+	}
+
+	if !inst.MoveQueuedPrompt(third.ID, -2) {
+		t.Fatal("MoveQueuedPrompt() to the front failed")
+	}
+	if got := queueTexts(inst); len(got) != 3 || got[0] != "third" || got[1] != "first" || got[2] != "second" {
+		t.Errorf("queue after move = %v, want [third first second]", got)
+	}
+	if inst.MoveQueuedPrompt(third.ID, -1) {
+		t.Error("moving the head further forward should fail")
+	}
+
+	if !inst.UpdateQueuedPrompt(first.ID, "first, edited") {
+		t.Error("UpdateQueuedPrompt() failed")
+	}
+	if !inst.RemoveQueuedPrompt(second.ID) || inst.RemoveQueuedPrompt(second.ID) {
+		t.Error("RemoveQueuedPrompt() should succeed once")
+	}
+	if got := queueTexts(inst); len(got) != 2 || got[1] != "first, edited" {
+		t.Errorf("queue = %v, want [third, first, edited]", got)
+	}
+
+	if n := inst.ClearPromptQueue(); n != 2 || len(inst.QueuedPrompts()) != 0 {
+		t.Errorf("ClearPromptQueue() = %d, queue %v", n, queueTexts(inst))
+	}
+}
+
+func TestPromptQueueDropsDeliveredPrompts(t *testing.T) {
+	// A reloaded copy of the instance still lists a prompt the old copy sent
+	inst := &Instance{ID: "queue-reload", Title: "queue", Status: StatusRunning}
+	sent := inst.EnqueuePrompt("already sent")
+	inst.EnqueuePrompt("still queued")
+
+	promptQueueMu.Lock()
+	deliveredPrompts[sent.ID] = time.Now()
+	promptQueueMu.Unlock()
+	defer func() {
+		promptQueueMu.Lock()
+		delete(deliveredPrompts, sent.ID)
+		promptQueueMu.Unlock()
+	}()
+
+	// Running: nothing is delivered, but the stale prompt is dropped
+	inst.updatePromptQueue()
+	if got := queueTexts(inst); len(got) != 1 || got[0] != "still queued" {
+		t.Errorf("queue = %v, want only the undelivered prompt", got)
+	}
+	if !inst.TakeQueueDelivered() || inst.TakeQueueDelivered() {
+		t.Error("TakeQueueDelivered() should report the shorter queue exactly once")
+	}
+}
+
+func TestPromptQueuePersists(t *testing.T) {
+	s := &Storage{path: filepath.Join(t.TempDir(), "sessions.json"), profile: "_test"}
+	inst := &Instance{ID: "queue-save", Title: "queue", ProjectPath: "/tmp/queue", Tool: "shell", CreatedAt: time.Now()}
+	inst.EnqueuePrompt("after restart")
+	if err := s.SaveWithGroups([]*Instance{inst}, nil); err != nil {
+		t.Fatalf("SaveWithGroups() error = %v", err)
+	}
+
+	data, _, err := s.LoadLite()
+	if err != nil || len(data) != 1 {
+		t.Fatalf("LoadLite() = %v, %v", data, err)
+	}
+	if q := data[0].PromptQueue; len(q) != 1 || q[0].Text != "after restart" {
+		t.Errorf("saved queue = %+v, want the queued prompt", q)
+	}
+}
+
+func TestPromptQueueMergesConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	tui := &Storage{path: path, profile: "_test"}
+	cli := &Storage{path: path, profile: "_test"}
+
+	inst := &Instance{ID: "queue-merge", Title: "queue", ProjectPath: "/tmp/queue", Tool: "shell", CreatedAt: time.Now()}
+	first := inst.EnqueuePrompt("first")
+	if err := tui.SaveWithGroups([]*Instance{inst}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The CLI loads the sessions and queues a prompt...
+	cliInstances, _, err := cli.LoadWithGroups()
+	if err != nil || len(cliInstances) != 1 {
+		t.Fatalf("LoadWithGroups() = %v, %v", cliInstances, err)
+	}
+	cliInstances[0].EnqueuePrompt("from cli")
+
+	// ...while the TUI delivers the first prompt, queues another and saves
+	inst.RemoveQueuedPrompt(first.ID)
+	inst.EnqueuePrompt("from tui")
+	if err := tui.SaveWithGroups([]*Instance{inst}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The CLI's save keeps the TUI's changes
+	if err := cli.SaveWithGroups(cliInstances, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := queueTexts(cliInstances[0]); len(got) != 2 || got[0] != "from cli" || got[1] != "from tui" {
+		t.Errorf("CLI queue = %v, want [from cli, from tui]", got)
+	}
+
+	// And the TUI's next save keeps the CLI's prompt
+	if err := tui.SaveWithGroups([]*Instance{inst}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := queueTexts(inst); len(got) != 2 || got[0] != "from tui" || got[1] != "from cli" {
+		t.Errorf("TUI queue = %v, want [from tui, from cli]", got)
+	}
+}
@@ -0,0 +1,351 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// raceCheckTimeout caps how long a race's check command may run per contestant
+const raceCheckTimeout = 10 * time.Minute
+
+// Race is the same prompt attempted by several agents, each in its own worktree.
+// Races are stored as JSON in the profile's races/ directory.
+type Race struct {
+	Name         string           `json:"name"`
+	Prompt       string           `json:"prompt"`
+	RepoRoot     string           `json:"repo_root"`
+	BaseBranch   string           `json:"base_branch"` // Branch the worktrees were created from
+	BaseCommit   string           `json:"base_commit"` // Its HEAD at race start (diff base)
+	CheckCommand string           `json:"check_command,omitempty"`
+	GroupPath    string           `json:"group_path"`
+	CreatedAt    time.Time        `json:"created_at"`
+	Contestants  []RaceContestant `json:"contestants"`
+}
+
+// RaceContestant is one agent taking part in a race
+type RaceContestant struct {
+	Name         string `json:"name"` // Unique within the race: tool name, suffixed for repeats (claude-2)
+	Tool         string `json:"tool"`
+	SessionID    string `json:"session_id"`
+	Branch       string `json:"branch"`
+	WorktreePath string `json:"worktree_path"`
+}
+
+// RaceResult is the comparison data for one contestant
+type RaceResult struct {
+	Contestant  RaceContestant
+	Status      Status
+	Diff        git.DiffStat
+	DiffErr     error
+	Checked     bool   // Check command was run
+	CheckPassed bool   // Check command exited 0
+	CheckOutput string // Last lines of check output
+	Tokens      int    // Total tokens (0 if the tool has no analytics)
+	Cost        float64
+	Elapsed     time.Duration // Race start until the agent's last activity
+}
+
+// GetRacesDir returns the directory races are stored in for a profile
+func GetRacesDir(profile string) (string, error) {
+	profileDir, err := GetProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(profileDir, "races"), nil
+}
+
+// raceFilePath returns the manifest path for a race
+func raceFilePath(profile, name string) (string, error) {
+	dir, err := GetRacesDir(profile)
+	if err != nil {
+		return "", err
+	}
+	name = filepath.Base(name)
+	if name == "." || name == ".." || name == "" {
+		return "", fmt.Errorf("invalid race name: %q", name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// RaceContestantNames returns a unique contestant name per tool entry:
+// claude,claude,gemini -> claude, claude-2, gemini
+func RaceContestantNames(tools []string) []string {
+	seen := make(map[string]int)
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		seen[tool]++
+		if seen[tool] == 1 {
+			names = append(names, tool)
+		} else {
+			names = append(names, tool+"-"+strconv.Itoa(seen[tool]))
+		}
+	}
+	return names
+}
+
+// NewRace creates a worktree and an unstarted session per tool, all branched
+// from the current HEAD of repoDir and grouped under a new "race-<name>" group.
+// If any worktree fails, the ones already created are removed again.
+func NewRace(name, prompt, repoDir string, tools []string, checkCommand, location string) (*Race, []*Instance, error) {
+	if len(tools) == 0 {
+		return nil, nil, errors.New("at least one tool is required")
+	}
+	if !git.IsGitRepo(repoDir) {
+		return nil, nil, fmt.Errorf("%s is not a git repository", repoDir)
+	}
+	repoRoot, err := git.GetRepoRoot(repoDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseBranch, err := git.GetCurrentBranch(repoRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseCommit, err := git.GetHeadCommit(repoRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	if location == "" {
+		location = GetRepoWorktreeSettings(repoRoot).DefaultLocation
+	}
+
+	race := &Race{
+		Name:         name,
+		Prompt:       prompt,
+		RepoRoot:     repoRoot,
+		BaseBranch:   baseBranch,
+		BaseCommit:   baseCommit,
+		CheckCommand: checkCommand,
+		GroupPath:    "race-" + name,
+		CreatedAt:    time.Now(),
+	}
+
+	var instances []*Instance
+	cleanup := func() {
+		for _, c := range race.Contestants {
+			_ = git.RemoveWorktree(repoRoot, c.WorktreePath, true)
+			_ = git.DeleteBranch(repoRoot, c.Branch, true)
+		}
+	}
+
+	for idx, contestant := range RaceContestantNames(tools) {
+		tool := tools[idx]
+		branch := fmt.Sprintf("race/%s/%s", name, contestant)
+		if err := git.ValidateBranchName(branch); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("invalid race name: %w", err)
+		}
+		if git.BranchExists(repoRoot, branch) {
+			cleanup()
+			return nil, nil, fmt.Errorf("branch %s already exists", branch)
+		}
+
+		worktreePath := git.GenerateWorktreePath(repoRoot, branch, location)
+		if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to create parent directory: %w", err)
+		}
+		if err := git.CreateWorktree(repoRoot, worktreePath, branch); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+
+		inst := NewInstanceWithGroupAndTool(fmt.Sprintf("%s:%s", name, contestant), worktreePath, race.GroupPath, tool)
+		inst.Command = tool
+		if toolDef := GetToolDef(tool); toolDef != nil {
+			inst.Command = toolDef.Command
+		}
+		inst.WorktreePath = worktreePath
+		inst.WorktreeRepoRoot = repoRoot
+		inst.WorktreeBranch = branch
+		race.Contestants = append(race.Contestants, RaceContestant{
+			Name:         contestant,
+			Tool:         tool,
+			SessionID:    inst.ID,
+			Branch:       branch,
+			WorktreePath: worktreePath,
+		})
+		if err := inst.PrepareWorktree(); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		instances = append(instances, inst)
+	}
+
+	return race, instances, nil
+}
+
+// Save writes the race manifest to the profile's races directory
+func (r *Race) Save(profile string) error {
+	path, err := raceFilePath(profile, r.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create races dir: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal race: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write race: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save race: %w", err)
+	}
+	return nil
+}
+
+// LoadRace reads a race manifest by name
+func LoadRace(profile, name string) (*Race, error) {
+	path, err := raceFilePath(profile, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("race '%s' not found", name)
+		}
+		return nil, err
+	}
+	var race Race
+	if err := json.Unmarshal(data, &race); err != nil {
+		return nil, fmt.Errorf("failed to parse race '%s': %w", name, err)
+	}
+	return &race, nil
+}
+
+// ListRaces returns all races of a profile, newest first
+func ListRaces(profile string) ([]*Race, error) {
+	dir, err := GetRacesDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var races []*Race
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		race, err := LoadRace(profile, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		races = append(races, race)
+	}
+	sort.Slice(races, func(a, b int) bool {
+		return races[a].CreatedAt.After(races[b].CreatedAt)
+	})
+	return races, nil
+}
+
+// DeleteRace removes a race manifest (sessions and worktrees are not touched)
+func DeleteRace(profile, name string) error {
+	path, err := raceFilePath(profile, name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Contestant returns the contestant with the given name
+func (r *Race) Contestant(name string) (RaceContestant, bool) {
+	for _, c := range r.Contestants {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return RaceContestant{}, false
+}
+
+// Compare collects diff stats, usage and (if runCheck) check results for every
+// contestant. instances maps session IDs to loaded instances; contestants whose
+// session is gone are still reported from their worktree.
+func (r *Race) Compare(instances map[string]*Instance, runCheck bool) []RaceResult {
+	results := make([]RaceResult, 0, len(r.Contestants))
+	for _, c := range r.Contestants {
+		result := RaceResult{Contestant: c, Status: StatusError}
+		result.Diff, result.DiffErr = git.GetDiffStat(c.WorktreePath, r.BaseCommit)
+
+		if inst := instances[c.SessionID]; inst != nil {
+			_ = inst.UpdateStatus()
+			result.Status = inst.Status
+			result.Tokens, result.Cost, result.Elapsed = sessionUsage(inst, r.CreatedAt)
+		}
+
+		if runCheck && r.CheckCommand != "" && result.DiffErr == nil {
+			result.Checked = true
+			result.CheckPassed, result.CheckOutput = runRaceCheck(c.WorktreePath, r.CheckCommand)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runRaceCheck runs the race's check command in a worktree and returns whether
+// it passed and the tail of its output
+func runRaceCheck(dir, command string) (bool, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), raceCheckTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+
+	lines := strings.Split(strings.TrimRight(output.String(), "\n"), "\n")
+	if len(lines) > 5 {
+		lines = lines[len(lines)-5:]
+	}
+	tail := strings.Join(lines, "\n")
+	if ctx.Err() == context.DeadlineExceeded {
+		tail = fmt.Sprintf("timed out after %s\n%s", raceCheckTimeout, tail)
+	}
+	return err == nil, tail
+}
+
+// DiscardContestant stops a contestant's session and deletes its worktree and
+// branch, uncommitted work included. The caller removes the instance.
+func (r *Race) DiscardContestant(c RaceContestant, inst *Instance) error {
+	if inst != nil && inst.Exists() {
+		if err := inst.Kill(); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(c.WorktreePath); err == nil {
+		if err := git.RemoveWorktree(r.RepoRoot, c.WorktreePath, true); err != nil {
+			return err
+		}
+	}
+	if git.BranchExists(r.RepoRoot, c.Branch) {
+		return git.DeleteBranch(r.RepoRoot, c.Branch, true)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/git/gittest"
+)
+
+func TestRaceContestantNames(t *testing.T) {
+	got := RaceContestantNames([]string{"claude", "gemini", "claude", "claude"})
+	want := []string{"claude", "gemini", "claude-2", "claude-3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RaceContestantNames() = %v, want %v", got, want)
+	}
+}
+
+// newTestRace creates a repo and a race with claude and shell contestants
+func newTestRace(t *testing.T, check string) (*Race, []*Instance, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	ClearUserConfigCache()
+	t.Cleanup(ClearUserConfigCache)
+
+	repo := gittest.NewRepo(t)
+	race, instances, err := NewRace("fix", "fix the bug", repo, []string{"claude", "shell"}, check, "sibling")
+	if err != nil {
+		t.Fatalf("NewRace() failed: %v", err)
+	}
+	return race, instances, repo
+}
+
+func TestNewRace(t *testing.T) {
+	race, instances, repo := newTestRace(t, "")
+
+	if race.BaseBranch != "main" || race.GroupPath != "race-fix" || race.RepoRoot != repo {
+		t.Errorf("unexpected race: %+v", race)
+	}
+	if len(instances) != 2 || len(race.Contestants) != 2 {
+		t.Fatalf("expected 2 contestants, got %d/%d", len(instances), len(race.Contestants))
+	}
+	for idx, c := range race.Contestants {
+		inst := instances[idx]
+		if inst.ID != c.SessionID || inst.Tool != c.Tool || inst.GroupPath != "race-fix" || inst.WorktreeBranch != c.Branch {
+			t.Errorf("instance does not match contestant %s: %+v", c.Name, inst)
+		}
+		if _, err := os.Stat(c.WorktreePath); err != nil {
+			t.Errorf("worktree for %s missing: %v", c.Name, err)
+		}
+	}
+	if race.Contestants[0].Branch != "race/fix/claude" {
+		t.Errorf("unexpected branch %q", race.Contestants[0].Branch)
+	}
+
+	// A second race with the same name would reuse the branches
+	if _, _, err := NewRace("fix", "again", repo, []string{"gemini", "claude"}, "", "sibling"); err == nil {
+		t.Fatal("expected error for existing branch")
+	}
+	if git.BranchExists(repo, "race/fix/gemini") {
+		t.Error("worktrees created before the failure should be rolled back")
+	}
+}
+
+func TestRace_SaveLoadListDelete(t *testing.T) {
+	race, _, _ := newTestRace(t, "")
+
+	if err := race.Save("default"); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := LoadRace("default", "fix")
+	if err != nil {
+		t.Fatalf("LoadRace() failed: %v", err)
+	}
+	if loaded.Prompt != race.Prompt || !reflect.DeepEqual(loaded.Contestants, race.Contestants) {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+
+	races, err := ListRaces("default")
+	if err != nil || len(races) != 1 {
+		t.Fatalf("ListRaces() = %d races, err %v", len(races), err)
+	}
+
+	if err := DeleteRace("default", "fix"); err != nil {
+		t.Fatalf("DeleteRace() failed: %v", err)
+	}
+	if _, err := LoadRace("default", "fix"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestRace_CompareAndDiscard(t *testing.T) {
+	race, instances, repo := newTestRace(t, "test -f done.txt")
+
+	claude := race.Contestants[0]
+	gittest.CommitFile(t, claude.WorktreePath, "done.txt", "one\ntwo\n")
+
+	byID := map[string]*Instance{}
+	for _, inst := range instances {
+		byID[inst.ID] = inst
+	}
+	results := race.Compare(byID, true)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if r := results[0]; r.Diff != (git.DiffStat{Files: 1, Insertions: 2}) || !r.Checked || !r.CheckPassed {
+		t.Errorf("unexpected result for claude: %+v", r)
+	}
+	if r := results[1]; r.Diff.Files != 0 || !r.Checked || r.CheckPassed {
+		t.Errorf("unexpected result for shell: %+v", r)
+	}
+
+	if err := race.DiscardContestant(claude, byID[claude.SessionID]); err != nil {
+		t.Fatalf("DiscardContestant() failed: %v", err)
+	}
+	if _, err := os.Stat(claude.WorktreePath); !os.IsNotExist(err) {
+		t.Error("worktree should be removed")
+	}
+	if git.BranchExists(repo, claude.Branch) {
+		t.Error("branch should be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(repo, "done.txt")); !os.IsNotExist(err) {
+		t.Error("discarded work must not reach the repository")
+	}
+}
@@ -0,0 +1,156 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// ProfileSession is a session together with the profile it belongs to
+type ProfileSession struct {
+	Profile  string
+	Instance *Instance
+}
+
+// RepoWorktree is one worktree of a repository and the sessions working in it
+type RepoWorktree struct {
+	git.Worktree
+	Main     bool        // The original clone rather than a linked worktree
+	Status   *git.Status // Branch and changes (nil if git status failed)
+	Sessions []ProfileSession
+}
+
+// Dirty returns true if the worktree has uncommitted or untracked changes
+func (w *RepoWorktree) Dirty() bool {
+	return w.Status.Dirty()
+}
+
+// RepoStatus is every worktree of a repository with the sessions (from any
+// profile) that work in them
+type RepoStatus struct {
+	Root      string // Main worktree path
+	Worktrees []RepoWorktree
+	// Sessions created in a worktree of this repo that no longer exists
+	Orphaned []ProfileSession
+}
+
+// SessionCount returns the number of sessions working in the repository
+func (r *RepoStatus) SessionCount() int {
+	count := len(r.Orphaned)
+	for _, wt := range r.Worktrees {
+		count += len(wt.Sessions)
+	}
+	return count
+}
+
+// LoadAllProfileSessions loads the sessions of every profile. Profiles that
+// fail to load are skipped.
+func LoadAllProfileSessions() ([]ProfileSession, error) {
+	profiles, err := ListProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var sessions []ProfileSession
+	for _, profile := range profiles {
+		storage, err := NewStorageWithProfile(profile)
+		if err != nil {
+			continue
+		}
+		instances, _, err := storage.LoadWithGroups()
+		if err != nil {
+			continue
+		}
+		for _, inst := range instances {
+			sessions = append(sessions, ProfileSession{Profile: profile, Instance: inst})
+		}
+	}
+	return sessions, nil
+}
+
+// RepoWorktreePaths returns the normalized paths of every worktree of the
+// repository containing dir, main worktree first
+func RepoWorktreePaths(dir string) (root string, paths []string, err error) {
+	root, err = git.GetMainWorktreePath(dir)
+	if err != nil {
+		return "", nil, errors.New("not a git repository")
+	}
+	worktrees, err := git.ListWorktrees(root)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, wt := range worktrees {
+		if !wt.Bare {
+			paths = append(paths, normalizePath(wt.Path))
+		}
+	}
+	return normalizePath(root), paths, nil
+}
+
+// WorktreeForPath returns the index of the worktree path containing path, or
+// -1. Nested worktrees (e.g. under .worktrees/) win over the repo containing them.
+func WorktreeForPath(path string, worktreePaths []string) int {
+	path = normalizePath(path)
+	best := -1
+	for idx, wt := range worktreePaths {
+		if path != wt && !strings.HasPrefix(path, wt+string(filepath.Separator)) {
+			continue
+		}
+		if best == -1 || len(wt) > len(worktreePaths[best]) {
+			best = idx
+		}
+	}
+	return best
+}
+
+// GetRepoStatus collects every worktree of the repository containing dir and
+// matches sessions against them by project path. With withGitStatus, each
+// worktree's branch and uncommitted changes are read too.
+func GetRepoStatus(dir string, sessions []ProfileSession, withGitStatus bool) (*RepoStatus, error) {
+	root, err := git.GetMainWorktreePath(dir)
+	if err != nil {
+		return nil, errors.New("not a git repository")
+	}
+	worktrees, err := git.ListWorktrees(root)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RepoStatus{Root: root}
+	var paths []string
+	for _, wt := range worktrees {
+		if wt.Bare {
+			continue
+		}
+		rw := RepoWorktree{Worktree: wt, Main: normalizePath(wt.Path) == normalizePath(root)}
+		if withGitStatus {
+			if st, err := git.GetStatus(wt.Path); err == nil {
+				rw.Status = st
+			}
+		}
+		status.Worktrees = append(status.Worktrees, rw)
+		paths = append(paths, normalizePath(wt.Path))
+	}
+
+	normalizedRoot := normalizePath(root)
+	for _, ps := range sessions {
+		if idx := WorktreeForPath(ps.Instance.ProjectPath, paths); idx >= 0 {
+			status.Worktrees[idx].Sessions = append(status.Worktrees[idx].Sessions, ps)
+		} else if ps.Instance.WorktreeRepoRoot != "" && normalizePath(ps.Instance.WorktreeRepoRoot) == normalizedRoot {
+			status.Orphaned = append(status.Orphaned, ps)
+		}
+	}
+
+	// Main worktree first, then by path
+	sort.SliceStable(status.Worktrees, func(a, b int) bool {
+		if status.Worktrees[a].Main != status.Worktrees[b].Main {
+			return status.Worktrees[a].Main
+		}
+		return status.Worktrees[a].Path < status.Worktrees[b].Path
+	})
+	return status, nil
+}
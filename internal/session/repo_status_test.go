@@ -0,0 +1,80 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git/gittest"
+)
+
+func TestWorktreeForPath(t *testing.T) {
+	paths := []string{"/src/repo", "/src/repo/.worktrees/feature", "/src/repo-fix"}
+	tests := map[string]int{
+		"/src/repo":                        0,
+		"/src/repo/pkg/api":                0,
+		"/src/repo/.worktrees/feature/cmd": 1,
+		"/src/repo-fix":                    2,
+		"/src/repository":                  -1,
+		"/elsewhere":                       -1,
+	}
+	for path, want := range tests {
+		if got := WorktreeForPath(path, paths); got != want {
+			t.Errorf("WorktreeForPath(%q) = %d, want %d", path, got, want)
+		}
+	}
+}
+
+func TestGetRepoStatus(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	feature := filepath.Join(filepath.Dir(repo), "repo-feature")
+	gittest.Run(t, repo, "worktree", "add", "-q", "-b", "feature", feature)
+	if err := os.WriteFile(filepath.Join(feature, "new.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inMain := NewInstance("main-work", filepath.Join(repo, "sub"))
+	inFeature := NewInstance("feature-work", feature)
+	gone := NewInstance("gone", filepath.Join(filepath.Dir(repo), "repo-removed"))
+	gone.WorktreeRepoRoot = repo
+	unrelated := NewInstance("other", t.TempDir())
+	sessions := []ProfileSession{
+		{Profile: "default", Instance: inMain},
+		{Profile: "work", Instance: inFeature},
+		{Profile: "work", Instance: gone},
+		{Profile: "default", Instance: unrelated},
+	}
+
+	// Works from inside any worktree
+	status, err := GetRepoStatus(feature, sessions, true)
+	if err != nil {
+		t.Fatalf("GetRepoStatus() failed: %v", err)
+	}
+	if status.Root != repo || len(status.Worktrees) != 2 {
+		t.Fatalf("GetRepoStatus() = root %q with %d worktrees, want %q with 2", status.Root, len(status.Worktrees), repo)
+	}
+
+	main, wt := status.Worktrees[0], status.Worktrees[1]
+	if !main.Main || main.Branch != "main" || main.Dirty() {
+		t.Errorf("main worktree = %+v, want clean main branch first", main)
+	}
+	if len(main.Sessions) != 1 || main.Sessions[0].Instance != inMain {
+		t.Errorf("main worktree sessions = %+v, want main-work", main.Sessions)
+	}
+	if wt.Branch != "feature" || !wt.Dirty() {
+		t.Errorf("feature worktree = %+v, want dirty feature branch", wt)
+	}
+	if len(wt.Sessions) != 1 || wt.Sessions[0].Profile != "work" {
+		t.Errorf("feature worktree sessions = %+v, want feature-work from profile work", wt.Sessions)
+	}
+	if len(status.Orphaned) != 1 || status.Orphaned[0].Instance != gone {
+		t.Errorf("Orphaned = %+v, want the session whose worktree was removed", status.Orphaned)
+	}
+	if status.SessionCount() != 3 {
+		t.Errorf("SessionCount() = %d, want 3", status.SessionCount())
+	}
+
+	if _, err := GetRepoStatus(t.TempDir(), sessions, false); err == nil {
+		t.Error("GetRepoStatus() should fail outside a git repository")
+	}
+}
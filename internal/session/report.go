@@ -0,0 +1,498 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportCacheVersion invalidates cached transcript usage when parsing changes
+const reportCacheVersion = 2
+
+// Report dimensions
+const (
+	ReportByProject = "project"
+	ReportByGroup   = "group"
+	ReportByTool    = "tool"
+	ReportByModel   = "model"
+	ReportByDay     = "day"
+)
+
+// ReportDimensions lists the valid values of ReportOptions.By
+var ReportDimensions = []string{ReportByProject, ReportByGroup, ReportByTool, ReportByModel, ReportByDay}
+
+// reportTopTools is how many of the most used tools a report row lists
+const reportTopTools = 3
+
+// reportUnknown labels usage that cannot be attributed
+const reportUnknown = "(unknown)"
+
+// reportNoSession is the group of transcripts no agent-deck session refers to
+const reportNoSession = "(no session)"
+
+// ReportOptions selects what a usage report covers
+type ReportOptions struct {
+	Since time.Time // Usage on or after this day
+	By    string    // One of ReportDimensions
+}
+
+// ReportRow is the usage of one project, group, tool, model or day
+type ReportRow struct {
+	Key              string        `json:"key"`
+	Sessions         int           `json:"sessions"`
+	Turns            int           `json:"turns"`
+	InputTokens      int           `json:"input_tokens"`
+	OutputTokens     int           `json:"output_tokens"`
+	CacheReadTokens  int           `json:"cache_read_input_tokens"`
+	CacheWriteTokens int           `json:"cache_creation_input_tokens"`
+	Cost             float64       `json:"cost"` // Estimated USD
+	ActiveTime       time.Duration `json:"active_time"`
+	TopTools         []ToolCall    `json:"top_tools"`
+
+	sessions map[string]bool
+	tools    map[string]int
+}
+
+// TotalTokens returns the sum of all token types
+func (r *ReportRow) TotalTokens() int {
+	return r.InputTokens + r.OutputTokens + r.CacheReadTokens + r.CacheWriteTokens
+}
+
+// add counts a message of a transcript in the row
+func (r *ReportRow) add(t *transcriptUsage, m *usageMessage) {
+	if r.sessions == nil {
+		r.sessions = make(map[string]bool)
+		r.tools = make(map[string]int)
+	}
+	r.sessions[t.Path] = true
+	r.Turns++
+	r.InputTokens += m.InputTokens
+	r.OutputTokens += m.OutputTokens
+	r.CacheReadTokens += m.CacheReadTokens
+	r.CacheWriteTokens += m.CacheWriteTokens
+	r.Cost += m.Cost
+	r.ActiveTime += m.ActiveTime
+	for _, name := range m.Tools {
+		r.tools[name]++
+	}
+}
+
+// finish fills Sessions and TopTools from what was added
+func (r *ReportRow) finish() {
+	r.Sessions = len(r.sessions)
+	r.TopTools = []ToolCall{}
+	for name, count := range r.tools {
+		r.TopTools = append(r.TopTools, ToolCall{Name: name, Count: count})
+	}
+	sort.Slice(r.TopTools, func(a, b int) bool {
+		if r.TopTools[a].Count != r.TopTools[b].Count {
+			return r.TopTools[a].Count > r.TopTools[b].Count
+		}
+		return r.TopTools[a].Name < r.TopTools[b].Name
+	})
+	if len(r.TopTools) > reportTopTools {
+		r.TopTools = r.TopTools[:reportTopTools]
+	}
+}
+
+// UsageReport aggregates agent usage across all Claude and Gemini transcripts
+type UsageReport struct {
+	Since       time.Time   `json:"since"`
+	By          string      `json:"by"`
+	Rows        []ReportRow `json:"rows"`
+	Total       ReportRow   `json:"total"`
+	Transcripts int         `json:"transcripts"` // Transcripts with usage in the period
+	Parsed      int         `json:"parsed"`      // Transcripts read from disk rather than the cache
+}
+
+// transcriptUsage is the cached usage of one transcript file
+type transcriptUsage struct {
+	Path        string         `json:"path"`
+	Tool        string         `json:"tool"`
+	SessionID   string         `json:"session_id"`
+	ProjectPath string         `json:"project_path,omitempty"`
+	Size        int64          `json:"size"`
+	ModTime     time.Time      `json:"mod_time"`
+	Messages    []usageMessage `json:"messages"`
+}
+
+// usageMessage is the usage of one agent message in a transcript
+type usageMessage struct {
+	ID               string        `json:"id,omitempty"` // Same in copies and forks of the transcript
+	Time             time.Time     `json:"time"`
+	Model            string        `json:"model,omitempty"`
+	InputTokens      int           `json:"in,omitempty"`
+	OutputTokens     int           `json:"out,omitempty"`
+	CacheReadTokens  int           `json:"cache_read,omitempty"`
+	CacheWriteTokens int           `json:"cache_write,omitempty"`
+	Cost             float64       `json:"cost,omitempty"`        // Estimated USD
+	ActiveTime       time.Duration `json:"active_time,omitempty"` // Pause since the previous message credited as work
+	Tools            []string      `json:"tools,omitempty"`
+}
+
+// day returns the local day the message was sent on, empty without a timestamp
+func (m *usageMessage) day() string {
+	if m.Time.IsZero() {
+		return ""
+	}
+	return m.Time.Local().Format("2006-01-02")
+}
+
+// start returns the time of the first timestamped message
+func (t *transcriptUsage) start() time.Time {
+	for idx := range t.Messages {
+		if !t.Messages[idx].Time.IsZero() {
+			return t.Messages[idx].Time
+		}
+	}
+	return time.Time{}
+}
+
+// reportCache maps transcript paths to their parsed usage, so reports only
+// re-read transcripts that changed
+type reportCache struct {
+	Version     int                         `json:"version"`
+	Transcripts map[string]*transcriptUsage `json:"transcripts"`
+}
+
+// getReportCachePath returns the location of the report cache. Transcripts
+// are shared by all profiles, so the cache is too.
+func getReportCachePath() (string, error) {
+	dir, err := GetAgentDeckDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "report-cache.json"), nil
+}
+
+// loadReportCache reads the cache, starting over if it is missing, unreadable
+// or from another version
+func loadReportCache() *reportCache {
+	cache := &reportCache{Version: reportCacheVersion, Transcripts: make(map[string]*transcriptUsage)}
+	path, err := getReportCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	var stored reportCache
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != reportCacheVersion || stored.Transcripts == nil {
+		return cache
+	}
+	return &stored
+}
+
+// listTranscripts returns the Claude and Gemini transcript files on disk
+// with the tool that wrote them
+func listTranscripts() map[string]string {
+	files := make(map[string]string)
+	claude, _ := filepath.Glob(filepath.Join(GetClaudeConfigDir(), "projects", "*", "*.jsonl"))
+	for _, path := range claude {
+		files[path] = "claude"
+	}
+	gemini, _ := filepath.Glob(filepath.Join(GetGeminiConfigDir(), "tmp", "*", "chats", "session-*.json"))
+	for _, path := range gemini {
+		files[path] = "gemini"
+	}
+	return files
+}
+
+// parseTranscript reads the usage of a transcript file
+func parseTranscript(path, tool string) (*transcriptUsage, error) {
+	t := &transcriptUsage{Path: path, Tool: tool}
+	switch tool {
+	case "claude":
+		// A transcript of its own rather than the shared tailer: reports read
+		// every transcript once and don't need to follow them
+		transcript := &claudeTranscript{onMessage: func(m usageMessage, merged bool) {
+			if merged {
+				t.Messages[len(t.Messages)-1] = m
+				return
+			}
+			t.Messages = append(t.Messages, m)
+		}}
+		if err := transcript.update(path); err != nil {
+			return nil, err
+		}
+		t.SessionID = strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		t.ProjectPath = transcript.analytics.ProjectPath
+	case "gemini":
+		session, err := readGeminiSessionMessages(path)
+		if err != nil {
+			return nil, err
+		}
+		t.SessionID = session.SessionID
+		for _, msg := range session.Messages {
+			if msg.Type != "gemini" {
+				continue
+			}
+			m := usageMessage{
+				ID:           msg.ID,
+				Time:         msg.Timestamp,
+				Model:        msg.Model,
+				InputTokens:  msg.Tokens.Input,
+				OutputTokens: msg.Tokens.Output,
+				Cost:         msg.cost(),
+			}
+			for _, call := range msg.ToolCalls {
+				if call.Name != "" {
+					m.Tools = append(m.Tools, call.Name)
+				}
+			}
+			t.Messages = append(t.Messages, m)
+		}
+	default:
+		return nil, fmt.Errorf("unknown transcript tool %q", tool)
+	}
+
+	var last time.Time
+	for idx := range t.Messages {
+		m := &t.Messages[idx]
+		if m.Time.IsZero() {
+			continue
+		}
+		m.ActiveTime = activeGap(last, m.Time)
+		last = m.Time
+	}
+	return t, nil
+}
+
+// loadTranscripts returns the usage of every transcript that may have
+// activity since the given day, reading only transcripts that changed since
+// they were cached. It also returns how many were read from disk.
+func loadTranscripts(since time.Time) ([]*transcriptUsage, int, error) {
+	cache := loadReportCache()
+	files := listTranscripts()
+
+	var result []*transcriptUsage
+	parsed := 0
+	dirty := false
+	for path, tool := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(since) {
+			// Nothing was written to it in the period
+			continue
+		}
+		cached := cache.Transcripts[path]
+		if cached == nil || cached.Size != info.Size() || !cached.ModTime.Equal(info.ModTime()) {
+			t, err := parseTranscript(path, tool)
+			if err != nil {
+				continue
+			}
+			t.Size = info.Size()
+			t.ModTime = info.ModTime()
+			cache.Transcripts[path] = t
+			cached = t
+			parsed++
+			dirty = true
+		}
+		result = append(result, cached)
+	}
+	for path := range cache.Transcripts {
+		if _, ok := files[path]; !ok {
+			delete(cache.Transcripts, path)
+			dirty = true
+		}
+	}
+
+	if dirty {
+		cachePath, err := getReportCachePath()
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := writeJSONAtomic(cachePath, cache); err != nil {
+			return nil, 0, fmt.Errorf("failed to save report cache: %w", err)
+		}
+	}
+	return result, parsed, nil
+}
+
+// reportAttribution maps transcripts to the agent-deck sessions, groups and
+// projects they belong to
+type reportAttribution struct {
+	bySessionID  map[string]ProfileSession
+	byClaudeDir  map[string]string // Claude project dir name -> project path
+	byGeminiHash map[string]string // Gemini project hash -> project path
+}
+
+// newReportAttribution indexes the sessions of all profiles
+func newReportAttribution(sessions []ProfileSession) *reportAttribution {
+	a := &reportAttribution{
+		bySessionID:  make(map[string]ProfileSession),
+		byClaudeDir:  make(map[string]string),
+		byGeminiHash: make(map[string]string),
+	}
+	for _, ps := range sessions {
+		inst := ps.Instance
+		if inst.ClaudeSessionID != "" {
+			a.bySessionID[inst.ClaudeSessionID] = ps
+		}
+		if inst.GeminiSessionID != "" {
+			a.bySessionID[inst.GeminiSessionID] = ps
+		}
+		if inst.ProjectPath == "" {
+			continue
+		}
+		resolved := inst.ProjectPath
+		if real, err := filepath.EvalSymlinks(inst.ProjectPath); err == nil {
+			resolved = real
+		}
+		a.byClaudeDir[ConvertToClaudeDirName(resolved)] = inst.ProjectPath
+		if hash := HashProjectPath(inst.ProjectPath); hash != "" {
+			a.byGeminiHash[hash] = inst.ProjectPath
+		}
+	}
+	return a
+}
+
+// project returns the project path a transcript was recorded in
+func (a *reportAttribution) project(t *transcriptUsage) string {
+	if t.ProjectPath != "" {
+		return t.ProjectPath
+	}
+	if ps, ok := a.bySessionID[t.SessionID]; ok && ps.Instance.ProjectPath != "" {
+		return ps.Instance.ProjectPath
+	}
+	switch t.Tool {
+	case "claude":
+		if path, ok := a.byClaudeDir[filepath.Base(filepath.Dir(t.Path))]; ok {
+			return path
+		}
+	case "gemini":
+		// ~/.gemini/tmp/<hash>/chats/session-*.json
+		if path, ok := a.byGeminiHash[filepath.Base(filepath.Dir(filepath.Dir(t.Path)))]; ok {
+			return path
+		}
+	}
+	return reportUnknown
+}
+
+// group returns the group of the session a transcript belongs to. Groups of
+// profiles other than the default are prefixed with the profile name.
+func (a *reportAttribution) group(t *transcriptUsage) string {
+	ps, ok := a.bySessionID[t.SessionID]
+	if !ok {
+		return reportNoSession
+	}
+	group := ps.Instance.GroupPath
+	if group == "" {
+		group = DefaultGroupPath
+	}
+	if ps.Profile != DefaultProfile {
+		group = ps.Profile + ":" + group
+	}
+	return group
+}
+
+// BuildUsageReport aggregates usage since opts.Since across the transcripts
+// of all Claude and Gemini sessions on this machine, including ones no
+// agent-deck session refers to anymore, grouped by opts.By
+func BuildUsageReport(opts ReportOptions) (*UsageReport, error) {
+	valid := false
+	for _, by := range ReportDimensions {
+		valid = valid || opts.By == by
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid report grouping %q (use %s)", opts.By, strings.Join(ReportDimensions, ", "))
+	}
+
+	transcripts, parsed, err := loadTranscripts(opts.Since)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := LoadAllProfileSessions()
+	if err != nil {
+		return nil, err
+	}
+	return aggregateUsage(transcripts, newReportAttribution(sessions), opts, parsed), nil
+}
+
+// aggregateUsage groups the usage of transcripts into report rows
+func aggregateUsage(transcripts []*transcriptUsage, attr *reportAttribution, opts ReportOptions, parsed int) *UsageReport {
+	sinceDay := opts.Since.Local().Format("2006-01-02")
+	rows := make(map[string]*ReportRow)
+	report := &UsageReport{Since: opts.Since, By: opts.By, Parsed: parsed}
+	counted := make(map[string]bool)
+
+	// Copied transcripts and forked sessions repeat the messages they were
+	// made from. Each message is counted once, in the oldest transcript.
+	ordered := append([]*transcriptUsage(nil), transcripts...)
+	sort.Slice(ordered, func(a, b int) bool {
+		sa, sb := ordered[a].start(), ordered[b].start()
+		if !sa.Equal(sb) {
+			return sa.Before(sb)
+		}
+		return ordered[a].Path < ordered[b].Path
+	})
+	seen := make(map[string]bool)
+
+	for _, t := range ordered {
+		for idx := range t.Messages {
+			m := &t.Messages[idx]
+			day := m.day()
+			if day < sinceDay {
+				continue
+			}
+			if m.ID != "" {
+				id := t.Tool + ":" + m.ID
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+			}
+			var key string
+			switch opts.By {
+			case ReportByProject:
+				key = attr.project(t)
+			case ReportByGroup:
+				key = attr.group(t)
+			case ReportByTool:
+				key = t.Tool
+			case ReportByModel:
+				key = m.Model
+			case ReportByDay:
+				key = day
+			}
+			if key == "" {
+				key = reportUnknown
+			}
+			row, ok := rows[key]
+			if !ok {
+				row = &ReportRow{Key: key}
+				rows[key] = row
+			}
+			row.add(t, m)
+			report.Total.add(t, m)
+			counted[t.Path] = true
+		}
+	}
+
+	report.Rows = make([]ReportRow, 0, len(rows))
+	for _, row := range rows {
+		row.finish()
+		report.Rows = append(report.Rows, *row)
+	}
+	sort.Slice(report.Rows, func(a, b int) bool {
+		ra, rb := report.Rows[a], report.Rows[b]
+		if opts.By == ReportByDay {
+			return ra.Key < rb.Key
+		}
+		if ra.Cost != rb.Cost {
+			return ra.Cost > rb.Cost
+		}
+		return ra.Key < rb.Key
+	})
+	report.Total.Key = "TOTAL"
+	report.Total.finish()
+	report.Transcripts = len(counted)
+	return report
+}
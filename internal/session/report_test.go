@@ -0,0 +1,211 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestTranscript writes a Claude transcript in an isolated Claude config dir
+func writeTestTranscript(t *testing.T, configDir, projectDir, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(configDir, "projects", projectDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSessionJSONL_DailyUsage(t *testing.T) {
+	day1 := time.Now().Add(-48 * time.Hour).Truncate(time.Hour)
+	day2 := day1.Add(24 * time.Hour)
+	ts := func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+
+	path := writeTestTranscript(t, t.TempDir(), "-repo", "s1.jsonl",
+		`{"type":"user","timestamp":"`+ts(day1)+`","message":{"content":"hi"}}
+{"type":"assistant","timestamp":"`+ts(day1.Add(time.Minute))+`","cwd":"/src/repo","message":{"model":"claude-opus-4-1","usage":{"input_tokens":100,"output_tokens":10},"content":[{"type":"tool_use","name":"Bash"}]}}
+{"type":"assistant","timestamp":"`+ts(day1.Add(3*time.Minute))+`","message":{"model":"claude-opus-4-1","usage":{"input_tokens":200,"output_tokens":20},"content":[{"type":"tool_use","name":"Bash"}]}}
+{"type":"assistant","timestamp":"`+ts(day2)+`","message":{"model":"claude-3-5-haiku","usage":{"input_tokens":50,"output_tokens":5}}}
+`)
+
+	analytics, err := ParseSessionJSONL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analytics.ProjectPath != "/src/repo" {
+		t.Errorf("ProjectPath = %q, want /src/repo", analytics.ProjectPath)
+	}
+	if len(analytics.DailyUsage) != 2 {
+		t.Fatalf("DailyUsage = %+v, want 2 slices", analytics.DailyUsage)
+	}
+
+	first := analytics.DailyUsage[0]
+	if first.Day != day1.Local().Format("2006-01-02") || first.Model != "claude-opus-4-1" {
+		t.Errorf("first slice = %s %s", first.Day, first.Model)
+	}
+	if first.Turns != 2 || first.InputTokens != 300 || first.Tools["Bash"] != 2 {
+		t.Errorf("first slice = %+v", first)
+	}
+	// The 2 minutes between agent messages count; the day-long pause does not
+	if first.ActiveTime != 2*time.Minute {
+		t.Errorf("first slice ActiveTime = %s, want 2m", first.ActiveTime)
+	}
+	second := analytics.DailyUsage[1]
+	if second.ActiveTime != 0 {
+		t.Errorf("second slice ActiveTime = %s, want 0", second.ActiveTime)
+	}
+	// Opus is priced as opus even though the exact ID is not listed
+	wantCost := (&SessionAnalytics{InputTokens: 300, OutputTokens: 30}).CalculateCost("claude-opus-4-20250514")
+	if first.Cost != wantCost {
+		t.Errorf("first slice Cost = %f, want %f", first.Cost, wantCost)
+	}
+}
+
+func TestAggregateUsage(t *testing.T) {
+	today := time.Now()
+	old := time.Now().AddDate(0, 0, -40)
+
+	work := &Instance{ClaudeSessionID: "s1", GroupPath: "work", ProjectPath: "/src/api"}
+	side := &Instance{GeminiSessionID: "g1", GroupPath: "side", ProjectPath: "/src/web"}
+	attr := newReportAttribution([]ProfileSession{
+		{Profile: DefaultProfile, Instance: work},
+		{Profile: "home", Instance: side},
+	})
+	transcripts := []*transcriptUsage{
+		{Path: "/c/s1.jsonl", Tool: "claude", SessionID: "s1", ProjectPath: "/src/api", Messages: []usageMessage{
+			{Time: old, Model: "m1", InputTokens: 1000, Cost: 9},
+			{Time: today, Model: "m1", InputTokens: 60, Cost: 0.5, Tools: []string{"Bash", "Bash"}},
+			{Time: today, Model: "m1", InputTokens: 40, Cost: 0.5, Tools: []string{"Bash", "Edit"}},
+		}},
+		{Path: "/g/g1.json", Tool: "gemini", SessionID: "g1", Messages: []usageMessage{
+			{Time: today, Model: "m2", InputTokens: 50, Cost: 2, Tools: []string{"read_file"}},
+		}},
+		{Path: "/c/orphan.jsonl", Tool: "claude", SessionID: "gone", ProjectPath: "/src/api", Messages: []usageMessage{
+			{Time: today, Model: "m1", InputTokens: 10, Cost: 0.5},
+		}},
+	}
+	opts := ReportOptions{Since: time.Now().AddDate(0, 0, -30)}
+
+	opts.By = ReportByGroup
+	report := aggregateUsage(transcripts, attr, opts, 0)
+	keys := map[string]float64{}
+	for _, row := range report.Rows {
+		keys[row.Key] = row.Cost
+	}
+	want := map[string]float64{"work": 1, "home:side": 2, reportNoSession: 0.5}
+	if len(keys) != len(want) {
+		t.Fatalf("group rows = %v, want %v", keys, want)
+	}
+	for key, cost := range want {
+		if keys[key] != cost {
+			t.Errorf("group %q cost = %v, want %v", key, keys[key], cost)
+		}
+	}
+	if report.Rows[0].Key != "home:side" {
+		t.Errorf("rows should be sorted by cost, first = %q", report.Rows[0].Key)
+	}
+	if report.Total.Cost != 3.5 || report.Total.Sessions != 3 || report.Transcripts != 3 {
+		t.Errorf("total = %+v, transcripts = %d", report.Total, report.Transcripts)
+	}
+
+	opts.By = ReportByProject
+	report = aggregateUsage(transcripts, attr, opts, 0)
+	if len(report.Rows) != 2 {
+		t.Fatalf("project rows = %+v, want /src/api and /src/web", report.Rows)
+	}
+	api := report.Rows[0]
+	if api.Key != "/src/api" {
+		api = report.Rows[1]
+	}
+	if api.Sessions != 2 || api.Turns != 3 || len(api.TopTools) != 2 || api.TopTools[0].Name != "Bash" {
+		t.Errorf("/src/api row = %+v", api)
+	}
+}
+
+func TestAggregateUsage_CopiesCountedOnce(t *testing.T) {
+	configDir := t.TempDir()
+	start := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	line := func(uuid, id string, at time.Duration, input int, tool string) string {
+		return `{"type":"assistant","uuid":"` + uuid + `","requestId":"req_` + id + `","timestamp":"` + start.Add(at).UTC().Format(time.RFC3339) +
+			`","message":{"id":"msg_` + id + `","usage":{"input_tokens":` + fmt.Sprint(input) + `},"content":[{"type":"tool_use","name":"` + tool + `"}]}}` + "\n"
+	}
+	// The first response is written as two lines, one per content block
+	history := line("u1", "1", 0, 100, "Read") + line("u2", "1", 0, 100, "Bash") + line("u3", "2", time.Minute, 200, "Edit")
+
+	original := writeTestTranscript(t, configDir, "-src-api", "s1.jsonl", history)
+	// Copied into a worktree's project dir and continued there
+	copied := writeTestTranscript(t, configDir, "-src-api-wt", "s1.jsonl", history+line("u4", "3", 2*time.Minute, 300, "Bash"))
+	// Forked into a new session, which repeats the history it was forked from
+	forked := writeTestTranscript(t, configDir, "-src-api", "s2.jsonl", history+line("u5", "4", 3*time.Minute, 400, "Bash"))
+
+	var transcripts []*transcriptUsage
+	for _, path := range []string{forked, copied, original} {
+		usage, err := parseTranscript(path, "claude")
+		if err != nil {
+			t.Fatal(err)
+		}
+		transcripts = append(transcripts, usage)
+	}
+	if got := len(transcripts[2].Messages); got != 2 {
+		t.Fatalf("original has %d messages, want the split response read as one", got)
+	}
+
+	report := aggregateUsage(transcripts, newReportAttribution(nil), ReportOptions{By: ReportByTool}, 0)
+	total := report.Total
+	if total.Turns != 4 || total.InputTokens != 1000 {
+		t.Errorf("total = %d turns, %d input tokens, want 4 and 1000", total.Turns, total.InputTokens)
+	}
+	// The original and its copy hold the same history, which only one of them is credited with
+	if total.Sessions != 2 || report.Transcripts != 2 {
+		t.Errorf("sessions = %d, transcripts = %d, want 2", total.Sessions, report.Transcripts)
+	}
+	if total.TopTools[0] != (ToolCall{Name: "Bash", Count: 3}) {
+		t.Errorf("top tools = %+v, want Bash 3 times", total.TopTools)
+	}
+}
+
+func TestLoadTranscripts_Cache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, "claude")
+	t.Setenv("CLAUDE_CONFIG_DIR", configDir)
+
+	line := `{"type":"assistant","timestamp":"` + time.Now().UTC().Format(time.RFC3339) + `","message":{"usage":{"input_tokens":10}}}` + "\n"
+	path := writeTestTranscript(t, configDir, "-repo", "s1.jsonl", line)
+	since := time.Now().AddDate(0, 0, -1)
+
+	transcripts, parsed, err := loadTranscripts(since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transcripts) != 1 || parsed != 1 {
+		t.Fatalf("first load: %d transcripts, %d parsed", len(transcripts), parsed)
+	}
+
+	if _, parsed, _ = loadTranscripts(since); parsed != 0 {
+		t.Errorf("unchanged transcript was parsed again")
+	}
+
+	if err := os.WriteFile(path, []byte(line+line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	transcripts, parsed, _ = loadTranscripts(since)
+	if parsed != 1 || len(transcripts[0].Messages) != 2 {
+		t.Errorf("changed transcript: parsed = %d, messages = %+v", parsed, transcripts[0].Messages)
+	}
+
+	// Transcripts not written to in the period are skipped
+	old := time.Now().AddDate(0, 0, -10)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if transcripts, _, _ = loadTranscripts(since); len(transcripts) != 0 {
+		t.Errorf("old transcript should be skipped, got %d", len(transcripts))
+	}
+}
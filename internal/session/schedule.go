@@ -0,0 +1,557 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultScheduleTimeout bounds a run that sets no timeout
+const defaultScheduleTimeout = 30 * time.Minute
+
+// maxScheduleRuns is how many runs of each job are kept in its history
+const maxScheduleRuns = 50
+
+// scheduleNameRe restricts job names to something safe to type and to use
+// in file names
+var scheduleNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Scheduled run statuses
+const (
+	ScheduleRunning = "running"
+	ScheduleDone    = "done"
+	ScheduleFailed  = "failed"
+)
+
+// What started a scheduled run
+const (
+	ScheduleTriggerCron   = "cron"
+	ScheduleTriggerManual = "manual"
+)
+
+// ScheduledJob sends a prompt to a session whenever its cron expression
+// fires. The prompt goes either to an existing session or to one created
+// from Template on the first run and reused after that.
+type ScheduledJob struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Cron      string            `json:"cron"`
+	Prompt    string            `json:"prompt"`
+	Session   string            `json:"session,omitempty"`    // Existing session, by ID or title
+	Template  *PipelineTemplate `json:"template,omitempty"`   // Or: session to create
+	SessionID string            `json:"session_id,omitempty"` // Session the template created
+	Timeout   string            `json:"timeout,omitempty"`    // Per run, e.g. "45m" (default 30m)
+	CreatedAt time.Time         `json:"created_at"`
+
+	NextRunAt  time.Time `json:"next_run_at"`
+	LastRunAt  time.Time `json:"last_run_at,omitempty"`
+	LastStatus string    `json:"last_status,omitempty"`
+}
+
+// ScheduleRun is one run of a scheduled job, kept in the job's history
+type ScheduleRun struct {
+	JobID      string    `json:"job_id"`
+	JobName    string    `json:"job_name"`
+	Trigger    string    `json:"trigger"`
+	Status     string    `json:"status"`
+	PID        int       `json:"pid,omitempty"` // Process running it, while running
+	SessionID  string    `json:"session_id,omitempty"`
+	Prompt     string    `json:"prompt"`
+	Output     string    `json:"output,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+}
+
+// Validate checks a job before it is saved
+func (j *ScheduledJob) Validate() error {
+	if !scheduleNameRe.MatchString(j.Name) {
+		return fmt.Errorf("invalid job name %q: use letters, digits, '.', '_' and '-'", j.Name)
+	}
+	if _, err := ParseCron(j.Cron); err != nil {
+		return err
+	}
+	if strings.TrimSpace(j.Prompt) == "" {
+		return fmt.Errorf("job '%s' has no prompt", j.Name)
+	}
+	if (j.Session == "") == (j.Template == nil) {
+		return fmt.Errorf("job '%s' needs exactly one of a session or a template", j.Name)
+	}
+	if j.Template != nil {
+		if j.Template.Tool == "" {
+			return fmt.Errorf("job '%s': template needs a tool", j.Name)
+		}
+		if j.Template.Path == "" {
+			return fmt.Errorf("job '%s': template needs a path", j.Name)
+		}
+	}
+	if _, err := j.RunTimeout(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RunTimeout returns how long a run may take
+func (j *ScheduledJob) RunTimeout() (time.Duration, error) {
+	if j.Timeout == "" {
+		return defaultScheduleTimeout, nil
+	}
+	d, err := time.ParseDuration(j.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("job '%s': invalid timeout %q", j.Name, j.Timeout)
+	}
+	return d, nil
+}
+
+// ScheduleNext sets NextRunAt to the first time the cron fires after t
+func (j *ScheduledJob) ScheduleNext(t time.Time) error {
+	cron, err := ParseCron(j.Cron)
+	if err != nil {
+		return err
+	}
+	j.NextRunAt = cron.Next(t)
+	return nil
+}
+
+// Due reports whether a cron run should start at now
+func (j *ScheduledJob) Due(now time.Time) bool {
+	return !j.NextRunAt.IsZero() && !now.Before(j.NextRunAt)
+}
+
+// TemplateSession returns the tool, path, title and group of the session a
+// template job creates
+func (j *ScheduledJob) TemplateSession() (tool, path, title, group string) {
+	t := j.Template
+	path = t.Path
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	title = t.Title
+	if title == "" {
+		title = "schedule-" + j.Name
+	}
+	group = t.Group
+	if group == "" {
+		group = "scheduled"
+	}
+	return t.Tool, filepath.Clean(path), title, group
+}
+
+// NewScheduledJob creates an unsaved job; AddScheduledJob validates it and
+// sets its first run
+func NewScheduledJob(name, cron, prompt string) *ScheduledJob {
+	return &ScheduledJob{
+		ID:        "sch-" + randomString(8),
+		Name:      name,
+		Cron:      cron,
+		Prompt:    prompt,
+		CreatedAt: time.Now(),
+	}
+}
+
+// getSchedulesPath returns the file holding a profile's scheduled jobs
+func getSchedulesPath(profile string) (string, error) {
+	profileDir, err := GetProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(profileDir, "schedules.json"), nil
+}
+
+// GetScheduleRunsDir returns the directory holding the run history of a
+// profile's scheduled jobs, one file per job
+func GetScheduleRunsDir(profile string) (string, error) {
+	profileDir, err := GetProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(profileDir, "schedule-runs"), nil
+}
+
+// writeJSONAtomic writes v to path through a temp file and rename. Each write
+// gets its own temp file, so concurrent writers never write into the same one.
+func writeJSONAtomic(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// LoadScheduledJobs returns a profile's jobs ordered by name
+func LoadScheduledJobs(profile string) ([]*ScheduledJob, error) {
+	path, err := getSchedulesPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var file struct {
+		Jobs []*ScheduledJob `json:"jobs"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	sort.Slice(file.Jobs, func(a, b int) bool {
+		return file.Jobs[a].Name < file.Jobs[b].Name
+	})
+	return file.Jobs, nil
+}
+
+// SaveScheduledJobs replaces a profile's jobs
+func SaveScheduledJobs(profile string, jobs []*ScheduledJob) error {
+	path, err := getSchedulesPath(profile)
+	if err != nil {
+		return err
+	}
+	file := struct {
+		Jobs []*ScheduledJob `json:"jobs"`
+	}{Jobs: jobs}
+	if file.Jobs == nil {
+		file.Jobs = []*ScheduledJob{}
+	}
+	if err := writeJSONAtomic(path, file); err != nil {
+		return fmt.Errorf("failed to save scheduled jobs: %w", err)
+	}
+	return nil
+}
+
+// FindScheduledJob returns the job with the given name or ID
+func FindScheduledJob(jobs []*ScheduledJob, ref string) *ScheduledJob {
+	for _, job := range jobs {
+		if job.ID == ref || job.Name == ref {
+			return job
+		}
+	}
+	return nil
+}
+
+// AddScheduledJob validates a job, computes its first run and saves it
+func AddScheduledJob(profile string, job *ScheduledJob) error {
+	if err := job.Validate(); err != nil {
+		return err
+	}
+	jobs, err := LoadScheduledJobs(profile)
+	if err != nil {
+		return err
+	}
+	if FindScheduledJob(jobs, job.Name) != nil {
+		return fmt.Errorf("a job named '%s' already exists", job.Name)
+	}
+	if err := job.ScheduleNext(time.Now()); err != nil {
+		return err
+	}
+	if job.NextRunAt.IsZero() {
+		return fmt.Errorf("cron expression %q never fires", job.Cron)
+	}
+	return SaveScheduledJobs(profile, append(jobs, job))
+}
+
+// RemoveScheduledJob deletes a job and its run history
+func RemoveScheduledJob(profile, ref string) (*ScheduledJob, error) {
+	jobs, err := LoadScheduledJobs(profile)
+	if err != nil {
+		return nil, err
+	}
+	job := FindScheduledJob(jobs, ref)
+	if job == nil {
+		return nil, fmt.Errorf("scheduled job '%s' not found", ref)
+	}
+	kept := make([]*ScheduledJob, 0, len(jobs)-1)
+	for _, j := range jobs {
+		if j.ID != job.ID {
+			kept = append(kept, j)
+		}
+	}
+	if err := SaveScheduledJobs(profile, kept); err != nil {
+		return nil, err
+	}
+	if dir, err := GetScheduleRunsDir(profile); err == nil {
+		os.Remove(filepath.Join(dir, job.ID+".json"))
+	}
+	return job, nil
+}
+
+// updateScheduledJob applies fn to the saved copy of a job. Jobs are
+// reloaded first since commands may have changed the file meanwhile.
+func updateScheduledJob(profile, id string, fn func(*ScheduledJob)) error {
+	jobs, err := LoadScheduledJobs(profile)
+	if err != nil {
+		return err
+	}
+	job := FindScheduledJob(jobs, id)
+	if job == nil {
+		return fmt.Errorf("scheduled job '%s' not found", id)
+	}
+	fn(job)
+	return SaveScheduledJobs(profile, jobs)
+}
+
+// ListScheduleRuns returns a job's run history, newest first
+func ListScheduleRuns(profile, jobID string) ([]*ScheduleRun, error) {
+	dir, err := GetScheduleRunsDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, filepath.Base(jobID)+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var runs []*ScheduleRun
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse run history of '%s': %w", jobID, err)
+	}
+	sort.Slice(runs, func(a, b int) bool {
+		return runs[a].StartedAt.After(runs[b].StartedAt)
+	})
+	return runs, nil
+}
+
+// saveScheduleRun adds or updates a run (keyed by start time) in its job's
+// history, dropping the oldest runs beyond maxScheduleRuns
+func saveScheduleRun(profile string, run *ScheduleRun) error {
+	runs, err := ListScheduleRuns(profile, run.JobID)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for idx, r := range runs {
+		if r.StartedAt.Equal(run.StartedAt) {
+			runs[idx] = run
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		runs = append([]*ScheduleRun{run}, runs...)
+	}
+	if len(runs) > maxScheduleRuns {
+		runs = runs[:maxScheduleRuns]
+	}
+	dir, err := GetScheduleRunsDir(profile)
+	if err != nil {
+		return err
+	}
+	if err := writeJSONAtomic(filepath.Join(dir, filepath.Base(run.JobID)+".json"), runs); err != nil {
+		return fmt.Errorf("failed to save run history: %w", err)
+	}
+	return nil
+}
+
+// Interrupted reports whether a run still marked running lost its process
+func (r *ScheduleRun) Interrupted() bool {
+	return r.Status == ScheduleRunning && !processAlive(r.PID)
+}
+
+// DisplayStatus returns the status to show, with interrupted runs called out
+func (r *ScheduleRun) DisplayStatus() string {
+	if r.Interrupted() {
+		return PipelineInterrupted
+	}
+	return r.Status
+}
+
+// RunScheduledJob runs a job now: it starts or wakes the job's session,
+// sends the prompt, waits for the turn to finish and records the response.
+// The run is saved to the job's history as it progresses.
+func RunScheduledJob(profile string, job *ScheduledJob, trigger string) *ScheduleRun {
+	run := &ScheduleRun{
+		JobID:     job.ID,
+		JobName:   job.Name,
+		Trigger:   trigger,
+		Status:    ScheduleRunning,
+		PID:       os.Getpid(),
+		Prompt:    job.Prompt,
+		StartedAt: time.Now(),
+	}
+	save := func() {
+		if err := saveScheduleRun(profile, run); err != nil {
+			log.Printf("[SCHEDULE] %s: %v", job.Name, err)
+		}
+	}
+	save()
+
+	err := runScheduledPrompt(profile, job, run, save)
+	run.FinishedAt = time.Now()
+	run.PID = 0
+	run.Status = ScheduleDone
+	if err != nil {
+		run.Status = ScheduleFailed
+		run.Error = err.Error()
+	}
+	save()
+
+	if err := updateScheduledJob(profile, job.ID, func(j *ScheduledJob) {
+		j.LastRunAt = run.StartedAt
+		j.LastStatus = run.Status
+		if run.SessionID != "" && j.Template != nil {
+			j.SessionID = run.SessionID
+		}
+	}); err != nil {
+		log.Printf("[SCHEDULE] %s: %v", job.Name, err)
+	}
+	log.Printf("[SCHEDULE] %s: %s run %s", job.Name, trigger, run.Status)
+	return run
+}
+
+// runScheduledPrompt does the work of a run
+func runScheduledPrompt(profile string, job *ScheduledJob, run *ScheduleRun, save func()) error {
+	timeout, err := job.RunTimeout()
+	if err != nil {
+		return err
+	}
+	inst, err := scheduledJobSession(profile, job)
+	if err != nil {
+		return err
+	}
+	run.SessionID = inst.ID
+	save()
+
+	if err := inst.BudgetError(); err != nil {
+		return err
+	}
+	if !inst.Exists() {
+		if err := inst.StartWithMessage(job.Prompt); err != nil {
+			return fmt.Errorf("failed to start '%s': %w", inst.Title, err)
+		}
+		// Capture the tool's session ID so the response can be read
+		inst.PostStartSync(3 * time.Second)
+		if err := saveProfileSession(profile, inst); err != nil {
+			return err
+		}
+	} else {
+		_ = inst.UpdateStatus()
+		if err := inst.BudgetError(); err != nil {
+			return err
+		}
+		if inst.Status == StatusNeedsApproval {
+			return fmt.Errorf("'%s' is waiting on a permission prompt", inst.Title)
+		}
+		if err := inst.sendMessageWhenReady(job.Prompt); err != nil {
+			return fmt.Errorf("'%s' is not ready: %w", inst.Title, err)
+		}
+	}
+
+	if err := inst.WaitForTurn(run.StartedAt.Add(timeout)); err != nil {
+		return err
+	}
+	if inst.Tool == "claude" {
+		inst.UpdateClaudeSession(nil)
+	}
+	response, err := inst.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("failed to get response: %w", err)
+	}
+	run.Output = response.Content
+	return nil
+}
+
+// scheduledJobSession returns the session a job talks to: the named one, the
+// one its template created on an earlier run, or a new one from the template
+func scheduledJobSession(profile string, job *ScheduledJob) (*Instance, error) {
+	storage, err := NewStorageWithProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	instances, _, err := storage.LoadWithGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Template == nil {
+		for _, inst := range instances {
+			if inst.ID == job.Session || inst.Title == job.Session {
+				return inst, nil
+			}
+		}
+		for _, inst := range instances {
+			if strings.HasPrefix(inst.ID, job.Session) {
+				return inst, nil
+			}
+		}
+		return nil, fmt.Errorf("session '%s' not found", job.Session)
+	}
+
+	if job.SessionID != "" {
+		for _, inst := range instances {
+			if inst.ID == job.SessionID {
+				return inst, nil
+			}
+		}
+	}
+
+	tool, path, title, group := job.TemplateSession()
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("template path %s is not a directory", path)
+	}
+	inst := NewInstanceWithGroupAndTool(title, path, group, tool)
+	inst.Command = tool
+	if toolDef := GetToolDef(tool); toolDef != nil {
+		inst.Command = toolDef.Command
+	}
+	instances = append(instances, inst)
+	if err := storage.SaveWithGroups(instances, NewGroupTree(instances)); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	return inst, nil
+}
+
+// saveProfileSession writes one session back into freshly loaded session data
+func saveProfileSession(profile string, inst *Instance) error {
+	storage, err := NewStorageWithProfile(profile)
+	if err != nil {
+		return err
+	}
+	instances, _, err := storage.LoadWithGroups()
+	if err != nil {
+		return err
+	}
+	found := false
+	for idx, existing := range instances {
+		if existing.ID == inst.ID {
+			instances[idx] = inst
+			found = true
+			break
+		}
+	}
+	if !found {
+		instances = append(instances, inst)
+	}
+	return storage.SaveWithGroups(instances, NewGroupTree(instances))
+}
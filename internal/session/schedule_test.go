@@ -0,0 +1,180 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduledJob_Validate(t *testing.T) {
+	valid := func() *ScheduledJob {
+		job := NewScheduledJob("deps", "0 3 * * *", "update dependencies")
+		job.Session = "api"
+		return job
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*ScheduledJob)
+	}{
+		{"bad name", func(j *ScheduledJob) { j.Name = "nightly deps" }},
+		{"bad cron", func(j *ScheduledJob) { j.Cron = "every night" }},
+		{"no prompt", func(j *ScheduledJob) { j.Prompt = "  " }},
+		{"no target", func(j *ScheduledJob) { j.Session = "" }},
+		{"both targets", func(j *ScheduledJob) { j.Template = &PipelineTemplate{Tool: "claude", Path: "/tmp"} }},
+		{"template without path", func(j *ScheduledJob) {
+			j.Session = ""
+			j.Template = &PipelineTemplate{Tool: "claude"}
+		}},
+		{"bad timeout", func(j *ScheduledJob) { j.Timeout = "soon" }},
+	}
+	for _, tt := range tests {
+		job := valid()
+		tt.modify(job)
+		if err := job.Validate(); err == nil {
+			t.Errorf("%s: Validate() succeeded, want an error", tt.name)
+		}
+	}
+}
+
+func TestScheduledJob_TemplateSessionDefaults(t *testing.T) {
+	job := NewScheduledJob("flaky", "@hourly", "check the dashboard")
+	job.Template = &PipelineTemplate{Tool: "claude", Path: "/srv/ci/"}
+	tool, path, title, group := job.TemplateSession()
+	if tool != "claude" || path != "/srv/ci" || title != "schedule-flaky" || group != "scheduled" {
+		t.Errorf("TemplateSession() = %q, %q, %q, %q", tool, path, title, group)
+	}
+}
+
+func TestScheduledJobStorage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	job := NewScheduledJob("deps", "0 3 * * *", "update dependencies")
+	job.Session = "api"
+	if err := AddScheduledJob("_test", job); err != nil {
+		t.Fatalf("AddScheduledJob() error = %v", err)
+	}
+	if !job.NextRunAt.After(time.Now()) {
+		t.Errorf("NextRunAt = %v, want a future time", job.NextRunAt)
+	}
+	dup := NewScheduledJob("deps", "@daily", "again")
+	dup.Session = "api"
+	if err := AddScheduledJob("_test", dup); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("AddScheduledJob(duplicate) = %v, want an already exists error", err)
+	}
+	never := NewScheduledJob("never", "0 0 30 2 *", "p")
+	never.Session = "api"
+	if err := AddScheduledJob("_test", never); err == nil {
+		t.Error("AddScheduledJob() accepted a cron expression that never fires")
+	}
+
+	jobs, err := LoadScheduledJobs("_test")
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("LoadScheduledJobs() = %v, %v; want one job", jobs, err)
+	}
+	if FindScheduledJob(jobs, job.ID) == nil || FindScheduledJob(jobs, "deps") == nil {
+		t.Error("FindScheduledJob() should find a job by ID and by name")
+	}
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < maxScheduleRuns+5; i++ {
+		run := &ScheduleRun{JobID: job.ID, JobName: job.Name, Status: ScheduleDone, StartedAt: start.Add(time.Duration(i) * time.Second)}
+		if err := saveScheduleRun("_test", run); err != nil {
+			t.Fatalf("saveScheduleRun() error = %v", err)
+		}
+	}
+	runs, err := ListScheduleRuns("_test", job.ID)
+	if err != nil || len(runs) != maxScheduleRuns {
+		t.Fatalf("ListScheduleRuns() = %d runs, %v; want %d", len(runs), err, maxScheduleRuns)
+	}
+	if !runs[0].StartedAt.After(runs[1].StartedAt) {
+		t.Error("ListScheduleRuns() should return the newest run first")
+	}
+
+	if _, err := RemoveScheduledJob("_test", "deps"); err != nil {
+		t.Fatalf("RemoveScheduledJob() error = %v", err)
+	}
+	if jobs, _ := LoadScheduledJobs("_test"); len(jobs) != 0 {
+		t.Errorf("jobs after remove = %v, want none", jobs)
+	}
+	if runs, _ := ListScheduleRuns("_test", job.ID); len(runs) != 0 {
+		t.Errorf("history after remove = %d runs, want none", len(runs))
+	}
+	if _, err := RemoveScheduledJob("_test", "deps"); err == nil {
+		t.Error("RemoveScheduledJob() of a missing job should fail")
+	}
+}
+
+func TestScheduleRun_Interrupted(t *testing.T) {
+	run := &ScheduleRun{Status: ScheduleRunning}
+	if !run.Interrupted() || run.DisplayStatus() != PipelineInterrupted {
+		t.Error("a running run without a live process should be interrupted")
+	}
+	run.Status = ScheduleDone
+	if run.Interrupted() {
+		t.Error("a finished run is never interrupted")
+	}
+}
+
+func TestSchedulerLock(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if pid := SchedulerPID("_test"); pid != 0 {
+		t.Fatalf("SchedulerPID() = %d before any scheduler ran", pid)
+	}
+	if !acquireSchedulerLock("_test") || !acquireSchedulerLock("_test") {
+		t.Fatal("acquireSchedulerLock() should succeed, and again for its holder")
+	}
+	if pid := SchedulerPID("_test"); pid == 0 {
+		t.Error("SchedulerPID() = 0 while this process holds the lock")
+	}
+	releaseSchedulerLock("_test")
+	if pid := SchedulerPID("_test"); pid != 0 {
+		t.Errorf("SchedulerPID() = %d after release", pid)
+	}
+}
+
+func TestWriteJSONAtomic_ConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			errs <- writeJSONAtomic(path, map[string]int{"writer": n})
+		}(n)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("writeJSONAtomic() error = %v", err)
+		}
+	}
+
+	var got map[string]int
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("file holds a mix of writes: %v\n%s", err, data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
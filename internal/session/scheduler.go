@@ -0,0 +1,164 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// schedulerInterval is how often the scheduler looks for due jobs. Cron has
+// minute resolution, so runs start at most this late.
+const schedulerInterval = 30 * time.Second
+
+// getSchedulerLockPath returns the file naming the process that runs a
+// profile's jobs
+func getSchedulerLockPath(profile string) (string, error) {
+	profileDir, err := GetProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(profileDir, "scheduler.pid"), nil
+}
+
+// SchedulerPID returns the PID of the process running a profile's jobs, or
+// 0 if none is
+func SchedulerPID(profile string) int {
+	path, err := getSchedulerLockPath(profile)
+	if err != nil {
+		return 0
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || !processAlive(pid) {
+		return 0
+	}
+	return pid
+}
+
+// acquireSchedulerLock makes this process the profile's scheduler unless
+// another live process already is. Several TUIs and headless schedulers can
+// run at once; only the lock holder runs jobs, and another takes over when
+// it exits.
+func acquireSchedulerLock(profile string) bool {
+	path, err := getSchedulerLockPath(profile)
+	if err != nil {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+			f.Close()
+			return err == nil
+		}
+		if !os.IsExist(err) {
+			return false
+		}
+		pid := SchedulerPID(profile)
+		if pid == os.Getpid() {
+			return true
+		}
+		if pid != 0 {
+			return false
+		}
+		// Stale lock from a process that is gone
+		os.Remove(path)
+	}
+	return false
+}
+
+// releaseSchedulerLock gives up the lock if this process holds it
+func releaseSchedulerLock(profile string) {
+	if SchedulerPID(profile) != os.Getpid() {
+		return
+	}
+	if path, err := getSchedulerLockPath(profile); err == nil {
+		os.Remove(path)
+	}
+}
+
+// StartScheduler runs RunScheduler in a background goroutine
+func StartScheduler(ctx context.Context, profile string, onRun func(*ScheduleRun)) {
+	go RunScheduler(ctx, profile, onRun)
+}
+
+// RunScheduler starts due jobs of a profile until ctx is done, calling onRun
+// after each run. A job whose runs were missed (the machine slept, nothing
+// was running the scheduler) runs once and then waits for its next time.
+func RunScheduler(ctx context.Context, profile string, onRun func(*ScheduleRun)) {
+	var mu sync.Mutex
+	inFlight := make(map[string]bool)
+
+	tick := func() {
+		if !acquireSchedulerLock(profile) {
+			return
+		}
+		jobs, err := LoadScheduledJobs(profile)
+		if err != nil {
+			log.Printf("[SCHEDULE] %v", err)
+			return
+		}
+		now := time.Now()
+		for _, job := range jobs {
+			mu.Lock()
+			busy := inFlight[job.ID]
+			mu.Unlock()
+			if busy || !job.Due(now) {
+				continue
+			}
+			if err := updateScheduledJob(profile, job.ID, func(j *ScheduledJob) {
+				_ = j.ScheduleNext(now)
+			}); err != nil {
+				log.Printf("[SCHEDULE] %s: %v", job.Name, err)
+				continue
+			}
+
+			mu.Lock()
+			inFlight[job.ID] = true
+			mu.Unlock()
+			go func(job *ScheduledJob) {
+				run := RunScheduledJob(profile, job, ScheduleTriggerCron)
+				mu.Lock()
+				delete(inFlight, job.ID)
+				mu.Unlock()
+				if onRun != nil {
+					onRun(run)
+				}
+			}(job)
+		}
+	}
+
+	defer releaseSchedulerLock(profile)
+	tick()
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tick()
+		}
+	}
+}
+
+// ScheduleRunSummary describes a finished run in one line
+func ScheduleRunSummary(run *ScheduleRun) string {
+	elapsed := run.FinishedAt.Sub(run.StartedAt).Round(time.Second)
+	if run.Status == ScheduleFailed {
+		return fmt.Sprintf("Schedule '%s' failed after %s: %s", run.JobName, elapsed, run.Error)
+	}
+	return fmt.Sprintf("Schedule '%s' done in %s", run.JobName, elapsed)
+}
@@ -0,0 +1,615 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Secret reference prefixes accepted in MCPDef.Env and MCPDef.Headers values
+const (
+	SecretRefVault = "vault:"
+	SecretRefEnv   = "env:"
+)
+
+const (
+	vaultFileName    = "secrets.vault"
+	vaultKeyFileName = "vault.key"
+	vaultKeyService  = "agent-deck"
+	vaultKeyAccount  = "vault"
+)
+
+// vaultUseKeyring controls whether the vault key is stored in the OS keyring
+// (macOS Keychain via `security`, Linux Secret Service via `secret-tool`).
+// When unavailable the key falls back to ~/.agent-deck/vault.key (0600).
+var vaultUseKeyring = true
+
+var (
+	vaultMu          sync.Mutex
+	secretNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// vaultFile is the on-disk format: AES-256-GCM sealed vaultContents
+type vaultFile struct {
+	Version int    `json:"version"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// vaultContents holds global secrets and per-project overrides keyed by absolute project path
+type vaultContents struct {
+	Global   map[string]string            `json:"global"`
+	Projects map[string]map[string]string `json:"projects"`
+}
+
+// IsSecretRef returns true if value is a vault: or env: reference
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretRefVault) || strings.HasPrefix(value, SecretRefEnv)
+}
+
+// ValidateSecretName checks that a secret name is usable in a vault: reference
+func ValidateSecretName(name string) error {
+	if !secretNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid secret name '%s' (use letters, digits, '_', '-', '.')", name)
+	}
+	return nil
+}
+
+// NormalizeSecretProject converts a project path to the key used in the vault.
+// Empty means the global scope.
+func NormalizeSecretProject(projectPath string) string {
+	if projectPath == "" {
+		return ""
+	}
+	projectPath = expandTilde(projectPath)
+	if abs, err := filepath.Abs(projectPath); err == nil {
+		projectPath = abs
+	}
+	return filepath.Clean(projectPath)
+}
+
+// getVaultPath returns ~/.agent-deck/secrets.vault
+func getVaultPath() (string, error) {
+	dir, err := GetAgentDeckDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, vaultFileName), nil
+}
+
+// getVaultKeyPath returns ~/.agent-deck/vault.key (fallback when no OS keyring)
+func getVaultKeyPath() (string, error) {
+	dir, err := GetAgentDeckDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, vaultKeyFileName), nil
+}
+
+// keyringGet reads the vault key from the OS keyring
+func keyringGet() (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", vaultKeyService, "-a", vaultKeyAccount, "-w")
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return "", err
+		}
+		cmd = exec.Command("secret-tool", "lookup", "service", vaultKeyService, "account", vaultKeyAccount)
+	default:
+		return "", fmt.Errorf("no keyring support on %s", runtime.GOOS)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(output))
+	if key == "" {
+		return "", errors.New("empty keyring entry")
+	}
+	return key, nil
+}
+
+// keyringSet stores the vault key in the OS keyring
+func keyringSet(key string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// Passed on stdin in interactive mode, so the key never appears in
+		// the process list
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %q\n",
+			vaultKeyService, vaultKeyAccount, key))
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return err
+		}
+		cmd = exec.Command("secret-tool", "store", "--label", "agent-deck secrets vault", "service", vaultKeyService, "account", vaultKeyAccount)
+		cmd.Stdin = strings.NewReader(key)
+	default:
+		return fmt.Errorf("no keyring support on %s", runtime.GOOS)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(string(output)), err)
+	}
+	// `security -i` exits 0 even when its command fails, and a key that
+	// wasn't stored would leave the vault undecryptable
+	if stored, err := keyringGet(); err != nil || stored != key {
+		return errors.New("vault key could not be read back from the keyring")
+	}
+	return nil
+}
+
+// loadVaultKey returns the vault key, creating one if create is true and none exists
+func loadVaultKey(create bool) ([]byte, error) {
+	var encoded string
+	if vaultUseKeyring {
+		if key, err := keyringGet(); err == nil {
+			encoded = key
+		}
+	}
+
+	keyPath, err := getVaultKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	if encoded == "" {
+		if data, err := os.ReadFile(keyPath); err == nil {
+			encoded = strings.TrimSpace(string(data))
+		}
+	}
+
+	if encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, errors.New("vault key is corrupted")
+		}
+		return key, nil
+	}
+
+	if !create {
+		return nil, errors.New("vault key not found (keyring entry or ~/.agent-deck/vault.key)")
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate vault key: %w", err)
+	}
+	encoded = base64.StdEncoding.EncodeToString(key)
+
+	if vaultUseKeyring {
+		err := keyringSet(encoded)
+		if err == nil {
+			log.Printf("[Secrets] Vault key stored in OS keyring")
+			return key, nil
+		}
+		log.Printf("[Secrets] OS keyring unavailable (%v), using %s", err, keyPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create agent-deck directory: %w", err)
+	}
+	if err := os.WriteFile(keyPath, []byte(encoded+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write vault key: %w", err)
+	}
+	return key, nil
+}
+
+// loadVault decrypts the vault. A missing vault file yields empty contents.
+func loadVault() (*vaultContents, error) {
+	contents := &vaultContents{
+		Global:   make(map[string]string),
+		Projects: make(map[string]map[string]string),
+	}
+
+	path, err := getVaultPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return contents, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse vault: %w", err)
+	}
+
+	key, err := loadVaultKey(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newVaultCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt vault (wrong key?)")
+	}
+	if err := json.Unmarshal(plain, contents); err != nil {
+		return nil, fmt.Errorf("failed to parse vault contents: %w", err)
+	}
+	if contents.Global == nil {
+		contents.Global = make(map[string]string)
+	}
+	if contents.Projects == nil {
+		contents.Projects = make(map[string]map[string]string)
+	}
+	return contents, nil
+}
+
+// saveVault encrypts and atomically writes the vault
+func saveVault(contents *vaultContents) error {
+	path, err := getVaultPath()
+	if err != nil {
+		return err
+	}
+	key, err := loadVaultKey(true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newVaultCipher(key)
+	if err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(contents)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data, err := json.Marshal(vaultFile{Version: 1, Nonce: nonce, Data: gcm.Seal(nil, nonce, plain, nil)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create agent-deck directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+	return nil
+}
+
+func newVaultCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// scope returns the secrets map for a project ("" = global), creating it if requested
+func (v *vaultContents) scope(project string, create bool) map[string]string {
+	if project == "" {
+		return v.Global
+	}
+	secrets := v.Projects[project]
+	if secrets == nil && create {
+		secrets = make(map[string]string)
+		v.Projects[project] = secrets
+	}
+	return secrets
+}
+
+// SetSecret stores a secret globally (project == "") or for a specific project
+func SetSecret(project, name, value string) error {
+	if err := ValidateSecretName(name); err != nil {
+		return err
+	}
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	contents, err := loadVault()
+	if err != nil {
+		return err
+	}
+	contents.scope(NormalizeSecretProject(project), true)[name] = value
+	return saveVault(contents)
+}
+
+// GetSecret returns a secret from exactly the given scope (no fallback to global)
+func GetSecret(project, name string) (string, bool, error) {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	contents, err := loadVault()
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := contents.scope(NormalizeSecretProject(project), false)[name]
+	return value, ok, nil
+}
+
+// DeleteSecret removes a secret from the given scope. Returns false if it didn't exist.
+func DeleteSecret(project, name string) (bool, error) {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	contents, err := loadVault()
+	if err != nil {
+		return false, err
+	}
+	project = NormalizeSecretProject(project)
+	secrets := contents.scope(project, false)
+	if _, ok := secrets[name]; !ok {
+		return false, nil
+	}
+	delete(secrets, name)
+	if project != "" && len(secrets) == 0 {
+		delete(contents.Projects, project)
+	}
+	return true, saveVault(contents)
+}
+
+// ListSecrets returns secret names by scope ("" = global). Values are never returned.
+func ListSecrets() (map[string][]string, error) {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	contents, err := loadVault()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	collect := func(scope string, secrets map[string]string) {
+		if len(secrets) == 0 {
+			return
+		}
+		names := make([]string, 0, len(secrets))
+		for name := range secrets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		result[scope] = names
+	}
+	collect("", contents.Global)
+	for project, secrets := range contents.Projects {
+		collect(project, secrets)
+	}
+	return result, nil
+}
+
+// LookupSecret finds a secret for a project: the nearest project scope at or
+// above projectPath wins, then the global scope.
+func LookupSecret(projectPath, name string) (string, bool, error) {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	contents, err := loadVault()
+	if err != nil {
+		return "", false, err
+	}
+	value, _, ok := contents.lookup(projectPath, name)
+	return value, ok, nil
+}
+
+// lookup resolves name for projectPath and reports the scope it came from ("" = global)
+func (v *vaultContents) lookup(projectPath, name string) (value, scope string, ok bool) {
+	for dir := NormalizeSecretProject(projectPath); dir != ""; {
+		if value, ok := v.Projects[dir][name]; ok {
+			return value, dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	value, ok = v.Global[name]
+	return value, "", ok
+}
+
+// ResolveSecretRef resolves a vault: or env: reference. Other values are returned unchanged.
+func ResolveSecretRef(value, projectPath string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SecretRefVault):
+		name := strings.TrimPrefix(value, SecretRefVault)
+		secret, ok, err := LookupSecret(projectPath, name)
+		if err != nil {
+			return "", fmt.Errorf("vault:%s: %w", name, err)
+		}
+		if !ok {
+			return "", fmt.Errorf("secret '%s' not found in vault (agent-deck secrets set %s)", name, name)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, SecretRefEnv):
+		name := strings.TrimPrefix(value, SecretRefEnv)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveSecretMap returns a copy of m with all secret references resolved
+func resolveSecretMap(m map[string]string, projectPath string) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	resolved := make(map[string]string, len(m))
+	for k, v := range m {
+		value, err := ResolveSecretRef(v, projectPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		resolved[k] = value
+	}
+	return resolved, nil
+}
+
+// HasSecretRefs returns true if any env or header value is a secret reference
+func (d *MCPDef) HasSecretRefs() bool {
+	for _, v := range d.Env {
+		if IsSecretRef(v) {
+			return true
+		}
+	}
+	for _, v := range d.Headers {
+		if IsSecretRef(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveMCPEnv returns the MCP's env with secret references resolved for projectPath
+func ResolveMCPEnv(def *MCPDef, projectPath string) (map[string]string, error) {
+	return resolveSecretMap(def.Env, projectPath)
+}
+
+// ResolveMCPHeaders returns the MCP's headers with secret references resolved for projectPath
+func ResolveMCPHeaders(def *MCPDef, projectPath string) (map[string]string, error) {
+	return resolveSecretMap(def.Headers, projectPath)
+}
+
+// mcpUsesProjectSecrets returns true if any vault: reference in the MCP's env
+// resolves to a project-scoped secret for projectPath. Such MCPs can't share
+// the pool, which runs with global secrets only.
+func mcpUsesProjectSecrets(def *MCPDef, projectPath string) bool {
+	if projectPath == "" || !def.HasSecretRefs() {
+		return false
+	}
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	contents, err := loadVault()
+	if err != nil {
+		return false
+	}
+	for _, v := range def.Env {
+		if !strings.HasPrefix(v, SecretRefVault) {
+			continue
+		}
+		if _, scope, ok := contents.lookup(projectPath, strings.TrimPrefix(v, SecretRefVault)); ok && scope != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// mcpSecretEnvPrefix starts the environment variables that carry resolved
+// MCP header secrets into a session
+const mcpSecretEnvPrefix = "AGENTDECK_MCP_"
+
+// MCPSecretEnvName returns the environment variable generated configs read
+// an MCP header secret from, e.g. AGENTDECK_MCP_GITHUB_AUTHORIZATION
+func MCPSecretEnvName(mcpName, header string) string {
+	name := strings.ToUpper(mcpName + "_" + header)
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	return mcpSecretEnvPrefix + name
+}
+
+// secretHeaderPlaceholders returns the MCP's headers with every secret
+// reference replaced by a ${VAR} reference, so generated configs (which are
+// often committed) never contain secret values. The session supplies the
+// variables at launch, see MCPHeaderSecretEnv.
+func secretHeaderPlaceholders(mcpName string, headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	result := make(map[string]string, len(headers))
+	for header, value := range headers {
+		if IsSecretRef(value) {
+			value = "${" + MCPSecretEnvName(mcpName, header) + "}"
+		}
+		result[header] = value
+	}
+	return result
+}
+
+// envPlaceholder returns VAR when value is exactly a "${VAR}" reference
+func envPlaceholder(value string) (string, bool) {
+	name, ok := strings.CutPrefix(value, "${")
+	if !ok {
+		return "", false
+	}
+	name, ok = strings.CutSuffix(name, "}")
+	return name, ok && name != ""
+}
+
+// mcpsWithHeaderSecrets returns which of the named MCPs are HTTP MCPs in
+// config.toml with a secret reference in their headers
+func mcpsWithHeaderSecrets(names []string) []string {
+	available := GetAvailableMCPs()
+	var result []string
+	for _, name := range names {
+		def, ok := available[name]
+		if !ok || def.URL == "" {
+			continue
+		}
+		for _, value := range def.Headers {
+			if IsSecretRef(value) {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// MCPHeaderSecretEnv resolves the header secrets of the named HTTP MCPs in
+// config.toml for projectPath, keyed by the variables generated configs
+// refer to. Sessions pass only the MCPs attached to them, so a session never
+// sees the secrets of other MCPs. MCPs whose secrets can't be resolved are
+// reported in the error; the others are still returned.
+func MCPHeaderSecretEnv(projectPath string, names []string) (map[string]string, error) {
+	env := make(map[string]string)
+	var errs []error
+	available := GetAvailableMCPs()
+	for _, name := range names {
+		def, ok := available[name]
+		if !ok || def.URL == "" {
+			continue
+		}
+		for header, value := range def.Headers {
+			if !IsSecretRef(value) {
+				continue
+			}
+			resolved, err := ResolveSecretRef(value, projectPath)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", name, header, err))
+				continue
+			}
+			env[MCPSecretEnvName(name, header)] = resolved
+		}
+	}
+	return env, errors.Join(errs...)
+}
@@ -0,0 +1,309 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupVaultTest isolates the vault under a temp HOME and disables the OS keyring
+func setupVaultTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	ClearUserConfigCache()
+	t.Cleanup(ClearUserConfigCache)
+
+	orig := vaultUseKeyring
+	vaultUseKeyring = false
+	t.Cleanup(func() { vaultUseKeyring = orig })
+	return tempDir
+}
+
+func TestSecrets_SetGetDelete(t *testing.T) {
+	home := setupVaultTest(t)
+
+	if err := SetSecret("", "github_token", "ghp_supersecret"); err != nil {
+		t.Fatalf("SetSecret failed: %v", err)
+	}
+
+	value, ok, err := GetSecret("", "github_token")
+	if err != nil || !ok || value != "ghp_supersecret" {
+		t.Fatalf("GetSecret = %q, %v, %v; want ghp_supersecret", value, ok, err)
+	}
+
+	// Vault is encrypted on disk and the key file is private
+	data, err := os.ReadFile(filepath.Join(home, ".agent-deck", vaultFileName))
+	if err != nil {
+		t.Fatalf("vault not written: %v", err)
+	}
+	if strings.Contains(string(data), "ghp_supersecret") {
+		t.Error("vault file contains the plaintext secret")
+	}
+	info, err := os.Stat(filepath.Join(home, ".agent-deck", vaultKeyFileName))
+	if err != nil {
+		t.Fatalf("key file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	removed, err := DeleteSecret("", "github_token")
+	if err != nil || !removed {
+		t.Fatalf("DeleteSecret = %v, %v", removed, err)
+	}
+	if _, ok, _ := GetSecret("", "github_token"); ok {
+		t.Error("secret still present after delete")
+	}
+	if removed, _ := DeleteSecret("", "github_token"); removed {
+		t.Error("deleting a missing secret should return false")
+	}
+}
+
+func TestSecrets_InvalidName(t *testing.T) {
+	setupVaultTest(t)
+	for _, name := range []string{"", "has space", "a/b", "x:y"} {
+		if err := SetSecret("", name, "v"); err == nil {
+			t.Errorf("SetSecret(%q) should fail", name)
+		}
+	}
+}
+
+func TestSecrets_ProjectScope(t *testing.T) {
+	setupVaultTest(t)
+	project := t.TempDir()
+	sub := filepath.Join(project, "pkg", "api")
+
+	if err := SetSecret("", "token", "global-value"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSecret(project, "token", "project-value"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", "global-value"},
+		{project, "project-value"},
+		{sub, "project-value"}, // nearest parent scope applies
+		{t.TempDir(), "global-value"},
+	}
+	for _, tt := range tests {
+		value, ok, err := LookupSecret(tt.path, "token")
+		if err != nil || !ok || value != tt.want {
+			t.Errorf("LookupSecret(%q) = %q, %v, %v; want %q", tt.path, value, ok, err, tt.want)
+		}
+	}
+
+	scopes, err := ListSecrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scopes[""]) != 1 || len(scopes[NormalizeSecretProject(project)]) != 1 {
+		t.Errorf("ListSecrets() = %v, want one global and one project secret", scopes)
+	}
+
+	def := &MCPDef{Env: map[string]string{"TOKEN": "vault:token"}}
+	if !mcpUsesProjectSecrets(def, sub) {
+		t.Error("expected project override to be detected")
+	}
+	if mcpUsesProjectSecrets(def, t.TempDir()) {
+		t.Error("unrelated project should use global secrets")
+	}
+}
+
+func TestResolveSecretRef(t *testing.T) {
+	setupVaultTest(t)
+	t.Setenv("AGENTDECK_TEST_TOKEN", "from-env")
+	if err := SetSecret("", "api_key", "from-vault"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "plain", false},
+		{"env:AGENTDECK_TEST_TOKEN", "from-env", false},
+		{"vault:api_key", "from-vault", false},
+		{"env:AGENTDECK_TEST_MISSING", "", true},
+		{"vault:missing", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveSecretRef(tt.value, "")
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ResolveSecretRef(%q) = %q, %v; want %q, err=%v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteMCPJsonFromConfig_SecretsNotWritten(t *testing.T) {
+	home := setupVaultTest(t)
+	if err := SetSecret("", "github_token", "ghp_supersecret"); err != nil {
+		t.Fatal(err)
+	}
+
+	configTOML := `
+[mcps.github]
+command = "npx"
+args = ["-y", "@modelcontextprotocol/server-github"]
+env = { GITHUB_TOKEN = "vault:github_token", LOG_LEVEL = "debug" }
+
+[mcps.plain]
+command = "echo"
+env = { MODE = "test" }
+`
+	if err := os.WriteFile(filepath.Join(home, ".agent-deck", UserConfigFileName), []byte(configTOML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ClearUserConfigCache()
+
+	project := t.TempDir()
+	if err := WriteMCPJsonFromConfig(project, []string{"github", "plain"}); err != nil {
+		t.Fatalf("WriteMCPJsonFromConfig failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(project, ".mcp.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ghp_supersecret") {
+		t.Fatal(".mcp.json contains the resolved secret")
+	}
+
+	var mcpConfig struct {
+		MCPServers map[string]MCPServerConfig `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &mcpConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	github := mcpConfig.MCPServers["github"]
+	wantArgs := []string{"mcp", "exec", "--project", project, "github"}
+	if strings.Join(github.Args, " ") != strings.Join(wantArgs, " ") {
+		t.Errorf("github args = %v, want launcher args %v", github.Args, wantArgs)
+	}
+
+	// MCPs without secret references are written as before
+	plain := mcpConfig.MCPServers["plain"]
+	if plain.Command != "echo" || plain.Env["MODE"] != "test" {
+		t.Errorf("plain MCP = %+v, want direct stdio config", plain)
+	}
+}
+
+func TestWriteMCPJsonFromConfig_HeaderSecretsNotWritten(t *testing.T) {
+	home := setupVaultTest(t)
+	if err := SetSecret("", "docs_token", "Bearer supersecret"); err != nil {
+		t.Fatal(err)
+	}
+	configTOML := `
+[mcps.docs]
+url = "https://docs.example.com/mcp"
+headers = { Authorization = "vault:docs_token", X-Team = "core" }
+`
+	if err := os.WriteFile(filepath.Join(home, ".agent-deck", UserConfigFileName), []byte(configTOML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ClearUserConfigCache()
+
+	project := t.TempDir()
+	if err := WriteMCPJsonFromConfig(project, []string{"docs"}); err != nil {
+		t.Fatalf("WriteMCPJsonFromConfig failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(project, ".mcp.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "supersecret") {
+		t.Fatal(".mcp.json contains the resolved header secret")
+	}
+	var mcpConfig struct {
+		MCPServers map[string]MCPServerConfig `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &mcpConfig); err != nil {
+		t.Fatal(err)
+	}
+	headers := mcpConfig.MCPServers["docs"].Headers
+	if headers["Authorization"] != "${AGENTDECK_MCP_DOCS_AUTHORIZATION}" || headers["X-Team"] != "core" {
+		t.Errorf("headers = %v, want a variable reference for the secret", headers)
+	}
+
+	// The environment of a session with the MCP attached supplies the variable
+	if got := mcpsWithHeaderSecrets([]string{"docs", "unknown"}); len(got) != 1 || got[0] != "docs" {
+		t.Errorf("mcpsWithHeaderSecrets() = %v, want [docs]", got)
+	}
+	if cmd := mcpSecretEnvCommand(project, []string{"docs"}); !strings.Contains(cmd, "--mcps 'docs'") {
+		t.Errorf("mcpSecretEnvCommand(docs) = %q, want it to ask for docs only", cmd)
+	}
+	env, err := MCPHeaderSecretEnv(project, []string{"docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 1 || env["AGENTDECK_MCP_DOCS_AUTHORIZATION"] != "Bearer supersecret" {
+		t.Errorf("MCPHeaderSecretEnv = %v", env)
+	}
+	if got := ShellExports(env); got != "export AGENTDECK_MCP_DOCS_AUTHORIZATION='Bearer supersecret'\n" {
+		t.Errorf("ShellExports = %q", got)
+	}
+
+	// Codex and OpenCode get their own variable syntax
+	cfg := MCPServerConfig{URL: "https://docs.example.com/mcp", Headers: headers}
+	codex := codexServerFromConfig(cfg)
+	if codex.EnvHTTPHeaders["Authorization"] != "AGENTDECK_MCP_DOCS_AUTHORIZATION" || codex.HTTPHeaders["X-Team"] != "core" {
+		t.Errorf("codex server = %+v", codex)
+	}
+	if _, ok := codex.HTTPHeaders["Authorization"]; ok {
+		t.Error("codex http_headers still holds the secret header")
+	}
+	if openCode := openCodeServerFromConfig(cfg); openCode.Headers["Authorization"] != "{env:AGENTDECK_MCP_DOCS_AUTHORIZATION}" {
+		t.Errorf("opencode headers = %v", openCode.Headers)
+	}
+}
+
+func TestMCPHeaderSecretEnv_OnlyNamedMCPs(t *testing.T) {
+	home := setupVaultTest(t)
+	if err := SetSecret("", "docs_token", "Bearer docs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSecret("", "billing_token", "Bearer billing"); err != nil {
+		t.Fatal(err)
+	}
+	configTOML := `
+[mcps.docs]
+url = "https://docs.example.com/mcp"
+headers = { Authorization = "vault:docs_token" }
+
+[mcps.billing]
+url = "https://billing.example.com/mcp"
+headers = { Authorization = "vault:billing_token" }
+
+[mcps.plain]
+command = "echo"
+`
+	if err := os.WriteFile(filepath.Join(home, ".agent-deck", UserConfigFileName), []byte(configTOML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ClearUserConfigCache()
+
+	project := t.TempDir()
+	env, err := MCPHeaderSecretEnv(project, []string{"docs", "plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 1 || env["AGENTDECK_MCP_DOCS_AUTHORIZATION"] != "Bearer docs" {
+		t.Errorf("MCPHeaderSecretEnv(docs, plain) = %v, want only the docs secret", env)
+	}
+
+	// Sessions without a secret-using MCP attached export nothing
+	if cmd := mcpSecretEnvCommand(project, []string{"plain"}); cmd != "" {
+		t.Errorf("mcpSecretEnvCommand(plain) = %q, want none", cmd)
+	}
+	if cmd := mcpSecretEnvCommand(project, nil); cmd != "" {
+		t.Errorf("mcpSecretEnvCommand(nil) = %q, want none", cmd)
+	}
+}
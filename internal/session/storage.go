@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/asheshgoplani/agent-deck/internal/tmux"
@@ -74,6 +75,13 @@ type InstanceData struct {
 	WorktreePath     string `json:"worktree_path,omitempty"`
 	WorktreeRepoRoot string `json:"worktree_repo_root,omitempty"`
 	WorktreeBranch   string `json:"worktree_branch,omitempty"`
+	BootstrapPending bool   `json:"bootstrap_pending,omitempty"`
+	BootstrapError   string `json:"bootstrap_error,omitempty"`
+
+	// Fork lineage
+	ForkedFromID       string    `json:"forked_from_id,omitempty"`
+	ForkedFromClaudeID string    `json:"forked_from_claude_id,omitempty"`
+	ForkedAt           time.Time `json:"forked_at,omitempty"`
 
 	// Claude session (persisted for resume after app restart)
 	ClaudeSessionID  string    `json:"claude_session_id,omitempty"`
@@ -96,6 +104,17 @@ type InstanceData struct {
 	// Latest user input for context
 	LatestPrompt string `json:"latest_prompt,omitempty"`
 
+	// Prompts waiting to be delivered (survive restarts)
+	PromptQueue []QueuedPrompt `json:"prompt_queue,omitempty"`
+
+	// Permission dialog the session was blocked on
+	PendingApproval *ApprovalRequest `json:"pending_approval,omitempty"`
+
+	// Budget state (see budget.go)
+	BusyTime       time.Duration `json:"busy_time,omitempty"`
+	BudgetBaseline *BudgetUsage  `json:"budget_baseline,omitempty"`
+	BudgetExceeded string        `json:"budget_exceeded,omitempty"`
+
 	// MCP tracking (persisted for sync status display)
 	LoadedMCPNames []string `json:"loaded_mcp_names,omitempty"`
 }
@@ -113,8 +132,9 @@ type GroupData struct {
 // Thread-safe with mutex protection for concurrent access
 type Storage struct {
 	path    string
-	profile string     // The profile this storage is for
-	mu      sync.Mutex // Protects all file operations
+	profile string          // The profile this storage is for
+	mu      sync.Mutex      // Protects all file operations
+	synced  map[string]bool // Queued prompts in the file when it was last read or written
 }
 
 // NewStorage creates a new storage instance using the default profile.
@@ -206,6 +226,17 @@ func (s *Storage) SaveWithGroups(instances []*Instance, groupTree *GroupTree) er
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Other agent-deck processes (the TUI, CLI commands) save the same file.
+	// Hold the lock from reading their changes until ours are written.
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to lock sessions file: %w", err)
+	}
+	defer unlock()
+	if disk, err := s.loadFromFile(s.path); err == nil {
+		mergePromptQueues(instances, disk.Instances, s.syncedPrompts())
+	}
+
 	// Convert instances to serializable format
 	data := StorageData{
 		Instances: make([]*InstanceData, len(instances)),
@@ -232,6 +263,11 @@ func (s *Storage) SaveWithGroups(instances []*Instance, groupTree *GroupTree) er
 			WorktreePath:       inst.WorktreePath,
 			WorktreeRepoRoot:   inst.WorktreeRepoRoot,
 			WorktreeBranch:     inst.WorktreeBranch,
+			BootstrapPending:   inst.BootstrapPending,
+			BootstrapError:     inst.BootstrapError,
+			ForkedFromID:       inst.ForkedFromID,
+			ForkedFromClaudeID: inst.ForkedFromClaudeID,
+			ForkedAt:           inst.ForkedAt,
 			ClaudeSessionID:    inst.ClaudeSessionID,
 			ClaudeDetectedAt:   inst.ClaudeDetectedAt,
 			GeminiSessionID:    inst.GeminiSessionID,
@@ -243,6 +279,11 @@ func (s *Storage) SaveWithGroups(instances []*Instance, groupTree *GroupTree) er
 			CodexSessionID:     inst.CodexSessionID,
 			CodexDetectedAt:    inst.CodexDetectedAt,
 			LatestPrompt:       inst.LatestPrompt,
+			PromptQueue:        inst.QueuedPrompts(),
+			PendingApproval:    inst.PendingApproval,
+			BusyTime:           inst.BusyTime,
+			BudgetBaseline:     inst.BudgetBaseline,
+			BudgetExceeded:     inst.BudgetExceeded,
 			LoadedMCPNames:     inst.LoadedMCPNames,
 		}
 	}
@@ -303,10 +344,37 @@ func (s *Storage) SaveWithGroups(instances []*Instance, groupTree *GroupTree) er
 	if err := os.Rename(tmpPath, s.path); err != nil {
 		return fmt.Errorf("failed to finalize save: %w", err)
 	}
+	markPromptsSynced(s.syncedPrompts(), data.Instances)
 
 	return nil
 }
 
+// syncedPrompts returns the IDs of the prompts that were queued in the file
+// when it was last read or written. Caller must hold mu.
+func (s *Storage) syncedPrompts() map[string]bool {
+	if s.synced == nil {
+		s.synced = make(map[string]bool)
+	}
+	return s.synced
+}
+
+// lockFile takes an exclusive advisory lock on path's lock file, waiting for
+// other processes to release it. The returned func releases it.
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
 // validateStorageData checks data integrity before saving
 func validateStorageData(data *StorageData) error {
 	if data == nil {
@@ -495,6 +563,7 @@ func (s *Storage) recoverFromBackups() (*StorageData, error) {
 
 // convertToInstances converts StorageData to Instance slice
 func (s *Storage) convertToInstances(data *StorageData) ([]*Instance, []*GroupData, error) {
+	markPromptsSynced(s.syncedPrompts(), data.Instances)
 
 	// ═══════════════════════════════════════════════════════════════════
 	// MIGRATION: Convert old "My Sessions" paths to normalized "my-sessions"
@@ -568,6 +637,11 @@ func (s *Storage) convertToInstances(data *StorageData) ([]*Instance, []*GroupDa
 			WorktreePath:       instData.WorktreePath,
 			WorktreeRepoRoot:   instData.WorktreeRepoRoot,
 			WorktreeBranch:     instData.WorktreeBranch,
+			BootstrapPending:   instData.BootstrapPending,
+			BootstrapError:     instData.BootstrapError,
+			ForkedFromID:       instData.ForkedFromID,
+			ForkedFromClaudeID: instData.ForkedFromClaudeID,
+			ForkedAt:           instData.ForkedAt,
 			ClaudeSessionID:    instData.ClaudeSessionID,
 			ClaudeDetectedAt:   instData.ClaudeDetectedAt,
 			GeminiSessionID:    instData.GeminiSessionID,
@@ -579,6 +653,11 @@ func (s *Storage) convertToInstances(data *StorageData) ([]*Instance, []*GroupDa
 			CodexSessionID:     instData.CodexSessionID,
 			CodexDetectedAt:    instData.CodexDetectedAt,
 			LatestPrompt:       instData.LatestPrompt,
+			PromptQueue:        instData.PromptQueue,
+			PendingApproval:    instData.PendingApproval,
+			BusyTime:           instData.BusyTime,
+			BudgetBaseline:     instData.BudgetBaseline,
+			BudgetExceeded:     instData.BudgetExceeded,
 			LoadedMCPNames:     instData.LoadedMCPNames,
 			tmuxSession:        tmuxSess,
 		}
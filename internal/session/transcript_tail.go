@@ -0,0 +1,530 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// claudeTranscripts follows every Claude transcript read by this process.
+// Analytics, the latest prompt, the last response and turns all come from
+// it, so a transcript is read once and afterwards only its appended bytes are
+// parsed.
+var claudeTranscripts = newClaudeTranscriptTailer()
+
+// claudeTranscriptIdle is how long a transcript nobody reads stays followed.
+// Transcripts of deleted sessions, and ones a session replaced with a new
+// conversation, are dropped after it.
+const claudeTranscriptIdle = 10 * time.Minute
+
+// claudeActivityLimit is how many of the latest usage points are kept per
+// transcript. Older points are dropped in batches once there are twice as many.
+const claudeActivityLimit = 5000
+
+// claudeTurnLimit is how many turns are followed per transcript. A new turn
+// replaces the one that started first.
+const claudeTurnLimit = 4
+
+// claudeHeadSize is how much of the start of a transcript is kept to notice
+// it was rewritten in place
+const claudeHeadSize = 512
+
+// claudeTranscriptTailer keeps one claudeTranscript per JSONL path
+type claudeTranscriptTailer struct {
+	mu        sync.Mutex
+	files     map[string]*claudeTranscript
+	lastSweep time.Time
+}
+
+// newClaudeTranscriptTailer creates a tailer that follows no files yet
+func newClaudeTranscriptTailer() *claudeTranscriptTailer {
+	return &claudeTranscriptTailer{files: make(map[string]*claudeTranscript)}
+}
+
+// read brings the transcript at path up to date and calls fn with it while
+// no other reader can change it. Files that disappeared are forgotten.
+func (tt *claudeTranscriptTailer) read(path string, fn func(*claudeTranscript)) error {
+	now := time.Now()
+	tt.mu.Lock()
+	if now.Sub(tt.lastSweep) > claudeTranscriptIdle {
+		for p, t := range tt.files {
+			if now.Sub(t.lastRead) > claudeTranscriptIdle {
+				delete(tt.files, p)
+			}
+		}
+		tt.lastSweep = now
+	}
+	t, ok := tt.files[path]
+	if !ok {
+		t = &claudeTranscript{}
+		tt.files[path] = t
+	}
+	t.lastRead = now
+	tt.mu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.update(path); err != nil {
+		if os.IsNotExist(err) {
+			tt.mu.Lock()
+			if tt.files[path] == t {
+				delete(tt.files, path)
+			}
+			tt.mu.Unlock()
+		}
+		return err
+	}
+	fn(t)
+	return nil
+}
+
+// claudeTranscript is the read position in one transcript and the running
+// totals of everything read so far
+type claudeTranscript struct {
+	mu        sync.Mutex
+	lastRead  time.Time                         // Guarded by the tailer's mu
+	onMessage func(m usageMessage, merged bool) // Called with each agent message, if set; merged means m replaces the previous one
+	claudeTranscriptState
+}
+
+// claudeTranscriptState is reset when the transcript is truncated, replaced or
+// rewritten
+type claudeTranscriptState struct {
+	file    os.FileInfo // The file read so far, to notice it was replaced
+	head    []byte      // Its first bytes, to notice it was rewritten
+	offset  int64       // Bytes consumed, including partial
+	partial []byte      // Start of a line whose newline was not written yet
+
+	analytics    SessionAnalytics  // Totals; tool calls, daily usage and timing are filled in by snapshot
+	lastSnapshot *SessionAnalytics // Last snapshot, until something is read
+	toolCounts   map[string]int
+	slicer       usageSlicer
+	firstTime    time.Time
+	lastTime     time.Time
+	current      claudeResponse // Response the last assistant line belonged to
+
+	sessionID    string
+	prompt       string // Latest user prompt, on one line
+	response     string // Last assistant text
+	responseTime string
+
+	turns map[int64]*claudeTurnReader // Turns being followed, by start offset
+}
+
+// claudeResponse is what was counted for one API response so far
+type claudeResponse struct {
+	usage   SessionAnalytics // Tokens and EstimatedCost of its latest line
+	slice   *UsageSlice
+	point   bool // Whether it added the latest activity point
+	message usageMessage
+}
+
+// claudeTranscriptEntry is the part of a transcript line the readers use
+type claudeTranscriptEntry struct {
+	Type      string `json:"type"`
+	UUID      string `json:"uuid,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	SessionID string `json:"sessionId"`
+	Timestamp string `json:"timestamp"`
+	Cwd       string `json:"cwd,omitempty"`
+	Message   struct {
+		ID    string `json:"id,omitempty"`
+		Role  string `json:"role"`
+		Model string `json:"model,omitempty"`
+		Usage struct {
+			InputTokens              int `json:"input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// claudeContentBlock is one block of a message's content
+type claudeContentBlock struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// update parses what was appended to the transcript since the last update.
+// A transcript that shrank, was replaced by another file or was rewritten in
+// place is read again from the start.
+func (t *claudeTranscript) update(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if t.file != nil && (!os.SameFile(t.file, info) || info.Size() < t.offset || t.rewritten(f, info)) {
+		t.claudeTranscriptState = claudeTranscriptState{}
+	}
+	t.file = info
+	if info.Size() == t.offset {
+		return nil
+	}
+	if len(t.head) < claudeHeadSize && info.Size() > int64(len(t.head)) {
+		head := make([]byte, min(info.Size(), claudeHeadSize))
+		if n, err := f.ReadAt(head, 0); err == nil || err == io.EOF {
+			t.head = head[:n]
+		}
+	}
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(f, 64*1024)
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		t.offset += int64(len(chunk))
+		switch err {
+		case nil:
+			line := chunk
+			if len(t.partial) > 0 {
+				line = append(t.partial, chunk...)
+				t.partial = nil
+			}
+			t.consume(line, t.offset-int64(len(line)))
+		case bufio.ErrBufferFull:
+			// Lines holding large tool outputs span several buffers
+			t.partial = append(t.partial, chunk...)
+		default:
+			t.partial = append(t.partial, chunk...)
+			// The last line may be a complete record without its newline
+			// (yet). Only a complete object is valid JSON, so a line that is
+			// still being written stays pending.
+			if len(t.partial) > 0 && json.Valid(t.partial) {
+				t.consume(t.partial, t.offset-int64(len(t.partial)))
+				t.partial = nil
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// rewritten reports whether a file that was modified since the last update
+// starts differently than it did, i.e. was rewritten rather than appended to
+func (t *claudeTranscript) rewritten(f *os.File, info os.FileInfo) bool {
+	if info.ModTime().Equal(t.file.ModTime()) || len(t.head) == 0 {
+		return false
+	}
+	head := make([]byte, len(t.head))
+	if _, err := f.ReadAt(head, 0); err != nil {
+		return true
+	}
+	return !bytes.Equal(head, t.head)
+}
+
+// consume adds the transcript line that starts at offset to the running
+// totals and the turns being followed
+func (t *claudeTranscript) consume(line []byte, offset int64) {
+	t.lastSnapshot = nil
+	for start, turn := range t.turns {
+		if offset >= start {
+			turn.line(line, offset)
+		}
+	}
+
+	var entry claudeTranscriptEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return // Skip malformed lines
+	}
+	if t.sessionID == "" && entry.SessionID != "" {
+		t.sessionID = entry.SessionID
+	}
+	if t.analytics.ProjectPath == "" && entry.Cwd != "" {
+		t.analytics.ProjectPath = entry.Cwd
+	}
+
+	var blocks []claudeContentBlock
+	_ = json.Unmarshal(entry.Message.Content, &blocks)
+
+	switch entry.Message.Role {
+	case "user":
+		if text := claudeMessageText(entry.Message.Content, blocks, " "); text != "" {
+			// Single line for display
+			t.prompt = strings.Join(strings.Fields(text), " ")
+		}
+	case "assistant":
+		if text := claudeMessageText(entry.Message.Content, blocks, "\n"); text != "" {
+			t.response = text
+			t.responseTime = entry.Timestamp
+		}
+	}
+
+	// Only assistant messages count towards analytics
+	if entry.Type != "assistant" {
+		return
+	}
+	ts, _ := time.Parse(time.RFC3339, entry.Timestamp)
+	usage := entry.Message.Usage
+	a := &t.analytics
+
+	if !ts.IsZero() {
+		if t.firstTime.IsZero() || ts.Before(t.firstTime) {
+			t.firstTime = ts
+		}
+		if ts.After(t.lastTime) {
+			t.lastTime = ts
+		}
+	}
+
+	// Each message is priced at its own model's rate
+	message := SessionAnalytics{
+		InputTokens:      usage.InputTokens,
+		OutputTokens:     usage.OutputTokens,
+		CacheReadTokens:  usage.CacheReadInputTokens,
+		CacheWriteTokens: usage.CacheCreationInputTokens,
+	}
+	message.EstimatedCost = message.CalculateCost(entry.Message.Model)
+
+	// Claude writes each content block of a response on its own line and
+	// repeats the response's usage on every one. Later lines replace the
+	// usage counted for the response instead of adding to it.
+	cur := &t.current
+	id := entry.messageID()
+	merged := id != "" && id == cur.message.ID
+	if merged {
+		t.addUsage(cur.slice, &cur.usage, -1)
+	} else {
+		a.TotalTurns++
+		*cur = claudeResponse{
+			slice:   t.slicer.add(ts, entry.Message.Model),
+			message: usageMessage{ID: id, Time: ts, Model: entry.Message.Model},
+		}
+	}
+	cur.usage = message
+	t.addUsage(cur.slice, &cur.usage, 1)
+
+	// Current context size is the last turn's input + cache read
+	a.CurrentContextTokens = usage.InputTokens + usage.CacheReadInputTokens
+
+	switch {
+	case merged && cur.point:
+		point := &a.Activity[len(a.Activity)-1]
+		point.Tokens = message.TotalTokens()
+		point.Cost = message.EstimatedCost
+	case !merged && !ts.IsZero():
+		a.Activity = append(a.Activity, UsagePoint{
+			Time:   ts,
+			Tokens: message.TotalTokens(),
+			Cost:   message.EstimatedCost,
+		})
+		cur.point = true
+		if len(a.Activity) >= 2*claudeActivityLimit {
+			a.Activity = append(a.Activity[:0], a.Activity[len(a.Activity)-claudeActivityLimit:]...)
+		}
+	}
+
+	// Every tool_use block is a call of its own, on whichever line it is
+	for _, block := range blocks {
+		if block.Type == "tool_use" && block.Name != "" {
+			if t.toolCounts == nil {
+				t.toolCounts = make(map[string]int)
+			}
+			t.toolCounts[block.Name]++
+			if cur.slice.Tools == nil {
+				cur.slice.Tools = make(map[string]int)
+			}
+			cur.slice.Tools[block.Name]++
+			cur.message.Tools = append(cur.message.Tools, block.Name)
+		}
+	}
+
+	if t.onMessage != nil {
+		m := cur.message
+		m.InputTokens = message.InputTokens
+		m.OutputTokens = message.OutputTokens
+		m.CacheReadTokens = message.CacheReadTokens
+		m.CacheWriteTokens = message.CacheWriteTokens
+		m.Cost = message.EstimatedCost
+		m.Tools = append([]string(nil), cur.message.Tools...)
+		t.onMessage(m, merged)
+	}
+}
+
+// addUsage adds one response's usage to the totals and its slice, or takes it
+// away again when sign is -1
+func (t *claudeTranscript) addUsage(slice *UsageSlice, u *SessionAnalytics, sign int) {
+	a := &t.analytics
+	a.InputTokens += sign * u.InputTokens
+	a.OutputTokens += sign * u.OutputTokens
+	a.CacheReadTokens += sign * u.CacheReadTokens
+	a.CacheWriteTokens += sign * u.CacheWriteTokens
+	a.EstimatedCost += float64(sign) * u.EstimatedCost
+
+	slice.InputTokens += sign * u.InputTokens
+	slice.OutputTokens += sign * u.OutputTokens
+	slice.CacheReadTokens += sign * u.CacheReadTokens
+	slice.CacheWriteTokens += sign * u.CacheWriteTokens
+}
+
+// messageID identifies the API response a line records. Copies and forks of
+// a transcript repeat it, and a response split over several lines shares it.
+func (e *claudeTranscriptEntry) messageID() string {
+	switch {
+	case e.Message.ID != "" && e.RequestID != "":
+		return e.Message.ID + ":" + e.RequestID
+	case e.Message.ID != "":
+		return e.Message.ID
+	}
+	return e.UUID
+}
+
+// claudeMessageText returns the text of a message's content, which is
+// either a string or blocks whose text blocks are joined with sep
+func claudeMessageText(content json.RawMessage, blocks []claudeContentBlock, sep string) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text
+	}
+	var sb strings.Builder
+	for _, block := range blocks {
+		if block.Type == "text" && block.Text != "" {
+			sb.WriteString(block.Text)
+			sb.WriteString(sep)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// snapshot returns the analytics read so far. Later updates don't change
+// them, and until something is read the same snapshot is returned, so callers
+// must not modify it.
+func (t *claudeTranscript) snapshot() *SessionAnalytics {
+	if t.lastSnapshot != nil {
+		return t.lastSnapshot
+	}
+	analytics := t.analytics
+	activity := t.analytics.Activity
+	if len(activity) > claudeActivityLimit {
+		activity = activity[len(activity)-claudeActivityLimit:]
+	}
+	analytics.Activity = append([]UsagePoint(nil), activity...)
+
+	analytics.ToolCalls = make([]ToolCall, 0, len(t.toolCounts))
+	for name, count := range t.toolCounts {
+		analytics.ToolCalls = append(analytics.ToolCalls, ToolCall{Name: name, Count: count})
+	}
+
+	analytics.DailyUsage = t.slicer.result()
+	for idx := range analytics.DailyUsage {
+		slice := &analytics.DailyUsage[idx]
+		if slice.Tools != nil {
+			tools := make(map[string]int, len(slice.Tools))
+			for name, count := range slice.Tools {
+				tools[name] = count
+			}
+			slice.Tools = tools
+		}
+		slice.Cost = (&SessionAnalytics{
+			InputTokens:      slice.InputTokens,
+			OutputTokens:     slice.OutputTokens,
+			CacheReadTokens:  slice.CacheReadTokens,
+			CacheWriteTokens: slice.CacheWriteTokens,
+		}).CalculateCost(slice.Model)
+	}
+
+	analytics.StartTime = t.firstTime
+	analytics.LastActive = t.lastTime
+	if !t.firstTime.IsZero() && !t.lastTime.IsZero() {
+		analytics.Duration = t.lastTime.Sub(t.firstTime)
+	}
+	t.lastSnapshot = &analytics
+	return t.lastSnapshot
+}
+
+// turn returns the turn that starts at offset and follows it from now on.
+// Lines before the read position are read once when the turn is new.
+func (t *claudeTranscript) turn(path string, offset int64) (*TranscriptTurn, error) {
+	if reader, ok := t.turns[offset]; ok {
+		return reader.result(), nil
+	}
+
+	reader := newClaudeTurnReader(offset)
+	if read := t.offset - int64(len(t.partial)); offset < read {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		lines := bufio.NewReader(io.NewSectionReader(f, offset, read-offset))
+		pos := offset
+		for {
+			line, err := lines.ReadBytes('\n')
+			if len(line) > 0 {
+				reader.line(line, pos)
+				pos += int64(len(line))
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if t.turns == nil {
+		t.turns = make(map[int64]*claudeTurnReader)
+	}
+	if len(t.turns) >= claudeTurnLimit {
+		first := int64(-1)
+		for start := range t.turns {
+			if first < 0 || start < first {
+				first = start
+			}
+		}
+		delete(t.turns, first)
+	}
+	t.turns[offset] = reader
+	return reader.result(), nil
+}
+
+// claudeLatestPrompt returns the last user prompt in a Claude transcript
+func claudeLatestPrompt(path string) (string, error) {
+	var prompt string
+	err := claudeTranscripts.read(path, func(t *claudeTranscript) {
+		prompt = t.prompt
+	})
+	return prompt, err
+}
+
+// claudeLastResponse returns the last assistant message in a Claude transcript
+func claudeLastResponse(path string) (*ResponseOutput, error) {
+	var response *ResponseOutput
+	err := claudeTranscripts.read(path, func(t *claudeTranscript) {
+		if t.response == "" {
+			return
+		}
+		response = &ResponseOutput{
+			Tool:      "claude",
+			Role:      "assistant",
+			Content:   t.response,
+			Timestamp: t.responseTime,
+			SessionID: t.sessionID,
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	if response == nil {
+		return nil, fmt.Errorf("no assistant response found in session")
+	}
+	return response, nil
+}
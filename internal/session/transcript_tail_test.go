@@ -0,0 +1,247 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	tailUserLine      = `{"type":"user","sessionId":"s1","message":{"role":"user","content":"fix the\nbuild"}}` + "\n"
+	tailAssistantLine = `{"type":"assistant","sessionId":"s1","timestamp":"2026-03-11T10:00:00Z","message":{"role":"assistant","usage":{"input_tokens":10},"content":[{"type":"text","text":"Done."},{"type":"tool_use","name":"Bash"}]}}` + "\n"
+)
+
+// appendFile appends content to the file at path
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tailSnapshot reads the transcript with tailer and returns its state
+func tailSnapshot(t *testing.T, tailer *claudeTranscriptTailer, path string) (*SessionAnalytics, string, string) {
+	t.Helper()
+	var analytics *SessionAnalytics
+	var prompt, response string
+	if err := tailer.read(path, func(tr *claudeTranscript) {
+		analytics = tr.snapshot()
+		prompt = tr.prompt
+		response = tr.response
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return analytics, prompt, response
+}
+
+func TestClaudeTranscriptTailer_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	appendFile(t, path, tailUserLine+tailAssistantLine)
+	tailer := newClaudeTranscriptTailer()
+
+	first, prompt, response := tailSnapshot(t, tailer, path)
+	if first.InputTokens != 10 || prompt != "fix the build" || response != "Done." {
+		t.Fatalf("first read: tokens %d, prompt %q, response %q", first.InputTokens, prompt, response)
+	}
+	offset := tailer.files[path].offset
+
+	appendFile(t, path, tailAssistantLine)
+	second, _, _ := tailSnapshot(t, tailer, path)
+	if second.InputTokens != 20 || second.TotalTurns != 2 || second.ToolCalls[0].Count != 2 {
+		t.Errorf("after append = %+v", second)
+	}
+	if got := tailer.files[path].offset; got != offset+int64(len(tailAssistantLine)) {
+		t.Errorf("offset = %d, want only the appended line read past %d", got, offset)
+	}
+	// Snapshots are not changed by later reads
+	if first.InputTokens != 10 || len(first.Activity) != 1 {
+		t.Errorf("earlier snapshot changed: %+v", first)
+	}
+}
+
+func TestClaudeTranscriptTailer_PartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	half := len(tailAssistantLine) / 2
+	appendFile(t, path, tailUserLine+tailAssistantLine[:half])
+	tailer := newClaudeTranscriptTailer()
+
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.TotalTurns != 0 {
+		t.Fatalf("half-written line was counted: %+v", analytics)
+	}
+	appendFile(t, path, tailAssistantLine[half:])
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.TotalTurns != 1 || analytics.InputTokens != 10 {
+		t.Errorf("completed line = %+v", analytics)
+	}
+
+	// A complete record is read before its newline arrives, and only once
+	appendFile(t, path, tailAssistantLine[:len(tailAssistantLine)-1])
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.TotalTurns != 2 {
+		t.Errorf("record without newline: turns = %d, want 2", analytics.TotalTurns)
+	}
+	appendFile(t, path, "\n")
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.TotalTurns != 2 {
+		t.Errorf("newline after a read record: turns = %d, want 2", analytics.TotalTurns)
+	}
+}
+
+func TestClaudeTranscriptTailer_TruncationAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s1.jsonl")
+	appendFile(t, path, tailAssistantLine+tailAssistantLine)
+	tailer := newClaudeTranscriptTailer()
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.TotalTurns != 2 {
+		t.Fatalf("turns = %d, want 2", analytics.TotalTurns)
+	}
+
+	// Truncated in place
+	if err := os.WriteFile(path, []byte(tailAssistantLine), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.TotalTurns != 1 {
+		t.Errorf("after truncation: turns = %d, want 1", analytics.TotalTurns)
+	}
+
+	// Replaced by a larger file
+	rotated := filepath.Join(dir, "new.jsonl")
+	appendFile(t, rotated, tailUserLine+tailAssistantLine+tailAssistantLine+tailAssistantLine)
+	if err := os.Rename(rotated, path); err != nil {
+		t.Fatal(err)
+	}
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.TotalTurns != 3 || analytics.InputTokens != 30 {
+		t.Errorf("after rotation = %+v", analytics)
+	}
+
+	// Removed files are forgotten
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := tailer.read(path, func(*claudeTranscript) {}); err == nil {
+		t.Error("reading a removed transcript should fail")
+	}
+	if _, ok := tailer.files[path]; ok {
+		t.Error("removed transcript is still followed")
+	}
+}
+
+func TestClaudeLastResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	appendFile(t, path, tailUserLine)
+	if _, err := claudeLastResponse(path); err == nil {
+		t.Error("transcript without an answer should fail")
+	}
+
+	appendFile(t, path, tailAssistantLine)
+	response, err := claudeLastResponse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Content != "Done." || response.SessionID != "s1" || response.Timestamp != "2026-03-11T10:00:00Z" {
+		t.Errorf("response = %+v", response)
+	}
+	if prompt, err := claudeLatestPrompt(path); err != nil || prompt != "fix the build" {
+		t.Errorf("prompt = %q, %v", prompt, err)
+	}
+}
+
+func TestClaudeTranscriptTailer_RewriteSameSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	appendFile(t, path, tailAssistantLine)
+	tailer := newClaudeTranscriptTailer()
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.InputTokens != 10 {
+		t.Fatalf("tokens = %d, want 10", analytics.InputTokens)
+	}
+
+	// Rewritten in place with a record of the same length
+	rewritten := strings.Replace(tailAssistantLine, `"input_tokens":10`, `"input_tokens":70`, 1)
+	if err := os.WriteFile(path, []byte(rewritten), 0600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if analytics, _, _ := tailSnapshot(t, tailer, path); analytics.InputTokens != 70 || analytics.TotalTurns != 1 {
+		t.Errorf("after rewrite = %+v", analytics)
+	}
+}
+
+func TestClaudeTranscriptTailer_SnapshotReused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	appendFile(t, path, tailAssistantLine)
+	tailer := newClaudeTranscriptTailer()
+
+	first, _, _ := tailSnapshot(t, tailer, path)
+	if again, _, _ := tailSnapshot(t, tailer, path); again != first {
+		t.Error("unchanged transcript should return the same snapshot")
+	}
+	appendFile(t, path, tailAssistantLine)
+	if changed, _, _ := tailSnapshot(t, tailer, path); changed == first || changed.TotalTurns != 2 {
+		t.Errorf("appended transcript returned %+v", changed)
+	}
+}
+
+func TestClaudeTranscriptTailer_ActivityCapped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	appendFile(t, path, strings.Repeat(tailAssistantLine, claudeActivityLimit+10))
+	tailer := newClaudeTranscriptTailer()
+
+	analytics, _, _ := tailSnapshot(t, tailer, path)
+	if len(analytics.Activity) != claudeActivityLimit || analytics.TotalTurns != claudeActivityLimit+10 {
+		t.Errorf("activity = %d points, turns = %d", len(analytics.Activity), analytics.TotalTurns)
+	}
+	appendFile(t, path, strings.Repeat(tailAssistantLine, claudeActivityLimit))
+	tailSnapshot(t, tailer, path)
+	if got := len(tailer.files[path].analytics.Activity); got >= 2*claudeActivityLimit {
+		t.Errorf("followed transcript keeps %d activity points", got)
+	}
+}
+
+func TestClaudeTranscriptTailer_EvictsIdle(t *testing.T) {
+	dir := t.TempDir()
+	idle := filepath.Join(dir, "idle.jsonl")
+	active := filepath.Join(dir, "active.jsonl")
+	appendFile(t, idle, tailAssistantLine)
+	appendFile(t, active, tailAssistantLine)
+	tailer := newClaudeTranscriptTailer()
+	tailSnapshot(t, tailer, idle)
+
+	stale := time.Now().Add(-2 * claudeTranscriptIdle)
+	tailer.files[idle].lastRead = stale
+	tailer.lastSweep = stale
+	tailSnapshot(t, tailer, active)
+	if _, ok := tailer.files[idle]; ok {
+		t.Error("transcript nobody read is still followed")
+	}
+	if _, ok := tailer.files[active]; !ok {
+		t.Error("transcript just read is not followed")
+	}
+}
+
+func TestClaudeTranscriptTailer_TurnBeforeReadPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	appendFile(t, path, tailUserLine+tailAssistantLine)
+	// The whole transcript is read before the turn is asked for
+	if _, err := ParseSessionJSONL(path); err != nil {
+		t.Fatal(err)
+	}
+
+	turn, err := ReadClaudeTurn(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !turn.PromptSeen || turn.Text() != "Done." || turn.EndOffset != TranscriptSize(path) {
+		t.Errorf("turn = %+v", turn)
+	}
+
+	appendFile(t, path, `{"type":"user","message":{"role":"user","content":"next"}}`+"\n"+tailAssistantLine)
+	if again, _ := ReadClaudeTurn(path, 0); again.Text() != "Done." || again.EndOffset != turn.EndOffset {
+		t.Errorf("turn changed after the next prompt: %+v", again)
+	}
+}
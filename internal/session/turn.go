@@ -0,0 +1,264 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TurnMessage is assistant text produced during a turn
+type TurnMessage struct {
+	Text      string `json:"text"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Offset    int64  `json:"offset"` // Byte offset of the transcript line it starts on
+}
+
+// TurnToolCall is a tool the assistant called during a turn, with its result
+// once the tool has run
+type TurnToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	Result    string          `json:"result,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+	Done      bool            `json:"done"` // A result was recorded
+	Timestamp string          `json:"timestamp,omitempty"`
+	Offset    int64           `json:"offset"`
+}
+
+// TranscriptTurn is what an agent produced in response to one prompt, read
+// from its JSONL transcript starting at a byte offset
+type TranscriptTurn struct {
+	StartOffset int64          `json:"start_offset"`
+	EndOffset   int64          `json:"end_offset"` // Just past the last complete line read
+	PromptSeen  bool           `json:"prompt_seen"`
+	Messages    []TurnMessage  `json:"messages"`
+	ToolCalls   []TurnToolCall `json:"tool_calls"`
+	// The last assistant output was text and every tool call has a result,
+	// i.e. the agent is no longer working on the prompt
+	Complete bool `json:"complete"`
+}
+
+// Text returns the turn's assistant messages joined by blank lines
+func (t *TranscriptTurn) Text() string {
+	texts := make([]string, 0, len(t.Messages))
+	for _, m := range t.Messages {
+		texts = append(texts, m.Text)
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// PendingToolCalls returns the tool calls that have no result yet. When the
+// agent is waiting for input with a pending call, it is asking for permission.
+func (t *TranscriptTurn) PendingToolCalls() []TurnToolCall {
+	var pending []TurnToolCall
+	for _, call := range t.ToolCalls {
+		if !call.Done {
+			pending = append(pending, call)
+		}
+	}
+	return pending
+}
+
+// TranscriptSize returns the current size of a transcript, or 0 if it
+// doesn't exist yet. Use it as the offset before sending a prompt.
+func TranscriptSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// ReadClaudeTurn reads the turn that starts at offset in a Claude JSONL
+// transcript: the first user prompt after offset and everything the assistant
+// did in response, up to the next user prompt. A missing transcript is an
+// empty turn, since Claude creates the file on the first prompt. The turn is
+// followed with the transcript, so polling it only reads what was appended.
+func ReadClaudeTurn(path string, offset int64) (*TranscriptTurn, error) {
+	var turn *TranscriptTurn
+	var turnErr error
+	err := claudeTranscripts.read(path, func(t *claudeTranscript) {
+		turn, turnErr = t.turn(path, offset)
+	})
+	if os.IsNotExist(err) {
+		return &TranscriptTurn{StartOffset: offset, EndOffset: offset}, nil
+	}
+	if err == nil {
+		err = turnErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return turn, nil
+}
+
+// claudeTurnReader builds a TranscriptTurn from the transcript lines after
+// its start, fed to it in order
+type claudeTurnReader struct {
+	turn              TranscriptTurn
+	calls             map[string]int // tool_use id -> index in turn.ToolCalls
+	lastMessageID     string
+	lastOutputWasText bool
+	done              bool // The next prompt was read
+}
+
+// newClaudeTurnReader creates a reader for the turn starting at offset
+func newClaudeTurnReader(offset int64) *claudeTurnReader {
+	return &claudeTurnReader{
+		turn:  TranscriptTurn{StartOffset: offset, EndOffset: offset},
+		calls: make(map[string]int),
+	}
+}
+
+// result returns a copy of the turn read so far
+func (r *claudeTurnReader) result() *TranscriptTurn {
+	turn := r.turn
+	if !r.done {
+		turn.Complete = turn.PromptSeen && r.lastOutputWasText && len(turn.PendingToolCalls()) == 0
+	}
+	turn.Messages = append([]TurnMessage(nil), r.turn.Messages...)
+	turn.ToolCalls = append([]TurnToolCall(nil), r.turn.ToolCalls...)
+	return &turn
+}
+
+// line adds the complete transcript line that starts at offset to the turn
+func (r *claudeTurnReader) line(line []byte, offset int64) {
+	type contentBlock struct {
+		Type      string          `json:"type"`
+		Text      string          `json:"text"`
+		ID        string          `json:"id"`
+		Name      string          `json:"name"`
+		Input     json.RawMessage `json:"input"`
+		ToolUseID string          `json:"tool_use_id"`
+		Content   json.RawMessage `json:"content"`
+		IsError   bool            `json:"is_error"`
+	}
+	type claudeMessage struct {
+		ID      string          `json:"id"`
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	type claudeRecord struct {
+		Type        string          `json:"type"`
+		IsSidechain bool            `json:"isSidechain"`
+		IsMeta      bool            `json:"isMeta"`
+		Message     json.RawMessage `json:"message"`
+		Timestamp   string          `json:"timestamp"`
+	}
+
+	if r.done {
+		return
+	}
+	turn := &r.turn
+	pos := offset + int64(len(line))
+
+	var record claudeRecord
+	if err := json.Unmarshal(line, &record); err != nil || len(record.Message) == 0 {
+		turn.EndOffset = pos
+		return
+	}
+	// Subagent (Task tool) chatter and injected context are not the turn's output
+	if record.IsSidechain || record.IsMeta {
+		turn.EndOffset = pos
+		return
+	}
+	var msg claudeMessage
+	if err := json.Unmarshal(record.Message, &msg); err != nil {
+		turn.EndOffset = pos
+		return
+	}
+
+	var blocks []contentBlock
+	var text string
+	if err := json.Unmarshal(msg.Content, &text); err == nil {
+		blocks = []contentBlock{{Type: "text", Text: text}}
+	} else if err := json.Unmarshal(msg.Content, &blocks); err != nil {
+		turn.EndOffset = pos
+		return
+	}
+
+	switch msg.Role {
+	case "user":
+		isPrompt := false
+		for _, b := range blocks {
+			if b.Type == "tool_result" {
+				idx, ok := r.calls[b.ToolUseID]
+				if !ok {
+					continue
+				}
+				turn.ToolCalls[idx].Done = true
+				turn.ToolCalls[idx].IsError = b.IsError
+				turn.ToolCalls[idx].Result = toolResultText(b.Content)
+			} else if b.Type == "text" {
+				isPrompt = true
+			}
+		}
+		if isPrompt {
+			if turn.PromptSeen {
+				// The next prompt starts a new turn
+				turn.Complete = r.lastOutputWasText && len(turn.PendingToolCalls()) == 0
+				r.done = true
+				return
+			}
+			turn.PromptSeen = true
+		}
+
+	case "assistant":
+		if !turn.PromptSeen {
+			break
+		}
+		for _, b := range blocks {
+			switch b.Type {
+			case "text":
+				if strings.TrimSpace(b.Text) == "" {
+					continue
+				}
+				// Claude writes one line per content block; blocks of the
+				// same API message form one message
+				if msg.ID != "" && msg.ID == r.lastMessageID && r.lastOutputWasText && len(turn.Messages) > 0 {
+					last := &turn.Messages[len(turn.Messages)-1]
+					last.Text += "\n" + b.Text
+				} else {
+					turn.Messages = append(turn.Messages, TurnMessage{Text: b.Text, Timestamp: record.Timestamp, Offset: offset})
+				}
+				r.lastOutputWasText = true
+			case "tool_use":
+				r.calls[b.ID] = len(turn.ToolCalls)
+				turn.ToolCalls = append(turn.ToolCalls, TurnToolCall{
+					ID:        b.ID,
+					Name:      b.Name,
+					Input:     b.Input,
+					Timestamp: record.Timestamp,
+					Offset:    offset,
+				})
+				r.lastOutputWasText = false
+			}
+		}
+		r.lastMessageID = msg.ID
+	}
+	turn.EndOffset = pos
+}
+
+// toolResultText flattens a tool_result's content (a string or text blocks)
+func toolResultText(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadClaudeTurn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	earlier := `{"type":"user","message":{"role":"user","content":"earlier prompt"}}
+{"type":"assistant","message":{"id":"m0","role":"assistant","content":[{"type":"text","text":"earlier answer"}]}}
+`
+	if err := os.WriteFile(path, []byte(earlier), 0644); err != nil {
+		t.Fatal(err)
+	}
+	offset := TranscriptSize(path)
+
+	// Nothing written yet after the offset
+	turn, err := ReadClaudeTurn(path, offset)
+	if err != nil || turn.PromptSeen || turn.Complete || turn.EndOffset != offset {
+		t.Fatalf("ReadClaudeTurn() before the prompt = %+v, %v", turn, err)
+	}
+
+	appendLines := func(lines string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(lines); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	appendLines(`{"type":"user","message":{"role":"user","content":"run the tests"},"timestamp":"t1"}
+{"type":"assistant","message":{"id":"m1","role":"assistant","content":[{"type":"text","text":"Running them."}]}}
+{"type":"assistant","message":{"id":"m1","role":"assistant","content":[{"type":"tool_use","id":"tu1","name":"Bash","input":{"command":"go test ./..."}}]}}
+`)
+	turn, _ = ReadClaudeTurn(path, offset)
+	if !turn.PromptSeen || turn.Complete {
+		t.Fatalf("turn with a pending tool call should not be complete: %+v", turn)
+	}
+	if pending := turn.PendingToolCalls(); len(pending) != 1 || pending[0].Name != "Bash" {
+		t.Errorf("PendingToolCalls() = %+v, want the Bash call", pending)
+	}
+
+	appendLines(`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu1","content":"ok  pkg 0.1s"}]}}
+{"type":"assistant","isSidechain":true,"message":{"id":"s1","role":"assistant","content":[{"type":"text","text":"subagent noise"}]}}
+{"type":"assistant","message":{"id":"m2","role":"assistant","content":[{"type":"text","text":"All tests pass."}]}}
+{"type":"assistant","message":{"id":"m2","role":"assistant","content":[{"type":"text","text":"Nothing to fix."}]}}
+{"type":"assistant","message":{"id":"m3","role":"assi`)
+	turn, _ = ReadClaudeTurn(path, offset)
+	if !turn.Complete {
+		t.Fatalf("turn should be complete once the last output is text: %+v", turn)
+	}
+	if len(turn.ToolCalls) != 1 || !turn.ToolCalls[0].Done || turn.ToolCalls[0].Result != "ok  pkg 0.1s" {
+		t.Errorf("ToolCalls = %+v, want the finished Bash call", turn.ToolCalls)
+	}
+	if got, want := turn.Text(), "Running them.\n\nAll tests pass.\nNothing to fix."; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if strings.Contains(turn.Text(), "earlier") || strings.Contains(turn.Text(), "subagent") {
+		t.Error("turn should exclude output before the offset and sidechain messages")
+	}
+	// The partial last line is not consumed
+	data, _ := os.ReadFile(path)
+	if turn.EndOffset != int64(strings.LastIndex(string(data), "\n")+1) {
+		t.Errorf("EndOffset = %d, want the end of the last complete line", turn.EndOffset)
+	}
+
+	// A following prompt ends the turn
+	appendLines(`stant","content":[{"type":"text","text":"late"}]}}
+{"type":"user","message":{"role":"user","content":"next prompt"}}
+{"type":"assistant","message":{"id":"m4","role":"assistant","content":[{"type":"text","text":"next answer"}]}}
+`)
+	turn, _ = ReadClaudeTurn(path, offset)
+	if !strings.HasSuffix(turn.Text(), "late") || strings.Contains(turn.Text(), "next answer") {
+		t.Errorf("Text() = %q, want output up to the next prompt only", turn.Text())
+	}
+
+	// Missing transcript: Claude hasn't written it yet
+	if turn, err := ReadClaudeTurn(filepath.Join(t.TempDir(), "missing.jsonl"), 0); err != nil || turn.PromptSeen {
+		t.Errorf("ReadClaudeTurn() on a missing file = %+v, %v; want an empty turn", turn, err)
+	}
+}
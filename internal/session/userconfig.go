@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/asheshgoplani/agent-deck/internal/platform"
@@ -71,6 +72,15 @@ type UserConfig struct {
 
 	// Maintenance defines automatic maintenance worker settings
 	Maintenance MaintenanceSettings `toml:"maintenance"`
+
+	// Checkpoints defines automatic git checkpoints of session working trees
+	Checkpoints CheckpointSettings `toml:"checkpoints"`
+
+	// Permissions defines which tool permission dialogs are answered automatically
+	Permissions PermissionSettings `toml:"permissions"`
+
+	// Budgets caps what sessions may spend in cost, tokens, turns and time
+	Budgets BudgetSettings `toml:"budgets"`
 }
 
 // MCPPoolSettings defines HTTP MCP pool configuration
@@ -111,6 +121,22 @@ type MCPPoolSettings struct {
 
 	// SocketWaitTimeout is seconds to wait for socket to become ready (default: 5)
 	SocketWaitTimeout int `toml:"socket_wait_timeout"`
+
+	// IdleTimeout is seconds the standalone pool process keeps an MCP running
+	// after no running session has it loaded (default: 600, -1 = never stop)
+	IdleTimeout int `toml:"idle_timeout"`
+}
+
+// GetIdleTimeout returns how long an unreferenced MCP may idle before the
+// standalone pool stops it. Zero means idle shutdown is disabled.
+func (m *MCPPoolSettings) GetIdleTimeout() time.Duration {
+	if m.IdleTimeout < 0 {
+		return 0
+	}
+	if m.IdleTimeout == 0 {
+		return 600 * time.Second
+	}
+	return time.Duration(m.IdleTimeout) * time.Second
 }
 
 // LogSettings defines log file management configuration
@@ -370,6 +396,27 @@ type WorktreeSettings struct {
 	DefaultLocation string `toml:"default_location"`
 	// AutoCleanup: remove worktree when session is deleted
 	AutoCleanup bool `toml:"auto_cleanup"`
+	// FinishStrategy: how `worktree finish` lands the branch: "rebase" (rebase onto
+	// the target, then fast-forward it), "squash" (squash-merge into the target) or
+	// "pr" (rebase and push the branch for a pull request without merging)
+	FinishStrategy string `toml:"finish_strategy"`
+	// FinishTarget: branch to land on (default: branch checked out in the main worktree)
+	FinishTarget string `toml:"finish_target"`
+	// FinishPush: push the branch to origin before landing it ("pr" always pushes)
+	FinishPush bool `toml:"finish_push"`
+	// FinishSession: what happens to the session afterwards: "delete" (default) or
+	// "archive" (stop it and move it to the archived group)
+	FinishSession string `toml:"finish_session"`
+	// CopyFiles: files or globs (relative to the repo root) copied from the main
+	// worktree into each new worktree, e.g. [".env", "config/*.local.json"]
+	CopyFiles []string `toml:"copy_files"`
+	// SymlinkFiles: files or globs symlinked instead of copied, e.g. ["node_modules"]
+	SymlinkFiles []string `toml:"symlink_files"`
+	// Bootstrap: shell command run in a new worktree before the agent starts
+	// (e.g. "npm install"). If it fails, the session shows an error instead.
+	Bootstrap string `toml:"bootstrap"`
+	// BootstrapTimeout: seconds the bootstrap command may run (default: 600)
+	BootstrapTimeout int `toml:"bootstrap_timeout"`
 }
 
 // GlobalSearchSettings defines global conversation search configuration
@@ -471,6 +518,7 @@ type MCPDef struct {
 	Args []string `toml:"args"`
 
 	// Env is optional environment variables
+	// Values may be secret references: "vault:NAME" or "env:NAME"
 	Env map[string]string `toml:"env"`
 
 	// Description is optional help text shown in the MCP Manager
@@ -486,6 +534,7 @@ type MCPDef struct {
 
 	// Headers is optional HTTP headers for HTTP/SSE MCPs (e.g., for authentication)
 	// Example: { Authorization = "Bearer token123" }
+	// Values may be secret references: "vault:NAME" or "env:NAME"
 	Headers map[string]string `toml:"headers"`
 
 	// Server defines how to auto-start an HTTP MCP server process
@@ -530,6 +579,48 @@ type MaintenanceSettings struct {
 	Enabled bool `toml:"enabled"`
 }
 
+// CheckpointSettings controls automatic git checkpoints. When enabled, each
+// time an agent finishes a turn (running -> waiting/idle) the session's working
+// tree is snapshotted into a hidden ref, refs/agent-deck/<session-id>/<n>,
+// without touching the index or branch.
+type CheckpointSettings struct {
+	// Enabled turns on checkpoints (default: false)
+	Enabled bool `toml:"enabled"`
+	// MaxPerSession: checkpoints kept per session, oldest pruned first (default: 50)
+	MaxPerSession int `toml:"max_per_session"`
+}
+
+// GetMaxPerSession returns the checkpoint limit with the default applied
+func (c CheckpointSettings) GetMaxPerSession() int {
+	if c.MaxPerSession <= 0 {
+		return 50
+	}
+	return c.MaxPerSession
+}
+
+// PermissionSettings controls tool permission dialogs in sessions that run
+// without --dangerously-skip-permissions. Such sessions show up as
+// needs-approval until someone answers the dialog.
+type PermissionSettings struct {
+	// AutoApprove lists rules in Claude's permission syntax whose dialogs are
+	// answered "Yes" automatically, e.g. "Read", "Bash(go test:*)" or
+	// "Edit(docs/*)". "*" matches anything, a trailing ":*" is a prefix match.
+	AutoApprove []string `toml:"auto_approve"`
+}
+
+// BudgetSettings defines session budgets. Each applies to every session it
+// covers separately; a group budget is not shared by the group's sessions.
+type BudgetSettings struct {
+	// Default applies to all sessions
+	Default Budget `toml:"default"`
+
+	// Groups are keyed by group path and also cover subgroups
+	Groups map[string]Budget `toml:"groups"`
+
+	// Sessions are keyed by session title or ID
+	Sessions map[string]Budget `toml:"sessions"`
+}
+
 // Default user config (empty maps)
 var defaultUserConfig = UserConfig{
 	Tools: make(map[string]ToolDef),
@@ -642,6 +733,12 @@ func SaveUserConfig(config *UserConfig) error {
 		return fmt.Errorf("failed to encode config: %w", err)
 	}
 
+	// Preserve comments and layout of an existing hand-edited file
+	if merged, ok := mergeExistingUserConfig(configPath, config); ok {
+		buf.Reset()
+		buf.WriteString(merged)
+	}
+
 	// ═══════════════════════════════════════════════════════════════════
 	// ATOMIC WRITE PATTERN: Prevents data corruption on crash/power loss
 	// 1. Write to temporary file with 0600 permissions
@@ -830,24 +927,25 @@ func GetLogSettings() LogSettings {
 func GetWorktreeSettings() WorktreeSettings {
 	config, err := LoadUserConfig()
 	if err != nil || config == nil {
-		return WorktreeSettings{
-			DefaultLocation: "sibling",
-			AutoCleanup:     true,
-		}
+		return worktreeSettingsWithDefaults(WorktreeSettings{})
 	}
+	return worktreeSettingsWithDefaults(config.Worktree)
+}
 
-	settings := config.Worktree
-
-	// Apply defaults for unset values
-	if settings.DefaultLocation == "" {
-		settings.DefaultLocation = "sibling"
-	}
+// worktreeSettingsWithDefaults fills in defaults for unset worktree settings
+func worktreeSettingsWithDefaults(settings WorktreeSettings) WorktreeSettings {
 	// AutoCleanup defaults to true (Go zero value is false)
 	// We detect if section was not present by checking if DefaultLocation is empty
-	if config.Worktree.DefaultLocation == "" {
+	if settings.DefaultLocation == "" {
+		settings.DefaultLocation = "sibling"
 		settings.AutoCleanup = true
 	}
-
+	if settings.FinishStrategy == "" {
+		settings.FinishStrategy = "rebase"
+	}
+	if settings.FinishSession == "" {
+		settings.FinishSession = "delete"
+	}
 	return settings
 }
 
@@ -966,6 +1064,24 @@ func GetMaintenanceSettings() MaintenanceSettings {
 	return config.Maintenance
 }
 
+// GetPermissionSettings returns permission dialog settings
+func GetPermissionSettings() PermissionSettings {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return PermissionSettings{}
+	}
+	return config.Permissions
+}
+
+// GetBudgetSettings returns session budget settings
+func GetBudgetSettings() BudgetSettings {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return BudgetSettings{}
+	}
+	return config.Budgets
+}
+
 // GetInstanceSettings returns instance behavior settings
 func GetInstanceSettings() InstanceSettings {
 	config, err := LoadUserConfig()
@@ -1003,6 +1119,10 @@ func getMCPPoolConfigSection() string {
 # pool_all = true           # Pool all MCPs defined above
 # fallback_to_stdio = true  # Fall back to stdio if socket fails
 # exclude_mcps = []         # MCPs to exclude from pooling
+# idle_timeout = 600        # Standalone pool: stop MCPs unused for N seconds (-1 = never)
+#
+# Run the pool as its own process so it outlives any single TUI:
+#   agent-deck mcp pool start
 `
 	}
 
@@ -1128,10 +1248,13 @@ default_tool = "claude"
 # description = "Read/write local files"
 
 # Example: GitHub MCP with token
+# Keep tokens out of this file and out of generated .mcp.json files:
+#   "vault:NAME" reads from the encrypted vault (agent-deck secrets set NAME)
+#   "env:NAME"   reads from agent-deck's environment at launch
 # [mcps.github]
 # command = "npx"
 # args = ["-y", "@modelcontextprotocol/server-github"]
-# env = { GITHUB_TOKEN = "ghp_your_token_here" }
+# env = { GITHUB_TOKEN = "vault:github_token" }
 # description = "GitHub repository operations"
 
 # Example: Sequential Thinking MCP
@@ -310,6 +310,9 @@ func TestGetWorktreeSettings(t *testing.T) {
 	if !settings.AutoCleanup {
 		t.Error("GetWorktreeSettings AutoCleanup: should default to true")
 	}
+	if settings.FinishStrategy != "rebase" || settings.FinishSession != "delete" {
+		t.Errorf("GetWorktreeSettings finish defaults: got %q/%q, want rebase/delete", settings.FinishStrategy, settings.FinishSession)
+	}
 }
 
 func TestGetWorktreeSettings_FromConfig(t *testing.T) {
@@ -326,6 +329,8 @@ func TestGetWorktreeSettings_FromConfig(t *testing.T) {
 		Worktree: WorktreeSettings{
 			DefaultLocation: "subdirectory",
 			AutoCleanup:     false,
+			FinishStrategy:  "squash",
+			FinishSession:   "archive",
 		},
 	}
 	_ = SaveUserConfig(config)
@@ -338,6 +343,9 @@ func TestGetWorktreeSettings_FromConfig(t *testing.T) {
 	if settings.AutoCleanup {
 		t.Error("GetWorktreeSettings AutoCleanup: should be false from config")
 	}
+	if settings.FinishStrategy != "squash" || settings.FinishSession != "archive" {
+		t.Errorf("GetWorktreeSettings finish: got %q/%q, want squash/archive", settings.FinishStrategy, settings.FinishSession)
+	}
 }
 
 // ============================================================================
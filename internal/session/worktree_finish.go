@@ -0,0 +1,186 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// ArchivedGroupPath is the group finished worktree sessions are moved to when archived
+const ArchivedGroupPath = "archived"
+
+// WorktreeFinishOptions controls how FinishWorktree lands a session's branch
+type WorktreeFinishOptions struct {
+	Strategy string // "rebase", "squash" or "pr"
+	Target   string // Branch to land on ("" = branch checked out in the main worktree)
+	Push     bool   // Push the branch to origin before landing it
+}
+
+// WorktreeFinishOptionsFromSettings returns finish options from the [worktree]
+// config, including the repository's trusted .agent-deck.toml
+func WorktreeFinishOptionsFromSettings(repoRoot string) WorktreeFinishOptions {
+	settings := GetRepoWorktreeSettings(repoRoot)
+	return WorktreeFinishOptions{
+		Strategy: settings.FinishStrategy,
+		Target:   settings.FinishTarget,
+		Push:     settings.FinishPush,
+	}
+}
+
+// WorktreeFinishResult describes what FinishWorktree did
+type WorktreeFinishResult struct {
+	Branch        string
+	Target        string
+	Strategy      string
+	Pushed        bool // Branch was pushed to origin
+	Merged        bool // Branch was landed on Target in the main worktree
+	BranchDeleted bool // Local branch was deleted after landing
+}
+
+// FinishWorktree lands the session's worktree branch and removes the worktree.
+//
+// Every step that can fail on repository state (uncommitted changes, a main
+// worktree on the wrong branch, rebase or merge conflicts, a rejected push)
+// runs before anything is removed, so an error leaves the worktree, branch and
+// session as they were. Conflicts are aborted and reported as git.ErrConflict.
+// The tmux session is stopped before the worktree is removed; deleting or
+// archiving the instance is left to the caller.
+func (i *Instance) FinishWorktree(opts WorktreeFinishOptions) (*WorktreeFinishResult, error) {
+	if !i.IsWorktree() {
+		return nil, fmt.Errorf("session '%s' is not in a worktree", i.Title)
+	}
+	if _, err := os.Stat(i.WorktreePath); err != nil {
+		return nil, fmt.Errorf("worktree %s not found: %w", i.WorktreePath, err)
+	}
+
+	switch opts.Strategy {
+	case "":
+		opts.Strategy = "rebase"
+	case "rebase", "squash", "pr":
+	default:
+		return nil, fmt.Errorf("unknown finish strategy %q (use rebase, squash or pr)", opts.Strategy)
+	}
+
+	repoRoot := i.WorktreeRepoRoot
+	if repoRoot == "" {
+		mainPath, err := git.GetMainWorktreePath(i.WorktreePath)
+		if err != nil {
+			return nil, err
+		}
+		repoRoot = mainPath
+	}
+	branch := i.WorktreeBranch
+	if branch == "" {
+		current, err := git.GetCurrentBranch(i.WorktreePath)
+		if err != nil {
+			return nil, err
+		}
+		branch = current
+	}
+
+	// Nothing may be lost: the worktree must be fully committed
+	status, err := git.GetStatus(i.WorktreePath)
+	if err != nil {
+		return nil, err
+	}
+	if status.Dirty() {
+		return nil, fmt.Errorf("worktree has %d uncommitted change(s); commit or stash them first", len(status.Files))
+	}
+
+	target := opts.Target
+	mainBranch, err := git.GetCurrentBranch(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	if target == "" {
+		if mainBranch == "HEAD" {
+			return nil, errors.New("main worktree has a detached HEAD; pass a target branch")
+		}
+		target = mainBranch
+	}
+	if target == branch {
+		return nil, fmt.Errorf("target branch %s is the session's own branch", target)
+	}
+	if !git.BranchExists(repoRoot, target) {
+		return nil, fmt.Errorf("target branch %s does not exist", target)
+	}
+
+	result := &WorktreeFinishResult{Branch: branch, Target: target, Strategy: opts.Strategy}
+
+	// Landing on the target happens in the main worktree, which must have it
+	// checked out and must not have tracked changes a merge could touch
+	if opts.Strategy != "pr" {
+		if mainBranch != target {
+			return nil, fmt.Errorf("main worktree %s is on %s, not %s; check out %s there first", repoRoot, mainBranch, target, target)
+		}
+		mainStatus, err := git.GetStatus(repoRoot)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range mainStatus.Files {
+			if f.Index != '?' {
+				return nil, fmt.Errorf("main worktree %s has uncommitted changes; commit or stash them first", repoRoot)
+			}
+		}
+	}
+
+	if opts.Strategy != "squash" {
+		if err := git.Rebase(i.WorktreePath, target); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Push || opts.Strategy == "pr" {
+		if err := git.PushBranch(i.WorktreePath, branch); err != nil {
+			return nil, err
+		}
+		result.Pushed = true
+	}
+
+	switch opts.Strategy {
+	case "rebase":
+		if err := git.MergeFastForward(repoRoot, branch); err != nil {
+			return nil, err
+		}
+		result.Merged = true
+	case "squash":
+		if err := git.SquashMerge(repoRoot, branch, fmt.Sprintf("%s (%s)", i.Title, branch)); err != nil {
+			return nil, err
+		}
+		result.Merged = true
+	}
+
+	// Point of no return: stop the session and remove its worktree
+	if i.Exists() {
+		if err := i.Kill(); err != nil {
+			return result, err
+		}
+	}
+	if err := git.RemoveWorktree(repoRoot, i.WorktreePath, false); err != nil {
+		return result, err
+	}
+
+	// A PR branch lives on until the PR is merged
+	if result.Merged {
+		if err := git.DeleteBranch(repoRoot, branch, opts.Strategy == "squash"); err != nil {
+			return result, err
+		}
+		result.BranchDeleted = true
+	}
+
+	return result, nil
+}
+
+// ArchiveFinishedWorktree points a finished worktree session back at its
+// repository so it can be kept after its worktree is gone. The caller moves
+// it to ArchivedGroupPath and saves.
+func (i *Instance) ArchiveFinishedWorktree() {
+	if i.WorktreeRepoRoot != "" {
+		i.ProjectPath = i.WorktreeRepoRoot
+	}
+	i.WorktreePath = ""
+	i.WorktreeRepoRoot = ""
+	i.WorktreeBranch = ""
+}
@@ -0,0 +1,134 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/git/gittest"
+)
+
+// newFinishTestSession creates a repo with one commit and a worktree session on branch
+func newFinishTestSession(t *testing.T, branch string) (*Instance, string) {
+	t.Helper()
+	repo := gittest.NewRepo(t)
+	worktree := git.GenerateWorktreePath(repo, branch, "sibling")
+	if err := git.CreateWorktree(repo, worktree, branch); err != nil {
+		t.Fatal(err)
+	}
+	gittest.Run(t, worktree, "config", "user.email", "test@test.com")
+	gittest.Run(t, worktree, "config", "user.name", "Test User")
+
+	inst := NewInstance("agent", worktree)
+	inst.WorktreePath = worktree
+	inst.WorktreeRepoRoot = repo
+	inst.WorktreeBranch = branch
+	return inst, repo
+}
+
+func TestFinishWorktree_Rebase(t *testing.T) {
+	inst, repo := newFinishTestSession(t, "feature/a")
+	worktree := inst.WorktreePath
+	gittest.CommitFile(t, worktree, "feature.txt", "feature\n")
+	gittest.CommitFile(t, repo, "main.txt", "main moved on\n")
+
+	result, err := inst.FinishWorktree(WorktreeFinishOptions{Strategy: "rebase"})
+	if err != nil {
+		t.Fatalf("FinishWorktree() failed: %v", err)
+	}
+	if result.Target != "main" || !result.Merged || !result.BranchDeleted || result.Pushed {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "feature.txt")); err != nil {
+		t.Error("feature commit should be on main")
+	}
+	if _, err := os.Stat(worktree); !os.IsNotExist(err) {
+		t.Error("worktree should be removed")
+	}
+	if git.BranchExists(repo, "feature/a") {
+		t.Error("branch should be deleted")
+	}
+	// Rebase + fast-forward keeps history linear
+	if merges := gittest.Run(t, repo, "rev-list", "--merges", "main"); merges != "" {
+		t.Errorf("expected no merge commits, got %s", merges)
+	}
+}
+
+func TestFinishWorktree_Squash(t *testing.T) {
+	inst, repo := newFinishTestSession(t, "feature/b")
+	gittest.CommitFile(t, inst.WorktreePath, "one.txt", "1\n")
+	gittest.CommitFile(t, inst.WorktreePath, "two.txt", "2\n")
+
+	if _, err := inst.FinishWorktree(WorktreeFinishOptions{Strategy: "squash"}); err != nil {
+		t.Fatalf("FinishWorktree() failed: %v", err)
+	}
+	if count := gittest.Run(t, repo, "rev-list", "--count", "main"); count != "2" {
+		t.Errorf("expected init + one squash commit, got %s commits", count)
+	}
+	if git.BranchExists(repo, "feature/b") {
+		t.Error("branch should be deleted")
+	}
+}
+
+func TestFinishWorktree_StopsSafely(t *testing.T) {
+	t.Run("uncommitted changes", func(t *testing.T) {
+		inst, _ := newFinishTestSession(t, "feature/dirty")
+		if err := os.WriteFile(filepath.Join(inst.WorktreePath, "wip.txt"), []byte("wip"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := inst.FinishWorktree(WorktreeFinishOptions{})
+		if err == nil || !strings.Contains(err.Error(), "uncommitted") {
+			t.Fatalf("expected uncommitted changes error, got %v", err)
+		}
+		if _, err := os.Stat(inst.WorktreePath); err != nil {
+			t.Error("worktree must be kept")
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		inst, repo := newFinishTestSession(t, "feature/conflict")
+		gittest.CommitFile(t, inst.WorktreePath, "shared.txt", "from worktree\n")
+		gittest.CommitFile(t, repo, "shared.txt", "from main\n")
+		head := gittest.Run(t, inst.WorktreePath, "rev-parse", "HEAD")
+
+		_, err := inst.FinishWorktree(WorktreeFinishOptions{Strategy: "rebase"})
+		if !errors.Is(err, git.ErrConflict) {
+			t.Fatalf("expected ErrConflict, got %v", err)
+		}
+		if got := gittest.Run(t, inst.WorktreePath, "rev-parse", "HEAD"); got != head {
+			t.Error("rebase should be aborted and the branch left untouched")
+		}
+		if status, _ := git.GetStatus(inst.WorktreePath); status.Dirty() {
+			t.Error("worktree should be clean after abort")
+		}
+		if !git.BranchExists(repo, "feature/conflict") {
+			t.Error("branch must be kept")
+		}
+	})
+
+	t.Run("main worktree on another branch", func(t *testing.T) {
+		inst, repo := newFinishTestSession(t, "feature/c")
+		gittest.Run(t, repo, "checkout", "-q", "-b", "other")
+		_, err := inst.FinishWorktree(WorktreeFinishOptions{Target: "main"})
+		if err == nil || !strings.Contains(err.Error(), "check out main") {
+			t.Fatalf("expected wrong-branch error, got %v", err)
+		}
+	})
+}
+
+func TestArchiveFinishedWorktree(t *testing.T) {
+	inst := NewInstance("agent", "/repo-feature")
+	inst.WorktreePath = "/repo-feature"
+	inst.WorktreeRepoRoot = "/repo"
+	inst.WorktreeBranch = "feature"
+
+	inst.ArchiveFinishedWorktree()
+
+	if inst.ProjectPath != "/repo" || inst.IsWorktree() || inst.WorktreeBranch != "" {
+		t.Errorf("unexpected instance after archive: path=%q worktree=%q branch=%q",
+			inst.ProjectPath, inst.WorktreePath, inst.WorktreeBranch)
+	}
+}
@@ -0,0 +1,172 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultBootstrapTimeout caps a worktree bootstrap command when bootstrap_timeout is unset
+const defaultBootstrapTimeout = 10 * time.Minute
+
+// SetupWorktree copies and symlinks the configured untracked files (.env,
+// node_modules, local config) from the main worktree into a new worktree.
+// Patterns are globs relative to the repo root; patterns matching nothing are
+// skipped and files already present in the worktree are left alone.
+func SetupWorktree(repoRoot, worktreePath string, settings WorktreeSettings) error {
+	if err := linkWorktreeFiles(repoRoot, worktreePath, settings.CopyFiles, copyPath); err != nil {
+		return err
+	}
+	return linkWorktreeFiles(repoRoot, worktreePath, settings.SymlinkFiles, os.Symlink)
+}
+
+// linkWorktreeFiles applies op(src, dst) to every match of patterns in repoRoot
+func linkWorktreeFiles(repoRoot, worktreePath string, patterns []string, op func(src, dst string) error) error {
+	for _, pattern := range patterns {
+		clean := filepath.Clean(pattern)
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("worktree file pattern %q must be relative to the repo root", pattern)
+		}
+		matches, err := filepath.Glob(filepath.Join(repoRoot, clean))
+		if err != nil {
+			return fmt.Errorf("invalid worktree file pattern %q: %w", pattern, err)
+		}
+		for _, src := range matches {
+			rel, err := filepath.Rel(repoRoot, src)
+			if err != nil {
+				return err
+			}
+			dst := filepath.Join(worktreePath, rel)
+			if _, err := os.Lstat(dst); err == nil {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			if err := op(src, dst); err != nil {
+				return fmt.Errorf("failed to set up %s in worktree: %w", rel, err)
+			}
+		}
+	}
+	return nil
+}
+
+// copyPath copies a file, symlink or directory tree, keeping file modes
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.IsDir():
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// PrepareWorktree sets up a newly created worktree session: configured files are
+// copied or symlinked now, and a configured bootstrap command is marked to run
+// on the next Start, before the agent launches.
+func (i *Instance) PrepareWorktree() error {
+	if !i.IsWorktree() || i.WorktreeRepoRoot == "" {
+		return nil
+	}
+	settings := GetRepoWorktreeSettings(i.WorktreeRepoRoot)
+	if err := SetupWorktree(i.WorktreeRepoRoot, i.WorktreePath, settings); err != nil {
+		return err
+	}
+	i.BootstrapPending = strings.TrimSpace(settings.Bootstrap) != ""
+	return nil
+}
+
+// runWorktreeBootstrap runs a pending bootstrap command in the worktree. On
+// failure the session is put in the error state with BootstrapError explaining
+// why, and the bootstrap stays pending so the next start retries it.
+func (i *Instance) runWorktreeBootstrap() error {
+	if !i.BootstrapPending {
+		return nil
+	}
+	settings := GetRepoWorktreeSettings(i.WorktreeRepoRoot)
+	command := strings.TrimSpace(settings.Bootstrap)
+	if command == "" {
+		i.BootstrapPending = false
+		i.BootstrapError = ""
+		return nil
+	}
+
+	timeout := defaultBootstrapTimeout
+	if settings.BootstrapTimeout > 0 {
+		timeout = time.Duration(settings.BootstrapTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = i.WorktreePath
+	cmd.Env = append(os.Environ(),
+		"AGENTDECK_REPO_ROOT="+i.WorktreeRepoRoot,
+		"AGENTDECK_WORKTREE="+i.WorktreePath,
+	)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Run(); err != nil {
+		reason := err.Error()
+		if ctx.Err() == context.DeadlineExceeded {
+			reason = fmt.Sprintf("timed out after %s", timeout)
+		}
+		lines := strings.Split(strings.TrimRight(output.String(), "\n"), "\n")
+		if len(lines) > 5 {
+			lines = lines[len(lines)-5:]
+		}
+		i.BootstrapError = reason
+		if tail := strings.TrimSpace(strings.Join(lines, "\n")); tail != "" {
+			i.BootstrapError += ": " + tail
+		}
+		i.Status = StatusError
+		return fmt.Errorf("worktree bootstrap `%s` failed: %s", command, i.BootstrapError)
+	}
+
+	i.BootstrapPending = false
+	i.BootstrapError = ""
+	return nil
+}
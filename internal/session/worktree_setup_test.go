@@ -0,0 +1,144 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git/gittest"
+)
+
+func TestGetRepoWorktreeSettings(t *testing.T) {
+	writeTestConfig(t, `
+[worktree]
+default_location = "subdirectory"
+copy_files = [".env"]
+bootstrap = "make setup"
+`)
+
+	repo := gittest.NewRepo(t)
+	if got := GetRepoWorktreeSettings(repo); got.Bootstrap != "make setup" || !reflect.DeepEqual(got.CopyFiles, []string{".env"}) {
+		t.Errorf("without a repo config the global settings apply, got %+v", got)
+	}
+
+	repoConfig := `
+[worktree]
+symlink_files = ["node_modules"]
+bootstrap = ""
+`
+	if err := os.WriteFile(filepath.Join(repo, RepoConfigFileName), []byte(repoConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetRepoWorktreeSettings(repo); got.Bootstrap != "make setup" {
+		t.Errorf("an untrusted repo config should be ignored, got %+v", got)
+	}
+
+	trustTestRepoConfig(t, repo)
+	got := GetRepoWorktreeSettings(repo)
+	if got.DefaultLocation != "subdirectory" || !reflect.DeepEqual(got.CopyFiles, []string{".env"}) {
+		t.Errorf("keys missing from the repo config should keep global values, got %+v", got)
+	}
+	if !reflect.DeepEqual(got.SymlinkFiles, []string{"node_modules"}) || got.Bootstrap != "" {
+		t.Errorf("repo config should override global values, got %+v", got)
+	}
+}
+
+func TestSetupWorktree(t *testing.T) {
+	repo := t.TempDir()
+	worktree := t.TempDir()
+	files := map[string]string{
+		".env":                   "SECRET=1\n",
+		"config/app.local.json":  "{}\n",
+		"config/db.local.json":   "{}\n",
+		"node_modules/pkg/index": "module\n",
+		"README.md":              "main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Tracked files already checked out in the worktree are not overwritten
+	if err := os.WriteFile(filepath.Join(worktree, "README.md"), []byte("worktree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := WorktreeSettings{
+		CopyFiles:    []string{".env", "config/*.local.json", "README.md", "missing.txt"},
+		SymlinkFiles: []string{"node_modules"},
+	}
+	if err := SetupWorktree(repo, worktree, settings); err != nil {
+		t.Fatalf("SetupWorktree() failed: %v", err)
+	}
+
+	for _, name := range []string{".env", "config/app.local.json", "config/db.local.json"} {
+		data, err := os.ReadFile(filepath.Join(worktree, name))
+		if err != nil || string(data) != files[name] {
+			t.Errorf("%s not copied: %q, %v", name, data, err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(worktree, "README.md")); string(data) != "worktree\n" {
+		t.Error("existing worktree file should be left alone")
+	}
+	target, err := os.Readlink(filepath.Join(worktree, "node_modules"))
+	if err != nil || target != filepath.Join(repo, "node_modules") {
+		t.Errorf("node_modules should be symlinked to the main worktree, got %q, %v", target, err)
+	}
+
+	if err := SetupWorktree(repo, worktree, WorktreeSettings{CopyFiles: []string{"../outside"}}); err == nil {
+		t.Error("patterns outside the repo root should be rejected")
+	}
+}
+
+func TestRunWorktreeBootstrap(t *testing.T) {
+	repo := t.TempDir()
+	worktree := t.TempDir()
+
+	newSession := func(bootstrap string) *Instance {
+		writeTestConfig(t, "[worktree]\nbootstrap = '"+bootstrap+"'\n")
+		inst := NewInstance("agent", worktree)
+		inst.WorktreePath = worktree
+		inst.WorktreeRepoRoot = repo
+		inst.WorktreeBranch = "feature"
+		if err := inst.PrepareWorktree(); err != nil {
+			t.Fatalf("PrepareWorktree() failed: %v", err)
+		}
+		if !inst.BootstrapPending {
+			t.Fatal("bootstrap should be pending after PrepareWorktree")
+		}
+		return inst
+	}
+
+	t.Run("success", func(t *testing.T) {
+		inst := newSession(`echo "$AGENTDECK_REPO_ROOT" > bootstrapped`)
+		if err := inst.runWorktreeBootstrap(); err != nil {
+			t.Fatalf("runWorktreeBootstrap() failed: %v", err)
+		}
+		if inst.BootstrapPending || inst.BootstrapError != "" {
+			t.Errorf("bootstrap should be done: pending=%v error=%q", inst.BootstrapPending, inst.BootstrapError)
+		}
+		if data, _ := os.ReadFile(filepath.Join(worktree, "bootstrapped")); strings.TrimSpace(string(data)) != repo {
+			t.Errorf("bootstrap should run in the worktree with AGENTDECK_REPO_ROOT set, got %q", data)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		inst := newSession("echo missing dependency; exit 3")
+		err := inst.runWorktreeBootstrap()
+		if err == nil {
+			t.Fatal("expected bootstrap error")
+		}
+		if inst.Status != StatusError || !inst.BootstrapPending {
+			t.Errorf("failed bootstrap should leave the session in error with the bootstrap pending, got status=%s pending=%v", inst.Status, inst.BootstrapPending)
+		}
+		if !strings.Contains(inst.BootstrapError, "missing dependency") {
+			t.Errorf("BootstrapError should include the command output, got %q", inst.BootstrapError)
+		}
+	})
+}
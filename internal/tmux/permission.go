@@ -0,0 +1,156 @@
+package tmux
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PermissionRequest is a tool permission dialog found in a pane: the agent
+// asked whether it may run Tool on Command and is blocked until answered
+type PermissionRequest struct {
+	Tool    string // Claude tool name: Bash, Edit, Write, Read, WebFetch, MCP
+	Command string // Bash command, file path, URL or MCP call; may be empty
+
+	// AllowAlways is set when option 2 is a "Yes, and don't ask again" style
+	// answer rather than "No"
+	AllowAlways bool
+}
+
+// permissionHeaders maps the header line above a Claude permission dialog
+// to the tool it asks about
+var permissionHeaders = map[string]string{
+	"Bash command": "Bash",
+	"Edit file":    "Edit",
+	"Create file":  "Write",
+	"Write file":   "Write",
+	"Read file":    "Read",
+	"Fetch":        "WebFetch",
+	"Tool use":     "MCP",
+}
+
+// permissionScanLines is how far from the bottom of the pane a dialog is
+// looked for; answered dialogs scroll away or are cleared
+const permissionScanLines = 40
+
+// permissionFileQuestion extracts the file from "Do you want to make this
+// edit to foo.go?" and "Do you want to create foo.go?"
+var permissionFileQuestion = regexp.MustCompile(`^Do you want to (?:make this edit to|create|overwrite) (.+?)\?$`)
+
+// ParsePermissionPrompt finds an unanswered tool permission dialog at the
+// bottom of the pane and returns what it asks for. Only dialogs with both
+// a question ("Do you want ...") and a "Yes" option below it are reported,
+// so the trust prompt and ordinary questions in the transcript don't match.
+func ParsePermissionPrompt(content string) (PermissionRequest, bool) {
+	lines := strings.Split(StripANSI(content), "\n")
+	var clean []string
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "│┃"))
+		if line == "" || isBorderLine(line) {
+			continue
+		}
+		clean = append(clean, line)
+	}
+	if len(clean) > permissionScanLines {
+		clean = clean[len(clean)-permissionScanLines:]
+	}
+
+	question := -1
+	for idx := len(clean) - 1; idx >= 0; idx-- {
+		if strings.HasPrefix(clean[idx], "Do you want") || strings.HasPrefix(clean[idx], "Would you like") {
+			question = idx
+			break
+		}
+	}
+	if question < 0 {
+		return PermissionRequest{}, false
+	}
+	yes, always := permissionOptions(clean[question+1:])
+	if !yes {
+		return PermissionRequest{}, false
+	}
+
+	req := PermissionRequest{AllowAlways: always}
+	header := -1
+	for idx := question - 1; idx >= 0; idx-- {
+		if tool, ok := permissionHeaders[clean[idx]]; ok {
+			req.Tool = tool
+			header = idx
+			break
+		}
+	}
+	if m := permissionFileQuestion.FindStringSubmatch(clean[question]); m != nil {
+		req.Command = m[1]
+	} else if header >= 0 {
+		command, ok := permissionCommand(clean[header+1 : question])
+		if !ok {
+			return PermissionRequest{}, false
+		}
+		req.Command = command
+	}
+	return req, true
+}
+
+// permissionCommand rebuilds the command from the lines between a dialog's
+// header and its question. Long commands wrap and multi-line commands keep
+// their lines, so every line counts; the lines are joined with newlines,
+// which keeps such commands from matching an auto_approve pattern. Claude
+// may show a one-line description below the command. It is only dropped
+// when it clearly reads as one; a line that might be the wrapped end of the
+// command is kept, so the command has several lines and is never approved
+// automatically. Without any command line the dialog can't be trusted and
+// ok is false.
+func permissionCommand(body []string) (string, bool) {
+	if len(body) > 1 && isPermissionDescription(body[len(body)-1]) {
+		body = body[:len(body)-1]
+	}
+	if len(body) == 0 {
+		return "", false
+	}
+	return strings.Join(body, "\n"), true
+}
+
+// isPermissionDescription reports whether a line reads as the sentence
+// Claude shows below a command rather than part of the command: several
+// words starting with a capital letter, and only letters, digits and
+// punctuation a sentence uses, so no shell operators, flags or paths
+func isPermissionDescription(line string) bool {
+	words := strings.Fields(strings.TrimSuffix(line, "."))
+	if len(words) < 2 || !unicode.IsUpper([]rune(words[0])[0]) {
+		return false
+	}
+	for _, word := range words {
+		for _, r := range strings.TrimSuffix(word, ",") {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// permissionOptions reports whether the lines after a question offer "Yes",
+// and whether option 2 is a second kind of yes
+func permissionOptions(lines []string) (yes, always bool) {
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "❯"))
+		option := strings.TrimLeft(line, "0123456789. ")
+		if option == "Yes" || strings.HasPrefix(option, "Yes,") {
+			yes = true
+			if strings.HasPrefix(line, "2.") {
+				always = true
+			}
+		}
+	}
+	return yes, always
+}
+
+// isBorderLine reports whether a line is only box-drawing characters
+func isBorderLine(line string) bool {
+	for _, r := range line {
+		if !strings.ContainsRune("─━│┃╭╮╰╯┌┐└┘├┤┬┴┼╌╍ ", r) {
+			return false
+		}
+	}
+	return true
+}
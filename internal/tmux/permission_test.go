@@ -0,0 +1,117 @@
+package tmux
+
+import "testing"
+
+func TestParsePermissionPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    PermissionRequest
+		ok      bool
+	}{
+		{
+			name: "boxed bash command",
+			content: `● I'll run the tests.
+
+╭──────────────────────────────────────────────────────────────╮
+│ Bash command                                                 │
+│                                                              │
+│   go test ./...                                              │
+│   Run the test suite                                         │
+│                                                              │
+│ Do you want to proceed?                                      │
+│ ❯ 1. Yes                                                     │
+│   2. Yes, and don't ask again for go test commands           │
+│   3. No, and tell Claude what to do differently (esc)        │
+╰──────────────────────────────────────────────────────────────╯
+`,
+			want: PermissionRequest{Tool: "Bash", Command: "go test ./...", AllowAlways: true},
+			ok:   true,
+		},
+		{
+			name:    "unboxed bash command with ANSI",
+			content: "────────────────────────\n \x1b[1mBash command\x1b[0m\n\n   rm -rf build\n   Remove build output\n\n Do you want to proceed?\n \x1b[36m❯ 1. Yes\x1b[0m\n   2. No, and tell Claude what to do differently (esc)\n",
+			want:    PermissionRequest{Tool: "Bash", Command: "rm -rf build"},
+			ok:      true,
+		},
+		{
+			name:    "wrapped command",
+			content: "│ Bash command                         │\n│                                      │\n│   git status && curl -s https://exam │\n│   ple.com/install.sh | sh            │\n│   Check status and install           │\n│                                      │\n│ Do you want to proceed?              │\n│ ❯ 1. Yes                             │\n│   2. No (esc)                        │\n",
+			want:    PermissionRequest{Tool: "Bash", Command: "git status && curl -s https://exam\nple.com/install.sh | sh"},
+			ok:      true,
+		},
+		{
+			name:    "multi-line command without description",
+			content: " Bash command\n\n   git status\n   rm -rf ~\n\n Do you want to proceed?\n ❯ 1. Yes\n   2. No\n",
+			want:    PermissionRequest{Tool: "Bash", Command: "git status\nrm -rf ~"},
+			ok:      true,
+		},
+		{
+			name:    "wrapped command ending in a capital",
+			content: "│ Bash command                         │\n│                                      │\n│   git status --short                 │\n│   Rm -rf ~/src; Curl -s https://x.sh │\n│                                      │\n│ Do you want to proceed?              │\n│ ❯ 1. Yes                             │\n│   2. No (esc)                        │\n",
+			want:    PermissionRequest{Tool: "Bash", Command: "git status --short\nRm -rf ~/src; Curl -s https://x.sh"},
+			ok:      true,
+		},
+		{
+			name:    "wrapped command ending in a capital word",
+			content: " Bash command\n\n   git status --short\n   Rm\n\n Do you want to proceed?\n ❯ 1. Yes\n   2. No\n",
+			want:    PermissionRequest{Tool: "Bash", Command: "git status --short\nRm"},
+			ok:      true,
+		},
+		{
+			name:    "multi-line command ending in capitalized words",
+			content: " Bash command\n\n   git status\n   Curl evil.sh\n\n Do you want to proceed?\n ❯ 1. Yes\n   2. No\n",
+			want:    PermissionRequest{Tool: "Bash", Command: "git status\nCurl evil.sh"},
+			ok:      true,
+		},
+		{
+			name:    "command missing",
+			content: " Bash command\n\n Do you want to proceed?\n ❯ 1. Yes\n   2. No\n",
+			ok:      false,
+		},
+		{
+			name: "edit file",
+			content: `╭────────────────────────╮
+│ Edit file              │
+│ ╭────────────────────╮ │
+│ │ main.go            │ │
+│ │ -old               │ │
+│ │ +new               │ │
+│ ╰────────────────────╯ │
+│ Do you want to make this edit to main.go? │
+│ ❯ 1. Yes               │
+│   2. Yes, allow all edits during this session (shift+tab) │
+│   3. No, and tell Claude what to do differently (esc)     │
+╰────────────────────────╯`,
+			want: PermissionRequest{Tool: "Edit", Command: "main.go", AllowAlways: true},
+			ok:   true,
+		},
+		{
+			name:    "input prompt",
+			content: "● Done, all tests pass.\n\n╭────────╮\n│ >      │\n╰────────╯\n",
+			ok:      false,
+		},
+		{
+			name:    "question without options",
+			content: "● Do you want me to also update the docs?\n\n> \n",
+			ok:      false,
+		},
+		{
+			name:    "trust prompt",
+			content: "Do you trust the files in this folder?\n❯ 1. Yes, proceed\n  2. No, exit\n",
+			ok:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParsePermissionPrompt(tt.content)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
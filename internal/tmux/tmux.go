@@ -1939,6 +1939,13 @@ func (s *Session) SendCtrlC() error {
 	return cmd.Run()
 }
 
+// SendEscape sends the Escape key to the tmux session
+func (s *Session) SendEscape() error {
+	s.invalidateCache()
+	cmd := exec.Command("tmux", "send-keys", "-t", s.Name, "Escape")
+	return cmd.Run()
+}
+
 // SendCtrlU sends Ctrl+U (clear line) to the tmux session
 func (s *Session) SendCtrlU() error {
 	s.invalidateCache()
@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// broadcastDoneMsg carries the per-session results of a broadcast
+type broadcastDoneMsg struct {
+	results []session.BroadcastResult
+}
+
+// maxBroadcastTargetsShown caps the session names listed above the input
+const maxBroadcastTargetsShown = 8
+
+// BroadcastDialog sends one message to several sessions and shows what
+// happened in each
+type BroadcastDialog struct {
+	visible bool
+	width   int
+	height  int
+
+	label   string // What was selected, e.g. "3 selected sessions" or "group work"
+	targets []*session.Instance
+	input   textinput.Model
+	force   bool
+
+	sending bool
+	results []session.BroadcastResult
+}
+
+// NewBroadcastDialog creates a new broadcast dialog
+func NewBroadcastDialog() *BroadcastDialog {
+	input := textinput.New()
+	input.Placeholder = "message, e.g. pull main and rerun the tests"
+	input.CharLimit = 4000
+	input.Width = 60
+	return &BroadcastDialog{input: input}
+}
+
+// Show opens the dialog for a set of target sessions
+func (d *BroadcastDialog) Show(targets []*session.Instance, label string) tea.Cmd {
+	d.visible = true
+	d.targets = targets
+	d.label = label
+	d.force = false
+	d.sending = false
+	d.results = nil
+	d.input.SetValue("")
+	return d.input.Focus()
+}
+
+// Hide hides the broadcast dialog
+func (d *BroadcastDialog) Hide() {
+	d.visible = false
+	d.input.Blur()
+}
+
+// IsVisible returns whether the broadcast dialog is visible
+func (d *BroadcastDialog) IsVisible() bool {
+	return d.visible
+}
+
+// SetSize sets the dimensions of the dialog
+func (d *BroadcastDialog) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+	d.input.Width = d.dialogWidth() - 8
+}
+
+// SetResults shows the outcome of the broadcast
+func (d *BroadcastDialog) SetResults(msg broadcastDoneMsg) {
+	d.sending = false
+	d.results = msg.results
+}
+
+// Update handles keys. On enter it returns the command that performs the
+// broadcast in the background.
+func (d *BroadcastDialog) Update(msg tea.KeyMsg) (*BroadcastDialog, tea.Cmd) {
+	if !d.visible {
+		return d, nil
+	}
+	if d.sending {
+		return d, nil
+	}
+	if d.results != nil {
+		switch msg.String() {
+		case "esc", "enter", "q":
+			d.Hide()
+		}
+		return d, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		d.Hide()
+		return d, nil
+	case "tab":
+		d.force = !d.force
+		return d, nil
+	case "enter":
+		message := strings.TrimSpace(d.input.Value())
+		if message == "" || len(d.targets) == 0 {
+			return d, nil
+		}
+		d.sending = true
+		d.input.Blur()
+		targets := d.targets
+		opts := session.BroadcastOptions{Force: d.force}
+		return d, func() tea.Msg {
+			return broadcastDoneMsg{results: session.Broadcast(targets, message, opts)}
+		}
+	}
+
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return d, cmd
+}
+
+// dialogWidth returns the inner width of the dialog
+func (d *BroadcastDialog) dialogWidth() int {
+	width := d.width - 8
+	if width > 90 {
+		width = 90
+	}
+	if width < 40 {
+		width = 40
+	}
+	return width
+}
+
+// View renders the broadcast dialog
+func (d *BroadcastDialog) View() string {
+	if !d.visible {
+		return ""
+	}
+
+	width := d.dialogWidth()
+	inner := width - 4 // DialogBoxStyle padding
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText)
+	busyStyle := lipgloss.NewStyle().Foreground(ColorYellow)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(runewidth.Truncate(fmt.Sprintf("BROADCAST · %s", d.label), inner, "…")))
+	content.WriteString("\n\n")
+
+	var footer string
+	switch {
+	case d.results != nil:
+		okStyle := lipgloss.NewStyle().Foreground(ColorGreen)
+		errStyle := lipgloss.NewStyle().Foreground(ColorRed)
+		sent := 0
+		for _, r := range d.results {
+			outcome := r.Outcome
+			style := errStyle
+			switch r.Outcome {
+			case session.BroadcastSent:
+				sent++
+				style = okStyle
+			case session.BroadcastBusy:
+				outcome = "busy, skipped"
+				style = busyStyle
+			case session.BroadcastNotRunning:
+				outcome = "not running"
+				style = DimStyle
+			case session.BroadcastOverBudget:
+				outcome = "over budget, skipped"
+				style = busyStyle
+			}
+			if r.Err != nil {
+				outcome += ": " + r.Err.Error()
+			}
+			title := runewidth.FillRight(runewidth.Truncate(r.Title, 24, "…"), 24)
+			line := textStyle.Render(title) + "  " + style.Render(runewidth.Truncate(outcome, inner-26, "…"))
+			content.WriteString(line + "\n")
+		}
+		content.WriteString("\n")
+		content.WriteString(textStyle.Render(fmt.Sprintf("Sent to %d/%d sessions", sent, len(d.results))))
+		footer = "esc close"
+
+	case d.sending:
+		content.WriteString(DimStyle.Render(fmt.Sprintf("Sending to %d sessions, each once it is ready...", len(d.targets))))
+		footer = "waiting for agents"
+
+	default:
+		busy := 0
+		names := make([]string, 0, len(d.targets))
+		for idx, inst := range d.targets {
+			if inst.Status == session.StatusRunning {
+				busy++
+			}
+			if idx < maxBroadcastTargetsShown {
+				names = append(names, inst.Title)
+			}
+		}
+		list := strings.Join(names, ", ")
+		if len(d.targets) > maxBroadcastTargetsShown {
+			list += fmt.Sprintf(" and %d more", len(d.targets)-maxBroadcastTargetsShown)
+		}
+		if len(d.targets) == 0 {
+			list = "no sessions"
+		}
+		content.WriteString(DimStyle.Render(runewidth.Truncate(fmt.Sprintf("To %d: %s", len(d.targets), list), inner, "…")))
+		content.WriteString("\n\n")
+		content.WriteString(d.input.View())
+		content.WriteString("\n\n")
+		if d.force {
+			content.WriteString(busyStyle.Render("[x] also type into sessions that are mid-turn"))
+		} else {
+			mode := "[ ] skip sessions that are mid-turn"
+			if busy > 0 {
+				mode += fmt.Sprintf(" (%d now)", busy)
+			}
+			content.WriteString(textStyle.Render(mode))
+		}
+		footer = "enter send • tab toggle mid-turn • esc cancel"
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(footerStyle.Render(footer))
+
+	box := DialogBoxStyle.
+		Width(width).
+		Render(content.String())
+
+	return centerInScreen(box, d.width, d.height)
+}
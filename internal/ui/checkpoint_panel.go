@@ -0,0 +1,331 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// checkpointsLoadedMsg carries a session's checkpoints for the timeline
+type checkpointsLoadedMsg struct {
+	sessionID   string
+	checkpoints []git.Checkpoint
+	err         error
+}
+
+// checkpointDiffMsg carries what one checkpoint changed
+type checkpointDiffMsg struct {
+	diff string
+	err  error
+}
+
+// checkpointRolledBackMsg signals that a rollback finished
+type checkpointRolledBackMsg struct {
+	sessionID string
+	number    int
+	err       error
+}
+
+// CheckpointPanel shows a session's working tree checkpoints as a timeline
+type CheckpointPanel struct {
+	visible bool
+	width   int
+	height  int
+
+	sessionID    string
+	sessionTitle string
+	loading      bool
+	err          error
+	checkpoints  []git.Checkpoint // Newest first
+	cursor       int
+
+	// Diff view (what the selected checkpoint changed)
+	showDiff     bool
+	diffLoading  bool
+	diff         string
+	diffErr      error
+	scrollOffset int
+}
+
+// NewCheckpointPanel creates a new checkpoint panel
+func NewCheckpointPanel() *CheckpointPanel {
+	return &CheckpointPanel{}
+}
+
+// Show opens the panel for a session while its checkpoints load
+func (p *CheckpointPanel) Show(sessionID, sessionTitle string) {
+	p.visible = true
+	p.sessionID = sessionID
+	p.sessionTitle = sessionTitle
+	p.loading = true
+	p.err = nil
+	p.checkpoints = nil
+	p.cursor = 0
+	p.showDiff = false
+	p.scrollOffset = 0
+}
+
+// Hide hides the checkpoint panel
+func (p *CheckpointPanel) Hide() {
+	p.visible = false
+	p.showDiff = false
+}
+
+// IsVisible returns whether the checkpoint panel is visible
+func (p *CheckpointPanel) IsVisible() bool {
+	return p.visible
+}
+
+// ShowingDiff returns whether the panel shows a checkpoint's diff instead of the timeline
+func (p *CheckpointPanel) ShowingDiff() bool {
+	return p.showDiff
+}
+
+// SetSize sets the dimensions of the panel
+func (p *CheckpointPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SessionID returns the ID of the session whose checkpoints are shown
+func (p *CheckpointPanel) SessionID() string {
+	return p.sessionID
+}
+
+// SetCheckpoints fills in the loaded checkpoints (given oldest first)
+func (p *CheckpointPanel) SetCheckpoints(msg checkpointsLoadedMsg) {
+	if msg.sessionID != p.sessionID {
+		return
+	}
+	p.loading = false
+	p.err = msg.err
+	p.checkpoints = make([]git.Checkpoint, 0, len(msg.checkpoints))
+	for i := len(msg.checkpoints) - 1; i >= 0; i-- {
+		p.checkpoints = append(p.checkpoints, msg.checkpoints[i])
+	}
+	if p.cursor >= len(p.checkpoints) {
+		p.cursor = 0
+	}
+}
+
+// Selected returns the checkpoint under the cursor
+func (p *CheckpointPanel) Selected() *git.Checkpoint {
+	if p.cursor < 0 || p.cursor >= len(p.checkpoints) {
+		return nil
+	}
+	return &p.checkpoints[p.cursor]
+}
+
+// ShowDiffLoading switches to the diff view while the diff is computed
+func (p *CheckpointPanel) ShowDiffLoading() {
+	p.showDiff = true
+	p.diffLoading = true
+	p.diff = ""
+	p.diffErr = nil
+	p.scrollOffset = 0
+}
+
+// SetDiff fills in the checkpoint's diff
+func (p *CheckpointPanel) SetDiff(msg checkpointDiffMsg) {
+	if !p.showDiff {
+		return
+	}
+	p.diffLoading = false
+	p.diff = msg.diff
+	p.diffErr = msg.err
+}
+
+// Update handles navigation and closing; diff and rollback are handled by Home
+func (p *CheckpointPanel) Update(msg tea.KeyMsg) (*CheckpointPanel, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	if p.showDiff {
+		switch msg.String() {
+		case "j", "down":
+			p.scrollOffset++
+		case "k", "up":
+			if p.scrollOffset > 0 {
+				p.scrollOffset--
+			}
+		case "ctrl+d", "pgdown", " ":
+			p.scrollOffset += p.contentHeight() / 2
+		case "ctrl+u", "pgup":
+			p.scrollOffset -= p.contentHeight() / 2
+			if p.scrollOffset < 0 {
+				p.scrollOffset = 0
+			}
+		case "esc", "q", "enter", "d":
+			p.showDiff = false
+		}
+		return p, nil
+	}
+
+	switch msg.String() {
+	case "j", "down":
+		if p.cursor < len(p.checkpoints)-1 {
+			p.cursor++
+		}
+	case "k", "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "g":
+		p.cursor = 0
+	case "G":
+		if len(p.checkpoints) > 0 {
+			p.cursor = len(p.checkpoints) - 1
+		}
+	case "esc", "q", "H":
+		p.Hide()
+	}
+	return p, nil
+}
+
+// contentHeight returns the number of scrollable lines that fit in the panel
+func (p *CheckpointPanel) contentHeight() int {
+	// Border (2) + padding (2) + header (2) + footer (2)
+	height := p.height - 8
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// dialogWidth returns the inner width of the panel
+func (p *CheckpointPanel) dialogWidth() int {
+	width := p.width - 8
+	if width < 40 {
+		width = 40
+	}
+	return width
+}
+
+// timelineLines renders one line per checkpoint, newest first
+func (p *CheckpointPanel) timelineLines(width int) []string {
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorBg).Background(ColorAccent).Bold(true)
+	numberStyle := lipgloss.NewStyle().Foreground(ColorYellow)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText)
+
+	var lines []string
+	for i, cp := range p.checkpoints {
+		marker := "│ "
+		if i == len(p.checkpoints)-1 {
+			marker = "└ "
+		}
+		number := fmt.Sprintf("#%-3d", cp.Number)
+		when := cp.Time.Format("Jan 2 15:04")
+		prompt := cp.Subject
+		avail := width - runewidth.StringWidth(marker+number+when) - 4
+		if avail < 5 {
+			avail = 5
+		}
+		if runewidth.StringWidth(prompt) > avail {
+			prompt = runewidth.Truncate(prompt, avail, "…")
+		}
+
+		row := DimStyle.Render(marker) + numberStyle.Render(number) + " " + DimStyle.Render(when) + "  "
+		if i == p.cursor {
+			row += selectedStyle.Render(prompt)
+		} else {
+			row += textStyle.Render(prompt)
+		}
+		lines = append(lines, row)
+	}
+	return lines
+}
+
+// View renders the checkpoint panel
+func (p *CheckpointPanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	width := p.dialogWidth()
+	inner := width - 4 // DialogBoxStyle padding
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+	errStyle := lipgloss.NewStyle().Foreground(ColorRed)
+
+	var content strings.Builder
+	var body []string
+	var footer string
+	visible := p.contentHeight()
+
+	if p.showDiff {
+		title := "CHECKPOINT"
+		if cp := p.Selected(); cp != nil {
+			title = fmt.Sprintf("CHECKPOINT #%d · %s", cp.Number, cp.Subject)
+		}
+		content.WriteString(titleStyle.Render(runewidth.Truncate(title, inner, "…")))
+		content.WriteString("\n\n")
+		switch {
+		case p.diffLoading:
+			body = []string{DimStyle.Render("Loading diff...")}
+		case p.diffErr != nil:
+			body = []string{errStyle.Render(p.diffErr.Error())}
+		case p.diff == "":
+			body = []string{DimStyle.Render("No file changes")}
+		default:
+			for _, line := range strings.Split(p.diff, "\n") {
+				body = append(body, renderDiffLine(line, inner))
+			}
+		}
+		maxScroll := len(body) - visible
+		if maxScroll < 0 {
+			maxScroll = 0
+		}
+		if p.scrollOffset > maxScroll {
+			p.scrollOffset = maxScroll
+		}
+		footer = "j/k scroll • esc back to timeline"
+	} else {
+		content.WriteString(titleStyle.Render(fmt.Sprintf("CHECKPOINTS · %s", p.sessionTitle)))
+		content.WriteString("\n\n")
+		switch {
+		case p.loading:
+			body = []string{DimStyle.Render("Loading checkpoints...")}
+		case p.err != nil:
+			body = []string{errStyle.Render(p.err.Error())}
+		case len(p.checkpoints) == 0:
+			body = []string{
+				DimStyle.Render("No checkpoints yet."),
+				DimStyle.Render("With [checkpoints] enabled = true in config.toml, the working tree"),
+				DimStyle.Render("is saved each time the agent finishes a turn."),
+			}
+		default:
+			body = p.timelineLines(inner)
+			// Keep the selected checkpoint in view
+			if p.cursor < p.scrollOffset {
+				p.scrollOffset = p.cursor
+			}
+			if p.cursor >= p.scrollOffset+visible {
+				p.scrollOffset = p.cursor - visible + 1
+			}
+		}
+		footer = "enter/d what changed • r roll back files to here • esc close"
+	}
+
+	end := p.scrollOffset + visible
+	if end > len(body) {
+		end = len(body)
+	}
+	start := p.scrollOffset
+	if start > end {
+		start = end
+	}
+	content.WriteString(strings.Join(body[start:end], "\n"))
+	content.WriteString("\n\n")
+	content.WriteString(footerStyle.Render(footer))
+
+	box := DialogBoxStyle.
+		Width(width).
+		Render(content.String())
+
+	return centerInScreen(box, p.width, p.height)
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCheckpointPanel_View(t *testing.T) {
+	panel := NewCheckpointPanel()
+	panel.SetSize(100, 30)
+	panel.Show("sess-1", "api-work")
+	if !strings.Contains(panel.View(), "Loading checkpoints...") {
+		t.Error("view should show loading state before checkpoints arrive")
+	}
+
+	// Checkpoints for another session are ignored
+	panel.SetCheckpoints(checkpointsLoadedMsg{sessionID: "other", checkpoints: []git.Checkpoint{{Number: 9}}})
+	if panel.Selected() != nil {
+		t.Error("checkpoints for another session should be ignored")
+	}
+
+	now := time.Now()
+	panel.SetCheckpoints(checkpointsLoadedMsg{sessionID: "sess-1", checkpoints: []git.Checkpoint{
+		{Number: 1, Time: now, Subject: "add the endpoint"},
+		{Number: 2, Time: now, Subject: "write tests"},
+	}})
+	if sel := panel.Selected(); sel == nil || sel.Number != 2 {
+		t.Fatalf("cursor should start on the newest checkpoint, got %+v", sel)
+	}
+	view := panel.View()
+	for _, want := range []string{"CHECKPOINTS · api-work", "#1", "#2", "add the endpoint", "write tests", "r roll back"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	panel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if sel := panel.Selected(); sel == nil || sel.Number != 1 {
+		t.Errorf("j should move to the older checkpoint, got %+v", sel)
+	}
+
+	panel.ShowDiffLoading()
+	panel.SetDiff(checkpointDiffMsg{diff: "+added line"})
+	if view := panel.View(); !strings.Contains(view, "CHECKPOINT #1") || !strings.Contains(view, "added line") {
+		t.Error("diff view should show the selected checkpoint's changes")
+	}
+	panel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if panel.ShowingDiff() || !panel.IsVisible() {
+		t.Error("esc in the diff view should return to the timeline")
+	}
+	panel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if panel.IsVisible() {
+		t.Error("esc on the timeline should close the panel")
+	}
+}
@@ -15,6 +15,10 @@ const (
 	ConfirmDeleteSession ConfirmType = iota
 	ConfirmDeleteGroup
 	ConfirmQuitWithPool
+	ConfirmFinishWorktree
+	ConfirmTrustRepoConfig
+	ConfirmPruneBranch
+	ConfirmRollbackCheckpoint
 )
 
 // ConfirmDialog handles confirmation for destructive actions
@@ -25,7 +29,9 @@ type ConfirmDialog struct {
 	targetName  string // Display name
 	width       int
 	height      int
-	mcpCount    int // Number of running MCPs (for quit confirmation)
+	mcpCount    int    // Number of running MCPs (for quit confirmation)
+	details     string // Extra details (for worktree finish: what will happen)
+	checkpoint  int    // Checkpoint number (for rollback confirmation)
 }
 
 // NewConfirmDialog creates a new confirmation dialog
@@ -49,6 +55,48 @@ func (c *ConfirmDialog) ShowDeleteGroup(groupPath, groupName string) {
 	c.targetName = groupName
 }
 
+// ShowFinishWorktree shows confirmation for finishing a worktree session
+func (c *ConfirmDialog) ShowFinishWorktree(sessionID, sessionName, details string) {
+	c.visible = true
+	c.confirmType = ConfirmFinishWorktree
+	c.targetID = sessionID
+	c.targetName = sessionName
+	c.details = details
+}
+
+// ShowPruneBranch shows confirmation for deleting a session and everything forked from it
+func (c *ConfirmDialog) ShowPruneBranch(sessionID, sessionName, details string) {
+	c.visible = true
+	c.confirmType = ConfirmPruneBranch
+	c.targetID = sessionID
+	c.targetName = sessionName
+	c.details = details
+}
+
+// ShowRollbackCheckpoint shows confirmation for restoring a session's working tree to a checkpoint
+func (c *ConfirmDialog) ShowRollbackCheckpoint(sessionID, sessionName string, checkpoint int, details string) {
+	c.visible = true
+	c.confirmType = ConfirmRollbackCheckpoint
+	c.targetID = sessionID
+	c.targetName = sessionName
+	c.checkpoint = checkpoint
+	c.details = details
+}
+
+// GetCheckpoint returns the checkpoint number being rolled back to
+func (c *ConfirmDialog) GetCheckpoint() int {
+	return c.checkpoint
+}
+
+// ShowTrustRepoConfig asks whether to apply a repository's .agent-deck.toml
+func (c *ConfirmDialog) ShowTrustRepoConfig(configPath, details string) {
+	c.visible = true
+	c.confirmType = ConfirmTrustRepoConfig
+	c.targetID = configPath
+	c.targetName = configPath
+	c.details = details
+}
+
 // ShowQuitWithPool shows confirmation for quitting with MCP pool running
 func (c *ConfirmDialog) ShowQuitWithPool(mcpCount int) {
 	c.visible = true
@@ -155,6 +203,98 @@ func (c *ConfirmDialog) View() string {
 			Render("(Esc to cancel)")
 		buttons = lipgloss.JoinHorizontal(lipgloss.Center, buttonYes, "  ", buttonNo, "  ", escHint)
 
+	case ConfirmFinishWorktree:
+		title = "Finish Worktree?"
+		warning = fmt.Sprintf("This will land the branch of:\n\n  \"%s\"", c.targetName)
+		details = c.details
+		borderColor = ColorAccent
+
+		buttonYes := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorGreen).
+			Padding(0, 2).
+			Bold(true).
+			Render("y Finish")
+		buttonNo := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorAccent).
+			Padding(0, 2).
+			Bold(true).
+			Render("n Cancel")
+		escHint := lipgloss.NewStyle().
+			Foreground(ColorTextDim).
+			Render("(Esc to cancel)")
+		buttons = lipgloss.JoinHorizontal(lipgloss.Center, buttonYes, "  ", buttonNo, "  ", escHint)
+
+	case ConfirmPruneBranch:
+		title = "⚠️  Prune Branch?"
+		warning = fmt.Sprintf("This will delete the session and its descendants:\n\n  \"%s\"", c.targetName)
+		details = c.details
+		borderColor = ColorRed
+
+		buttonYes := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorRed).
+			Padding(0, 2).
+			Bold(true).
+			Render("y Prune")
+		buttonNo := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorAccent).
+			Padding(0, 2).
+			Bold(true).
+			Render("n Cancel")
+		escHint := lipgloss.NewStyle().
+			Foreground(ColorTextDim).
+			Render("(Esc to cancel)")
+		buttons = lipgloss.JoinHorizontal(lipgloss.Center, buttonYes, "  ", buttonNo, "  ", escHint)
+
+	case ConfirmRollbackCheckpoint:
+		title = "⚠️  Roll Back Files?"
+		warning = fmt.Sprintf("Restore the working tree of \"%s\"\nto checkpoint %d?", c.targetName, c.checkpoint)
+		details = c.details
+		borderColor = ColorYellow
+
+		buttonYes := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorYellow).
+			Padding(0, 2).
+			Bold(true).
+			Render("y Roll back")
+		buttonNo := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorAccent).
+			Padding(0, 2).
+			Bold(true).
+			Render("n Cancel")
+		escHint := lipgloss.NewStyle().
+			Foreground(ColorTextDim).
+			Render("(Esc to cancel)")
+		buttons = lipgloss.JoinHorizontal(lipgloss.Center, buttonYes, "  ", buttonNo, "  ", escHint)
+
+	case ConfirmTrustRepoConfig:
+		title = "Trust Repo Config?"
+		warning = fmt.Sprintf("This repository has a config file:\n\n  %s", c.targetName)
+		details = c.details
+		borderColor = ColorYellow
+
+		buttonYes := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorGreen).
+			Padding(0, 2).
+			Bold(true).
+			Render("y Trust")
+		buttonNo := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorAccent).
+			Padding(0, 2).
+			Bold(true).
+			Render("n Ignore")
+		escHint := lipgloss.NewStyle().
+			Foreground(ColorTextDim).
+			Render("(Esc to ignore)")
+		buttons = lipgloss.JoinHorizontal(lipgloss.Center, buttonYes, "  ", buttonNo, "  ", escHint)
+
 	case ConfirmQuitWithPool:
 		title = "MCP Pool Running"
 		warning = fmt.Sprintf("%d MCP servers are running in the pool.", c.mcpCount)
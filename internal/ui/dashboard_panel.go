@@ -0,0 +1,444 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// dashboardListSize caps the session, tool and context lists
+const dashboardListSize = 5
+
+// billingBlockWindow is the length of a Claude billing block
+const billingBlockWindow = 5 * time.Hour
+
+// Context window sizes used for context pressure
+const (
+	claudeContextLimit = 200000
+	geminiContextLimit = 1000000
+)
+
+// sparkBlocks are the levels of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// dashboardLoadedMsg carries freshly collected dashboard data
+type dashboardLoadedMsg struct {
+	data *dashboardData
+}
+
+// dashboardTotal sums usage over a period
+type dashboardTotal struct {
+	Tokens   int
+	Cost     float64
+	Sessions int
+}
+
+// dashboardSession is one session's line in the dashboard lists
+type dashboardSession struct {
+	Title         string
+	Tool          string
+	Cost          float64 // This week
+	ContextTokens int
+	ContextLimit  int
+}
+
+// ContextPercent returns how full the session's context window is
+func (s dashboardSession) ContextPercent() float64 {
+	if s.ContextLimit == 0 {
+		return 0
+	}
+	return float64(s.ContextTokens) / float64(s.ContextLimit) * 100
+}
+
+// dashboardData is what the dashboard shows, collected off the UI thread
+type dashboardData struct {
+	Today, Week dashboardTotal
+	Block       dashboardTotal
+	BlockStart  time.Time // Zero when no billing block is active
+	Hourly      [24]int   // Tokens per hour, oldest first; the last is the current hour
+	TopSessions []dashboardSession
+	MCPTools    []session.ToolCall
+	Context     []dashboardSession
+	UpdatedAt   time.Time
+}
+
+// startOfWeek returns Monday 00:00 of the week containing t
+func startOfWeek(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// collectDashboard gathers usage of the given sessions as of now
+func collectDashboard(instances []*session.Instance, now time.Time) *dashboardData {
+	data := &dashboardData{UpdatedAt: now}
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := startOfWeek(now)
+	hourStart := now.Truncate(time.Hour)
+	firstHour := hourStart.Add(-23 * time.Hour)
+
+	var all [][]session.UsagePoint
+	var times []time.Time
+	mcpCounts := make(map[string]int)
+
+	for _, inst := range instances {
+		var points []session.UsagePoint
+		line := dashboardSession{Title: inst.Title, Tool: inst.Tool}
+		switch inst.Tool {
+		case "claude":
+			path := inst.GetJSONLPath()
+			if path == "" {
+				continue
+			}
+			analytics, err := session.ParseSessionJSONL(path)
+			if err != nil {
+				continue
+			}
+			points = analytics.Activity
+			line.ContextTokens = analytics.CurrentContextTokens
+			line.ContextLimit = claudeContextLimit
+			for _, call := range analytics.ToolCalls {
+				if strings.HasPrefix(call.Name, "mcp__") {
+					mcpCounts[call.Name] += call.Count
+				}
+			}
+		case "gemini":
+			analytics := inst.GeminiAnalytics
+			if analytics == nil {
+				continue
+			}
+			points = analytics.Activity
+			line.ContextTokens = analytics.CurrentContextTokens
+			line.ContextLimit = geminiContextLimit
+		default:
+			continue
+		}
+
+		var inToday, inWeek bool
+		for _, p := range points {
+			times = append(times, p.Time)
+			if !p.Time.Before(todayStart) {
+				data.Today.Tokens += p.Tokens
+				data.Today.Cost += p.Cost
+				inToday = true
+			}
+			if !p.Time.Before(weekStart) {
+				data.Week.Tokens += p.Tokens
+				data.Week.Cost += p.Cost
+				line.Cost += p.Cost
+				inWeek = true
+			}
+			if !p.Time.Before(firstHour) && p.Time.Before(hourStart.Add(time.Hour)) {
+				data.Hourly[int(p.Time.Sub(firstHour)/time.Hour)] += p.Tokens
+			}
+		}
+		if inToday {
+			data.Today.Sessions++
+		}
+		if inWeek {
+			data.Week.Sessions++
+			data.TopSessions = append(data.TopSessions, line)
+		}
+		if inst.Status != session.StatusError && line.ContextTokens > 0 {
+			data.Context = append(data.Context, line)
+		}
+		all = append(all, points)
+	}
+
+	// Billing block: the 5-hour window of all sessions' activity that is still open
+	if blocks := session.CalculateBillingBlocks(times, billingBlockWindow); len(blocks) > 0 &&
+		now.Sub(blocks[len(blocks)-1].StartTime) < billingBlockWindow {
+		data.BlockStart = blocks[len(blocks)-1].StartTime
+		blockEnd := data.BlockStart.Add(billingBlockWindow)
+		for _, points := range all {
+			inBlock := false
+			for _, p := range points {
+				if !p.Time.Before(data.BlockStart) && p.Time.Before(blockEnd) {
+					data.Block.Tokens += p.Tokens
+					data.Block.Cost += p.Cost
+					inBlock = true
+				}
+			}
+			if inBlock {
+				data.Block.Sessions++
+			}
+		}
+	}
+
+	sort.SliceStable(data.TopSessions, func(a, b int) bool {
+		return data.TopSessions[a].Cost > data.TopSessions[b].Cost
+	})
+	if len(data.TopSessions) > dashboardListSize {
+		data.TopSessions = data.TopSessions[:dashboardListSize]
+	}
+	sort.SliceStable(data.Context, func(a, b int) bool {
+		return data.Context[a].ContextPercent() > data.Context[b].ContextPercent()
+	})
+	if len(data.Context) > dashboardListSize {
+		data.Context = data.Context[:dashboardListSize]
+	}
+	for name, count := range mcpCounts {
+		data.MCPTools = append(data.MCPTools, session.ToolCall{Name: name, Count: count})
+	}
+	sort.Slice(data.MCPTools, func(a, b int) bool {
+		if data.MCPTools[a].Count != data.MCPTools[b].Count {
+			return data.MCPTools[a].Count > data.MCPTools[b].Count
+		}
+		return data.MCPTools[a].Name < data.MCPTools[b].Name
+	})
+	if len(data.MCPTools) > dashboardListSize {
+		data.MCPTools = data.MCPTools[:dashboardListSize]
+	}
+	return data
+}
+
+// sparkline renders values as a row of block characters scaled to the largest
+func sparkline(values []int) string {
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if peak > 0 && v > 0 {
+			level = 1 + v*(len(sparkBlocks)-2)/peak
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// formatTokensShort formats a token count compactly, e.g. "950", "12.3k", "4.1M"
+func formatTokensShort(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// mcpToolLabel turns "mcp__github__create_issue" into "github · create_issue"
+func mcpToolLabel(name string) string {
+	parts := strings.SplitN(strings.TrimPrefix(name, "mcp__"), "__", 2)
+	if len(parts) == 2 {
+		return parts[0] + " · " + parts[1]
+	}
+	return parts[0]
+}
+
+// DashboardPanel shows cost and activity across all sessions; Home
+// refreshes its data on every tick while it is open
+type DashboardPanel struct {
+	visible bool
+	width   int
+	height  int
+
+	loading bool
+	data    *dashboardData
+}
+
+// NewDashboardPanel creates a new dashboard panel
+func NewDashboardPanel() *DashboardPanel {
+	return &DashboardPanel{}
+}
+
+// Show opens the panel; the last data stays on screen until new data loads
+func (p *DashboardPanel) Show() {
+	p.visible = true
+	p.loading = p.data == nil
+}
+
+// Hide hides the dashboard panel
+func (p *DashboardPanel) Hide() {
+	p.visible = false
+}
+
+// IsVisible returns whether the dashboard panel is visible
+func (p *DashboardPanel) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize sets the dimensions of the panel
+func (p *DashboardPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetData fills in freshly collected data
+func (p *DashboardPanel) SetData(data *dashboardData) {
+	p.loading = false
+	p.data = data
+}
+
+// Update handles closing
+func (p *DashboardPanel) Update(msg tea.KeyMsg) (*DashboardPanel, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+	switch msg.String() {
+	case "esc", "q", "U":
+		p.Hide()
+	}
+	return p, nil
+}
+
+// dialogWidth returns the inner width of the panel
+func (p *DashboardPanel) dialogWidth() int {
+	width := p.width - 8
+	if width > 90 {
+		width = 90
+	}
+	if width < 50 {
+		width = 50
+	}
+	return width
+}
+
+// contextBar renders a filled bar colored by how full a context window is
+func contextBar(percent float64, width int) string {
+	if percent > 100 {
+		percent = 100
+	}
+	filled := int(percent / 100 * float64(width))
+	var color lipgloss.Color
+	switch {
+	case percent < 60:
+		color = ColorGreen
+	case percent < 80:
+		color = ColorYellow
+	default:
+		color = ColorRed
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("█", filled)) +
+		DimStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// View renders the dashboard panel
+func (p *DashboardPanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	width := p.dialogWidth()
+	inner := width - 4 // DialogBoxStyle padding
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorText)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText)
+	costStyle := lipgloss.NewStyle().Foreground(ColorGreen)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("USAGE DASHBOARD"))
+	if p.data != nil {
+		content.WriteString(DimStyle.Render("  updated " + p.data.UpdatedAt.Format("15:04:05")))
+	}
+	content.WriteString("\n\n")
+
+	if p.loading || p.data == nil {
+		content.WriteString(DimStyle.Render("Reading session transcripts..."))
+	} else {
+		d := p.data
+		totalLine := func(label string, t dashboardTotal) string {
+			return fmt.Sprintf("%s %s %s %s",
+				textStyle.Render(fmt.Sprintf("%-11s", label)),
+				textStyle.Render(fmt.Sprintf("%9s", formatTokensShort(t.Tokens))),
+				costStyle.Render(fmt.Sprintf("%10s", fmt.Sprintf("$%.2f", t.Cost))),
+				DimStyle.Render(fmt.Sprintf("%9d", t.Sessions)))
+		}
+		content.WriteString(DimStyle.Render(fmt.Sprintf("%-11s %9s %10s %9s", "", "TOKENS", "COST", "SESSIONS")))
+		content.WriteString("\n")
+		content.WriteString(totalLine("Today", d.Today))
+		content.WriteString("\n")
+		content.WriteString(totalLine("This week", d.Week))
+		content.WriteString("\n")
+		if d.BlockStart.IsZero() {
+			content.WriteString(textStyle.Render(fmt.Sprintf("%-11s ", "Block")))
+			content.WriteString(DimStyle.Render("no activity in the last 5h"))
+		} else {
+			end := d.BlockStart.Add(billingBlockWindow)
+			content.WriteString(totalLine("Block", d.Block))
+			content.WriteString(DimStyle.Render(fmt.Sprintf("  %s–%s, %s left",
+				d.BlockStart.Format("15:04"), end.Format("15:04"),
+				formatDuration(time.Until(end).Round(time.Minute)))))
+		}
+		content.WriteString("\n\n")
+
+		peak, peakHour := 0, 0
+		for i, v := range d.Hourly {
+			if v > peak {
+				peak, peakHour = v, i
+			}
+		}
+		content.WriteString(headerStyle.Render("Tokens per hour (last 24h)"))
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Foreground(ColorCyan).Render(sparkline(d.Hourly[:])))
+		if peak > 0 {
+			at := d.UpdatedAt.Truncate(time.Hour).Add(time.Duration(peakHour-23) * time.Hour)
+			content.WriteString(DimStyle.Render(fmt.Sprintf("  peak %s at %s", formatTokensShort(peak), at.Format("15:04"))))
+		}
+		content.WriteString("\n\n")
+
+		content.WriteString(headerStyle.Render("Most expensive this week"))
+		content.WriteString("\n")
+		if len(d.TopSessions) == 0 {
+			content.WriteString(DimStyle.Render("  No usage this week"))
+			content.WriteString("\n")
+		}
+		for _, s := range d.TopSessions {
+			title := runewidth.Truncate(s.Title, inner-22, "…")
+			content.WriteString(fmt.Sprintf("  %s %s %s\n",
+				textStyle.Render(runewidth.FillRight(title, inner-22)),
+				DimStyle.Render(fmt.Sprintf("%-7s", s.Tool)),
+				costStyle.Render(fmt.Sprintf("%9s", fmt.Sprintf("$%.2f", s.Cost)))))
+		}
+		content.WriteString("\n")
+
+		content.WriteString(headerStyle.Render("Busiest MCP tools"))
+		content.WriteString("\n")
+		if len(d.MCPTools) == 0 {
+			content.WriteString(DimStyle.Render("  No MCP tool calls"))
+			content.WriteString("\n")
+		}
+		for _, tool := range d.MCPTools {
+			label := runewidth.Truncate(mcpToolLabel(tool.Name), inner-10, "…")
+			content.WriteString(fmt.Sprintf("  %s %s\n",
+				textStyle.Render(runewidth.FillRight(label, inner-10)),
+				DimStyle.Render(fmt.Sprintf("%6d", tool.Count))))
+		}
+		content.WriteString("\n")
+
+		content.WriteString(headerStyle.Render("Context window"))
+		content.WriteString("\n")
+		if len(d.Context) == 0 {
+			content.WriteString(DimStyle.Render("  No open sessions with context"))
+			content.WriteString("\n")
+		}
+		barWidth := 20
+		for _, s := range d.Context {
+			title := runewidth.Truncate(s.Title, inner-barWidth-10, "…")
+			content.WriteString(fmt.Sprintf("  %s %s %s\n",
+				textStyle.Render(runewidth.FillRight(title, inner-barWidth-10)),
+				contextBar(s.ContextPercent(), barWidth),
+				DimStyle.Render(fmt.Sprintf("%4.0f%%", s.ContextPercent()))))
+		}
+	}
+
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render("Costs are estimates • esc close"))
+
+	box := DialogBoxStyle.
+		Width(width).
+		Render(content.String())
+
+	return centerInScreen(box, p.width, p.height)
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func TestStartOfWeek(t *testing.T) {
+	// Sunday belongs to the week that started on the Monday before
+	sunday := time.Date(2026, 3, 15, 18, 30, 0, 0, time.Local)
+	want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local)
+	if got := startOfWeek(sunday); !got.Equal(want) {
+		t.Errorf("startOfWeek(%s) = %s, want %s", sunday, got, want)
+	}
+	monday := time.Date(2026, 3, 9, 0, 5, 0, 0, time.Local)
+	if got := startOfWeek(monday); !got.Equal(want) {
+		t.Errorf("startOfWeek(%s) = %s, want %s", monday, got, want)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{0, 1, 50, 100}); got != "▁▂▅█" {
+		t.Errorf("sparkline = %q, want ▁▂▅█", got)
+	}
+	if got := sparkline([]int{0, 0}); got != "▁▁" {
+		t.Errorf("sparkline of zeros = %q", got)
+	}
+}
+
+func TestMCPToolLabel(t *testing.T) {
+	if got := mcpToolLabel("mcp__github__create_issue"); got != "github · create_issue" {
+		t.Errorf("mcpToolLabel = %q", got)
+	}
+}
+
+func TestCollectDashboard(t *testing.T) {
+	now := time.Date(2026, 3, 11, 14, 30, 0, 0, time.Local) // Wednesday
+	point := func(ago time.Duration, tokens int, cost float64) session.UsagePoint {
+		return session.UsagePoint{Time: now.Add(-ago), Tokens: tokens, Cost: cost}
+	}
+	busy := &session.Instance{Title: "busy", Tool: "gemini", Status: session.StatusRunning,
+		GeminiAnalytics: &session.GeminiSessionAnalytics{
+			CurrentContextTokens: 900000,
+			Activity: []session.UsagePoint{
+				point(50*time.Hour, 1000, 1), // Monday: this week, not today
+				point(2*time.Hour, 200, 0.2),
+				point(10*time.Minute, 300, 0.3),
+			},
+		}}
+	quiet := &session.Instance{Title: "quiet", Tool: "gemini", Status: session.StatusError,
+		GeminiAnalytics: &session.GeminiSessionAnalytics{
+			CurrentContextTokens: 1000,
+			Activity:             []session.UsagePoint{point(9*24*time.Hour, 5000, 5)}, // Last week
+		}}
+
+	data := collectDashboard([]*session.Instance{quiet, busy}, now)
+
+	if data.Today != (dashboardTotal{Tokens: 500, Cost: 0.5, Sessions: 1}) {
+		t.Errorf("Today = %+v", data.Today)
+	}
+	if data.Week.Tokens != 1500 || data.Week.Sessions != 1 {
+		t.Errorf("Week = %+v", data.Week)
+	}
+	// The block opened with the message 2h ago
+	if !data.BlockStart.Equal(now.Add(-2*time.Hour)) || data.Block.Tokens != 500 {
+		t.Errorf("block from %s with %d tokens", data.BlockStart, data.Block.Tokens)
+	}
+	if data.Hourly[23] != 300 || data.Hourly[21] != 200 {
+		t.Errorf("Hourly = %v", data.Hourly)
+	}
+	if len(data.TopSessions) != 1 || data.TopSessions[0].Title != "busy" {
+		t.Errorf("TopSessions = %+v", data.TopSessions)
+	}
+	// Sessions whose process is gone don't count towards context pressure
+	if len(data.Context) != 1 || data.Context[0].ContextPercent() != 90 {
+		t.Errorf("Context = %+v", data.Context)
+	}
+
+	panel := NewDashboardPanel()
+	panel.SetSize(120, 60)
+	panel.Show()
+	panel.SetData(data)
+	view := panel.View()
+	for _, want := range []string{"USAGE DASHBOARD", "Today", "$0.50", "busy", "90%", "No MCP tool calls"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+}
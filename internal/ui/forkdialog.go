@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/asheshgoplani/agent-deck/internal/git"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,11 +17,16 @@ type ForkDialog struct {
 	nameInput     textinput.Model
 	groupInput    textinput.Model
 	optionsPanel  *ClaudeOptionsPanel
-	focusIndex    int // 0=name, 1=group, 2+=options
+	focusIndex    int // 0=name, 1=group, 2=worktree, 3=branch (if worktree), then options
 	width         int
 	height        int
 	projectPath   string
+	tool          string // Tool of the session being forked; Claude options only apply to "claude"
 	validationErr string // Inline validation error displayed inside the dialog
+
+	// Fork into a new git worktree
+	worktreeEnabled bool
+	branchInput     textinput.Model
 }
 
 // NewForkDialog creates a new fork dialog
@@ -35,9 +41,15 @@ func NewForkDialog() *ForkDialog {
 	groupInput.CharLimit = 64
 	groupInput.Width = 40
 
+	branchInput := textinput.New()
+	branchInput.Placeholder = "fork/branch-name"
+	branchInput.CharLimit = 100
+	branchInput.Width = 40
+
 	return &ForkDialog{
 		nameInput:    nameInput,
 		groupInput:   groupInput,
+		branchInput:  branchInput,
 		optionsPanel: NewClaudeOptionsPanelForFork(),
 	}
 }
@@ -47,11 +59,15 @@ func (d *ForkDialog) Show(originalName, projectPath, groupPath string) {
 	d.visible = true
 	d.validationErr = ""
 	d.projectPath = projectPath
+	d.tool = "claude"
 	d.nameInput.SetValue(originalName + " (fork)")
 	d.groupInput.SetValue(groupPath)
 	d.focusIndex = 0
+	d.worktreeEnabled = false
+	d.branchInput.SetValue("")
 	d.nameInput.Focus()
 	d.groupInput.Blur()
+	d.branchInput.Blur()
 	d.optionsPanel.Blur()
 
 	// Initialize options with defaults from config
@@ -60,11 +76,23 @@ func (d *ForkDialog) Show(originalName, projectPath, groupPath string) {
 	}
 }
 
+// SetTool sets the tool of the session being forked. Claude options are
+// only shown for Claude sessions.
+func (d *ForkDialog) SetTool(tool string) {
+	d.tool = tool
+}
+
+// hasOptions returns whether the dialog shows the Claude options panel
+func (d *ForkDialog) hasOptions() bool {
+	return d.tool == "claude"
+}
+
 // Hide hides the dialog
 func (d *ForkDialog) Hide() {
 	d.visible = false
 	d.nameInput.Blur()
 	d.groupInput.Blur()
+	d.branchInput.Blur()
 	d.optionsPanel.Blur()
 }
 
@@ -78,8 +106,34 @@ func (d *ForkDialog) GetValues() (name, group string) {
 	return d.nameInput.Value(), d.groupInput.Value()
 }
 
-// GetOptions returns the current Claude options
+// GetWorktree returns the branch to fork into and whether worktree mode is enabled
+func (d *ForkDialog) GetWorktree() (branch string, enabled bool) {
+	return strings.TrimSpace(d.branchInput.Value()), d.worktreeEnabled
+}
+
+// ToggleWorktree toggles forking into a new worktree, suggesting a branch name
+func (d *ForkDialog) ToggleWorktree() {
+	d.worktreeEnabled = !d.worktreeEnabled
+	if d.worktreeEnabled && strings.TrimSpace(d.branchInput.Value()) == "" {
+		name := strings.ToLower(strings.TrimSpace(d.nameInput.Value()))
+		name = strings.NewReplacer("(", "", ")", "").Replace(name)
+		d.branchInput.SetValue("fork/" + strings.Trim(git.SanitizeBranchName(name), "-"))
+	}
+}
+
+// optionsIndex returns the focus index of the first Claude option
+func (d *ForkDialog) optionsIndex() int {
+	if d.worktreeEnabled {
+		return 4
+	}
+	return 3
+}
+
+// GetOptions returns the current Claude options, or nil for other tools
 func (d *ForkDialog) GetOptions() *session.ClaudeOptions {
+	if !d.hasOptions() {
+		return nil
+	}
 	return d.optionsPanel.GetOptions()
 }
 
@@ -98,6 +152,18 @@ func (d *ForkDialog) Validate() string {
 	if len(name) > MaxNameLength {
 		return fmt.Sprintf("Session name too long (max %d characters)", MaxNameLength)
 	}
+	if d.worktreeEnabled {
+		branch := strings.TrimSpace(d.branchInput.Value())
+		if branch == "" {
+			return "Branch name required for worktree"
+		}
+		if err := git.ValidateBranchName(branch); err != nil {
+			return err.Error()
+		}
+		if d.projectPath != "" && !git.IsGitRepo(d.projectPath) {
+			return "Project is not a git repository"
+		}
+	}
 	return ""
 }
 
@@ -121,8 +187,11 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "tab", "down":
-			if d.focusIndex < 2 {
-				// Move from name/group to next field or options
+			if !d.hasOptions() && d.focusIndex >= d.optionsIndex()-1 {
+				return d, nil
+			}
+			if d.focusIndex < d.optionsIndex() {
+				// Move from name/group/worktree to next field or options
 				d.focusIndex++
 				d.updateFocus()
 			} else {
@@ -134,11 +203,11 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 			return d, nil
 
 		case "shift+tab", "up":
-			if d.focusIndex == 2 && d.optionsPanel.focusIndex == 0 {
-				// At first option item, move back to group
-				d.focusIndex = 1
+			if d.focusIndex == d.optionsIndex() && d.optionsPanel.focusIndex == 0 {
+				// At first option item, move back to worktree/branch
+				d.focusIndex--
 				d.updateFocus()
-			} else if d.focusIndex < 2 {
+			} else if d.focusIndex < d.optionsIndex() {
 				d.focusIndex--
 				if d.focusIndex < 0 {
 					d.focusIndex = 0
@@ -162,8 +231,15 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 			}
 
 		case " ", "left", "right":
+			// Space toggles the worktree checkbox
+			if d.focusIndex == 2 {
+				if msg.String() == " " {
+					d.ToggleWorktree()
+				}
+				return d, nil
+			}
 			// Delegate space/arrow keys to options panel if focused there
-			if d.focusIndex >= 2 {
+			if d.focusIndex >= d.optionsIndex() {
 				var cmd tea.Cmd
 				d.optionsPanel, cmd = d.optionsPanel.Update(msg)
 				return d, cmd
@@ -178,9 +254,15 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 		d.nameInput, cmd = d.nameInput.Update(msg)
 	case 1:
 		d.groupInput, cmd = d.groupInput.Update(msg)
+	case 2:
+		// Worktree checkbox has no text input
 	default:
-		// Options panel handles its own inputs
-		d.optionsPanel, cmd = d.optionsPanel.Update(msg)
+		if d.worktreeEnabled && d.focusIndex == 3 {
+			d.branchInput, cmd = d.branchInput.Update(msg)
+		} else {
+			// Options panel handles its own inputs
+			d.optionsPanel, cmd = d.optionsPanel.Update(msg)
+		}
 	}
 
 	return d, cmd
@@ -189,13 +271,18 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 func (d *ForkDialog) updateFocus() {
 	d.nameInput.Blur()
 	d.groupInput.Blur()
+	d.branchInput.Blur()
 	d.optionsPanel.Blur()
 
-	switch d.focusIndex {
-	case 0:
+	switch {
+	case d.focusIndex == 0:
 		d.nameInput.Focus()
-	case 1:
+	case d.focusIndex == 1:
 		d.groupInput.Focus()
+	case d.focusIndex == 2:
+		// Worktree checkbox
+	case d.worktreeEnabled && d.focusIndex == 3:
+		d.branchInput.Focus()
 	default:
 		d.optionsPanel.Focus()
 	}
@@ -246,18 +333,44 @@ func (d *ForkDialog) View() string {
 		groupLabel = labelStyle.Render("  Group:")
 	}
 
+	// Worktree checkbox and branch input
+	checkbox := "[ ]"
+	if d.worktreeEnabled {
+		checkbox = "[x]"
+	}
+	var worktreeSection string
+	if d.focusIndex == 2 {
+		worktreeSection = activeLabelStyle.Render("▶ "+checkbox+" Fork into new worktree (Space)") + "\n"
+	} else {
+		worktreeSection = labelStyle.Render("  "+checkbox+" Fork into new worktree") + "\n"
+	}
+	if d.worktreeEnabled {
+		branchLabel := labelStyle.Render("  Branch:")
+		if d.focusIndex == 3 {
+			branchLabel = activeLabelStyle.Render("▶ Branch:")
+		}
+		worktreeSection += "\n" + branchLabel + "\n" + "  " + d.branchInput.View() + "\n"
+	}
+	worktreeSection += "\n"
+
 	errLine := ""
 	if d.validationErr != "" {
 		errStyle := lipgloss.NewStyle().Foreground(ColorRed).Bold(true)
 		errLine = "\n" + errStyle.Render("  ⚠ "+d.validationErr) + "\n"
 	}
 
+	optionsSection := ""
+	if d.hasOptions() {
+		optionsSection = d.optionsPanel.View()
+	}
+
 	content := titleStyle.Render("Fork Session") + "\n\n" +
 		nameLabel + "\n" +
 		"  " + d.nameInput.View() + "\n\n" +
 		groupLabel + "\n" +
 		"  " + d.groupInput.View() + "\n\n" +
-		d.optionsPanel.View() +
+		worktreeSection +
+		optionsSection +
 		errLine + "\n" +
 		lipgloss.NewStyle().Foreground(ColorComment).
 			Render("Enter create │ Esc cancel │ Tab next │ Space toggle")
@@ -3,6 +3,8 @@ package ui
 import (
 	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestNewForkDialog(t *testing.T) {
@@ -175,3 +177,61 @@ func TestForkDialog_Show_ClearsError(t *testing.T) {
 		t.Error("Show() should clear validationErr")
 	}
 }
+
+func TestForkDialog_Worktree(t *testing.T) {
+	d := NewForkDialog()
+	d.SetSize(80, 40)
+	d.Show("Auth Refactor", "", "group")
+
+	if _, enabled := d.GetWorktree(); enabled {
+		t.Fatal("worktree should be off by default")
+	}
+
+	// Tab to the worktree checkbox and toggle it with space
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+
+	branch, enabled := d.GetWorktree()
+	if !enabled || branch != "fork/auth-refactor-fork" {
+		t.Errorf("GetWorktree() = (%q, %v), want suggested branch", branch, enabled)
+	}
+	if !strings.Contains(d.View(), "Branch:") {
+		t.Error("View should show the branch input when worktree is enabled")
+	}
+
+	d.branchInput.SetValue("")
+	if err := d.Validate(); err != "Branch name required for worktree" {
+		t.Errorf("Validate() = %q, want branch required", err)
+	}
+
+	// Show resets the worktree choice
+	d.Show("Test", "", "group")
+	if _, enabled := d.GetWorktree(); enabled {
+		t.Error("Show() should reset worktree mode")
+	}
+}
+
+func TestForkDialog_NonClaudeHidesOptions(t *testing.T) {
+	d := NewForkDialog()
+	d.Show("Test", "", "group")
+	if d.GetOptions() == nil {
+		t.Fatal("GetOptions() should return Claude options for Claude sessions")
+	}
+
+	d.SetTool("gemini")
+	if d.GetOptions() != nil {
+		t.Error("GetOptions() should be nil for non-Claude sessions")
+	}
+	if strings.Contains(d.View(), "Claude") {
+		t.Error("View() should not show Claude options for non-Claude sessions")
+	}
+
+	// Tab stops at the worktree checkbox
+	for i := 0; i < 5; i++ {
+		d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	if d.focusIndex != 2 {
+		t.Errorf("focusIndex = %d, want 2 (worktree checkbox)", d.focusIndex)
+	}
+}
@@ -0,0 +1,304 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// gitPanelFetchedMsg carries git status and diff for the git panel
+type gitPanelFetchedMsg struct {
+	sessionID string
+	workDir   string
+	status    *git.Status
+	diff      string
+	err       error
+}
+
+// GitPanel shows branch, changed files and the uncommitted diff of a session
+type GitPanel struct {
+	visible      bool
+	width        int
+	height       int
+	scrollOffset int
+
+	sessionID string
+	title     string
+	workDir   string
+	loading   bool
+	status    *git.Status
+	diff      string
+	err       error
+}
+
+// NewGitPanel creates a new git panel
+func NewGitPanel() *GitPanel {
+	return &GitPanel{}
+}
+
+// Show opens the panel for inst; data arrives later via SetData
+func (g *GitPanel) Show(inst *session.Instance) {
+	g.visible = true
+	g.scrollOffset = 0
+	g.sessionID = inst.ID
+	g.title = inst.Title
+	g.workDir = inst.ProjectPath
+	g.loading = true
+	g.status = nil
+	g.diff = ""
+	g.err = nil
+}
+
+// Hide hides the git panel
+func (g *GitPanel) Hide() {
+	g.visible = false
+}
+
+// IsVisible returns whether the git panel is visible
+func (g *GitPanel) IsVisible() bool {
+	return g.visible
+}
+
+// SessionID returns the ID of the session shown in the panel
+func (g *GitPanel) SessionID() string {
+	return g.sessionID
+}
+
+// SetSize sets the dimensions of the panel
+func (g *GitPanel) SetSize(width, height int) {
+	g.width = width
+	g.height = height
+}
+
+// SetLoading marks the panel as waiting for a refresh
+func (g *GitPanel) SetLoading() {
+	g.loading = true
+}
+
+// SetData updates the panel with fetched git state
+func (g *GitPanel) SetData(msg gitPanelFetchedMsg) {
+	if msg.sessionID != g.sessionID {
+		return
+	}
+	g.loading = false
+	g.workDir = msg.workDir
+	g.status = msg.status
+	g.diff = msg.diff
+	g.err = msg.err
+}
+
+// Update handles scrolling and closing; refresh ("r") is handled by Home
+func (g *GitPanel) Update(msg tea.KeyMsg) (*GitPanel, tea.Cmd) {
+	if !g.visible {
+		return g, nil
+	}
+
+	switch msg.String() {
+	case "j", "down":
+		g.scrollOffset++
+	case "k", "up":
+		if g.scrollOffset > 0 {
+			g.scrollOffset--
+		}
+	case "ctrl+d", "pgdown", " ":
+		g.scrollOffset += g.pageSize()
+	case "ctrl+u", "pgup":
+		g.scrollOffset -= g.pageSize()
+		if g.scrollOffset < 0 {
+			g.scrollOffset = 0
+		}
+	case "g":
+		g.scrollOffset = 0
+	case "G":
+		g.scrollOffset = 1 << 30 // Clamped in View()
+	case "esc", "q", "D":
+		g.Hide()
+	}
+	return g, nil
+}
+
+// pageSize returns how far ctrl+d/ctrl+u scroll
+func (g *GitPanel) pageSize() int {
+	if h := g.contentHeight() / 2; h > 1 {
+		return h
+	}
+	return 1
+}
+
+// contentHeight returns the number of scrollable lines that fit in the panel
+func (g *GitPanel) contentHeight() int {
+	// Border (2) + padding (2) + header (4) + footer (2)
+	height := g.height - 10
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// dialogWidth returns the inner width of the panel
+func (g *GitPanel) dialogWidth() int {
+	width := g.width - 8
+	if width < 40 {
+		width = 40
+	}
+	return width
+}
+
+// gitFileCodeStyle colors a short status code: conflicts red, untracked dim,
+// staged green, unstaged yellow
+func gitFileCodeStyle(f git.FileStatus) lipgloss.Style {
+	switch {
+	case f.Index == 'U' || f.Worktree == 'U':
+		return lipgloss.NewStyle().Foreground(ColorRed).Bold(true)
+	case f.Index == '?':
+		return lipgloss.NewStyle().Foreground(ColorComment)
+	case f.Worktree == '.':
+		return lipgloss.NewStyle().Foreground(ColorGreen)
+	default:
+		return lipgloss.NewStyle().Foreground(ColorYellow)
+	}
+}
+
+// renderDiffLine colors one line of a unified diff
+func renderDiffLine(line string, width int) string {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	if runewidth.StringWidth(line) > width {
+		line = runewidth.Truncate(line, width, "…")
+	}
+
+	var style lipgloss.Style
+	switch {
+	case strings.HasPrefix(line, "diff --git"):
+		style = lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+		strings.HasPrefix(line, "index "), strings.HasPrefix(line, "new file"),
+		strings.HasPrefix(line, "deleted file"), strings.HasPrefix(line, "similarity"),
+		strings.HasPrefix(line, "rename "):
+		style = lipgloss.NewStyle().Foreground(ColorTextDim)
+	case strings.HasPrefix(line, "@@"):
+		style = lipgloss.NewStyle().Foreground(ColorCyan)
+	case strings.HasPrefix(line, "+"):
+		style = lipgloss.NewStyle().Foreground(ColorGreen)
+	case strings.HasPrefix(line, "-"):
+		style = lipgloss.NewStyle().Foreground(ColorRed)
+	default:
+		style = lipgloss.NewStyle().Foreground(ColorText)
+	}
+	return style.Render(line)
+}
+
+// renderGitBranchLine renders "⎇ branch → upstream ↑a ↓b"
+func renderGitBranchLine(status *git.Status) string {
+	branch := status.Branch
+	if status.Detached {
+		branch = "(detached HEAD)"
+	}
+	line := lipgloss.NewStyle().Foreground(ColorPurple).Bold(true).Render("⎇ " + branch)
+	if status.Upstream != "" {
+		line += DimStyle.Render(" → " + status.Upstream)
+		line += " " + lipgloss.NewStyle().Foreground(ColorCyan).Render(fmt.Sprintf("↑%d", status.Ahead))
+		line += " " + lipgloss.NewStyle().Foreground(ColorYellow).Render(fmt.Sprintf("↓%d", status.Behind))
+	}
+	return line
+}
+
+// bodyLines builds the scrollable part of the panel: files, then diff
+func (g *GitPanel) bodyLines(width int) []string {
+	sectionStyle := lipgloss.NewStyle().Foreground(ColorCyan).Bold(true)
+
+	var lines []string
+	if !g.status.Dirty() {
+		lines = append(lines, DimStyle.Render("Working tree clean"))
+		return lines
+	}
+
+	lines = append(lines, sectionStyle.Render(fmt.Sprintf("CHANGED FILES (%d)", len(g.status.Files))))
+	for _, f := range g.status.Files {
+		path := f.Path
+		if f.OrigPath != "" {
+			path = f.OrigPath + " → " + f.Path
+		}
+		if runewidth.StringWidth(path) > width-5 {
+			path = runewidth.Truncate(path, width-5, "…")
+		}
+		lines = append(lines, "  "+gitFileCodeStyle(f).Render(f.Code())+" "+lipgloss.NewStyle().Foreground(ColorText).Render(path))
+	}
+
+	if g.diff != "" {
+		lines = append(lines, "")
+		lines = append(lines, sectionStyle.Render("DIFF"))
+		for _, line := range strings.Split(strings.TrimRight(g.diff, "\n"), "\n") {
+			lines = append(lines, renderDiffLine(line, width))
+		}
+	}
+	return lines
+}
+
+// View renders the git panel
+func (g *GitPanel) View() string {
+	if !g.visible {
+		return ""
+	}
+
+	width := g.dialogWidth()
+	inner := width - 4 // DialogBoxStyle padding
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+	scrollIndicatorStyle := lipgloss.NewStyle().Foreground(ColorYellow).Bold(true)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("GIT · " + g.title))
+	content.WriteString("\n")
+	content.WriteString(DimStyle.Render(truncatePath(g.workDir, inner)))
+	content.WriteString("\n")
+
+	var body []string
+	switch {
+	case g.loading && g.status == nil:
+		content.WriteString("\n")
+		body = []string{DimStyle.Render("Loading git status...")}
+	case g.err != nil:
+		content.WriteString("\n")
+		body = []string{lipgloss.NewStyle().Foreground(ColorRed).Render(g.err.Error())}
+	default:
+		content.WriteString(renderGitBranchLine(g.status))
+		content.WriteString("\n")
+		body = g.bodyLines(inner)
+	}
+	content.WriteString("\n")
+
+	// Clamp scroll offset and render the visible window
+	visible := g.contentHeight()
+	maxScroll := len(body) - visible
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if g.scrollOffset > maxScroll {
+		g.scrollOffset = maxScroll
+	}
+	end := g.scrollOffset + visible
+	if end > len(body) {
+		end = len(body)
+	}
+	content.WriteString(strings.Join(body[g.scrollOffset:end], "\n"))
+
+	// Footer with scroll position
+	content.WriteString("\n\n")
+	if maxScroll > 0 {
+		content.WriteString(scrollIndicatorStyle.Render(fmt.Sprintf("%d-%d/%d ", g.scrollOffset+1, end, len(body))))
+		content.WriteString(footerStyle.Render("j/k scroll • ^d/^u page • r refresh • esc close"))
+	} else {
+		content.WriteString(footerStyle.Render("r refresh • esc close"))
+	}
+
+	box := DialogBoxStyle.
+		Width(width).
+		Render(content.String())
+
+	return centerInScreen(box, g.width, g.height)
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGitPanel_View(t *testing.T) {
+	panel := NewGitPanel()
+	panel.SetSize(100, 30)
+
+	inst := session.NewInstance("worker", "/tmp/project")
+	panel.Show(inst)
+	if !panel.IsVisible() {
+		t.Fatal("panel should be visible after Show")
+	}
+	if !strings.Contains(panel.View(), "Loading git status") {
+		t.Error("expected loading state before data arrives")
+	}
+
+	// Data for another session is ignored
+	panel.SetData(gitPanelFetchedMsg{sessionID: "other", status: &git.Status{Branch: "wrong"}})
+	if strings.Contains(panel.View(), "wrong") {
+		t.Error("data for another session should be ignored")
+	}
+
+	panel.SetData(gitPanelFetchedMsg{
+		sessionID: inst.ID,
+		workDir:   "/tmp/project",
+		status: &git.Status{
+			Branch:   "feature",
+			Upstream: "origin/feature",
+			Ahead:    2,
+			Files:    []git.FileStatus{{Path: "main.go", Index: '.', Worktree: 'M'}},
+		},
+		diff: "diff --git a/main.go b/main.go\n@@ -1 +1 @@\n-old\n+new\n",
+	})
+
+	view := panel.View()
+	for _, want := range []string{"feature", "origin/feature", "↑2", "CHANGED FILES (1)", "main.go", "+new", "-old"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	// Scrolling past the end is clamped
+	panel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	panel.View()
+	if panel.scrollOffset != 0 {
+		t.Errorf("scrollOffset = %d, want 0 when content fits", panel.scrollOffset)
+	}
+
+	panel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if panel.IsVisible() {
+		t.Error("esc should close the panel")
+	}
+}
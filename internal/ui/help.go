@@ -116,12 +116,24 @@ func (h *HelpOverlay) View() string {
 				{"d", "Delete session"},
 				{"Ctrl+Z", "Undo delete"},
 				{"m", "Move to group"},
-				{"Shift+M", "MCP Manager (Claude)"},
+				{"Shift+M", "MCP Manager"},
 				{"v", "Toggle preview mode (output/stats/both)"},
+				{"Shift+D", "Git status and diff"},
+				{"Shift+W", "Finish worktree (land branch, clean up)"},
+				{"Shift+T", "Conversation tree (forks, sub-sessions)"},
+				{"Shift+H", "Checkpoint timeline (roll back files)"},
+				{"Shift+Q", "Prompt queue (send when agent is ready)"},
+				{"Shift+P", "Pipeline runs (live progress)"},
+				{"Shift+U", "Usage dashboard (cost, activity, context)"},
+				{"Space", "Mark session/group for broadcast"},
+				{"Shift+B", "Broadcast message (marked or group)"},
+				{"a / Shift+A", "Approve permission prompt (A: don't ask again)"},
+				{"Shift+N", "Deny permission prompt"},
+				{"Shift+O", "Override budget (count usage from zero)"},
 				{"u", "Mark unread"},
 				{"K / J", "Reorder up/down"},
-				{"f", "Quick fork (Claude only)"},
-				{"F", "Fork with options (Claude only)"},
+				{"f", "Quick fork (Claude, Gemini, Codex)"},
+				{"F", "Fork with options"},
 				{"c", "Copy output to clipboard"},
 				{"x", "Send output to session"},
 			},
@@ -141,6 +153,8 @@ func (h *HelpOverlay) View() string {
 				{"/waiting", "Filter waiting"},
 				{"/running", "Filter running"},
 				{"/idle", "Filter idle"},
+				{"%", "Filter to selected session's repo"},
+				{"0", "Clear filters"},
 			},
 		},
 		{
@@ -58,6 +58,10 @@ const (
 	// analyticsCacheTTL - how long analytics data remains valid before refresh
 	// Analytics don't change frequently, so 5s is a good balance between freshness and performance
 	analyticsCacheTTL = 5 * time.Second
+
+	// gitStatusRefreshInterval - how often the background worker refreshes git badges
+	// Matches the git status cache TTL so each directory runs git at most once per interval
+	gitStatusRefreshInterval = 5 * time.Second
 )
 
 // UI spacing constants (2-char grid system)
@@ -143,6 +147,16 @@ type Home struct {
 	analyticsPanel      *AnalyticsPanel      // For displaying session analytics
 	geminiModelDialog   *GeminiModelDialog   // For selecting Gemini model
 	sessionPickerDialog *SessionPickerDialog // For sending output to another session
+	gitPanel            *GitPanel            // For showing git status and diff
+	lineagePanel        *LineagePanel        // For showing fork and sub-session lineage
+	checkpointPanel     *CheckpointPanel     // For showing and rolling back working tree checkpoints
+	queuePanel          *QueuePanel          // For editing a session's prompt queue
+	pipelinePanel       *PipelinePanel       // For following pipeline runs
+	dashboardPanel      *DashboardPanel      // For cost and activity across sessions
+	dashboardFetching   bool                 // A dashboard refresh is in flight
+	broadcastDialog     *BroadcastDialog     // For sending one message to many sessions
+	selectedSessions    map[string]bool      // Sessions marked with space for a broadcast
+	pendingNewDialog    *pendingNewDialog    // New session dialog waiting on a repo config trust prompt
 
 	// Analytics cache (async fetching with TTL)
 	currentAnalytics       *session.SessionAnalytics                  // Current analytics for selected session (Claude)
@@ -153,11 +167,17 @@ type Home struct {
 	geminiAnalyticsCache   map[string]*session.GeminiSessionAnalytics // TTL cache: sessionID -> analytics (Gemini)
 	analyticsCacheTime     map[string]time.Time                       // TTL cache: sessionID -> cache timestamp
 
+	// Git status for list badges (filled by the background worker, read by View())
+	gitStatuses    map[string]*git.Status // sessionID -> last known git status (nil = not a repo)
+	gitStatusMu    sync.RWMutex           // Protects gitStatuses
+	lastGitRefresh time.Time              // When git statuses were last refreshed (background worker only)
+
 	// State
 	cursor         int            // Selected item index in flatItems
 	viewOffset     int            // First visible item index (for scrolling)
 	isAttaching    atomic.Bool    // Prevents View() output during attach (fixes Bubble Tea Issue #431) - atomic for thread safety
 	statusFilter   session.Status // Filter sessions by status ("" = all, or specific status)
+	repoFilter     *repoFilter    // Show only sessions in one repository's worktrees (nil = all)
 	previewMode    PreviewMode    // What to show in preview pane (both, output-only, analytics-only)
 	err            error
 	errTime        time.Time  // When error occurred (for auto-dismiss)
@@ -374,6 +394,12 @@ type maintenanceCompleteMsg struct {
 	result session.MaintenanceResult
 }
 
+// ScheduleRunMsg is sent from main.go via p.Send() when a scheduled job
+// finishes a run
+type ScheduleRunMsg struct {
+	Run *session.ScheduleRun
+}
+
 // clearMaintenanceMsg signals auto-clear of maintenance banner
 type clearMaintenanceMsg struct{}
 
@@ -448,6 +474,14 @@ func NewHomeWithProfileAndMode(profile string, isPrimary bool) *Home {
 		analyticsPanel:       NewAnalyticsPanel(),
 		geminiModelDialog:    NewGeminiModelDialog(),
 		sessionPickerDialog:  NewSessionPickerDialog(),
+		gitPanel:             NewGitPanel(),
+		lineagePanel:         NewLineagePanel(),
+		checkpointPanel:      NewCheckpointPanel(),
+		queuePanel:           NewQueuePanel(),
+		pipelinePanel:        NewPipelinePanel(),
+		dashboardPanel:       NewDashboardPanel(),
+		broadcastDialog:      NewBroadcastDialog(),
+		selectedSessions:     make(map[string]bool),
 		cursor:               0,
 		initialLoading:       true, // Show splash until sessions load
 		ctx:                  ctx,
@@ -461,6 +495,7 @@ func NewHomeWithProfileAndMode(profile string, isPrimary bool) *Home {
 		analyticsCache:       make(map[string]*session.SessionAnalytics),
 		geminiAnalyticsCache: make(map[string]*session.GeminiSessionAnalytics),
 		analyticsCacheTime:   make(map[string]time.Time),
+		gitStatuses:          make(map[string]*git.Status),
 		launchingSessions:    make(map[string]time.Time),
 		resumingSessions:     make(map[string]time.Time),
 		mcpLoadingSessions:   make(map[string]time.Time),
@@ -675,13 +710,13 @@ func (h *Home) restoreState(state reloadState) {
 func (h *Home) rebuildFlatItems() {
 	allItems := h.groupTree.Flatten()
 
-	// Apply status filter if active
-	if h.statusFilter != "" {
+	// Apply status and repo filters if active
+	if h.statusFilter != "" || h.repoFilter != nil {
 		// First pass: identify groups that have matching sessions
 		groupsWithMatches := make(map[string]bool)
 		for _, item := range allItems {
 			if item.Type == session.ItemTypeSession && item.Session != nil {
-				if item.Session.Status == h.statusFilter {
+				if h.matchesFilters(item.Session) {
 					// Mark this session's group and all parent groups as having matches
 					groupsWithMatches[item.Path] = true
 					// Also mark parent paths
@@ -704,7 +739,7 @@ func (h *Home) rebuildFlatItems() {
 				}
 			} else if item.Type == session.ItemTypeSession && item.Session != nil {
 				// Keep session if it matches the filter
-				if item.Session.Status == h.statusFilter {
+				if h.matchesFilters(item.Session) {
 					filtered = append(filtered, item)
 				}
 			}
@@ -734,6 +769,19 @@ func (h *Home) rebuildFlatItems() {
 	h.syncViewport()
 }
 
+// matchesFilters returns true if a session passes the status and repo filters
+func (h *Home) matchesFilters(inst *session.Instance) bool {
+	if h.statusFilter != "" && inst.Status != h.statusFilter &&
+		!(h.statusFilter == session.StatusWaiting &&
+			(inst.Status == session.StatusNeedsApproval || inst.Status == session.StatusBudgetExceeded)) {
+		return false
+	}
+	if h.repoFilter != nil && session.WorktreeForPath(inst.ProjectPath, h.repoFilter.paths) < 0 {
+		return false
+	}
+	return true
+}
+
 // syncViewport ensures the cursor is visible within the viewport
 // Call this after any cursor movement
 func (h *Home) syncViewport() {
@@ -1218,6 +1266,8 @@ func (h *Home) hasActiveAnimation(sessionID string) bool {
 	// - StatusIdle (GRAY): Claude has stopped and user acknowledged
 	if inst.Status == session.StatusRunning ||
 		inst.Status == session.StatusWaiting ||
+		inst.Status == session.StatusNeedsApproval ||
+		inst.Status == session.StatusBudgetExceeded ||
 		inst.Status == session.StatusIdle {
 		// Session is ready - stop animation immediately
 		return false
@@ -1550,11 +1600,327 @@ func (h *Home) backgroundStatusUpdate() {
 		h.cachedStatusCounts.valid.Store(false)
 	}
 
+	// Refresh git badges (throttled to the git status cache TTL)
+	h.refreshGitStatuses(instances)
+
 	// Always sync notification bar - must check for signal file (Ctrl+b N acknowledgments)
 	// even when no status changes occurred
 	h.syncNotificationsBackground()
 }
 
+// refreshGitStatuses updates the git status of every session for the list badges
+// Runs in the background worker; git.RefreshStatus caches per directory, so
+// sessions sharing a working directory only run git once.
+func (h *Home) refreshGitStatuses(instances []*session.Instance) {
+	if time.Since(h.lastGitRefresh) < gitStatusRefreshInterval {
+		return
+	}
+	h.lastGitRefresh = time.Now()
+
+	statuses := make(map[string]*git.Status, len(instances))
+	for _, inst := range instances {
+		statuses[inst.ID] = git.RefreshStatus(inst.GetActualWorkDir())
+	}
+
+	h.gitStatusMu.Lock()
+	h.gitStatuses = statuses
+	h.gitStatusMu.Unlock()
+}
+
+// getGitStatus returns the last known git status of a session (nil if unknown or not a repo)
+func (h *Home) getGitStatus(sessionID string) *git.Status {
+	h.gitStatusMu.RLock()
+	defer h.gitStatusMu.RUnlock()
+	return h.gitStatuses[sessionID]
+}
+
+// fetchGitPanel returns a command that reads fresh git status and diff for the git panel
+func (h *Home) fetchGitPanel(inst *session.Instance) tea.Cmd {
+	sessionID := inst.ID
+	return func() tea.Msg {
+		workDir := inst.GetActualWorkDir()
+		git.InvalidateStatus(workDir)
+		status := git.RefreshStatus(workDir)
+		if status == nil {
+			return gitPanelFetchedMsg{
+				sessionID: sessionID,
+				workDir:   workDir,
+				err:       fmt.Errorf("not a git repository: %s", workDir),
+			}
+		}
+		diff, err := git.GetDiff(workDir)
+		return gitPanelFetchedMsg{
+			sessionID: sessionID,
+			workDir:   workDir,
+			status:    status,
+			diff:      diff,
+			err:       err,
+		}
+	}
+}
+
+// handleGitPanelKey handles keys when the git panel is visible
+func (h *Home) handleGitPanelKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "r" {
+		h.instancesMu.RLock()
+		inst := h.instanceByID[h.gitPanel.SessionID()]
+		h.instancesMu.RUnlock()
+		if inst != nil {
+			h.gitPanel.SetLoading()
+			return h, h.fetchGitPanel(inst)
+		}
+		return h, nil
+	}
+	h.gitPanel, _ = h.gitPanel.Update(msg)
+	return h, nil
+}
+
+// fetchLineageCosts returns a command that reads estimated costs for the
+// sessions in the lineage panel (parses session files, so off the UI thread)
+func (h *Home) fetchLineageCosts() tea.Cmd {
+	rootID := h.lineagePanel.RootID()
+	sessions := h.lineagePanel.Sessions()
+	return func() tea.Msg {
+		costs := make(map[string]float64, len(sessions))
+		for _, inst := range sessions {
+			costs[inst.ID] = inst.EstimatedCost()
+		}
+		return lineageCostsMsg{rootID: rootID, costs: costs}
+	}
+}
+
+// handleLineagePanelKey handles keys when the lineage panel is visible
+func (h *Home) handleLineagePanelKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	node := h.lineagePanel.Selected()
+	if h.lineagePanel.ShowingDiff() || node == nil {
+		h.lineagePanel, _ = h.lineagePanel.Update(msg)
+		return h, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		h.lineagePanel.Hide()
+		h.jumpToSession(node.Session)
+		return h, nil
+
+	case "d":
+		// Diff the selected session's last response against the one it came from
+		if node.Parent == nil {
+			h.lineagePanel.ShowDiffLoading(node.Session.Title)
+			h.lineagePanel.SetDiff(lineageDiffMsg{err: fmt.Errorf("'%s' is the root of the tree; select a fork or sub-session", node.Session.Title)})
+			return h, nil
+		}
+		from, to := node.Parent.Session, node.Session
+		h.lineagePanel.ShowDiffLoading(from.Title + " → " + to.Title)
+		return h, func() tea.Msg {
+			diff, err := session.DiffLastResponses(from, to)
+			return lineageDiffMsg{diff: diff, err: err}
+		}
+
+	case "x":
+		// Prune: delete the selected session and everything descended from it
+		sessions := node.Sessions()
+		details := fmt.Sprintf("• %d session(s) will be deleted\n• Their tmux sessions will be killed\n• Press Ctrl+Z after deletion to undo", len(sessions))
+		h.confirmDialog.ShowPruneBranch(node.Session.ID, node.Session.Title, details)
+		return h, nil
+	}
+
+	h.lineagePanel, _ = h.lineagePanel.Update(msg)
+	return h, nil
+}
+
+// pruneLineageBranch deletes the session with the given ID and all of its
+// forks and sub-sessions shown in the lineage panel
+func (h *Home) pruneLineageBranch(sessionID string) tea.Cmd {
+	h.instancesMu.RLock()
+	instances := append([]*session.Instance(nil), h.instances...)
+	h.instancesMu.RUnlock()
+
+	root := session.BuildLineage(instances, sessionID)
+	if root == nil {
+		return nil
+	}
+	node := root.Find(sessionID)
+	h.lineagePanel.Hide()
+
+	var cmds []tea.Cmd
+	for _, inst := range node.Sessions() {
+		cmds = append(cmds, h.deleteSession(inst))
+	}
+	return tea.Batch(cmds...)
+}
+
+// fetchCheckpoints returns a command that loads a session's checkpoints
+func (h *Home) fetchCheckpoints(inst *session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		checkpoints, err := inst.Checkpoints()
+		return checkpointsLoadedMsg{sessionID: inst.ID, checkpoints: checkpoints, err: err}
+	}
+}
+
+// handleCheckpointPanelKey handles keys when the checkpoint panel is visible
+func (h *Home) handleCheckpointPanelKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	cp := h.checkpointPanel.Selected()
+	inst := h.getInstanceByID(h.checkpointPanel.SessionID())
+	if h.checkpointPanel.ShowingDiff() || cp == nil || inst == nil {
+		h.checkpointPanel, _ = h.checkpointPanel.Update(msg)
+		return h, nil
+	}
+
+	switch msg.String() {
+	case "enter", "d":
+		// Show what the selected checkpoint changed
+		h.checkpointPanel.ShowDiffLoading()
+		projectPath, commit := inst.ProjectPath, cp.Commit
+		return h, func() tea.Msg {
+			diff, err := git.CheckpointDiff(projectPath, commit)
+			return checkpointDiffMsg{diff: diff, err: err}
+		}
+
+	case "r":
+		details := "• Files are restored to this checkpoint; newer files are removed\n" +
+			"• The current state is saved as a new checkpoint first\n" +
+			"• The index and branch are not touched"
+		h.confirmDialog.ShowRollbackCheckpoint(inst.ID, inst.Title, cp.Number, details)
+		return h, nil
+	}
+
+	h.checkpointPanel, _ = h.checkpointPanel.Update(msg)
+	return h, nil
+}
+
+// handleQueuePanelKey handles keys when the prompt queue panel is visible
+func (h *Home) handleQueuePanelKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// The instance may have been replaced by a reload since the panel opened
+	inst := h.getInstanceByID(h.queuePanel.SessionID())
+	if inst == nil {
+		h.queuePanel.Hide()
+		return h, nil
+	}
+	h.queuePanel.SetInstance(inst)
+
+	var cmd tea.Cmd
+	var changed bool
+	h.queuePanel, cmd, changed = h.queuePanel.Update(msg)
+	if changed {
+		h.saveInstances()
+	}
+	return h, cmd
+}
+
+// toggleBroadcastSelection marks or unmarks a session for a broadcast. On a
+// group it marks all of the group's sessions, or unmarks them if all are marked.
+func (h *Home) toggleBroadcastSelection(item session.Item) {
+	switch {
+	case item.Type == session.ItemTypeSession && item.Session != nil:
+		if h.selectedSessions[item.Session.ID] {
+			delete(h.selectedSessions, item.Session.ID)
+		} else {
+			h.selectedSessions[item.Session.ID] = true
+		}
+	case item.Type == session.ItemTypeGroup:
+		filter := session.BroadcastFilter{Group: item.Path}
+		var members []string
+		allMarked := true
+		h.instancesMu.RLock()
+		for _, inst := range h.instances {
+			if filter.Matches(inst) {
+				members = append(members, inst.ID)
+				allMarked = allMarked && h.selectedSessions[inst.ID]
+			}
+		}
+		h.instancesMu.RUnlock()
+		for _, id := range members {
+			if allMarked {
+				delete(h.selectedSessions, id)
+			} else {
+				h.selectedSessions[id] = true
+			}
+		}
+	}
+}
+
+// broadcastTargets returns the sessions a broadcast goes to: the marked
+// sessions if any, else the group or session under the cursor
+func (h *Home) broadcastTargets() ([]*session.Instance, string) {
+	h.instancesMu.RLock()
+	defer h.instancesMu.RUnlock()
+
+	var targets []*session.Instance
+	if len(h.selectedSessions) > 0 {
+		for _, inst := range h.instances {
+			if h.selectedSessions[inst.ID] {
+				targets = append(targets, inst)
+			}
+		}
+		return targets, fmt.Sprintf("%d selected sessions", len(targets))
+	}
+
+	if h.cursor >= len(h.flatItems) {
+		return nil, ""
+	}
+	item := h.flatItems[h.cursor]
+	switch {
+	case item.Type == session.ItemTypeGroup:
+		filter := session.BroadcastFilter{Group: item.Path}
+		for _, inst := range h.instances {
+			if filter.Matches(inst) {
+				targets = append(targets, inst)
+			}
+		}
+		return targets, "group " + item.Path
+	case item.Type == session.ItemTypeSession && item.Session != nil:
+		return []*session.Instance{item.Session}, item.Session.Title
+	}
+	return nil, ""
+}
+
+// fetchPipelineRuns returns a command that loads the profile's pipeline runs
+func (h *Home) fetchPipelineRuns() tea.Cmd {
+	profile := h.profile
+	return func() tea.Msg {
+		runs, err := session.ListPipelineRuns(profile)
+		return pipelineRunsLoadedMsg{runs: runs, err: err}
+	}
+}
+
+// fetchDashboard collects dashboard data in the background. Only transcripts
+// that changed since the last refresh are parsed again.
+func (h *Home) fetchDashboard() tea.Cmd {
+	if h.dashboardFetching {
+		return nil
+	}
+	h.dashboardFetching = true
+	h.instancesMu.RLock()
+	instances := make([]*session.Instance, len(h.instances))
+	copy(instances, h.instances)
+	h.instancesMu.RUnlock()
+	return func() tea.Msg {
+		return dashboardLoadedMsg{data: collectDashboard(instances, time.Now())}
+	}
+}
+
+// handlePipelinePanelKey handles keys when the pipeline panel is visible
+func (h *Home) handlePipelinePanelKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "enter" {
+		if inst := h.getInstanceByID(h.pipelinePanel.CurrentSessionID()); inst != nil {
+			h.pipelinePanel.Hide()
+			h.jumpToSession(inst)
+		}
+		return h, nil
+	}
+	h.pipelinePanel, _ = h.pipelinePanel.Update(msg)
+	return h, nil
+}
+
+// rollbackCheckpoint restores a session's working tree to checkpoint n in the background
+func (h *Home) rollbackCheckpoint(inst *session.Instance, n int) tea.Cmd {
+	return func() tea.Msg {
+		_, err := inst.RollbackToCheckpoint(n)
+		return checkpointRolledBackMsg{sessionID: inst.ID, number: n, err: err}
+	}
+}
+
 // syncNotificationsBackground updates the tmux notification bar directly
 // Called from background worker - does NOT depend on Bubble Tea
 func (h *Home) syncNotificationsBackground() {
@@ -1848,6 +2214,13 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		h.setupWizard.SetSize(msg.Width, msg.Height)
 		h.settingsPanel.SetSize(msg.Width, msg.Height)
 		h.geminiModelDialog.SetSize(msg.Width, msg.Height)
+		h.gitPanel.SetSize(msg.Width, msg.Height)
+		h.lineagePanel.SetSize(msg.Width, msg.Height)
+		h.checkpointPanel.SetSize(msg.Width, msg.Height)
+		h.queuePanel.SetSize(msg.Width, msg.Height)
+		h.pipelinePanel.SetSize(msg.Width, msg.Height)
+		h.dashboardPanel.SetSize(msg.Width, msg.Height)
+		h.broadcastDialog.SetSize(msg.Width, msg.Height)
 		return h, nil
 
 	case loadSessionsMsg:
@@ -1914,6 +2287,14 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			h.instancesMu.Unlock()
+			// Keep an open queue panel on the reloaded copy of its session
+			if h.queuePanel.IsVisible() {
+				if inst := h.getInstanceByID(h.queuePanel.SessionID()); inst != nil {
+					h.queuePanel.SetInstance(inst)
+				} else {
+					h.queuePanel.Hide()
+				}
+			}
 			// Invalidate status counts cache
 			h.cachedStatusCounts.valid.Store(false)
 			// Sync group tree with loaded data
@@ -1982,7 +2363,9 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		if msg.err != nil {
 			h.setError(msg.err)
-		} else {
+		}
+		// A session whose worktree bootstrap failed is still added, in the error state
+		if msg.instance != nil {
 			h.instancesMu.Lock()
 			h.instances = append(h.instances, msg.instance)
 			h.instanceByID[msg.instance.ID] = msg.instance
@@ -1993,7 +2376,9 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			h.cachedStatusCounts.valid.Store(false)
 
 			// Track as launching for animation
-			h.launchingSessions[msg.instance.ID] = time.Now()
+			if msg.err == nil {
+				h.launchingSessions[msg.instance.ID] = time.Now()
+			}
 
 			// Expand the group so the session is visible
 			if msg.instance.GroupPath != "" {
@@ -2036,7 +2421,9 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if msg.err != nil {
 			h.setError(msg.err)
-		} else {
+		}
+		// A session whose worktree bootstrap failed is still added, in the error state
+		if msg.instance != nil {
 			h.instancesMu.Lock()
 			h.instances = append(h.instances, msg.instance)
 			h.instanceByID[msg.instance.ID] = msg.instance
@@ -2048,7 +2435,9 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			h.cachedStatusCounts.valid.Store(false)
 
 			// Track as launching for animation
-			h.launchingSessions[msg.instance.ID] = time.Now()
+			if msg.err == nil {
+				h.launchingSessions[msg.instance.ID] = time.Now()
+			}
 
 			// Expand the group so the session is visible
 			if msg.instance.GroupPath != "" {
@@ -2127,6 +2516,47 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case worktreeFinishedMsg:
+		if msg.err != nil {
+			if msg.result != nil {
+				h.setError(fmt.Errorf("%s landed on %s, but cleanup failed: %w", msg.result.Branch, msg.result.Target, msg.err))
+			} else {
+				h.setError(fmt.Errorf("worktree finish stopped: %w", msg.err))
+			}
+			return h, nil
+		}
+
+		inst := h.getInstanceByID(msg.sessionID)
+		if inst == nil {
+			return h, nil
+		}
+		if msg.archive {
+			h.groupTree.MoveSessionToGroup(inst, session.ArchivedGroupPath)
+			inst.ArchiveFinishedWorktree()
+		} else {
+			h.instancesMu.Lock()
+			for i, s := range h.instances {
+				if s.ID == msg.sessionID {
+					h.instances = append(h.instances[:i], h.instances[i+1:]...)
+					break
+				}
+			}
+			delete(h.instanceByID, msg.sessionID)
+			h.instancesMu.Unlock()
+			h.groupTree.RemoveSession(inst)
+			h.invalidatePreviewCache(msg.sessionID)
+			h.search.SetItems(h.instances)
+		}
+		h.cachedStatusCounts.valid.Store(false)
+		h.rebuildFlatItems()
+		h.saveInstances()
+		if msg.result.Strategy == "pr" {
+			h.setError(fmt.Errorf("finished '%s': %s pushed for a pull request", inst.Title, msg.result.Branch))
+		} else {
+			h.setError(fmt.Errorf("finished '%s': %s landed on %s", inst.Title, msg.result.Branch, msg.result.Target))
+		}
+		return h, nil
+
 	case sessionRestoredMsg:
 		if h.isReloading {
 			log.Printf("[RELOAD-DEBUG] sessionRestoredMsg: skipping during reload")
@@ -2269,6 +2699,13 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case ScheduleRunMsg:
+		h.maintenanceMsg = session.ScheduleRunSummary(msg.Run)
+		h.maintenanceMsgTime = time.Now()
+		return h, tea.Tick(30*time.Second, func(_ time.Time) tea.Msg {
+			return clearMaintenanceMsg{}
+		})
+
 	case clearMaintenanceMsg:
 		h.maintenanceMsg = ""
 		return h, nil
@@ -2472,6 +2909,69 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case lineageCostsMsg:
+		h.lineagePanel.SetCosts(msg)
+		return h, nil
+
+	case lineageDiffMsg:
+		h.lineagePanel.SetDiff(msg)
+		return h, nil
+
+	case repoFilterLoadedMsg:
+		if msg.err != nil {
+			h.setError(msg.err)
+			return h, nil
+		}
+		h.repoFilter = msg.filter
+		h.rebuildFlatItems()
+		return h, nil
+
+	case checkpointsLoadedMsg:
+		h.checkpointPanel.SetCheckpoints(msg)
+		return h, nil
+
+	case checkpointDiffMsg:
+		h.checkpointPanel.SetDiff(msg)
+		return h, nil
+
+	case broadcastDoneMsg:
+		h.broadcastDialog.SetResults(msg)
+		return h, nil
+
+	case pipelineRunsLoadedMsg:
+		titles := make(map[string]string)
+		h.instancesMu.RLock()
+		for _, inst := range h.instances {
+			titles[inst.ID] = inst.Title
+		}
+		h.instancesMu.RUnlock()
+		h.pipelinePanel.SetRuns(msg, titles)
+		return h, nil
+
+	case dashboardLoadedMsg:
+		h.dashboardFetching = false
+		h.dashboardPanel.SetData(msg.data)
+		return h, nil
+
+	case checkpointRolledBackMsg:
+		if msg.err != nil {
+			h.setError(fmt.Errorf("rollback to checkpoint %d failed: %w", msg.number, msg.err))
+		}
+		// Reload the timeline: the pre-rollback state is now the newest checkpoint
+		if inst := h.getInstanceByID(msg.sessionID); inst != nil && h.checkpointPanel.IsVisible() {
+			return h, h.fetchCheckpoints(inst)
+		}
+		return h, nil
+
+	case gitPanelFetchedMsg:
+		h.gitPanel.SetData(msg)
+		if msg.err == nil {
+			h.gitStatusMu.Lock()
+			h.gitStatuses[msg.sessionID] = msg.status
+			h.gitStatusMu.Unlock()
+		}
+		return h, nil
+
 	case previewFetchedMsg:
 		// Async preview content received - update cache with timestamp
 		// Protect both previewFetchingID and previewCache with the same mutex
@@ -2604,6 +3104,29 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Sync notification bar with current session states
 		h.syncNotifications()
 
+		// Persist queues that shrank because a queued prompt was delivered
+		queueDelivered := false
+		h.instancesMu.RLock()
+		for _, inst := range h.instances {
+			if inst.TakeQueueDelivered() {
+				queueDelivered = true
+			}
+		}
+		h.instancesMu.RUnlock()
+		if queueDelivered {
+			h.saveInstances()
+		}
+
+		// Keep the pipeline panel live while it is open
+		var pipelineCmd tea.Cmd
+		if h.pipelinePanel.IsVisible() {
+			pipelineCmd = h.fetchPipelineRuns()
+		}
+		var dashboardCmd tea.Cmd
+		if h.dashboardPanel.IsVisible() {
+			dashboardCmd = h.fetchDashboard()
+		}
+
 		// Fetch preview for currently selected session (if stale/missing and not fetching)
 		// Cache expires after 2 seconds to show live terminal updates without excessive fetching
 		const previewCacheTTL = 2 * time.Second
@@ -2622,7 +3145,7 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			h.previewCacheMu.Unlock()
 		}
-		return h, tea.Batch(h.tick(), previewCmd)
+		return h, tea.Batch(h.tick(), previewCmd, pipelineCmd, dashboardCmd)
 
 	case tea.KeyMsg:
 		// Track user activity for adaptive status updates
@@ -2707,6 +3230,30 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if h.sessionPickerDialog.IsVisible() {
 			return h.handleSessionPickerDialogKey(msg)
 		}
+		if h.gitPanel.IsVisible() {
+			return h.handleGitPanelKey(msg)
+		}
+		if h.lineagePanel.IsVisible() {
+			return h.handleLineagePanelKey(msg)
+		}
+		if h.checkpointPanel.IsVisible() {
+			return h.handleCheckpointPanelKey(msg)
+		}
+		if h.queuePanel.IsVisible() {
+			return h.handleQueuePanelKey(msg)
+		}
+		if h.pipelinePanel.IsVisible() {
+			return h.handlePipelinePanelKey(msg)
+		}
+		if h.dashboardPanel.IsVisible() {
+			h.dashboardPanel, _ = h.dashboardPanel.Update(msg)
+			return h, nil
+		}
+		if h.broadcastDialog.IsVisible() {
+			var cmd tea.Cmd
+			h.broadcastDialog, cmd = h.broadcastDialog.Update(msg)
+			return h, cmd
+		}
 
 		// Main view keys
 		return h.handleMainKey(msg)
@@ -2916,7 +3463,7 @@ func (h *Home) handleNewDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 
 			// Generate worktree path using configured location
-			wtSettings := session.GetWorktreeSettings()
+			wtSettings := session.GetRepoWorktreeSettings(repoRoot)
 			worktreePath = git.GenerateWorktreePath(repoRoot, branchName, wtSettings.DefaultLocation)
 
 			// Ensure parent directory exists (needed for subdirectory mode)
@@ -2969,6 +3516,11 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			h.maintenanceMsg = ""
 			return h, nil
 		}
+		// Clear the broadcast selection
+		if len(h.selectedSessions) > 0 {
+			h.selectedSessions = make(map[string]bool)
+			return h, nil
+		}
 		// Double ESC to quit (#28) - for non-English keyboard users
 		// If ESC pressed twice within 500ms, quit the application
 		if time.Since(h.lastEscTime) < 500*time.Millisecond {
@@ -3214,34 +3766,30 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
-	case "f":
-		// Quick fork session (same title with " (fork)" suffix)
-		// Only available when session has a valid Claude session ID
-		if h.cursor < len(h.flatItems) {
-			item := h.flatItems[h.cursor]
-			if item.Type == session.ItemTypeSession && item.Session != nil && item.Session.CanFork() {
-				return h, h.quickForkSession(item.Session)
-			}
-		}
-		return h, nil
-
-	case "F", "shift+f":
-		// Fork with dialog (customize title and group)
-		// Only available when session has a valid Claude session ID
+	case "f", "F", "shift+f":
+		// f: quick fork (same title with " (fork)" suffix), F: fork with dialog
+		// Only available when the session's conversation can be forked
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
-			if item.Type == session.ItemTypeSession && item.Session != nil && item.Session.CanFork() {
+			if item.Type == session.ItemTypeSession && item.Session != nil {
+				if reason := item.Session.ForkUnavailableReason(); reason != "" {
+					h.setError(fmt.Errorf("cannot fork '%s': %s", item.Session.Title, reason))
+					return h, nil
+				}
+				if msg.String() == "f" {
+					return h, h.quickForkSession(item.Session)
+				}
 				return h, h.forkSessionWithDialog(item.Session)
 			}
 		}
 		return h, nil
 
 	case "M", "shift+m":
-		// MCP Manager - for Claude and Gemini sessions
+		// MCP Manager - for Claude, Gemini, Codex and OpenCode sessions
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil &&
-				(item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+				session.ToolSupportsMCP(item.Session.Tool) {
 				h.mcpDialog.SetSize(h.width, h.height)
 				if err := h.mcpDialog.Show(item.Session.ProjectPath, item.Session.ID, item.Session.Tool); err != nil {
 					h.setError(err)
@@ -3372,9 +3920,6 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		h.newDialog.SetPathSuggestions(paths)
 
-		// Apply user's preferred default tool from config
-		h.newDialog.SetDefaultTool(session.GetDefaultTool())
-
 		// Auto-select parent group from current cursor position
 		groupPath := session.DefaultGroupPath
 		groupName := session.DefaultGroupName
@@ -3392,7 +3937,14 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		defaultPath := h.getDefaultPathForGroup(groupPath)
-		h.newDialog.ShowInGroup(groupPath, groupName, defaultPath)
+
+		// Ask before applying a repo config seen for the first time (or edited since)
+		if rc, err := session.LoadRepoConfig(defaultPath); err == nil && rc != nil && !rc.Trusted {
+			h.pendingNewDialog = &pendingNewDialog{groupPath: groupPath, groupName: groupName, path: defaultPath, repoConfig: rc}
+			h.confirmDialog.ShowTrustRepoConfig(truncatePath(rc.Path, 44), repoConfigTrustDetails(rc))
+			return h, nil
+		}
+		h.showNewDialog(groupPath, groupName, defaultPath)
 		return h, nil
 
 	case "d":
@@ -3407,6 +3959,16 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case "W":
+		// Finish worktree: land the branch, remove the worktree, delete/archive the session
+		if h.cursor < len(h.flatItems) {
+			item := h.flatItems[h.cursor]
+			if item.Type == session.ItemTypeSession && item.Session != nil && item.Session.IsWorktree() {
+				h.confirmDialog.ShowFinishWorktree(item.Session.ID, item.Session.Title, worktreeFinishDetails(item.Session))
+			}
+		}
+		return h, nil
+
 	case "i":
 		return h, h.importSessions
 
@@ -3425,6 +3987,114 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case "D":
+		// Open git panel for the selected session's working directory
+		if h.cursor < len(h.flatItems) {
+			item := h.flatItems[h.cursor]
+			if item.Type == session.ItemTypeSession && item.Session != nil {
+				h.gitPanel.SetSize(h.width, h.height)
+				h.gitPanel.Show(item.Session)
+				return h, h.fetchGitPanel(item.Session)
+			}
+		}
+		return h, nil
+
+	case "T":
+		// Open the conversation tree (forks and sub-sessions) of the selected session
+		if h.cursor < len(h.flatItems) {
+			item := h.flatItems[h.cursor]
+			if item.Type == session.ItemTypeSession && item.Session != nil {
+				h.instancesMu.RLock()
+				instances := append([]*session.Instance(nil), h.instances...)
+				h.instancesMu.RUnlock()
+				h.lineagePanel.SetSize(h.width, h.height)
+				h.lineagePanel.Show(instances, item.Session.ID)
+				return h, h.fetchLineageCosts()
+			}
+		}
+		return h, nil
+
+	case "H":
+		// Open the checkpoint timeline of the selected session's working tree
+		if h.cursor < len(h.flatItems) {
+			item := h.flatItems[h.cursor]
+			if item.Type == session.ItemTypeSession && item.Session != nil {
+				h.checkpointPanel.SetSize(h.width, h.height)
+				h.checkpointPanel.Show(item.Session.ID, item.Session.Title)
+				return h, h.fetchCheckpoints(item.Session)
+			}
+		}
+		return h, nil
+
+	case "Q":
+		// Edit the prompt queue of the selected session
+		if h.cursor < len(h.flatItems) {
+			item := h.flatItems[h.cursor]
+			if item.Type == session.ItemTypeSession && item.Session != nil {
+				h.queuePanel.SetSize(h.width, h.height)
+				h.queuePanel.Show(item.Session)
+			}
+		}
+		return h, nil
+
+	case " ":
+		// Mark the session (or every session of the group) for a broadcast
+		if h.cursor < len(h.flatItems) {
+			h.toggleBroadcastSelection(h.flatItems[h.cursor])
+		}
+		return h, nil
+
+	case "B":
+		// Broadcast a message to the marked sessions, or to the group under the cursor
+		targets, label := h.broadcastTargets()
+		if len(targets) == 0 {
+			return h, nil
+		}
+		h.broadcastDialog.SetSize(h.width, h.height)
+		return h, h.broadcastDialog.Show(targets, label)
+
+	case "a", "A", "N":
+		// Answer the selected session's permission prompt without attaching
+		if inst := h.getSelectedSession(); inst != nil && inst.Status == session.StatusNeedsApproval {
+			var err error
+			if msg.String() == "N" {
+				err = inst.Deny()
+			} else {
+				err = inst.Approve(msg.String() == "A")
+			}
+			if err != nil {
+				h.setError(fmt.Errorf("%s: %w", inst.Title, err))
+				return h, nil
+			}
+			h.saveInstances()
+		}
+		return h, nil
+
+	case "O":
+		// Let the selected session continue past its budget
+		if inst := h.getSelectedSession(); inst != nil && (inst.BudgetExceeded != "" || inst.BudgetWarning != "") {
+			inst.OverrideBudget()
+			h.saveInstances()
+			h.maintenanceMsg = fmt.Sprintf("Budget of '%s' overridden; usage counts from zero again", inst.Title)
+			h.maintenanceMsgTime = time.Now()
+			return h, tea.Tick(30*time.Second, func(_ time.Time) tea.Msg {
+				return clearMaintenanceMsg{}
+			})
+		}
+		return h, nil
+
+	case "P":
+		// Follow pipeline runs of this profile
+		h.pipelinePanel.SetSize(h.width, h.height)
+		h.pipelinePanel.Show()
+		return h, h.fetchPipelineRuns()
+
+	case "U":
+		// Cost and activity across all sessions
+		h.dashboardPanel.SetSize(h.width, h.height)
+		h.dashboardPanel.Show()
+		return h, h.fetchDashboard()
+
 	case "v":
 		// Toggle preview mode (cycle: both → output-only → analytics-only → both)
 		h.previewMode = (h.previewMode + 1) % 3
@@ -3545,11 +4215,26 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return h, nil
 
 	case "0":
-		// Clear status filter (show all)
+		// Clear status and repo filters (show all)
 		h.statusFilter = ""
+		h.repoFilter = nil
 		h.rebuildFlatItems()
 		return h, nil
 
+	case "%", "shift+5":
+		// Filter to sessions in the selected session's repository (any worktree)
+		if h.repoFilter != nil {
+			h.repoFilter = nil // Toggle off
+			h.rebuildFlatItems()
+			return h, nil
+		}
+		inst := h.getSelectedSession()
+		if inst == nil {
+			h.setError(fmt.Errorf("select a session to filter by its repository"))
+			return h, nil
+		}
+		return h, h.loadRepoFilter(inst)
+
 	case "!", "shift+1":
 		// Filter to running sessions only
 		if h.statusFilter == session.StatusRunning {
@@ -3630,6 +4315,31 @@ func (h *Home) handleConfirmDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					h.confirmDialog.Hide()
 					return h, h.deleteSession(inst)
 				}
+			case ConfirmFinishWorktree:
+				sessionID := h.confirmDialog.GetTargetID()
+				if inst := h.getInstanceByID(sessionID); inst != nil {
+					h.confirmDialog.Hide()
+					return h, h.finishWorktree(inst)
+				}
+			case ConfirmPruneBranch:
+				h.confirmDialog.Hide()
+				return h, h.pruneLineageBranch(h.confirmDialog.GetTargetID())
+			case ConfirmRollbackCheckpoint:
+				sessionID, number := h.confirmDialog.GetTargetID(), h.confirmDialog.GetCheckpoint()
+				h.confirmDialog.Hide()
+				if inst := h.getInstanceByID(sessionID); inst != nil {
+					return h, h.rollbackCheckpoint(inst, number)
+				}
+				return h, nil
+			case ConfirmTrustRepoConfig:
+				h.confirmDialog.Hide()
+				if pending := h.pendingNewDialog; pending != nil {
+					if err := session.TrustRepoConfig(pending.repoConfig); err != nil {
+						h.setError(fmt.Errorf("failed to trust repo config: %w", err))
+					}
+				}
+				h.resumeNewDialog()
+				return h, nil
 			case ConfirmDeleteGroup:
 				groupPath := h.confirmDialog.GetTargetID()
 				h.groupTree.DeleteGroup(groupPath)
@@ -3645,6 +4355,10 @@ func (h *Home) handleConfirmDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "n", "N", "esc":
 			// User cancelled
 			h.confirmDialog.Hide()
+			if h.confirmDialog.GetConfirmType() == ConfirmTrustRepoConfig {
+				// Untrusted: create the session without the repo config
+				h.resumeNewDialog()
+			}
 			return h, nil
 		}
 	}
@@ -3881,6 +4595,10 @@ func (h *Home) handleForkDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Get fork parameters from dialog
 		title, groupPath := h.forkDialog.GetValues()
 		opts := h.forkDialog.GetOptions()
+		worktreeBranch, worktreeEnabled := h.forkDialog.GetWorktree()
+		if !worktreeEnabled {
+			worktreeBranch = ""
+		}
 		h.clearError() // Clear any previous error
 
 		// Find the currently selected session
@@ -3888,7 +4606,7 @@ func (h *Home) handleForkDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil {
 				h.forkDialog.Hide()
-				return h, h.forkSessionCmdWithOptions(item.Session, title, groupPath, opts)
+				return h, h.forkSessionCmdWithOptions(item.Session, title, groupPath, opts, worktreeBranch)
 			}
 		}
 		h.forkDialog.Hide()
@@ -4037,6 +4755,9 @@ func (h *Home) createSessionInGroupWithWorktreeAndOptions(name, path, command, g
 			inst.WorktreePath = worktreePath
 			inst.WorktreeRepoRoot = worktreeRepoRoot
 			inst.WorktreeBranch = worktreeBranch
+			if err := inst.PrepareWorktree(); err != nil {
+				return sessionCreatedMsg{err: fmt.Errorf("failed to set up worktree: %w", err)}
+			}
 		}
 
 		// Set Gemini YOLO mode if enabled (per-session override)
@@ -4052,6 +4773,9 @@ func (h *Home) createSessionInGroupWithWorktreeAndOptions(name, path, command, g
 		}
 
 		if err := inst.Start(); err != nil {
+			if inst.BootstrapError != "" {
+				return sessionCreatedMsg{instance: inst, err: err}
+			}
 			return sessionCreatedMsg{err: err}
 		}
 		return sessionCreatedMsg{instance: inst}
@@ -4076,18 +4800,20 @@ func (h *Home) forkSessionWithDialog(source *session.Instance) tea.Cmd {
 	}
 	// Pre-populate dialog with source session info
 	h.forkDialog.Show(source.Title, source.ProjectPath, source.GroupPath)
+	h.forkDialog.SetTool(source.Tool)
 	return nil
 }
 
 // forkSessionCmd creates a forked session with the given title and group
 // Shows immediate UI feedback by tracking the source session in forkingSessions
 func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath string) tea.Cmd {
-	return h.forkSessionCmdWithOptions(source, title, groupPath, nil)
+	return h.forkSessionCmdWithOptions(source, title, groupPath, nil, "")
 }
 
 // forkSessionCmdWithOptions creates a forked session with the given title, group, and Claude options
+// A non-empty worktreeBranch forks into a new git worktree on that branch
 // Shows immediate UI feedback by tracking the source session in forkingSessions
-func (h *Home) forkSessionCmdWithOptions(source *session.Instance, title, groupPath string, opts *session.ClaudeOptions) tea.Cmd {
+func (h *Home) forkSessionCmdWithOptions(source *session.Instance, title, groupPath string, opts *session.ClaudeOptions, worktreeBranch string) tea.Cmd {
 	if source == nil {
 		return nil
 	}
@@ -4107,13 +4833,23 @@ func (h *Home) forkSessionCmdWithOptions(source *session.Instance, title, groupP
 		}
 
 		// Use CreateForkedInstanceWithOptions to get the proper fork command with options
-		inst, _, err := source.CreateForkedInstanceWithOptions(title, groupPath, opts)
+		var inst *session.Instance
+		var err error
+		if worktreeBranch != "" {
+			inst, _, err = source.CreateForkedInstanceInWorktree(title, groupPath, worktreeBranch, "", opts)
+		} else {
+			inst, _, err = source.CreateForkedInstanceWithOptions(title, groupPath, opts)
+		}
 		if err != nil {
 			return sessionForkedMsg{err: fmt.Errorf("cannot create forked instance: %w", err), sourceID: sourceID}
 		}
 
 		// Start the forked session
 		if err := inst.Start(); err != nil {
+			if inst.BootstrapError != "" {
+				return sessionForkedMsg{instance: inst, err: err, sourceID: sourceID}
+			}
+			_ = inst.RemoveForkWorktree()
 			return sessionForkedMsg{err: err, sourceID: sourceID}
 		}
 
@@ -4134,6 +4870,92 @@ type sessionDeletedMsg struct {
 	killErr   error // Error from Kill() if any
 }
 
+// worktreeFinishedMsg signals that a worktree finish completed (or stopped)
+type worktreeFinishedMsg struct {
+	sessionID string
+	result    *session.WorktreeFinishResult
+	archive   bool
+	err       error
+}
+
+// worktreeFinishDetails describes what finishing inst will do, from the [worktree] config
+func worktreeFinishDetails(inst *session.Instance) string {
+	settings := session.GetRepoWorktreeSettings(inst.WorktreeRepoRoot)
+	target := settings.FinishTarget
+	if target == "" {
+		target = "the main worktree's branch"
+	}
+
+	var lines []string
+	switch settings.FinishStrategy {
+	case "squash":
+		lines = append(lines, fmt.Sprintf("• Squash-merge %s into %s", inst.WorktreeBranch, target))
+	case "pr":
+		lines = append(lines, fmt.Sprintf("• Rebase %s onto %s and push it", inst.WorktreeBranch, target))
+	default:
+		lines = append(lines, fmt.Sprintf("• Rebase %s onto %s and fast-forward", inst.WorktreeBranch, target))
+	}
+	if settings.FinishPush && settings.FinishStrategy != "pr" {
+		lines = append(lines, "• Push the branch to origin")
+	}
+	lines = append(lines, "• Remove worktree "+truncatePath(inst.WorktreePath, 36))
+	if settings.FinishSession == "archive" {
+		lines = append(lines, "• Move the session to '"+session.ArchivedGroupPath+"'")
+	} else {
+		lines = append(lines, "• Delete the session")
+	}
+	lines = append(lines, "• Stops without changes on conflicts")
+	return strings.Join(lines, "\n")
+}
+
+// pendingNewDialog remembers where the new session dialog was opened while
+// the user decides whether to trust the repository's config
+type pendingNewDialog struct {
+	groupPath  string
+	groupName  string
+	path       string
+	repoConfig *session.RepoConfig
+}
+
+// showNewDialog opens the new session dialog with defaults for path's repository
+func (h *Home) showNewDialog(groupPath, groupName, path string) {
+	// Apply the preferred default tool from config (repo config first)
+	h.newDialog.SetDefaultTool(session.GetDefaultToolForPath(path))
+	h.newDialog.ShowInGroup(groupPath, groupName, path)
+}
+
+// resumeNewDialog opens the new session dialog held back by the trust prompt
+func (h *Home) resumeNewDialog() {
+	if pending := h.pendingNewDialog; pending != nil {
+		h.pendingNewDialog = nil
+		h.showNewDialog(pending.groupPath, pending.groupName, pending.path)
+	}
+}
+
+// repoConfigTrustDetails lists what a repo config sets for the trust prompt
+func repoConfigTrustDetails(rc *session.RepoConfig) string {
+	var lines []string
+	for _, key := range rc.Keys() {
+		lines = append(lines, "• Sets "+key)
+	}
+	for _, command := range rc.Commands() {
+		lines = append(lines, "• Runs "+command)
+	}
+	lines = append(lines, "• Ignored until trusted; edits need trust again")
+	return strings.Join(lines, "\n")
+}
+
+// finishWorktree returns a command that lands inst's branch and removes its worktree
+func (h *Home) finishWorktree(inst *session.Instance) tea.Cmd {
+	id := inst.ID
+	archive := session.GetWorktreeSettings().FinishSession == "archive"
+	opts := session.WorktreeFinishOptionsFromSettings(inst.WorktreeRepoRoot)
+	return func() tea.Msg {
+		result, err := inst.FinishWorktree(opts)
+		return worktreeFinishedMsg{sessionID: id, result: result, archive: archive, err: err}
+	}
+}
+
 // sessionRestoredMsg signals that an undo-delete restore completed
 type sessionRestoredMsg struct {
 	instance *session.Instance
@@ -4318,7 +5140,7 @@ func (h *Home) countSessionStatuses() (running, waiting, idle, errored int) {
 		switch inst.Status {
 		case session.StatusRunning:
 			running++
-		case session.StatusWaiting:
+		case session.StatusWaiting, session.StatusNeedsApproval, session.StatusBudgetExceeded:
 			waiting++
 		case session.StatusIdle:
 			idle++
@@ -4439,9 +5261,22 @@ func (h *Home) renderFilterBar() string {
 		}
 	}
 
+	// Repo pill (only while the repo filter is active)
+	if h.repoFilter != nil {
+		repoLabel := fmt.Sprintf("⎇ %s · %d worktrees", filepath.Base(h.repoFilter.root), len(h.repoFilter.paths))
+		if h.repoFilter.otherProfiles > 0 {
+			repoLabel += fmt.Sprintf(" · +%d in other profiles", h.repoFilter.otherProfiles)
+		}
+		pills = append(pills, lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorPurple).
+			Bold(true).
+			Padding(0, 1).Render(repoLabel))
+	}
+
 	// Hint for keyboard shortcuts (shift+number to filter, 0 to clear)
 	hintStyle := lipgloss.NewStyle().Foreground(ColorComment).Faint(true)
-	hint := hintStyle.Render("  !@#$ filter • 0 all")
+	hint := hintStyle.Render("  !@#$ filter • % repo • 0 all")
 
 	// Join pills with spaces
 	filterRow := strings.Join(pills, " ") + hint
@@ -4539,6 +5374,27 @@ func (h *Home) View() string {
 	if h.sessionPickerDialog.IsVisible() {
 		return h.sessionPickerDialog.View()
 	}
+	if h.gitPanel.IsVisible() {
+		return h.gitPanel.View()
+	}
+	if h.lineagePanel.IsVisible() {
+		return h.lineagePanel.View()
+	}
+	if h.checkpointPanel.IsVisible() {
+		return h.checkpointPanel.View()
+	}
+	if h.queuePanel.IsVisible() {
+		return h.queuePanel.View()
+	}
+	if h.pipelinePanel.IsVisible() {
+		return h.pipelinePanel.View()
+	}
+	if h.dashboardPanel.IsVisible() {
+		return h.dashboardPanel.View()
+	}
+	if h.broadcastDialog.IsVisible() {
+		return h.broadcastDialog.View()
+	}
 
 	// Reuse viewBuilder to reduce allocations (reset and pre-allocate)
 	h.viewBuilder.Reset()
@@ -5296,7 +6152,7 @@ func (h *Home) renderHelpBarMinimal() string {
 			if item.Session != nil && item.Session.CanFork() {
 				contextKeys += " " + keyStyle.Render("f")
 			}
-			if item.Session != nil && (item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+			if item.Session != nil && session.ToolSupportsMCP(item.Session.Tool) {
 				contextKeys += " " + keyStyle.Render("M")
 			}
 		}
@@ -5351,8 +6207,10 @@ func (h *Home) renderHelpBarCompact() string {
 			if item.Session != nil && item.Session.CanFork() {
 				contextHints = append(contextHints, h.helpKeyShort("f", "Fork"))
 			}
-			if item.Session != nil && (item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+			if item.Session != nil && session.ToolSupportsMCP(item.Session.Tool) {
 				contextHints = append(contextHints, h.helpKeyShort("M", "MCP"))
+			}
+			if item.Session != nil && (item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
 				contextHints = append(contextHints, h.helpKeyShort("v", h.previewModeShort()))
 			}
 			contextHints = append(contextHints, h.helpKeyShort("c", "Copy"))
@@ -5449,18 +6307,28 @@ func (h *Home) renderHelpBarFull() string {
 			if item.Session != nil && item.Session.CanFork() {
 				primaryHints = append(primaryHints, h.helpKey("f/F", "Fork"))
 			}
-			// Show MCP Manager and preview mode toggle for Claude and Gemini sessions
-			if item.Session != nil && (item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+			// Show MCP Manager for sessions whose tool supports MCPs
+			if item.Session != nil && session.ToolSupportsMCP(item.Session.Tool) {
 				primaryHints = append(primaryHints, h.helpKey("M", "MCP"))
+			}
+			// Show preview mode toggle for Claude and Gemini sessions
+			if item.Session != nil && (item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
 				primaryHints = append(primaryHints, h.helpKey("v", h.previewModeShort()))
 			}
 			primaryHints = append(primaryHints, h.helpKey("c", "Copy"))
 			primaryHints = append(primaryHints, h.helpKey("x", "Send"))
 			secondaryHints = []string{
+				h.helpKey("D", "Git"),
 				h.helpKey("r", "Rename"),
 				h.helpKey("m", "Move"),
 				h.helpKey("d", "Delete"),
 			}
+			if item.Session != nil && item.Session.IsWorktree() {
+				secondaryHints = append(secondaryHints, h.helpKey("W", "Finish"))
+			}
+			if item.Session != nil && (item.Session.IsFork() || item.Session.IsSubSession()) {
+				secondaryHints = append(secondaryHints, h.helpKey("T", "Tree"))
+			}
 		}
 	}
 
@@ -5656,7 +6524,7 @@ func (h *Home) renderGroupItem(b *strings.Builder, item session.Item, selected b
 				switch sess.Status {
 				case session.StatusRunning:
 					running++
-				case session.StatusWaiting:
+				case session.StatusWaiting, session.StatusNeedsApproval, session.StatusBudgetExceeded:
 					waiting++
 				}
 			}
@@ -5740,6 +6608,12 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 	case session.StatusWaiting:
 		statusIcon = "◐"
 		statusStyle = SessionStatusWaiting
+	case session.StatusNeedsApproval:
+		statusIcon = "◆"
+		statusStyle = SessionStatusNeedsApproval
+	case session.StatusBudgetExceeded:
+		statusIcon = "⊘"
+		statusStyle = SessionStatusBudgetExceeded
 	case session.StatusIdle:
 		statusIcon = "○"
 		statusStyle = SessionStatusIdle
@@ -5756,7 +6630,7 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 	// Title styling - add bold/underline for accessibility (colorblind users)
 	var titleStyle lipgloss.Style
 	switch inst.Status {
-	case session.StatusRunning, session.StatusWaiting:
+	case session.StatusRunning, session.StatusWaiting, session.StatusNeedsApproval, session.StatusBudgetExceeded:
 		// Bold for active states (distinguishable without color)
 		titleStyle = SessionTitleActive
 	case session.StatusError:
@@ -5788,6 +6662,14 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 	}
 
 	title := titleStyle.Render(inst.Title)
+	if h.selectedSessions[inst.ID] {
+		// Marked for a broadcast
+		markStyle := lipgloss.NewStyle().Foreground(ColorGreen).Bold(true)
+		if selected {
+			markStyle = SessionStatusSelStyle
+		}
+		title = markStyle.Render("✓ ") + title
+	}
 	tool := toolStyle.Render(" " + inst.Tool)
 
 	// YOLO badge for Gemini sessions with YOLO mode enabled
@@ -5800,10 +6682,37 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 		yoloBadge = yoloStyle.Render(" [YOLO]")
 	}
 
-	// Build row: [baseIndent][selection][tree][status] [title] [tool] [yolo]
+	// Git badges: ±N uncommitted files, ↑N unpushed commits (from background cache)
+	gitBadge := ""
+	if gs := h.getGitStatus(inst.ID); gs != nil {
+		dirtyStyle := lipgloss.NewStyle().Foreground(ColorYellow)
+		aheadStyle := lipgloss.NewStyle().Foreground(ColorCyan)
+		if selected {
+			dirtyStyle = SessionStatusSelStyle
+			aheadStyle = SessionStatusSelStyle
+		}
+		if gs.Dirty() {
+			gitBadge += dirtyStyle.Render(fmt.Sprintf(" ±%d", len(gs.Files)))
+		}
+		if gs.Ahead > 0 {
+			gitBadge += aheadStyle.Render(fmt.Sprintf(" ↑%d", gs.Ahead))
+		}
+	}
+
+	// Queue badge: ≡N prompts waiting to be sent
+	queueBadge := ""
+	if n := len(inst.QueuedPrompts()); n > 0 {
+		queueStyle := lipgloss.NewStyle().Foreground(ColorPurple)
+		if selected {
+			queueStyle = SessionStatusSelStyle
+		}
+		queueBadge = queueStyle.Render(fmt.Sprintf(" ≡%d", n))
+	}
+
+	// Build row: [baseIndent][selection][tree][status] [title] [tool] [yolo] [git] [queue]
 	// Format: " ├─ ● session-name tool" or "▶└─ ● session-name tool"
 	// Sub-sessions get extra indent: "   ├─◐ sub-session tool"
-	row := fmt.Sprintf("%s%s%s %s %s%s%s", baseIndent, selectionPrefix, treeStyle.Render(treeConnector), status, title, tool, yoloBadge)
+	row := fmt.Sprintf("%s%s%s %s %s%s%s%s%s", baseIndent, selectionPrefix, treeStyle.Render(treeConnector), status, title, tool, yoloBadge, gitBadge, queueBadge)
 	b.WriteString(row)
 	b.WriteString("\n")
 }
@@ -6056,7 +6965,9 @@ func (h *Home) renderSessionInfoCard(inst *session.Instance, width, height int)
 		statusColor = ColorGreen
 	case session.StatusWaiting:
 		statusColor = ColorYellow
-	case session.StatusError:
+	case session.StatusNeedsApproval:
+		statusColor = ColorOrange
+	case session.StatusBudgetExceeded, session.StatusError:
 		statusColor = ColorRed
 	default:
 		statusColor = ColorTextDim
@@ -6134,6 +7045,12 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	case session.StatusWaiting:
 		statusIcon = "◐"
 		statusColor = ColorYellow
+	case session.StatusNeedsApproval:
+		statusIcon = "◆"
+		statusColor = ColorOrange
+	case session.StatusBudgetExceeded:
+		statusIcon = "⊘"
+		statusColor = ColorRed
 	case session.StatusError:
 		statusIcon = "✕"
 		statusColor = ColorRed
@@ -6177,6 +7094,34 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	b.WriteString(groupBadge)
 	b.WriteString("\n")
 
+	if selected.Status == session.StatusNeedsApproval && selected.PendingApproval != nil {
+		askStyle := lipgloss.NewStyle().Foreground(ColorOrange).Bold(true)
+		b.WriteString(askStyle.Render(runewidth.Truncate("◆ Asks: "+strings.ReplaceAll(selected.PendingApproval.String(), "\n", " ↵ "), width-4, "…")))
+		b.WriteString("\n")
+		b.WriteString(DimStyle.Render("  a approve • A approve, don't ask again • N deny"))
+		b.WriteString("\n")
+	}
+
+	if selected.BudgetExceeded != "" {
+		overStyle := lipgloss.NewStyle().Foreground(ColorRed).Bold(true)
+		b.WriteString(overStyle.Render(runewidth.Truncate("⊘ Over budget: "+selected.BudgetExceeded, width-4, "…")))
+		b.WriteString("\n")
+		b.WriteString(DimStyle.Render("  O override budget"))
+		b.WriteString("\n")
+	} else if selected.BudgetWarning != "" {
+		warnStyle := lipgloss.NewStyle().Foreground(ColorYellow)
+		b.WriteString(warnStyle.Render(runewidth.Truncate("⚠ Budget: "+selected.BudgetWarning, width-4, "…")))
+		b.WriteString("\n")
+	}
+
+	if selected.BootstrapError != "" {
+		errStyle := lipgloss.NewStyle().Foreground(ColorRed)
+		b.WriteString(errStyle.Render("✕ Worktree bootstrap failed: " + selected.BootstrapError))
+		b.WriteString("\n")
+		b.WriteString(DimStyle.Render("  Fix it and restart the session (R) to retry"))
+		b.WriteString("\n")
+	}
+
 	// Claude-specific info (session ID and MCPs)
 	if selected.Tool == "claude" {
 		// Section divider for Claude info
@@ -6613,6 +7558,8 @@ func (h *Home) renderPreviewPane(width, height int) string {
 			// STATUS-BASED CHECK: Session ready when Running/Waiting/Idle
 			sessionReady := selected.Status == session.StatusRunning ||
 				selected.Status == session.StatusWaiting ||
+				selected.Status == session.StatusNeedsApproval ||
+				selected.Status == session.StatusBudgetExceeded ||
 				selected.Status == session.StatusIdle
 
 			if !sessionReady {
@@ -6883,7 +7830,7 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 		switch sess.Status {
 		case session.StatusRunning:
 			running++
-		case session.StatusWaiting:
+		case session.StatusWaiting, session.StatusNeedsApproval, session.StatusBudgetExceeded:
 			waiting++
 		case session.StatusIdle:
 			idle++
@@ -6941,6 +7888,10 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 				statusIcon, statusColor = "●", ColorGreen
 			case session.StatusWaiting:
 				statusIcon, statusColor = "◐", ColorYellow
+			case session.StatusNeedsApproval:
+				statusIcon, statusColor = "◆", ColorOrange
+			case session.StatusBudgetExceeded:
+				statusIcon, statusColor = "⊘", ColorRed
 			case session.StatusError:
 				statusIcon, statusColor = "✕", ColorRed
 			}
@@ -769,3 +769,111 @@ func TestHomeViewAllLayoutModes(t *testing.T) {
 		})
 	}
 }
+
+func TestHomeRepoFilter(t *testing.T) {
+	home := NewHome()
+	home.width = 100
+	home.height = 30
+
+	inRepo := session.NewInstance("api", "/src/repo/api")
+	inWorktree := session.NewInstance("feature", "/src/repo-feature")
+	elsewhere := session.NewInstance("notes", "/src/notes")
+	home.instancesMu.Lock()
+	home.instances = []*session.Instance{inRepo, inWorktree, elsewhere}
+	home.instancesMu.Unlock()
+	home.groupTree = session.NewGroupTree(home.instances)
+
+	home.Update(repoFilterLoadedMsg{filter: &repoFilter{
+		root:          "/src/repo",
+		paths:         []string{"/src/repo", "/src/repo-feature"},
+		otherProfiles: 2,
+	}})
+
+	var titles []string
+	for _, item := range home.flatItems {
+		if item.Type == session.ItemTypeSession {
+			titles = append(titles, item.Session.Title)
+		}
+	}
+	if strings.Join(titles, ",") != "api,feature" {
+		t.Errorf("repo filter shows %v, want sessions in the repo's worktrees only", titles)
+	}
+	if bar := home.renderFilterBar(); !strings.Contains(bar, "repo · 2 worktrees · +2 in other profiles") {
+		t.Errorf("filter bar should show the repo pill, got %q", bar)
+	}
+
+	// 0 clears all filters
+	home.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'0'}})
+	if home.repoFilter != nil {
+		t.Error("0 should clear the repo filter")
+	}
+}
+
+func TestHomeBroadcastSelection(t *testing.T) {
+	home := NewHome()
+	home.width = 100
+	home.height = 30
+
+	api := session.NewInstanceWithGroup("api", "/src/api", "work")
+	web := session.NewInstanceWithGroup("web", "/src/web", "work/frontend")
+	notes := session.NewInstanceWithGroup("notes", "/src/notes", "personal")
+	home.instancesMu.Lock()
+	home.instances = []*session.Instance{api, web, notes}
+	home.instanceByID = map[string]*session.Instance{api.ID: api, web.ID: web, notes.ID: notes}
+	home.instancesMu.Unlock()
+	home.groupTree = session.NewGroupTree(home.instances)
+	home.rebuildFlatItems()
+
+	cursorTo := func(match func(session.Item) bool) {
+		t.Helper()
+		for idx, item := range home.flatItems {
+			if match(item) {
+				home.cursor = idx
+				return
+			}
+		}
+		t.Fatal("item not found")
+	}
+	space := tea.KeyMsg{Type: tea.KeySpace}
+
+	// With nothing marked, B targets the group under the cursor (subgroups included)
+	cursorTo(func(item session.Item) bool { return item.Type == session.ItemTypeGroup && item.Path == "work" })
+	if targets, label := home.broadcastTargets(); len(targets) != 2 || label != "group work" {
+		t.Errorf("broadcastTargets() on a group = %d sessions, %q", len(targets), label)
+	}
+
+	// Space on a group marks all its sessions, and again unmarks them
+	home.Update(space)
+	if !home.selectedSessions[api.ID] || !home.selectedSessions[web.ID] || home.selectedSessions[notes.ID] {
+		t.Errorf("space on a group should mark its sessions, got %v", home.selectedSessions)
+	}
+	home.Update(space)
+	if len(home.selectedSessions) != 0 {
+		t.Errorf("space on a fully marked group should unmark it, got %v", home.selectedSessions)
+	}
+
+	// Marked sessions take precedence over the cursor
+	cursorTo(func(item session.Item) bool { return item.Session == notes })
+	home.Update(space)
+	cursorTo(func(item session.Item) bool { return item.Session == api })
+	home.Update(space)
+	targets, label := home.broadcastTargets()
+	if len(targets) != 2 || label != "2 selected sessions" {
+		t.Errorf("broadcastTargets() = %d sessions, %q; want the 2 marked ones", len(targets), label)
+	}
+
+	home.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'B'}})
+	if !home.broadcastDialog.IsVisible() || !strings.Contains(home.broadcastDialog.View(), "BROADCAST · 2 selected sessions") {
+		t.Error("B should open the broadcast dialog for the marked sessions")
+	}
+	home.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if home.broadcastDialog.IsVisible() {
+		t.Error("esc should close the broadcast dialog")
+	}
+
+	// Esc in the list clears the marks
+	home.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(home.selectedSessions) != 0 {
+		t.Error("esc should clear the broadcast selection")
+	}
+}
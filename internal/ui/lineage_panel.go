@@ -0,0 +1,345 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// lineageCostsMsg carries estimated costs for the sessions in the lineage panel
+type lineageCostsMsg struct {
+	rootID string
+	costs  map[string]float64
+}
+
+// lineageDiffMsg carries the diff of two sessions' last responses
+type lineageDiffMsg struct {
+	diff string
+	err  error
+}
+
+// LineagePanel shows the fork and sub-session genealogy of a conversation
+type LineagePanel struct {
+	visible bool
+	width   int
+	height  int
+
+	root   *session.LineageNode
+	nodes  []*session.LineageNode // root.Walk(), in display order
+	cursor int
+	costs  map[string]float64 // sessionID -> estimated cost (nil while loading)
+
+	// Diff view (last responses of the selected session and its parent)
+	showDiff     bool
+	diffLoading  bool
+	diffTitle    string
+	diff         string
+	diffErr      error
+	scrollOffset int
+}
+
+// NewLineagePanel creates a new lineage panel
+func NewLineagePanel() *LineagePanel {
+	return &LineagePanel{}
+}
+
+// Show opens the panel on the lineage tree of the session with the given ID
+func (p *LineagePanel) Show(instances []*session.Instance, sessionID string) {
+	p.root = session.BuildLineage(instances, sessionID)
+	if p.root == nil {
+		return
+	}
+	p.visible = true
+	p.nodes = p.root.Walk()
+	p.cursor = 0
+	for i, n := range p.nodes {
+		if n.Session.ID == sessionID {
+			p.cursor = i
+			break
+		}
+	}
+	p.costs = nil
+	p.showDiff = false
+}
+
+// Hide hides the lineage panel
+func (p *LineagePanel) Hide() {
+	p.visible = false
+	p.showDiff = false
+}
+
+// IsVisible returns whether the lineage panel is visible
+func (p *LineagePanel) IsVisible() bool {
+	return p.visible
+}
+
+// ShowingDiff returns whether the panel shows a response diff instead of the tree
+func (p *LineagePanel) ShowingDiff() bool {
+	return p.showDiff
+}
+
+// SetSize sets the dimensions of the panel
+func (p *LineagePanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// RootID returns the ID of the root session of the shown tree
+func (p *LineagePanel) RootID() string {
+	if p.root == nil {
+		return ""
+	}
+	return p.root.Session.ID
+}
+
+// Sessions returns every session in the shown tree
+func (p *LineagePanel) Sessions() []*session.Instance {
+	if p.root == nil {
+		return nil
+	}
+	return p.root.Sessions()
+}
+
+// Selected returns the node under the cursor
+func (p *LineagePanel) Selected() *session.LineageNode {
+	if p.cursor < 0 || p.cursor >= len(p.nodes) {
+		return nil
+	}
+	return p.nodes[p.cursor]
+}
+
+// SetCosts fills in estimated costs fetched in the background
+func (p *LineagePanel) SetCosts(msg lineageCostsMsg) {
+	if msg.rootID == p.RootID() {
+		p.costs = msg.costs
+	}
+}
+
+// ShowDiffLoading switches to the diff view while the diff is computed
+func (p *LineagePanel) ShowDiffLoading(title string) {
+	p.showDiff = true
+	p.diffLoading = true
+	p.diffTitle = title
+	p.diff = ""
+	p.diffErr = nil
+	p.scrollOffset = 0
+}
+
+// SetDiff fills in the computed response diff
+func (p *LineagePanel) SetDiff(msg lineageDiffMsg) {
+	if !p.showDiff {
+		return
+	}
+	p.diffLoading = false
+	p.diff = msg.diff
+	p.diffErr = msg.err
+}
+
+// Update handles navigation and closing; jump, diff and prune are handled by Home
+func (p *LineagePanel) Update(msg tea.KeyMsg) (*LineagePanel, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+
+	if p.showDiff {
+		switch msg.String() {
+		case "j", "down":
+			p.scrollOffset++
+		case "k", "up":
+			if p.scrollOffset > 0 {
+				p.scrollOffset--
+			}
+		case "ctrl+d", "pgdown", " ":
+			p.scrollOffset += p.contentHeight() / 2
+		case "ctrl+u", "pgup":
+			p.scrollOffset -= p.contentHeight() / 2
+			if p.scrollOffset < 0 {
+				p.scrollOffset = 0
+			}
+		case "esc", "q", "d":
+			p.showDiff = false
+		}
+		return p, nil
+	}
+
+	switch msg.String() {
+	case "j", "down":
+		if p.cursor < len(p.nodes)-1 {
+			p.cursor++
+		}
+	case "k", "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "g":
+		p.cursor = 0
+	case "G":
+		p.cursor = len(p.nodes) - 1
+	case "esc", "q", "T":
+		p.Hide()
+	}
+	return p, nil
+}
+
+// contentHeight returns the number of scrollable lines that fit in the panel
+func (p *LineagePanel) contentHeight() int {
+	// Border (2) + padding (2) + header (2) + footer (2)
+	height := p.height - 8
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// dialogWidth returns the inner width of the panel
+func (p *LineagePanel) dialogWidth() int {
+	width := p.width - 8
+	if width < 40 {
+		width = 40
+	}
+	return width
+}
+
+// treeLines renders two lines per session: the tree row and its last prompt
+func (p *LineagePanel) treeLines(width int) ([]string, int) {
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorBg).Background(ColorAccent).Bold(true)
+	titleStyle := lipgloss.NewStyle().Foreground(ColorText)
+	treeStyle := lipgloss.NewStyle().Foreground(ColorBorder)
+	costStyle := lipgloss.NewStyle().Foreground(ColorYellow)
+
+	var lines []string
+	selectedLine := 0
+	for i, n := range p.nodes {
+		s := n.Session
+		prefix := n.TreePrefix()
+
+		label := s.Title
+		switch {
+		case n.Relation == session.LineageFork && !s.ForkedAt.IsZero():
+			label += " · fork " + s.ForkedAt.Format("Jan 2 15:04")
+		case n.Relation != session.LineageRoot:
+			label += " · " + n.Relation
+		}
+		cost := ""
+		if p.costs == nil {
+			cost = "…"
+		} else if c := p.costs[s.ID]; c > 0 {
+			cost = fmt.Sprintf("$%.2f", c)
+		}
+		avail := width - runewidth.StringWidth(prefix) - 2 - runewidth.StringWidth(cost) - 1
+		if avail < 5 {
+			avail = 5
+		}
+		if runewidth.StringWidth(label) > avail {
+			label = runewidth.Truncate(label, avail, "…")
+		}
+		pad := width - runewidth.StringWidth(prefix) - 2 - runewidth.StringWidth(label) - runewidth.StringWidth(cost)
+		if pad < 1 {
+			pad = 1
+		}
+
+		row := treeStyle.Render(prefix) + StatusIndicator(string(s.Status)) + " "
+		if i == p.cursor {
+			selectedLine = len(lines)
+			row += selectedStyle.Render(label)
+		} else {
+			row += titleStyle.Render(label)
+		}
+		row += strings.Repeat(" ", pad) + costStyle.Render(cost)
+		lines = append(lines, row)
+
+		// Last prompt, indented under the node and continuing the tree lines
+		indent := strings.NewReplacer("├─ ", "│  ", "└─ ", "   ").Replace(prefix)
+		if len(n.Children) > 0 {
+			indent += "│ "
+		} else {
+			indent += "  "
+		}
+		prompt := strings.Join(strings.Fields(s.LatestPrompt), " ")
+		if prompt == "" {
+			prompt = "(no prompt yet)"
+		}
+		if avail := width - runewidth.StringWidth(indent) - 1; runewidth.StringWidth(prompt) > avail {
+			prompt = runewidth.Truncate(prompt, avail, "…")
+		}
+		lines = append(lines, treeStyle.Render(indent)+" "+DimStyle.Render(prompt))
+	}
+	return lines, selectedLine
+}
+
+// View renders the lineage panel
+func (p *LineagePanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	width := p.dialogWidth()
+	inner := width - 4 // DialogBoxStyle padding
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+
+	var content strings.Builder
+	var body []string
+	var footer string
+	visible := p.contentHeight()
+
+	if p.showDiff {
+		content.WriteString(titleStyle.Render("LAST RESPONSES · " + p.diffTitle))
+		content.WriteString("\n\n")
+		switch {
+		case p.diffLoading:
+			body = []string{DimStyle.Render("Loading responses...")}
+		case p.diffErr != nil:
+			body = []string{lipgloss.NewStyle().Foreground(ColorRed).Render(p.diffErr.Error())}
+		case p.diff == "":
+			body = []string{DimStyle.Render("Last responses are identical")}
+		default:
+			for _, line := range strings.Split(strings.TrimRight(p.diff, "\n"), "\n") {
+				body = append(body, renderDiffLine(line, inner))
+			}
+		}
+		maxScroll := len(body) - visible
+		if maxScroll < 0 {
+			maxScroll = 0
+		}
+		if p.scrollOffset > maxScroll {
+			p.scrollOffset = maxScroll
+		}
+		footer = "j/k scroll • esc back to tree"
+	} else {
+		content.WriteString(titleStyle.Render(fmt.Sprintf("CONVERSATION TREE · %d sessions", len(p.nodes))))
+		content.WriteString("\n\n")
+		var selectedLine int
+		body, selectedLine = p.treeLines(inner)
+		// Keep the selected node (and its prompt line) in view
+		if selectedLine < p.scrollOffset {
+			p.scrollOffset = selectedLine
+		}
+		if selectedLine+2 > p.scrollOffset+visible {
+			p.scrollOffset = selectedLine + 2 - visible
+		}
+		footer = "enter jump • d diff with parent • x prune branch • esc close"
+	}
+
+	end := p.scrollOffset + visible
+	if end > len(body) {
+		end = len(body)
+	}
+	start := p.scrollOffset
+	if start > end {
+		start = end
+	}
+	content.WriteString(strings.Join(body[start:end], "\n"))
+	content.WriteString("\n\n")
+	content.WriteString(footerStyle.Render(footer))
+
+	box := DialogBoxStyle.
+		Width(width).
+		Render(content.String())
+
+	return centerInScreen(box, p.width, p.height)
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLineagePanel_View(t *testing.T) {
+	root := session.NewInstance("main-task", "/tmp/project")
+	root.LatestPrompt = "refactor the parser"
+	fork := session.NewInstance("try-channels", "/tmp/project")
+	fork.ForkedFromID = root.ID
+	fork.LatestPrompt = "use channels instead"
+
+	panel := NewLineagePanel()
+	panel.SetSize(100, 30)
+	panel.Show([]*session.Instance{root, fork}, fork.ID)
+	if !panel.IsVisible() {
+		t.Fatal("panel should be visible after Show")
+	}
+	if sel := panel.Selected(); sel == nil || sel.Session != fork {
+		t.Fatal("cursor should start on the session the panel was opened for")
+	}
+
+	view := panel.View()
+	for _, want := range []string{"CONVERSATION TREE · 2 sessions", "main-task", "└─", "try-channels", "refactor the parser", "use channels instead", "…"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	panel.SetCosts(lineageCostsMsg{rootID: root.ID, costs: map[string]float64{fork.ID: 1.5}})
+	if !strings.Contains(panel.View(), "$1.50") {
+		t.Error("view should show the fork's cost")
+	}
+
+	panel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	if panel.Selected().Session != root {
+		t.Error("k should move to the root")
+	}
+
+	panel.ShowDiffLoading("main-task → try-channels")
+	panel.SetDiff(lineageDiffMsg{diff: "--- main-task\n+++ try-channels\n-use a mutex\n+use a channel\n"})
+	if view := panel.View(); !strings.Contains(view, "+use a channel") || !strings.Contains(view, "LAST RESPONSES") {
+		t.Error("diff view should show the response diff")
+	}
+	panel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !panel.IsVisible() || panel.ShowingDiff() {
+		t.Error("esc in the diff view should return to the tree")
+	}
+
+	panel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if panel.IsVisible() {
+		t.Error("esc should close the panel")
+	}
+}
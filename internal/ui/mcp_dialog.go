@@ -2,6 +2,7 @@ package ui
 
 import (
 	"log"
+	"strings"
 
 	"github.com/asheshgoplani/agent-deck/internal/session"
 	tea "github.com/charmbracelet/bubbletea"
@@ -34,16 +35,18 @@ type MCPItem struct {
 	Transport      string // "stdio", "http", or "sse"
 	HTTPStatus     string // For HTTP MCPs: "running", "stopped", "external", etc.
 	HasServerCfg   bool   // True if HTTP MCP has [mcps.X.server] config
+	Unhealthy      bool   // True if the latest health probe failed (hung or broken MCP)
+	HealthDetail   string // Probe error for unhealthy MCPs
 }
 
-// MCPDialog handles MCP management for Claude and Gemini sessions
+// MCPDialog handles MCP management for Claude, Gemini, Codex and OpenCode sessions
 type MCPDialog struct {
 	visible     bool
 	width       int
 	height      int
 	projectPath string
 	sessionID   string // ID of the session being managed (for restart)
-	tool        string // "claude" or "gemini"
+	tool        string // "claude", "gemini", "codex" or "opencode"
 
 	// Current scope and column
 	scope  MCPScope
@@ -99,6 +102,7 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	// Build items lookup for descriptions, transport, and pool status
 	pool := session.GetGlobalPool()
 	httpPool := session.GetGlobalHTTPPool()
+	health := session.GetMCPHealth()
 	itemsMap := make(map[string]MCPItem)
 	for _, name := range allNames {
 		def, ok := availableMCPs[name]
@@ -135,7 +139,7 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 			isPooled = pool != nil && pool.ShouldPool(name) && pool.IsRunning(name)
 		}
 
-		itemsMap[name] = MCPItem{
+		item := MCPItem{
 			Name:         name,
 			Description:  desc,
 			IsPooled:     isPooled,
@@ -143,6 +147,11 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 			HTTPStatus:   httpStatus,
 			HasServerCfg: hasServerCfg,
 		}
+		if probe := health[name]; probe != nil && !probe.Healthy {
+			item.Unhealthy = true
+			item.HealthDetail = probe.Error
+		}
+		itemsMap[name] = item
 	}
 
 	// Track which MCPs are in the config.toml pool
@@ -159,34 +168,39 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	m.userAttached = nil
 	m.userAvailable = nil
 
-	if tool == "gemini" {
-		// Gemini: Only global MCPs from settings.json
-		mcpInfo := session.GetGeminiMCPInfo(projectPath)
-		globalAttachedNames := make(map[string]bool)
-		for _, name := range mcpInfo.Global {
-			globalAttachedNames[name] = true
+	if toolScope := session.ToolMCPScope(tool); toolScope != "" {
+		// Gemini/Codex/OpenCode: a single scope in the tool's own config file
+		attachedNames := make(map[string]bool)
+		for _, name := range session.GetToolMCPNames(tool, projectPath) {
+			attachedNames[name] = true
 		}
 
-		// Build attached/available lists for GLOBAL only
+		var attached, available []MCPItem
 		for _, name := range allNames {
 			item := itemsMap[name]
-			if globalAttachedNames[name] {
-				m.globalAttached = append(m.globalAttached, item)
+			if attachedNames[name] {
+				attached = append(attached, item)
 			} else {
-				m.globalAvailable = append(m.globalAvailable, item)
+				available = append(available, item)
 			}
 		}
 
-		// Add orphan GLOBAL MCPs (attached in settings.json but not in config.toml pool)
-		for name := range globalAttachedNames {
+		// Add orphan MCPs (attached in the tool's config but not in config.toml pool)
+		for name := range attachedNames {
 			if !poolNames[name] {
-				m.globalAttached = append(m.globalAttached, MCPItem{
+				attached = append(attached, MCPItem{
 					Name:        name,
 					Description: "(not in config.toml)",
 					IsOrphan:    true,
 				})
 			}
 		}
+
+		if toolScope == "local" {
+			m.localAttached, m.localAvailable = attached, available
+		} else {
+			m.globalAttached, m.globalAvailable = attached, available
+		}
 	} else {
 		// Claude: Load LOCAL attached from .mcp.json
 		localAttachedNames := make(map[string]bool)
@@ -279,8 +293,8 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 
 	m.visible = true
 	m.projectPath = projectPath
-	// Gemini only has global scope, Claude starts with local
-	if tool == "gemini" {
+	// Gemini and Codex only have global scope, Claude and OpenCode start with local
+	if session.ToolMCPScope(tool) == "global" {
 		m.scope = MCPScopeGlobal
 	} else {
 		m.scope = MCPScopeLocal
@@ -434,15 +448,19 @@ func (m *MCPDialog) Apply() error {
 	log.Printf("[MCP-DEBUG] Apply() called - tool=%q, localChanged=%v, globalChanged=%v, userChanged=%v, projectPath=%q",
 		m.tool, m.localChanged, m.globalChanged, m.userChanged, m.projectPath)
 
-	if m.tool == "gemini" {
-		// Gemini: Only global scope, write to settings.json
-		if m.globalChanged {
-			enabledNames := make([]string, len(m.globalAttached))
-			for i, item := range m.globalAttached {
+	if toolScope := session.ToolMCPScope(m.tool); toolScope != "" {
+		// Gemini/Codex/OpenCode: single scope, write to the tool's own config
+		attached, changed := m.globalAttached, m.globalChanged
+		if toolScope == "local" {
+			attached, changed = m.localAttached, m.localChanged
+		}
+		if changed {
+			enabledNames := make([]string, len(attached))
+			for i, item := range attached {
 				enabledNames[i] = item.Name
 			}
 
-			if err := session.WriteGeminiMCPSettings(enabledNames); err != nil {
+			if err := session.WriteToolMCPs(m.tool, m.projectPath, enabledNames); err != nil {
 				m.err = err
 				return err
 			}
@@ -523,8 +541,8 @@ func (m *MCPDialog) Update(msg tea.KeyMsg) (*MCPDialog, tea.Cmd) {
 	switch msg.String() {
 	case "tab":
 		// Switch scope: LOCAL -> GLOBAL -> USER -> LOCAL (Claude only)
-		// Gemini only has global scope, so Tab does nothing
-		if m.tool != "gemini" {
+		// Other tools only have one scope, so Tab does nothing
+		if session.ToolMCPScope(m.tool) == "" {
 			switch m.scope {
 			case MCPScopeLocal:
 				m.scope = MCPScopeGlobal
@@ -568,16 +586,21 @@ func (m *MCPDialog) View() string {
 
 	// Title varies by tool
 	title := "MCP Manager"
-	if m.tool == "gemini" {
+	switch m.tool {
+	case "gemini":
 		title = "MCP Manager (Gemini)"
+	case "codex":
+		title = "MCP Manager (Codex)"
+	case "opencode":
+		title = "MCP Manager (OpenCode)"
 	}
 
-	// Scope tabs - Gemini only has global
+	// Scope tabs - other tools only have one scope
 	var tabs string
-	if m.tool == "gemini" {
-		// Gemini: Only show GLOBAL (centered)
-		globalTab := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent).Render("[GLOBAL]")
-		tabs = "──────────────── " + globalTab + " ────────────────"
+	if toolScope := session.ToolMCPScope(m.tool); toolScope != "" {
+		// Only show the tool's scope (centered)
+		scopeTab := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent).Render("[" + strings.ToUpper(toolScope) + "]")
+		tabs = "──────────────── " + scopeTab + " ────────────────"
 	} else {
 		// Claude: Show LOCAL/GLOBAL/USER tabs
 		localTab := "LOCAL"
@@ -638,9 +661,14 @@ func (m *MCPDialog) View() string {
 
 	// Scope description
 	var scopeDesc string
-	if m.tool == "gemini" {
+	switch m.tool {
+	case "gemini":
 		scopeDesc = DimStyle.Render("Writes to: ~/.gemini/settings.json")
-	} else {
+	case "codex":
+		scopeDesc = DimStyle.Render("Writes to: ~/.codex/config.toml (all Codex sessions)")
+	case "opencode":
+		scopeDesc = DimStyle.Render("Writes to: opencode.json (this project only)")
+	default:
 		switch m.scope {
 		case MCPScopeLocal:
 			scopeDesc = DimStyle.Render("Writes to: .mcp.json (this project only)")
@@ -660,7 +688,7 @@ func (m *MCPDialog) View() string {
 	// Hint with consistent styling
 	hintStyle := lipgloss.NewStyle().Foreground(ColorComment)
 	var hint string
-	if m.tool == "gemini" {
+	if session.ToolMCPScope(m.tool) != "" {
 		hint = hintStyle.Render("←→ column │ Space move │ Enter apply │ Esc cancel")
 	} else {
 		hint = hintStyle.Render("Tab scope │ ←→ column │ Space move │ Enter apply │ Esc cancel")
@@ -689,7 +717,21 @@ func (m *MCPDialog) View() string {
 
 	// Transport legend
 	transportLegend := lipgloss.NewStyle().Foreground(ColorTextDim).Render(
-		"[S]=stdio  [H]=http  [E]=sse  ●=running  ○=external  ✗=stopped")
+		"[S]=stdio  [H]=http  [E]=sse  ●=running  ○=external  ✗=stopped  !=unresponsive")
+
+	// Probe error for the selected MCP
+	var healthText string
+	selected := attached
+	selectedIdx := attachedIdx
+	if m.column == MCPColumnAvailable {
+		selected = available
+		selectedIdx = availableIdx
+	}
+	if selectedIdx >= 0 && selectedIdx < len(selected) && selected[selectedIdx].Unhealthy {
+		item := selected[selectedIdx]
+		healthText = lipgloss.NewStyle().Foreground(ColorRed).Render(
+			"! " + item.Name + " is not responding: " + item.HealthDetail)
+	}
 
 	// Responsive dialog width
 	dialogWidth := 64
@@ -734,6 +776,9 @@ func (m *MCPDialog) View() string {
 	if errText != "" {
 		parts = append(parts, "", errText)
 	}
+	if healthText != "" {
+		parts = append(parts, "", healthText)
+	}
 	if orphanLegend != "" {
 		parts = append(parts, orphanLegend)
 	}
@@ -829,6 +874,11 @@ func (m *MCPDialog) renderColumn(title string, items []MCPItem, selectedIdx int,
 				}
 			}
 
+			// A failed health probe overrides the status dot
+			if item.Unhealthy {
+				prefix = prefix[:3] + "!"
+			}
+
 			name := prefix + " " + item.Name
 
 			// Add orphan indicator for MCPs not in config.toml
@@ -847,6 +897,12 @@ func (m *MCPDialog) renderColumn(title string, items []MCPItem, selectedIdx int,
 					Bold(true).
 					Width(colWidth).
 					Render(" > " + name)
+			} else if item.Unhealthy {
+				// Unresponsive MCPs shown in red
+				line = lipgloss.NewStyle().
+					Foreground(ColorRed).
+					Width(colWidth).
+					Render("   " + name)
 			} else if item.IsOrphan {
 				// Orphan MCPs shown in yellow/warning color
 				line = lipgloss.NewStyle().
@@ -132,12 +132,10 @@ func (d *NewDialog) ShowInGroup(groupPath, groupName, defaultPath string) {
 			d.pathInput.SetValue(cwd)
 		}
 	}
-	// Initialize Gemini YOLO mode and Claude options from global config
-	d.geminiYoloMode = false
-	if userConfig, err := session.LoadUserConfig(); err == nil && userConfig != nil {
-		d.geminiYoloMode = userConfig.Gemini.YoloMode
-		d.claudeOptions.SetDefaults(userConfig)
-	}
+	// Initialize Gemini YOLO mode and Claude options from config (repo config first)
+	config := session.LoadEffectiveConfig(d.pathInput.Value())
+	d.geminiYoloMode = config.Gemini.YoloMode
+	d.claudeOptions.SetDefaults(&config.UserConfig)
 }
 
 // SetDefaultTool sets the pre-selected command based on tool name
@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// pipelineRunsLoadedMsg carries the profile's pipeline runs for the panel
+type pipelineRunsLoadedMsg struct {
+	runs []*session.PipelineRun
+	err  error
+}
+
+// maxPipelinePanelRuns caps how many runs the panel lists
+const maxPipelinePanelRuns = 8
+
+// PipelinePanel shows the progress of pipeline runs; Home reloads the runs
+// on every tick while it is open
+type PipelinePanel struct {
+	visible bool
+	width   int
+	height  int
+
+	loading bool
+	err     error
+	runs    []*session.PipelineRun // Newest first
+	titles  map[string]string      // Session ID -> title
+	cursor  int
+}
+
+// NewPipelinePanel creates a new pipeline panel
+func NewPipelinePanel() *PipelinePanel {
+	return &PipelinePanel{}
+}
+
+// Show opens the panel while the runs load
+func (p *PipelinePanel) Show() {
+	p.visible = true
+	p.loading = true
+	p.err = nil
+	p.cursor = 0
+}
+
+// Hide hides the pipeline panel
+func (p *PipelinePanel) Hide() {
+	p.visible = false
+}
+
+// IsVisible returns whether the pipeline panel is visible
+func (p *PipelinePanel) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize sets the dimensions of the panel
+func (p *PipelinePanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetRuns fills in the loaded runs; titles names the sessions steps ran in
+func (p *PipelinePanel) SetRuns(msg pipelineRunsLoadedMsg, titles map[string]string) {
+	p.loading = false
+	p.err = msg.err
+	p.runs = msg.runs
+	if len(p.runs) > maxPipelinePanelRuns {
+		p.runs = p.runs[:maxPipelinePanelRuns]
+	}
+	p.titles = titles
+	if p.cursor >= len(p.runs) {
+		p.cursor = 0
+	}
+}
+
+// Selected returns the run under the cursor
+func (p *PipelinePanel) Selected() *session.PipelineRun {
+	if p.cursor < 0 || p.cursor >= len(p.runs) {
+		return nil
+	}
+	return p.runs[p.cursor]
+}
+
+// CurrentSessionID returns the session of the selected run's current step:
+// the running or failed one, else the last one that ran
+func (p *PipelinePanel) CurrentSessionID() string {
+	run := p.Selected()
+	if run == nil {
+		return ""
+	}
+	id := ""
+	for _, s := range run.Steps {
+		if s.SessionID == "" {
+			continue
+		}
+		id = s.SessionID
+		if s.Status == session.PipelineRunning || s.Status == session.PipelineFailed {
+			break
+		}
+	}
+	return id
+}
+
+// Update handles navigation and closing; jumping is handled by Home
+func (p *PipelinePanel) Update(msg tea.KeyMsg) (*PipelinePanel, tea.Cmd) {
+	if !p.visible {
+		return p, nil
+	}
+	switch msg.String() {
+	case "j", "down":
+		if p.cursor < len(p.runs)-1 {
+			p.cursor++
+		}
+	case "k", "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "esc", "q", "P":
+		p.Hide()
+	}
+	return p, nil
+}
+
+// pipelineStatusStyle colors a run or step status
+func pipelineStatusStyle(status string) lipgloss.Style {
+	switch status {
+	case session.PipelineDone:
+		return lipgloss.NewStyle().Foreground(ColorGreen)
+	case session.PipelineRunning:
+		return lipgloss.NewStyle().Foreground(ColorYellow)
+	case session.PipelineFailed, session.PipelineInterrupted:
+		return lipgloss.NewStyle().Foreground(ColorRed)
+	default:
+		return DimStyle
+	}
+}
+
+// pipelineStepSymbol returns the progress symbol of a step status
+func pipelineStepSymbol(status string) string {
+	switch status {
+	case session.PipelineDone:
+		return "✓"
+	case session.PipelineRunning:
+		return "●"
+	case session.PipelineSkipped:
+		return "–"
+	case session.PipelineFailed:
+		return "✗"
+	default:
+		return "○"
+	}
+}
+
+// dialogWidth returns the inner width of the panel
+func (p *PipelinePanel) dialogWidth() int {
+	width := p.width - 8
+	if width > 110 {
+		width = 110
+	}
+	if width < 40 {
+		width = 40
+	}
+	return width
+}
+
+// runLines renders one line per run
+func (p *PipelinePanel) runLines(width int) []string {
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorBg).Background(ColorAccent).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText)
+
+	var lines []string
+	for i, run := range p.runs {
+		status := run.DisplayStatus()
+		finished := 0
+		for _, s := range run.Steps {
+			if s.Status == session.PipelineDone || s.Status == session.PipelineSkipped {
+				finished++
+			}
+		}
+		id := runewidth.Truncate(run.ID, width-34, "…")
+		row := fmt.Sprintf("%-*s", runewidth.StringWidth(id), id)
+		if i == p.cursor {
+			row = selectedStyle.Render(row)
+		} else {
+			row = textStyle.Render(row)
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s  %s  %s",
+			row,
+			pipelineStatusStyle(status).Render(fmt.Sprintf("%-11s", status)),
+			DimStyle.Render(fmt.Sprintf("%d/%d", finished, len(run.Steps))),
+			DimStyle.Render(run.StartedAt.Format("Jan 2 15:04"))))
+	}
+	return lines
+}
+
+// stepLines renders the steps of the selected run
+func (p *PipelinePanel) stepLines(width int) []string {
+	run := p.Selected()
+	if run == nil {
+		return nil
+	}
+	nameStyle := lipgloss.NewStyle().Foreground(ColorText).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(ColorRed)
+
+	var lines []string
+	for _, s := range run.Steps {
+		style := pipelineStatusStyle(s.Status)
+		line := style.Render(pipelineStepSymbol(s.Status)) + " " + nameStyle.Render(s.Name)
+		if title := p.titles[s.SessionID]; title != "" {
+			line += DimStyle.Render(" → " + title)
+		}
+		switch s.Status {
+		case session.PipelineRunning:
+			line += style.Render(fmt.Sprintf("  %s", time.Since(s.StartedAt).Round(time.Second)))
+		case session.PipelineDone:
+			line += DimStyle.Render(fmt.Sprintf("  %s", s.FinishedAt.Sub(s.StartedAt).Round(time.Second)))
+		}
+		lines = append(lines, line)
+
+		detail := ""
+		if s.Error != "" {
+			detail = errStyle.Render("  " + runewidth.Truncate(s.Error, width-4, "…"))
+		} else if s.Output != "" {
+			detail = DimStyle.Render("  " + runewidth.Truncate(strings.Join(strings.Fields(s.Output), " "), width-4, "…"))
+		}
+		if detail != "" {
+			lines = append(lines, detail)
+		}
+	}
+	return lines
+}
+
+// View renders the pipeline panel
+func (p *PipelinePanel) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	width := p.dialogWidth()
+	inner := width - 4 // DialogBoxStyle padding
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+	errStyle := lipgloss.NewStyle().Foreground(ColorRed)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("PIPELINES"))
+	content.WriteString("\n\n")
+
+	switch {
+	case p.loading:
+		content.WriteString(DimStyle.Render("Loading pipeline runs..."))
+	case p.err != nil:
+		content.WriteString(errStyle.Render(p.err.Error()))
+	case len(p.runs) == 0:
+		content.WriteString(DimStyle.Render("No pipeline runs yet."))
+		content.WriteString("\n")
+		content.WriteString(DimStyle.Render("Start one with: agent-deck pipeline run <file.toml>"))
+	default:
+		content.WriteString(strings.Join(p.runLines(inner), "\n"))
+		content.WriteString("\n\n")
+		content.WriteString(DimStyle.Render(strings.Repeat("─", inner)))
+		content.WriteString("\n")
+		content.WriteString(strings.Join(p.stepLines(inner), "\n"))
+		if run := p.Selected(); run != nil && (run.Status == session.PipelineFailed || run.Interrupted()) {
+			content.WriteString("\n\n")
+			if run.Error != "" {
+				content.WriteString(errStyle.Render(runewidth.Truncate(run.Error, inner, "…")))
+				content.WriteString("\n")
+			}
+			content.WriteString(DimStyle.Render("Continue with: agent-deck pipeline resume " + run.ID))
+		}
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(footerStyle.Render("j/k select run • enter go to step session • esc close"))
+
+	box := DialogBoxStyle.
+		Width(width).
+		Render(content.String())
+
+	return centerInScreen(box, p.width, p.height)
+}
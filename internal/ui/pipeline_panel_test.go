@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPipelinePanel_View(t *testing.T) {
+	panel := NewPipelinePanel()
+	panel.SetSize(120, 40)
+	panel.Show()
+	if !strings.Contains(panel.View(), "Loading pipeline runs...") {
+		t.Error("view should show loading state before runs arrive")
+	}
+
+	now := time.Now()
+	running := &session.PipelineRun{
+		ID: "review-2", Status: session.PipelineRunning, PID: os.Getpid(), StartedAt: now,
+		Steps: []session.PipelineStepState{
+			{Name: "plan", Status: session.PipelineDone, SessionID: "s1", Output: "1. retry on 503", StartedAt: now, FinishedAt: now},
+			{Name: "implement", Status: session.PipelineRunning, SessionID: "s2", StartedAt: now},
+			{Name: "fix", Status: session.PipelinePending},
+		},
+	}
+	failed := &session.PipelineRun{
+		ID: "review-1", Status: session.PipelineFailed, Error: "step 'plan': not finished", StartedAt: now.Add(-time.Hour),
+		Steps: []session.PipelineStepState{{Name: "plan", Status: session.PipelineFailed, SessionID: "s1", Error: "not finished"}},
+	}
+	panel.SetRuns(pipelineRunsLoadedMsg{runs: []*session.PipelineRun{running, failed}}, map[string]string{"s1": "planner", "s2": "review-implement"})
+
+	view := panel.View()
+	for _, want := range []string{"PIPELINES", "review-2", "review-1", "1/3", "plan → planner", "retry on 503", "implement → review-implement"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+	if got := panel.CurrentSessionID(); got != "s2" {
+		t.Errorf("CurrentSessionID() = %q, want the running step's session", got)
+	}
+
+	panel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if view := panel.View(); !strings.Contains(view, "pipeline resume review-1") {
+		t.Error("a failed run should show how to resume it")
+	}
+	panel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if panel.IsVisible() {
+		t.Error("esc should close the panel")
+	}
+}
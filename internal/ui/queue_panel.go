@@ -0,0 +1,259 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// QueuePanel shows and edits a session's prompt queue
+type QueuePanel struct {
+	visible bool
+	width   int
+	height  int
+
+	inst   *session.Instance
+	cursor int
+
+	// Add/edit mode
+	editing bool
+	editID  string // Empty when adding a new prompt
+	input   textinput.Model
+}
+
+// NewQueuePanel creates a new prompt queue panel
+func NewQueuePanel() *QueuePanel {
+	input := textinput.New()
+	input.Placeholder = "prompt to send when the agent is ready"
+	input.CharLimit = 4000
+	input.Width = 60
+	return &QueuePanel{input: input}
+}
+
+// Show opens the panel for a session
+func (p *QueuePanel) Show(inst *session.Instance) {
+	p.visible = true
+	p.inst = inst
+	p.cursor = 0
+	p.stopEditing()
+}
+
+// Hide hides the queue panel
+func (p *QueuePanel) Hide() {
+	p.visible = false
+	p.stopEditing()
+}
+
+// IsVisible returns whether the queue panel is visible
+func (p *QueuePanel) IsVisible() bool {
+	return p.visible
+}
+
+// IsEditing returns whether a prompt is being added or edited
+func (p *QueuePanel) IsEditing() bool {
+	return p.editing
+}
+
+// SetSize sets the dimensions of the panel
+func (p *QueuePanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.input.Width = p.dialogWidth() - 10
+}
+
+// SessionID returns the ID of the session whose queue is shown
+func (p *QueuePanel) SessionID() string {
+	if p.inst == nil {
+		return ""
+	}
+	return p.inst.ID
+}
+
+// SetInstance points the panel at a reloaded copy of its session
+func (p *QueuePanel) SetInstance(inst *session.Instance) {
+	p.inst = inst
+}
+
+// startEditing opens the input for a new prompt (id empty) or an existing one
+func (p *QueuePanel) startEditing(id, text string) tea.Cmd {
+	p.editing = true
+	p.editID = id
+	p.input.SetValue(text)
+	p.input.CursorEnd()
+	return p.input.Focus()
+}
+
+// stopEditing closes the input
+func (p *QueuePanel) stopEditing() {
+	p.editing = false
+	p.editID = ""
+	p.input.SetValue("")
+	p.input.Blur()
+}
+
+// Update handles keys. changed reports that the queue was modified and
+// needs saving.
+func (p *QueuePanel) Update(msg tea.KeyMsg) (panel *QueuePanel, cmd tea.Cmd, changed bool) {
+	if !p.visible || p.inst == nil {
+		return p, nil, false
+	}
+
+	if p.editing {
+		switch msg.String() {
+		case "enter":
+			text := strings.TrimSpace(p.input.Value())
+			if text != "" {
+				if p.editID == "" {
+					p.inst.EnqueuePrompt(text)
+					p.cursor = len(p.inst.QueuedPrompts()) - 1
+					changed = true
+				} else {
+					// The prompt may have been delivered while it was edited
+					changed = p.inst.UpdateQueuedPrompt(p.editID, text)
+				}
+			}
+			p.stopEditing()
+		case "esc":
+			p.stopEditing()
+		default:
+			p.input, cmd = p.input.Update(msg)
+		}
+		return p, cmd, changed
+	}
+
+	queue := p.inst.QueuedPrompts()
+	var selected *session.QueuedPrompt
+	if p.cursor >= 0 && p.cursor < len(queue) {
+		selected = &queue[p.cursor]
+	}
+
+	switch msg.String() {
+	case "j", "down":
+		if p.cursor < len(queue)-1 {
+			p.cursor++
+		}
+	case "k", "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "a", "n":
+		cmd = p.startEditing("", "")
+	case "e", "enter":
+		if selected != nil {
+			cmd = p.startEditing(selected.ID, selected.Text)
+		}
+	case "d", "x", "delete":
+		if selected != nil {
+			changed = p.inst.RemoveQueuedPrompt(selected.ID)
+		}
+	case "K", "shift+up":
+		if selected != nil && p.inst.MoveQueuedPrompt(selected.ID, -1) {
+			p.cursor--
+			changed = true
+		}
+	case "J", "shift+down":
+		if selected != nil && p.inst.MoveQueuedPrompt(selected.ID, 1) {
+			p.cursor++
+			changed = true
+		}
+	case "C":
+		changed = p.inst.ClearPromptQueue() > 0
+	case "esc", "q", "Q":
+		p.Hide()
+	}
+	return p, cmd, changed
+}
+
+// dialogWidth returns the inner width of the panel
+func (p *QueuePanel) dialogWidth() int {
+	width := p.width - 8
+	if width > 100 {
+		width = 100
+	}
+	if width < 40 {
+		width = 40
+	}
+	return width
+}
+
+// View renders the queue panel
+func (p *QueuePanel) View() string {
+	if !p.visible || p.inst == nil {
+		return ""
+	}
+
+	width := p.dialogWidth()
+	inner := width - 4 // DialogBoxStyle padding
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+	footerStyle := lipgloss.NewStyle().Foreground(ColorComment).Italic(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorBg).Background(ColorAccent).Bold(true)
+	numberStyle := lipgloss.NewStyle().Foreground(ColorYellow)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText)
+
+	queue := p.inst.QueuedPrompts()
+	if p.cursor >= len(queue) {
+		p.cursor = len(queue) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(runewidth.Truncate(fmt.Sprintf("PROMPT QUEUE · %s", p.inst.Title), inner, "…")))
+	content.WriteString("\n")
+	content.WriteString(DimStyle.Render("Sent one at a time, each once the agent is waiting again"))
+	content.WriteString("\n\n")
+
+	if len(queue) == 0 {
+		content.WriteString(DimStyle.Render("No prompts queued."))
+	}
+	for i, prompt := range queue {
+		number := fmt.Sprintf("%2d. ", i+1)
+		when := prompt.QueuedAt.Format("15:04")
+		text := strings.Join(strings.Fields(prompt.Text), " ")
+		avail := inner - runewidth.StringWidth(number+when) - 2
+		if avail < 5 {
+			avail = 5
+		}
+		text = runewidth.Truncate(text, avail, "…")
+
+		row := numberStyle.Render(number) + DimStyle.Render(when) + "  "
+		if i == p.cursor && !p.editing {
+			row += selectedStyle.Render(text)
+		} else {
+			row += textStyle.Render(text)
+		}
+		if i > 0 {
+			content.WriteString("\n")
+		}
+		content.WriteString(row)
+	}
+
+	var footer string
+	if p.editing {
+		label := "Add prompt:"
+		if p.editID != "" {
+			label = "Edit prompt:"
+		}
+		content.WriteString("\n\n")
+		content.WriteString(textStyle.Render(label))
+		content.WriteString("\n")
+		content.WriteString(p.input.View())
+		footer = "enter save • esc cancel"
+	} else {
+		footer = "a add • e edit • d delete • K/J move • C clear • esc close"
+	}
+	content.WriteString("\n\n")
+	content.WriteString(footerStyle.Render(footer))
+
+	box := DialogBoxStyle.
+		Width(width).
+		Render(content.String())
+
+	return centerInScreen(box, p.width, p.height)
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestQueuePanel_Editing(t *testing.T) {
+	inst := &session.Instance{ID: "queue-panel", Title: "api-work"}
+	inst.EnqueuePrompt("write the tests")
+	inst.EnqueuePrompt("update the docs")
+
+	panel := NewQueuePanel()
+	panel.SetSize(100, 30)
+	panel.Show(inst)
+	view := panel.View()
+	for _, want := range []string{"PROMPT QUEUE · api-work", "1.", "write the tests", "update the docs"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	// Move the second prompt to the front
+	panel.Update(key("j"))
+	if _, _, changed := panel.Update(key("K")); !changed {
+		t.Error("K should report a change")
+	}
+	if q := inst.QueuedPrompts(); q[0].Text != "update the docs" {
+		t.Errorf("queue head = %q, want the moved prompt", q[0].Text)
+	}
+
+	// Add a prompt
+	panel.Update(key("a"))
+	if !panel.IsEditing() {
+		t.Fatal("a should open the input")
+	}
+	panel.Update(key("run lint"))
+	if _, _, changed := panel.Update(tea.KeyMsg{Type: tea.KeyEnter}); !changed {
+		t.Error("saving a new prompt should report a change")
+	}
+	if q := inst.QueuedPrompts(); len(q) != 3 || q[2].Text != "run lint" {
+		t.Errorf("queue = %+v, want the new prompt last", q)
+	}
+
+	// Delete the selected (new) prompt
+	panel.Update(key("d"))
+	if q := inst.QueuedPrompts(); len(q) != 2 {
+		t.Errorf("queue has %d prompts after delete, want 2", len(q))
+	}
+
+	panel.Update(key("C"))
+	if len(inst.QueuedPrompts()) != 0 || !strings.Contains(panel.View(), "No prompts queued") {
+		t.Error("C should clear the queue")
+	}
+	panel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if panel.IsVisible() {
+		t.Error("esc should close the panel")
+	}
+}
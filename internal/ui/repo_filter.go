@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// repoFilter limits the session list to one repository's worktrees
+type repoFilter struct {
+	root          string   // Main worktree path
+	paths         []string // Every worktree of the repo (normalized)
+	otherProfiles int      // Sessions in the repo that belong to other profiles
+}
+
+// repoFilterLoadedMsg carries the worktrees of the repo to filter by
+type repoFilterLoadedMsg struct {
+	filter *repoFilter
+	err    error
+}
+
+// loadRepoFilter collects the worktrees of inst's repository and counts the
+// sessions other profiles have in them
+func (h *Home) loadRepoFilter(inst *session.Instance) tea.Cmd {
+	projectPath := inst.ProjectPath
+	title := inst.Title
+	profile := h.profile
+	return func() tea.Msg {
+		root, paths, err := session.RepoWorktreePaths(projectPath)
+		if err != nil {
+			return repoFilterLoadedMsg{err: fmt.Errorf("'%s' is not in a git repository", title)}
+		}
+		filter := &repoFilter{root: root, paths: paths}
+		if all, err := session.LoadAllProfileSessions(); err == nil {
+			for _, ps := range all {
+				if ps.Profile != profile && session.WorktreeForPath(ps.Instance.ProjectPath, paths) >= 0 {
+					filter.otherProfiles++
+				}
+			}
+		}
+		return repoFilterLoadedMsg{filter: filter}
+	}
+}
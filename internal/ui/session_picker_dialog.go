@@ -185,6 +185,10 @@ func statusIndicator(status session.Status) string {
 		return lipgloss.NewStyle().Foreground(ColorGreen).Render("●")
 	case session.StatusWaiting:
 		return lipgloss.NewStyle().Foreground(ColorYellow).Render("◐")
+	case session.StatusNeedsApproval:
+		return lipgloss.NewStyle().Foreground(ColorOrange).Render("◆")
+	case session.StatusBudgetExceeded:
+		return lipgloss.NewStyle().Foreground(ColorRed).Render("⊘")
 	case session.StatusIdle:
 		return lipgloss.NewStyle().Foreground(ColorTextDim).Render("○")
 	default:
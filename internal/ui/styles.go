@@ -227,11 +227,13 @@ var (
 	TreeConnectorSelStyle lipgloss.Style
 
 	// Session status indicator styles
-	SessionStatusRunning  lipgloss.Style
-	SessionStatusWaiting  lipgloss.Style
-	SessionStatusIdle     lipgloss.Style
-	SessionStatusError    lipgloss.Style
-	SessionStatusSelStyle lipgloss.Style
+	SessionStatusRunning        lipgloss.Style
+	SessionStatusWaiting        lipgloss.Style
+	SessionStatusNeedsApproval  lipgloss.Style
+	SessionStatusBudgetExceeded lipgloss.Style
+	SessionStatusIdle           lipgloss.Style
+	SessionStatusError          lipgloss.Style
+	SessionStatusSelStyle       lipgloss.Style
 
 	// Session title styles by state
 	SessionTitleDefault  lipgloss.Style
@@ -480,6 +482,8 @@ func initStyles() {
 	// Session status indicator styles
 	SessionStatusRunning = lipgloss.NewStyle().Foreground(ColorGreen)
 	SessionStatusWaiting = lipgloss.NewStyle().Foreground(ColorYellow)
+	SessionStatusNeedsApproval = lipgloss.NewStyle().Foreground(ColorOrange)
+	SessionStatusBudgetExceeded = lipgloss.NewStyle().Foreground(ColorRed)
 	SessionStatusIdle = lipgloss.NewStyle().Foreground(ColorTextDim)
 	SessionStatusError = lipgloss.NewStyle().Foreground(ColorRed)
 	SessionStatusSelStyle = lipgloss.NewStyle().Foreground(ColorBg).Background(ColorAccent)